@@ -2,51 +2,54 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
 	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
-
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL is not set")
-	}
-
-	ctx := context.Background()
-	dbpool, err := pgxpool.New(ctx, dbURL)
+	cfg := config.MustLoad()
+	httpclient.Configure(cfg.HTTP)
+	content.Configure(cfg.Fetch)
+
+	// Shutdown cancels the in-flight fetch or summary and stops the run.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
 	if err != nil {
 		log.Fatalf("Unable to create connection pool: %v\n", err)
 	}
 	defer dbpool.Close()
 
 	store := storage.New(dbpool)
-	aiClient := ai.NewOllamaClient()
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://ollama:11434"
-	}
+	aiClient := ai.NewOllamaClient(cfg.Ollama)
+	ollamaURL := cfg.Ollama.URL
 
 	log.Println("Catch-up Job: Fetching top 20 stories without summaries...")
 
 	// Query top 20 stories without summaries, ordered by rank
 	query := `
-		SELECT id, title, url 
+		SELECT id, title, COALESCE(url, ''), text
 		FROM stories 
-		WHERE (summary IS NULL OR summary = '') AND url != '' 
+		WHERE (summary IS NULL OR summary = '') AND (url != '' OR text != '') 
 		ORDER BY hn_rank ASC NULLS LAST 
 		LIMIT 20
 	`
@@ -60,12 +63,13 @@ func main() {
 		ID    int
 		Title string
 		URL   string
+		Text  string
 	}
 
 	var jobs []StoryJob
 	for rows.Next() {
 		var j StoryJob
-		if err := rows.Scan(&j.ID, &j.Title, &j.URL); err != nil {
+		if err := rows.Scan(&j.ID, &j.Title, &j.URL, &j.Text); err != nil {
 			log.Printf("Scan failed: %v", err)
 			continue
 		}
@@ -75,82 +79,99 @@ func main() {
 	log.Printf("Found %d stories to process.", len(jobs))
 
 	for i, job := range jobs {
+		if ctx.Err() != nil {
+			log.Println("Catch-up Job: interrupted, stopping.")
+			return
+		}
 		log.Printf("[%d/%d] Processing story %d: %s", i+1, len(jobs), job.ID, job.Title)
-		processSummary(ctx, store, aiClient, ollamaURL, job.ID, job.Title, job.URL)
+		processSummary(ctx, store, aiClient, ollamaURL, job.ID, job.Title, job.URL, job.Text)
 		// Small delay to be kind to the CPU
-		time.Sleep(2 * time.Second)
+		select {
+		case <-ctx.Done():
+		case <-time.After(2 * time.Second):
+		}
 	}
 
 	log.Println("Catch-up Job Completed.")
 }
 
-func processSummary(ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, ollamaURL string, id int, title string, url string) {
+func processSummary(ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, ollamaURL string, id int, title string, url string, text string) {
 	workCtx, cancel := context.WithTimeout(ctx, 20*time.Minute)
 	defer cancel()
 
-	fetchRes, err := content.FetchArticle(url)
-	if err != nil {
+	setSummaryStatus(ctx, store, id, storage.SummaryFetching)
+	var textContent, failure, failureDetail string
+	if url == "" {
+		textContent = content.PlainText(text)
+	} else if fetchRes, err := content.FetchArticle(workCtx, url); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
 		log.Printf("Failed to fetch content (story %d): %v", id, err)
-		return
+		failure, failureDetail = storage.ClassifyFetch(err, 0, false, 0), err.Error()
+	} else if code := storage.ClassifyFetch(nil, fetchRes.StatusCode, fetchRes.Blocked, len(fetchRes.Content)); code != "" {
+		log.Printf("Unusable article content (story %d): %s", id, code)
+		failure, failureDetail = code, fmt.Sprintf("HTTP %d, %d bytes of content", fetchRes.StatusCode, len(fetchRes.Content))
+	} else {
+		textContent = fetchRes.Content
 	}
 
-	if len(fetchRes.Content) < 100 {
-		log.Printf("Content too short (story %d)", id)
-		return
+	// Fall back to the title and top comments when the article is unusable.
+	if failure != "" {
+		textContent = fallbackSummaryInput(ctx, store, id, title)
+		if textContent == "" {
+			recordSummaryFailure(ctx, store, id, failure, failureDetail)
+			return
+		}
+		log.Printf("Summarizing story %d from its title and top comments", id)
 	}
 
-	textContent := fetchRes.Content
 	if len(textContent) > 20000 {
 		textContent = textContent[:20000] + "..."
 	}
 
-	responseStr, err := aiClient.GenerateSummary(workCtx, ollamaURL, title, textContent)
+	setSummaryStatus(ctx, store, id, storage.SummaryGenerating)
+	responseStr, err := aiClient.GenerateSummary(workCtx, ollamaURL, "", title, textContent, ai.GenerationOptions{})
 	if err != nil {
 		log.Printf("Failed to generate summary (story %d): %v", id, err)
+		recordSummaryFailure(ctx, store, id, storage.FailureLLMError, err.Error())
 		return
 	}
 
-	cleanJSON := strings.TrimSpace(responseStr)
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```json")
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```")
-	cleanJSON = strings.TrimSuffix(cleanJSON, "```")
-	cleanJSON = strings.TrimSpace(cleanJSON)
+	parsed, err := ai.ParseSummaryResponse(responseStr)
+	if err != nil {
+		log.Printf("Failed to parse summary for story %d: %v. Raw: %s", id, err, responseStr)
+		recordSummaryFailure(ctx, store, id, storage.FailureJSONParse, err.Error())
+		return
+	}
 
-	var intermediate struct {
-		Summary interface{} `json:"summary"`
-		Topics  []string    `json:"topics"`
+	if err := store.UpdateStorySummaryAndTopics(workCtx, id, parsed.Text(), parsed.Topics); err != nil {
+		log.Printf("Failed to save summary (story %d): %v", id, err)
+	} else {
+		log.Printf("Successfully saved summary for story %d", id)
 	}
+}
 
-	var result struct {
-		Summary string
-		Topics  []string
+func setSummaryStatus(ctx context.Context, store *storage.Store, id int, status string) {
+	if err := store.SetSummaryStatus(ctx, id, status); err != nil {
+		log.Printf("Failed to set summary status (story %d): %v", id, err)
 	}
+}
 
-	if err := json.Unmarshal([]byte(cleanJSON), &intermediate); err != nil {
-		log.Printf("Failed to parse JSON for story %d. Error: %v. Raw: %s", id, err, responseStr)
-		result.Summary = responseStr
-		result.Topics = []string{}
-	} else {
-		switch v := intermediate.Summary.(type) {
-		case string:
-			result.Summary = v
-		case []interface{}:
-			var parts []string
-			for _, part := range v {
-				if s, ok := part.(string); ok {
-					parts = append(parts, s)
-				}
-			}
-			result.Summary = strings.Join(parts, " ")
-		default:
-			result.Summary = fmt.Sprintf("%v", v)
-		}
-		result.Topics = intermediate.Topics
+func fallbackSummaryInput(ctx context.Context, store *storage.Store, id int, title string) string {
+	comments, err := store.GetTopComments(ctx, id, storage.FallbackComments)
+	if err != nil {
+		log.Printf("Failed to fetch top comments (story %d): %v", id, err)
+		return ""
 	}
+	if len(comments) == 0 {
+		return ""
+	}
+	return storage.FallbackSummaryInput(title, comments)
+}
 
-	if err := store.UpdateStorySummaryAndTopics(workCtx, id, result.Summary, result.Topics); err != nil {
-		log.Printf("Failed to save summary (story %d): %v", id, err)
-	} else {
-		log.Printf("Successfully saved summary for story %d", id)
+func recordSummaryFailure(ctx context.Context, store *storage.Store, id int, code, detail string) {
+	if err := store.RecordSummaryFailure(ctx, id, code, detail); err != nil {
+		log.Printf("Failed to record summary failure (story %d): %v", id, err)
 	}
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Topic digest job: run from cron / a Kubernetes CronJob (e.g. hourly). For
+// every subscription that hasn't had a digest within the user's digest
+// frequency (daily/weekly, see /api/me/notifications) it writes an AI roundup
+// of that topic's stories into the notification outbox, which the ingest
+// service's dispatcher delivers over the user's alert channels.
+func main() {
+	maxStories := flag.Int("max-stories", 10, "Maximum stories per digest")
+	dryRun := flag.Bool("dry-run", false, "Print digests instead of enqueuing them")
+	flag.Parse()
+
+	cfg := config.MustLoad()
+	httpclient.Configure(cfg.HTTP)
+
+	ctx := context.Background()
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v\n", err)
+	}
+	defer dbpool.Close()
+
+	store := storage.New(dbpool)
+	aiClient := ai.NewOllamaClient(cfg.Ollama)
+	geminiClient := ai.NewGeminiClient(cfg.AI.Gemini)
+	geminiKey := cfg.AI.Gemini.APIKey
+	ollamaURL := cfg.Ollama.URL
+
+	subs, err := store.GetDueTopicSubscriptions(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load due subscriptions: %v", err)
+	}
+	log.Printf("Digest Job: %d subscriptions due", len(subs))
+
+	// One roundup per topic and frequency, shared by all of its subscribers.
+	type digestKey struct{ topic, frequency string }
+	byKey := make(map[digestKey][]storage.TopicSubscription)
+	var keys []digestKey
+	for _, sub := range subs {
+		k := digestKey{sub.Topic, sub.Frequency}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], sub)
+	}
+	prefsByUser := make(map[string]*storage.NotificationPreferences)
+
+	provider, _ := store.GetSetting(ctx, "ai_provider")
+	if provider == "" {
+		provider = "local"
+	}
+	model, _ := store.GetSetting(ctx, "ollama_model")
+
+	now := time.Now()
+	for _, k := range keys {
+		topic, subscribers := k.topic, byKey[k]
+		since := now.Add(-storage.DigestPeriod(k.frequency))
+		stories, err := store.GetSummarizedStoriesByTopic(ctx, topic, since, *maxStories)
+		if err != nil {
+			log.Printf("Failed to load stories for topic %q: %v", topic, err)
+			continue
+		}
+
+		if len(stories) == 0 {
+			log.Printf("Topic %q: no stories this period, skipping %d subscribers", topic, len(subscribers))
+			if !*dryRun {
+				for _, sub := range subscribers {
+					if err := store.SkipTopicDigest(ctx, sub, now); err != nil {
+						log.Printf("Failed to update subscription %s/%q: %v", sub.UserID, topic, err)
+					}
+				}
+			}
+			continue
+		}
+
+		roundup, err := generateRoundup(ctx, store, aiClient, geminiClient, geminiKey, provider, ollamaURL, model, topic, stories)
+		if err != nil {
+			// Leave last_digest_at untouched so the next run retries.
+			log.Printf("Failed to generate roundup for topic %q: %v", topic, err)
+			continue
+		}
+
+		subject := fmt.Sprintf("HN Station %s: %s (%d stories)", k.frequency, topic, len(stories))
+		body := renderDigestBody(topic, k.frequency, roundup, stories)
+
+		if *dryRun {
+			fmt.Printf("=== %s ===\n%s\n", subject, body)
+			continue
+		}
+
+		for _, sub := range subscribers {
+			prefs, ok := prefsByUser[sub.UserID]
+			if !ok {
+				if prefs, err = store.GetNotificationPreferences(ctx, sub.UserID); err != nil {
+					log.Printf("Failed to load notification preferences for %s: %v", sub.UserID, err)
+					continue
+				}
+				prefsByUser[sub.UserID] = prefs
+			}
+			if err := store.EnqueueTopicDigest(ctx, sub, prefs, subject, body, now); err != nil {
+				log.Printf("Failed to enqueue digest for %s/%q: %v", sub.UserID, topic, err)
+			}
+		}
+		log.Printf("Topic %q: enqueued digest for %d subscribers", topic, len(subscribers))
+	}
+
+	log.Println("Digest Job Completed.")
+}
+
+func generateRoundup(ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, geminiClient *ai.GeminiClient, geminiKey, provider, ollamaURL, model, topic string, stories []storage.Story) (string, error) {
+	var sb strings.Builder
+	for i, st := range stories {
+		sb.WriteString(fmt.Sprintf("[%d] %s (%d points)\n%s\n\n", i+1, st.Title, st.Score, *st.Summary))
+	}
+	contextText := sb.String()
+	prompt := fmt.Sprintf("Write a short roundup (2-3 paragraphs, plain text, no markdown headings) of what Hacker News discussed about %q, based on the story summaries above. Mention stories by their [number].", topic)
+
+	workCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	var lastErr error
+	if provider == "local" || provider == "both" {
+		resp, err := aiClient.GenerateChatResponse(workCtx, ollamaURL, model, contextText, nil, prompt, ai.GenerationOptions{})
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	if provider == "gemini" || provider == "both" {
+		if geminiKey != "" {
+			resp, usage, err := geminiClient.GenerateChatResponse(workCtx, geminiKey, contextText, nil, prompt, ai.GenerationOptions{})
+			recordAIUsage(ctx, store, usage)
+			if err == nil {
+				return resp, nil
+			}
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no AI provider available for %q", provider)
+	}
+	return "", lastErr
+}
+
+func renderDigestBody(topic, frequency, roundup string, stories []storage.Story) string {
+	period := "This week"
+	if frequency == storage.DigestDaily {
+		period = "Today"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s on Hacker News: %s\n\n", period, topic))
+	sb.WriteString(strings.TrimSpace(roundup))
+	sb.WriteString("\n\nStories:\n")
+	for i, st := range stories {
+		sb.WriteString(fmt.Sprintf("[%d] %s\n    https://news.ycombinator.com/item?id=%d\n", i+1, st.Title, st.ID))
+		if st.URL != "" {
+			sb.WriteString("    " + st.URL + "\n")
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nYou're receiving this because you subscribed to %q on HN Station.\n", topic))
+	return sb.String()
+}
+
+// recordAIUsage stores a cloud AI call made on the system key with its
+// estimated cost, so admin stats include background jobs.
+func recordAIUsage(ctx context.Context, store *storage.Store, u ai.Usage) {
+	if u.PromptTokens == 0 && u.OutputTokens == 0 {
+		return
+	}
+	err := store.RecordAIUsage(ctx, storage.AIUsage{
+		Provider:     u.Provider,
+		Model:        u.Model,
+		PromptTokens: u.PromptTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      u.EstimateCost(),
+	})
+	if err != nil {
+		log.Printf("Failed to record AI usage: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// story and comment mirror the fields of the API's JSON that the client
+// uses. They're declared here rather than imported so the client only
+// depends on the public API.
+type story struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Text        string    `json:"text"`
+	Score       int       `json:"score"`
+	By          string    `json:"by"`
+	Descendants int       `json:"descendants"`
+	PostedAt    time.Time `json:"time"`
+	IsRead      *bool     `json:"is_read"`
+	IsSaved     *bool     `json:"is_saved"`
+	Summary     *string   `json:"summary"`
+	Topics      []string  `json:"topics"`
+}
+
+func (s *story) read() bool  { return s.IsRead != nil && *s.IsRead }
+func (s *story) saved() bool { return s.IsSaved != nil && *s.IsSaved }
+
+func (s *story) validate() error {
+	if s.ID <= 0 || s.Title == "" || s.PostedAt.IsZero() {
+		return fmt.Errorf("story %d is missing id, title or time", s.ID)
+	}
+	return nil
+}
+
+type comment struct {
+	ID       int64     `json:"id"`
+	By       string    `json:"by"`
+	Text     string    `json:"text"`
+	PostedAt time.Time `json:"time"`
+	Depth    int       `json:"depth"`
+	Muted    bool      `json:"muted"`
+}
+
+func (c *comment) validate() error {
+	if c.ID <= 0 || c.Depth < 0 {
+		return fmt.Errorf("comment %d has an invalid id or depth", c.ID)
+	}
+	return nil
+}
+
+// client calls the HN Station API. Without a token it's an anonymous
+// visitor; the cookie jar keeps its read and saved state for the session.
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(baseURL, token string) *client {
+	jar, _ := cookiejar.New(nil)
+	return &client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second, Jar: jar},
+	}
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out, if given.
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		return fmt.Errorf("%s %s: unexpected content type %q", method, path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", method, path, err)
+	}
+	return nil
+}
+
+// stories fetches a page of the front page, or of an HN list if feed is set.
+func (c *client) stories(ctx context.Context, feed string, limit, offset int) ([]story, int, error) {
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	if feed != "" {
+		q.Set("feed", feed)
+	}
+	var resp struct {
+		Stories []story `json:"stories"`
+		Total   *int    `json:"total"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/stories?"+q.Encode(), nil, &resp); err != nil {
+		return nil, 0, err
+	}
+	if resp.Stories == nil || resp.Total == nil {
+		return nil, 0, fmt.Errorf("GET /api/stories: response is missing stories or total")
+	}
+	for i := range resp.Stories {
+		if err := resp.Stories[i].validate(); err != nil {
+			return nil, 0, fmt.Errorf("GET /api/stories: %w", err)
+		}
+	}
+	return resp.Stories, *resp.Total, nil
+}
+
+// storyDetails fetches a story with up to threads top-level comment threads.
+func (c *client) storyDetails(ctx context.Context, id int64, threads int) (*story, []comment, error) {
+	path := fmt.Sprintf("/api/stories/%d?comment_limit=%d", id, threads)
+	var resp struct {
+		Story    *story    `json:"story"`
+		Comments []comment `json:"comments"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, nil, err
+	}
+	if resp.Story == nil || resp.Comments == nil {
+		return nil, nil, fmt.Errorf("GET %s: response is missing story or comments", path)
+	}
+	if err := resp.Story.validate(); err != nil {
+		return nil, nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	for i := range resp.Comments {
+		if err := resp.Comments[i].validate(); err != nil {
+			return nil, nil, fmt.Errorf("GET %s: %w", path, err)
+		}
+	}
+	return resp.Story, resp.Comments, nil
+}
+
+// interact sets a story's read or saved flag; nil leaves a flag unchanged.
+func (c *client) interact(ctx context.Context, id int64, read, saved *bool) error {
+	body := map[string]*bool{}
+	if read != nil {
+		body["read"] = read
+	}
+	if saved != nil {
+		body["saved"] = saved
+	}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/stories/%d/interact", id), body, nil)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Terminal client for a running HN Station instance. Browses stories, shows
+// summaries and comments, and marks stories read or saved, all through the
+// public API.
+//
+// usage: go run ./cmd/hncli -base https://hnstation.dev
+//        HNS_TOKEN=hns_... go run ./cmd/hncli   (as a signed-in user)
+
+func main() {
+	base := flag.String("base", envOr("HNS_URL", "http://localhost:8080"), "HN Station base URL")
+	token := flag.String("token", os.Getenv("HNS_TOKEN"), "API key (hns_...) to act as a signed-in user")
+	feed := flag.String("feed", "", "initial list: new, best, ask, show or jobs (default front page)")
+	flag.Parse()
+
+	m := newModel(newClient(*base, *token), *feed)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "hncli:", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+)
+
+const (
+	// storiesPerPage and commentThreads size what one request loads.
+	storiesPerPage = 30
+	commentThreads = 30
+	// maxIndent caps comment indentation so deep threads stay readable.
+	maxIndent = 10
+)
+
+// feeds are the lists f cycles through; "" is the front page.
+var feeds = []string{"", "new", "best", "ask", "show", "jobs"}
+
+type view int
+
+const (
+	listView view = iota
+	storyView
+)
+
+type storiesMsg struct {
+	stories []story
+	total   int
+	err     error
+}
+
+type storyMsg struct {
+	story    *story
+	comments []comment
+	err      error
+}
+
+type interactMsg struct {
+	id          int64
+	read, saved *bool
+	err         error
+}
+
+type model struct {
+	client *client
+
+	feed    string
+	page    int
+	stories []story
+	total   int
+	cursor  int
+
+	view     view
+	story    *story
+	comments []comment
+	lines    []string // the story view, wrapped to width
+	scroll   int
+
+	width, height int
+	loading       bool
+	status        string // the last error, shown in the footer
+}
+
+func newModel(c *client, feed string) model {
+	return model{client: c, feed: feed, width: 80, height: 24, loading: true}
+}
+
+func (m model) Init() tea.Cmd {
+	return m.loadStories()
+}
+
+func (m model) loadStories() tea.Cmd {
+	feed, offset := m.feed, m.page*storiesPerPage
+	return func() tea.Msg {
+		stories, total, err := m.client.stories(context.Background(), feed, storiesPerPage, offset)
+		return storiesMsg{stories: stories, total: total, err: err}
+	}
+}
+
+func (m model) loadStory(id int64) tea.Cmd {
+	return func() tea.Msg {
+		st, comments, err := m.client.storyDetails(context.Background(), id, commentThreads)
+		return storyMsg{story: st, comments: comments, err: err}
+	}
+}
+
+func (m model) setFlags(id int64, read, saved *bool) tea.Cmd {
+	return func() tea.Msg {
+		err := m.client.interact(context.Background(), id, read, saved)
+		return interactMsg{id: id, read: read, saved: saved, err: err}
+	}
+}
+
+// selected is the story the read and saved keys act on.
+func (m *model) selected() *story {
+	if m.view == storyView {
+		return m.story
+	}
+	if m.cursor < len(m.stories) {
+		return &m.stories[m.cursor]
+	}
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.story != nil {
+			m.lines = renderStory(m.story, m.comments, m.width)
+		}
+
+	case storiesMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		m.stories, m.total, m.status = msg.stories, msg.total, ""
+		m.cursor = min(m.cursor, max(len(m.stories)-1, 0))
+
+	case storyMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		m.story, m.comments, m.status = msg.story, msg.comments, ""
+		m.view, m.scroll = storyView, 0
+		m.lines = renderStory(m.story, m.comments, m.width)
+		if !m.story.read() {
+			read := true
+			return m, m.setFlags(m.story.ID, &read, nil)
+		}
+
+	case interactMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		apply := func(st *story) {
+			if st == nil || st.ID != msg.id {
+				return
+			}
+			if msg.read != nil {
+				st.IsRead = msg.read
+			}
+			if msg.saved != nil {
+				st.IsSaved = msg.saved
+			}
+		}
+		for i := range m.stories {
+			apply(&m.stories[i])
+		}
+		apply(m.story)
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.loading {
+		return m, nil
+	}
+
+	switch key {
+	case "r":
+		if st := m.selected(); st != nil {
+			read := !st.read()
+			return m, m.setFlags(st.ID, &read, nil)
+		}
+		return m, nil
+	case "s":
+		if st := m.selected(); st != nil {
+			saved := !st.saved()
+			return m, m.setFlags(st.ID, nil, &saved)
+		}
+		return m, nil
+	}
+
+	if m.view == storyView {
+		page := max(m.height-2, 1)
+		switch key {
+		case "q", "esc", "backspace", "h", "left":
+			m.view, m.story, m.lines = listView, nil, nil
+		case "j", "down":
+			m.scroll++
+		case "k", "up":
+			m.scroll--
+		case " ", "pgdown", "f":
+			m.scroll += page
+		case "b", "pgup":
+			m.scroll -= page
+		case "g", "home":
+			m.scroll = 0
+		case "G", "end":
+			m.scroll = len(m.lines)
+		}
+		m.scroll = max(min(m.scroll, len(m.lines)-page), 0)
+		return m, nil
+	}
+
+	switch key {
+	case "q":
+		return m, tea.Quit
+	case "j", "down":
+		m.cursor = min(m.cursor+1, max(len(m.stories)-1, 0))
+	case "k", "up":
+		m.cursor = max(m.cursor-1, 0)
+	case "enter", "l", "right":
+		if st := m.selected(); st != nil {
+			m.loading = true
+			return m, m.loadStory(st.ID)
+		}
+	case "n":
+		if (m.page+1)*storiesPerPage < m.total {
+			m.page, m.cursor, m.loading = m.page+1, 0, true
+			return m, m.loadStories()
+		}
+	case "p":
+		if m.page > 0 {
+			m.page, m.cursor, m.loading = m.page-1, 0, true
+			return m, m.loadStories()
+		}
+	case "f":
+		for i, f := range feeds {
+			if f == m.feed {
+				m.feed = feeds[(i+1)%len(feeds)]
+				break
+			}
+		}
+		m.page, m.cursor, m.loading = 0, 0, true
+		return m, m.loadStories()
+	case "g":
+		m.loading = true
+		return m, m.loadStories()
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	if m.view == storyView {
+		return m.storyView()
+	}
+	return m.listView()
+}
+
+func (m model) listView() string {
+	var b strings.Builder
+	name := m.feed
+	if name == "" {
+		name = "front page"
+	}
+	fmt.Fprintf(&b, "HN Station: %s (page %d)\n\n", name, m.page+1)
+
+	// Each story takes two lines; scroll so the cursor stays visible.
+	visible := max((m.height-4)/2, 1)
+	start := max(m.cursor-visible+1, 0)
+	for i := start; i < len(m.stories) && i < start+visible; i++ {
+		st := &m.stories[i]
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		flags := "  "
+		if !st.read() {
+			flags = "• "
+		}
+		if st.saved() {
+			flags = flags[:len(flags)-1] + "*"
+		}
+		title := st.Title
+		if host := hostOf(st.URL); host != "" {
+			title += " (" + host + ")"
+		}
+		prefix := fmt.Sprintf("%s%s%3d. ", cursor, flags, m.page*storiesPerPage+i+1)
+		width := utf8.RuneCountInString(prefix)
+		fmt.Fprintf(&b, "%s%s\n", prefix, truncate(title, m.width-width))
+		fmt.Fprintf(&b, "%s%d points by %s %s | %d comments\n", strings.Repeat(" ", width), st.Score, st.By, timeAgo(st.PostedAt), st.Descendants)
+	}
+	if len(m.stories) == 0 && !m.loading {
+		b.WriteString("No stories.\n")
+	}
+	b.WriteString("\n" + m.footer("enter open · r read · s save · n/p page · f feed · g refresh · q quit"))
+	return b.String()
+}
+
+func (m model) storyView() string {
+	var b strings.Builder
+	end := min(m.scroll+max(m.height-2, 1), len(m.lines))
+	for _, l := range m.lines[m.scroll:end] {
+		b.WriteString(l + "\n")
+	}
+	b.WriteString("\n" + m.footer("j/k scroll · space/b page · r read · s save · esc back"))
+	return b.String()
+}
+
+func (m model) footer(help string) string {
+	switch {
+	case m.loading:
+		return "Loading..."
+	case m.status != "":
+		return "Error: " + truncate(m.status, m.width-7)
+	}
+	return help
+}
+
+// renderStory lays out a story, its summary and its comments as lines of at
+// most width columns.
+func renderStory(st *story, comments []comment, width int) []string {
+	width = max(width, 20)
+	var lines []string
+	lines = append(lines, wrap(st.Title, width)...)
+	if st.URL != "" {
+		lines = append(lines, st.URL)
+	}
+	lines = append(lines, fmt.Sprintf("%d points by %s %s | %d comments", st.Score, st.By, timeAgo(st.PostedAt), st.Descendants), "")
+
+	if st.Summary != nil && *st.Summary != "" {
+		lines = append(lines, "Summary")
+		for _, l := range strings.Split(*st.Summary, "\n") {
+			l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "-•*"))
+			if l == "" {
+				continue
+			}
+			for i, w := range wrap(l, width-4) {
+				marker := "    "
+				if i == 0 {
+					marker = "  - "
+				}
+				lines = append(lines, marker+w)
+			}
+		}
+		if len(st.Topics) > 0 {
+			lines = append(lines, "  Topics: "+strings.Join(st.Topics, ", "))
+		}
+		lines = append(lines, "")
+	}
+	for _, p := range paragraphs(st.Text) {
+		lines = append(lines, wrap(p, width)...)
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, fmt.Sprintf("Comments (%d)", st.Descendants), "")
+	for _, c := range comments {
+		indent := strings.Repeat(" ", 2*min(c.Depth, maxIndent))
+		lines = append(lines, fmt.Sprintf("%s%s %s", indent, c.By, timeAgo(c.PostedAt)))
+		if c.Muted {
+			lines = append(lines, indent+"[muted]", "")
+			continue
+		}
+		for _, p := range paragraphs(c.Text) {
+			for _, w := range wrap(p, width-len(indent)) {
+				lines = append(lines, indent+w)
+			}
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// paragraphs splits HN's HTML text into plain-text paragraphs.
+func paragraphs(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, "<p>") {
+		if p = strings.TrimSpace(content.PlainText(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// wrap word-wraps text to lines of at most width columns.
+func wrap(text string, width int) []string {
+	width = max(width, 10)
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width < 4 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-3]) + "..."
+}
+
+// timeAgo formats t relative to now, HN style.
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute") + " ago"
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour") + " ago"
+	default:
+		return plural(int(d.Hours()/24), "day") + " ago"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// hostOf returns the host of a story URL without "www.", or "".
+func hostOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// summaryDeferDelay is how long a deferred job waits, so jobs queued after it
+// for higher-ranked stories are claimed first.
+const summaryDeferDelay = 30 * time.Minute
+
+// summaryBackpressure decides which summary jobs an ingestion cycle queues
+// while the summary queue is backed up. Past maxDepth pending and running
+// jobs, stories below the front page's top keepRank are deferred if they have
+// no summary yet and shed if they would only refresh one. Decisions are
+// recorded for the admin dashboard.
+type summaryBackpressure struct {
+	maxDepth int // 0 disables backpressure
+	keepRank int
+
+	depth    atomic.Int64 // the backlog at the start of the cycle plus jobs queued since
+	shed     atomic.Int64
+	deferred atomic.Int64
+}
+
+// newSummaryBackpressure applies cfg's summary queue limits.
+func newSummaryBackpressure(cfg config.Ingest) *summaryBackpressure {
+	return &summaryBackpressure{
+		maxDepth: cfg.QueueMaxDepth,
+		keepRank: cfg.QueueKeepRank,
+	}
+}
+
+// reset starts a cycle from the queue's current backlog.
+func (b *summaryBackpressure) reset(ctx context.Context, store *storage.Store) {
+	b.shed.Store(0)
+	b.deferred.Store(0)
+	if b.maxDepth == 0 {
+		return
+	}
+	depth, err := store.SummaryQueueDepth(ctx)
+	if err != nil {
+		log.Printf("Failed to measure the summary queue, assuming it's empty: %v", err)
+	}
+	b.depth.Store(int64(depth))
+}
+
+// decide returns "" to queue a story's job now, storage.QueueDeferred to
+// queue it behind the backlog or storage.QueueShed to skip it. rank is the
+// story's front-page rank, nil if it's not on the front page.
+func (b *summaryBackpressure) decide(rank *int, hasSummary bool) string {
+	if b.maxDepth == 0 || b.depth.Load() < int64(b.maxDepth) {
+		return ""
+	}
+	if rank != nil && *rank <= b.keepRank {
+		return ""
+	}
+	if hasSummary {
+		return storage.QueueShed
+	}
+	return storage.QueueDeferred
+}
+
+// queued counts a job queued this cycle.
+func (b *summaryBackpressure) queued() {
+	b.depth.Add(1)
+}
+
+// record counts and stores a shed or deferral.
+func (b *summaryBackpressure) record(ctx context.Context, store *storage.Store, storyID int, decision string, rank *int) {
+	if decision == storage.QueueShed {
+		b.shed.Add(1)
+	} else {
+		b.deferred.Add(1)
+	}
+	if err := store.RecordSummaryQueueEvent(ctx, storyID, decision, int(b.depth.Load()), rank); err != nil {
+		log.Printf("Failed to record summary queue event (story %d): %v", storyID, err)
+	}
+}
+
+// report logs the cycle's decisions, if there were any.
+func (b *summaryBackpressure) report() {
+	shed, deferred := b.shed.Load(), b.deferred.Load()
+	if shed == 0 && deferred == 0 {
+		return
+	}
+	log.Printf("Summary queue backed up (%d jobs, limit %d): deferred %d and shed %d jobs for stories below front-page rank %d",
+		b.depth.Load(), b.maxDepth, deferred, shed, b.keepRank)
+}
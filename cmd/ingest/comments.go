@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// commentWorkers bounds concurrent comment fetches from HN per run.
+	commentWorkers = 16
+	// userWorkers bounds concurrent profile fetches from HN per run.
+	userWorkers = 4
+	// commentBatchSize is how many comments are saved per round trip.
+	commentBatchSize = 100
+	// commentFlushInterval bounds how long a partial batch waits.
+	commentFlushInterval = time.Second
+)
+
+// queue is an unbounded FIFO feeding a fixed set of workers. Workers can push
+// follow-up work without blocking on each other.
+type queue[T any] struct {
+	in  chan []T
+	out chan T
+}
+
+func newQueue[T any]() *queue[T] {
+	q := &queue[T]{in: make(chan []T), out: make(chan T)}
+	go q.run()
+	return q
+}
+
+func (q *queue[T]) run() {
+	var items []T
+	for {
+		var out chan T
+		var next T
+		if len(items) > 0 {
+			out, next = q.out, items[0]
+		}
+		select {
+		case batch, ok := <-q.in:
+			if !ok {
+				close(q.out)
+				return
+			}
+			items = append(items, batch...)
+		case out <- next:
+			items = items[1:]
+		}
+	}
+}
+
+func (q *queue[T]) push(items ...T) { q.in <- items }
+
+// close stops the queue once its remaining items have been taken; workers'
+// range loops over out then end.
+func (q *queue[T]) close() { close(q.in) }
+
+// commentRef is a comment to fetch.
+type commentRef struct {
+	id      int
+	storyID int64
+	// onlyNewKids skips replies that are already stored, for comments
+	// refetched because HN reported them as changed.
+	onlyNewKids bool
+}
+
+// commentPipeline ingests comment trees and their authors' profiles for one
+// ingestion run. A fixed pool of workers walks the trees breadth-first, each
+// author is fetched at most once per run, and comments are saved in batches
+// by a single writer so parents are always saved before their replies.
+type commentPipeline struct {
+	client *hn.Client
+	store  *storage.Store
+
+	comments *queue[commentRef]
+	pending  sync.WaitGroup // comments queued but not yet processed
+	workers  sync.WaitGroup
+
+	writes  chan storage.Comment
+	written chan struct{}
+
+	users       *queue[string]
+	usersMu     sync.Mutex
+	seenUsers   map[string]bool
+	userWorkers sync.WaitGroup
+
+	saved, refreshed, failures atomic.Int64
+}
+
+func newCommentPipeline(ctx context.Context, client *hn.Client, store *storage.Store) *commentPipeline {
+	p := &commentPipeline{
+		client:    client,
+		store:     store,
+		comments:  newQueue[commentRef](),
+		writes:    make(chan storage.Comment, commentBatchSize),
+		written:   make(chan struct{}),
+		users:     newQueue[string](),
+		seenUsers: make(map[string]bool),
+	}
+	for i := 0; i < commentWorkers; i++ {
+		p.workers.Add(1)
+		go func() {
+			defer p.workers.Done()
+			for ref := range p.comments.out {
+				p.fetchComment(ctx, ref)
+				p.pending.Done()
+			}
+		}()
+	}
+	for i := 0; i < userWorkers; i++ {
+		p.userWorkers.Add(1)
+		go func() {
+			defer p.userWorkers.Done()
+			for username := range p.users.out {
+				if ctx.Err() != nil {
+					continue
+				}
+				if processUser(ctx, p.client, p.store, username) {
+					p.refreshed.Add(1)
+				} else {
+					p.failures.Add(1)
+				}
+			}
+		}()
+	}
+	go p.writeComments(ctx)
+	return p
+}
+
+// AddStory queues a story's author and comment tree. With onlyNew set,
+// top-level comments that are already stored are skipped.
+func (p *commentPipeline) AddStory(ctx context.Context, item *hn.Item, onlyNew bool) {
+	p.AddUser(item.By)
+	p.addKids(ctx, item.Kids, int64(item.ID), onlyNew)
+}
+
+// SyncComment queues a stored comment to be refetched, along with any of its
+// replies that aren't stored yet.
+func (p *commentPipeline) SyncComment(id int, storyID int64) {
+	p.pending.Add(1)
+	p.comments.push(commentRef{id: id, storyID: storyID, onlyNewKids: true})
+}
+
+// AddUser queues an HN profile to be refreshed, once per run.
+func (p *commentPipeline) AddUser(username string) {
+	if username == "" {
+		return
+	}
+	p.usersMu.Lock()
+	seen := p.seenUsers[username]
+	p.seenUsers[username] = true
+	p.usersMu.Unlock()
+	if !seen {
+		p.users.push(username)
+	}
+}
+
+// Close waits for every queued comment and profile to be fetched and saved.
+func (p *commentPipeline) Close() {
+	p.pending.Wait()
+	p.comments.close()
+	p.workers.Wait()
+	close(p.writes)
+	<-p.written
+	p.users.close()
+	p.userWorkers.Wait()
+	log.Printf("Comments: %d saved, %d profiles refreshed, %d failures", p.saved.Load(), p.refreshed.Load(), p.failures.Load())
+}
+
+func (p *commentPipeline) addKids(ctx context.Context, kids []int, storyID int64, onlyNew bool) {
+	if onlyNew && len(kids) > 0 {
+		if known, err := p.store.KnownItems(ctx, kids); err != nil {
+			log.Printf("Failed to look up stored comments (story %d): %v", storyID, err)
+		} else {
+			var unseen []int
+			for _, id := range kids {
+				if _, ok := known[id]; !ok {
+					unseen = append(unseen, id)
+				}
+			}
+			kids = unseen
+		}
+	}
+	if len(kids) == 0 {
+		return
+	}
+
+	refs := make([]commentRef, len(kids))
+	for i, id := range kids {
+		refs[i] = commentRef{id: id, storyID: storyID}
+	}
+	p.pending.Add(len(refs))
+	p.comments.push(refs...)
+}
+
+func (p *commentPipeline) fetchComment(ctx context.Context, ref commentRef) {
+	if ctx.Err() != nil {
+		return
+	}
+	item, err := p.client.GetItem(ctx, ref.id)
+	if err != nil {
+		log.Printf("Failed to fetch comment %d: %v", ref.id, err)
+		p.failures.Add(1)
+		return
+	}
+	if item.Type != "comment" || item.Deleted || item.Dead {
+		return
+	}
+
+	var parentID *int64
+	if int64(item.Parent) != ref.storyID {
+		pID := int64(item.Parent)
+		parentID = &pID
+	}
+	// Queued before any reply is fetched, so the writer sees it first.
+	p.writes <- storage.Comment{
+		ID:       int64(item.ID),
+		StoryID:  ref.storyID,
+		ParentID: parentID,
+		Text:     item.Text,
+		By:       item.By,
+		PostedAt: time.Unix(item.Time, 0),
+	}
+	p.AddUser(item.By)
+	// Replies to a new comment are new too.
+	p.addKids(ctx, item.Kids, ref.storyID, ref.onlyNewKids)
+}
+
+// writeComments saves queued comments in batches, in the order queued.
+func (p *commentPipeline) writeComments(ctx context.Context) {
+	defer close(p.written)
+	ticker := time.NewTicker(commentFlushInterval)
+	defer ticker.Stop()
+
+	var batch []storage.Comment
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.store.UpsertComments(ctx, batch); err != nil {
+			// Save one by one so a single bad comment doesn't lose the rest.
+			log.Printf("Failed to save %d comments in a batch, retrying individually: %v", len(batch), err)
+			for _, c := range batch {
+				if err := p.store.UpsertComment(ctx, c); err != nil {
+					log.Printf("Failed to upsert comment %d: %v", c.ID, err)
+					p.failures.Add(1)
+					continue
+				}
+				p.saved.Add(1)
+			}
+		} else {
+			p.saved.Add(int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case c, ok := <-p.writes:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, c)
+			if len(batch) >= commentBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
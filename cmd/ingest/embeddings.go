@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// updateEmbeddings embeds the stories that have no embedding from embedder's
+// model, or whose title, summary or article changed since theirs was
+// computed. It gives up for the cycle on the first embedding failure, since
+// the embedding model is then most likely unreachable.
+func updateEmbeddings(ctx context.Context, store *storage.Store, embedder ai.Embedder, limit int) {
+	if embedder == nil || limit == 0 {
+		return
+	}
+	stories, err := store.StoriesNeedingEmbedding(ctx, embedder.Model(), limit)
+	if err != nil {
+		log.Printf("Failed to find stories to embed: %v", err)
+		return
+	}
+	if len(stories) == 0 {
+		return
+	}
+
+	embedded := 0
+	for _, st := range stories {
+		if ctx.Err() != nil {
+			break
+		}
+		vec, err := embedder.EmbedDocument(ctx, st.Text)
+		if err != nil {
+			log.Printf("Failed to embed story %d, skipping embeddings this cycle: %v", st.ID, err)
+			break
+		}
+		if err := store.SetStoryEmbedding(ctx, st.ID, vec, embedder.Model(), st.Hash); err != nil {
+			log.Printf("Failed to save embedding (story %d): %v", st.ID, err)
+			continue
+		}
+		embedded++
+	}
+	log.Printf("Embedded %d of %d stories with %s", embedded, len(stories), embedder.Model())
+}
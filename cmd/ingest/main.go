@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -13,33 +13,73 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
 	"github.com/rajeshkumarblr/hn_station/internal/content"
 	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/notify"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
-const (
-	WorkerCount  = 3
-	TotalStories = 20 // Only keep top 20 front-page stories
-)
+// parseFeeds splits a comma-separated feed list, dropping "top" (always
+// ingested) and duplicates.
+func parseFeeds(spec string) ([]string, error) {
+	var feeds []string
+	seen := map[string]bool{hn.FeedTop: true}
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" || f == "none" || seen[f] {
+			continue
+		}
+		if !hn.IsFeed(f) {
+			return nil, fmt.Errorf("unknown HN feed %q", f)
+		}
+		seen[f] = true
+		feeds = append(feeds, f)
+	}
+	return feeds, nil
+}
+
+// storyWorkers sizes the fetch worker pool for a cycle of n stories: one
+// worker per 4 stories, between 2 and 32.
+func storyWorkers(n int) int {
+	return min(max(n/4, 2), 32)
+}
 
 func main() {
-	// Parse CLI flags
-	interval := flag.Duration("interval", 1*time.Minute, "Interval between ingestion runs (e.g. 5m, 1h)")
+	// Parse CLI flags; the ingest settings given override the configuration.
+	defaults := config.Default().Ingest
+	interval := flag.Duration("interval", defaults.Interval, "Interval between ingestion runs, e.g. 5m or 1h (overrides ingest.interval)")
 	oneShot := flag.Bool("one-shot", false, "Run once and exit")
+	budget := flag.Duration("budget", 0, "Time budget for a one-shot run, e.g. as a scheduled serverless job (implies -one-shot); summary jobs left when it runs out are picked up by the next run")
+	fullSync := flag.Duration("full-sync", defaults.FullSync, "How often to refetch every story and comment tree; runs in between only refetch items HN reports as changed (0 = every run; overrides ingest.full_sync)")
+	storyCount := flag.Int("stories", defaults.Stories, "Number of front-page stories to ingest, up to 500 (overrides ingest.stories)")
+	feedsFlag := flag.String("feeds", defaults.Feeds, "Comma-separated HN lists to ingest besides the front page: new, best, ask, show, jobs (overrides ingest.feeds)")
 	flag.Parse()
 
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, relying on environment variables")
+	cfg := config.MustLoad()
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "interval":
+			cfg.Ingest.Interval = *interval
+		case "full-sync":
+			cfg.Ingest.FullSync = *fullSync
+		case "stories":
+			cfg.Ingest.Stories = min(*storyCount, config.MaxStoryCount)
+		case "feeds":
+			cfg.Ingest.Feeds = *feedsFlag
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
+	httpclient.Configure(cfg.HTTP)
+	content.Configure(cfg.Fetch)
 
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL is not set")
+	feeds, err := parseFeeds(cfg.Ingest.Feeds)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -54,8 +94,24 @@ func main() {
 		cancel()
 	}()
 
+	if *budget > 0 {
+		*oneShot = true
+		var cancelBudget context.CancelFunc
+		ctx, cancelBudget = context.WithTimeout(ctx, *budget)
+		defer cancelBudget()
+	}
+
 	// Connect to database
-	dbpool, err := pgxpool.New(ctx, dbURL)
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
 	if err != nil {
 		log.Fatalf("Unable to create connection pool: %v\n", err)
 	}
@@ -63,21 +119,45 @@ func main() {
 
 	store := storage.New(dbpool)
 	client := hn.NewClient()
-	aiClient := ai.NewOllamaClient()
+	aiClient := ai.NewOllamaClient(cfg.Ollama)
 
-	disableAI := os.Getenv("DISABLE_AI") == "true"
+	disableAI := cfg.Ingest.DisableAI
 	if disableAI {
-		log.Println("AI features are EXPLICITLY DISABLED via DISABLE_AI env var")
+		log.Println("AI features are EXPLICITLY DISABLED via ingest.disable_ai")
 	}
 
-	log.Printf("Starting Ingestion Service (Interval: %v, One-shot: %v)...", *interval, *oneShot)
+	log.Printf("Starting Ingestion Service (Interval: %v, One-shot: %v, Stories: %d, Feeds: %s)...", cfg.Ingest.Interval, *oneShot, cfg.Ingest.Stories, strings.Join(append([]string{hn.FeedTop}, feeds...), ","))
 
 	// Start Summary Workers
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://localhost:11434"
+	ollamaURL := cfg.Ollama.URL
+
+	// Summary jobs pick their providers from the ai_provider setting they
+	// were queued with.
+	aiProviders := ai.ProvidersFromConfig(aiClient, cfg)
+	if cfg.AI.Fake {
+		aiProviders = ai.FakeProviders(ai.FakeOptions{Latency: cfg.AI.FakeLatency, FailureRate: cfg.AI.FakeFailureRate})
+		log.Println("AI: using the fake provider; summaries are canned")
+	}
+	languages := ai.LanguagePolicyFromConfig(cfg.AI)
+	backpressure := newSummaryBackpressure(cfg.Ingest)
+	geminiKey := cfg.AI.Gemini.APIKey
+
+	// Story embeddings for semantic search, from ai.embeddings.
+	var embedder ai.Embedder
+	if !disableAI {
+		embedder = ai.EmbedderFromConfig(aiClient, cfg)
+		if cfg.AI.Fake && embedder != nil {
+			embedder = ai.FakeEmbedder{}
+		}
+	}
+	embedBatch := cfg.Ingest.EmbeddingBatch
+
+	// Keyword-flagged sensitive stories get a model's second opinion if
+	// ingest.sensitive_check is on.
+	sensitiveBatch := 0
+	if !disableAI && cfg.Ingest.SensitiveCheck {
+		sensitiveBatch = cfg.Ingest.SensitiveBatch
 	}
-	summaryQueue := make(chan SummaryJob, 100)
 
 	// Create a shared rate limiter for Ollama
 	// 500ms interval for faster local processing
@@ -85,218 +165,383 @@ func main() {
 	defer limiter.Stop()
 
 	var workerWg sync.WaitGroup
-	// 5 workers for local power
-	for i := 0; i < 5; i++ {
+	// drained is closed in one-shot mode to let workers exit once the queue
+	// is empty.
+	drained := make(chan struct{})
+	for i := 0; i < cfg.Ingest.SummaryWorkers; i++ {
 		workerWg.Add(1)
 		go func(workerID int) {
 			defer workerWg.Done()
-			startWorker(workerID, ctx, store, aiClient, ollamaURL, summaryQueue, limiter)
+			startWorker(workerID, ctx, store, aiProviders, geminiKey, languages, limiter, drained)
 		}(i)
 	}
 
+	// Notification outbox dispatcher (webhooks, email, push)
+	var dispatcher *notify.Dispatcher
+	var dispatcherWg sync.WaitGroup
+	if cfg.Ingest.Dispatcher {
+		dispatcher = notify.NewDispatcherFromConfig(store, cfg.Notify)
+		if !*oneShot {
+			dispatcherWg.Add(1)
+			go func() {
+				defer dispatcherWg.Done()
+				dispatcher.Run(ctx)
+			}()
+		}
+	}
+
+	// Full syncs refetch everything; runs in between only fetch new stories
+	// and the items HN's updates feed reports as changed.
+	var lastFullSync time.Time
+	if *budget > 0 {
+		// Budgeted runs are scheduled invocations; earlier ones recorded
+		// when the last full sync happened.
+		if lastFullSync, err = store.LastFullSync(ctx); err != nil {
+			log.Printf("Failed to look up the last full sync: %v", err)
+		}
+	}
+	ingest := func() {
+		full := (*oneShot && *budget == 0) || cfg.Ingest.FullSync <= 0 || time.Since(lastFullSync) >= cfg.Ingest.FullSync
+		if full {
+			lastFullSync = time.Now()
+		}
+		runID, err := store.StartIngestRun(ctx, full)
+		if err != nil {
+			log.Println(err)
+		}
+		runIngestion(ctx, client, store, aiClient, ollamaURL, disableAI, cfg.Ingest.Stories, feeds, full, backpressure, embedder, embedBatch)
+		checkSensitiveStories(ctx, store, aiProviders, geminiKey, sensitiveBatch)
+		notifyStoryWatches(ctx, store)
+		snapshotMetrics(ctx, store)
+		if runID != 0 {
+			status := storage.RunDone
+			if ctx.Err() != nil {
+				status = storage.RunTimedOut
+			}
+			finishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := store.FinishIngestRun(finishCtx, runID, status); err != nil {
+				log.Printf("Failed to record ingest run %d: %v", runID, err)
+			}
+			cancel()
+		}
+	}
+
 	// Run initially
-	runIngestion(ctx, client, store, aiClient, summaryQueue, disableAI)
+	ingest()
 
 	if *oneShot {
 		log.Println("One-shot mode: waiting for summary queue to drain...")
-		close(summaryQueue)
+		close(drained)
 		workerWg.Wait()
+		if *budget > 0 && ctx.Err() != nil {
+			log.Printf("One-shot mode: %v budget used up; remaining summary jobs are left for the next run", *budget)
+			return
+		}
+		if dispatcher != nil {
+			log.Println("One-shot mode: flushing notification outbox...")
+			for {
+				n, err := dispatcher.RunOnce(ctx)
+				if err != nil {
+					log.Printf("Notification dispatcher: %v", err)
+					break
+				}
+				if n == 0 {
+					break
+				}
+			}
+		}
 		log.Println("One-shot run completed.")
 		return
 	}
 
 	// Ticker for periodic updates
-	ticker := time.NewTicker(*interval)
+	ticker := time.NewTicker(cfg.Ingest.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("Shutting down ingestion service...")
-			close(summaryQueue)
 			workerWg.Wait()
+			dispatcherWg.Wait()
 			return
 		case <-ticker.C:
-			runIngestion(ctx, client, store, aiClient, summaryQueue, disableAI)
+			ingest()
 		}
 	}
 }
 
-type SummaryJob struct {
-	ID       int
-	URL      string
-	Title    string
-	Model    string
-	Provider string
-}
+const (
+	// summaryJobLease is how long a claimed job stays with its worker; it
+	// outlasts processSummary's own timeout so only crashed workers lose jobs.
+	summaryJobLease = 15 * time.Minute
+	// summaryJobPoll is how often idle workers look for new jobs.
+	summaryJobPoll = 5 * time.Second
+	// summaryJobMaxAttempts bounds retries of a failing job.
+	summaryJobMaxAttempts = 3
+	// summaryJobReserve is the least budget left for a worker to claim
+	// another job; a job cut off by the deadline is released unfinished.
+	summaryJobReserve = time.Minute
+	// summaryJobCooldown keeps a story whose job failed for good from being
+	// re-queued on every ingestion cycle.
+	summaryJobCooldown = time.Hour
+)
 
-func startWorker(id int, ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, ollamaURL string, jobs <-chan SummaryJob, limiter *time.Ticker) {
+// startWorker claims and runs summary jobs until ctx is cancelled, or until
+// drained is closed and no job is due.
+func startWorker(id int, ctx context.Context, store *storage.Store, aiProviders ai.Providers, geminiKey string, languages ai.LanguagePolicy, limiter *time.Ticker, drained <-chan struct{}) {
 	for {
+		// Wait for tick before claiming
 		select {
 		case <-ctx.Done():
 			return
-		case job, ok := <-jobs:
-			if !ok {
+		case <-limiter.C:
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < summaryJobReserve {
+			return
+		}
+
+		job, err := store.ClaimSummaryJob(ctx, storage.JobArticle, summaryJobLease)
+		if err != nil {
+			log.Printf("Worker %d: %v", id, err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
 				return
+			case <-drained:
+				if err == nil {
+					return
+				}
+			case <-time.After(summaryJobPoll):
+			}
+			continue
+		}
+
+		err = processSummary(ctx, store, aiProviders, geminiKey, languages, job)
+		if err == nil {
+			if err := store.MarkSummaryJobDone(ctx, job.ID); err != nil {
+				log.Printf("Worker %d: failed to complete summary job %d: %v", id, job.ID, err)
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			// Shutting down or out of budget: hand the job back so the next
+			// run starts on it right away. Failing that, the lease expires.
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := store.ReleaseSummaryJob(releaseCtx, job.ID); err != nil {
+				log.Printf("Worker %d: failed to release summary job %d: %v", id, job.ID, err)
 			}
-			// Wait for tick before processing
-			<-limiter.C
-			processSummary(ctx, store, aiClient, ollamaURL, job)
+			cancel()
+			return
+		}
+		dead := job.Attempts >= summaryJobMaxAttempts || ai.IsSafetyBlocked(err)
+		retryAt := time.Now().Add(time.Duration(job.Attempts*job.Attempts) * time.Minute)
+		if dead {
+			log.Printf("Worker %d: giving up on story %d after %d attempts: %v", id, job.StoryID, job.Attempts, err)
+		}
+		if err := store.MarkSummaryJobFailed(ctx, job.ID, err.Error(), retryAt, dead); err != nil {
+			log.Printf("Worker %d: failed to record summary job %d failure: %v", id, job.ID, err)
 		}
 	}
 }
 
-func processSummary(ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, ollamaURL string, job SummaryJob) {
-	log.Printf("Processing summary for story %d: %s", job.ID, job.Title)
+// processSummary summarizes a claimed job's story. A returned error means
+// the story has no summary yet and the job should be retried. Stories in a
+// language the policy skips get no summary and aren't retried.
+func processSummary(ctx context.Context, store *storage.Store, aiProviders ai.Providers, geminiKey string, languages ai.LanguagePolicy, job *storage.SummaryJob) error {
+	log.Printf("Processing summary for story %d: %s", job.StoryID, job.Title)
 
 	// Use a new context with timeout for the actual work
 	workCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 
-	fetchRes, err := content.FetchArticle(job.URL)
-	if err != nil {
-		log.Printf("Failed to fetch content (story %d): %v", job.ID, err)
-		return
+	setSummaryStatus(ctx, store, job.StoryID, storage.SummaryFetching)
+	var textContent, failure, failureDetail, lang string
+	if job.URL == "" {
+		// Ask/Tell HN: the post body stands in for the article.
+		textContent = content.PlainText(job.Text)
+		lang = content.DetectLanguage(job.Title + "\n" + textContent)
+		if err := store.SetStoryLanguage(ctx, job.StoryID, lang); err != nil {
+			log.Printf("Failed to store language (story %d): %v", job.StoryID, err)
+		}
+	} else if fetchRes, err := content.FetchArticle(workCtx, job.URL); err != nil {
+		if ctx.Err() != nil {
+			// Shutting down or out of budget; the worker releases the job.
+			return ctx.Err()
+		}
+		log.Printf("Failed to fetch content (story %d): %v", job.StoryID, err)
+		failure, failureDetail = storage.ClassifyFetch(err, 0, false, 0), err.Error()
+	} else if code := storage.ClassifyFetch(nil, fetchRes.StatusCode, fetchRes.Blocked, len(fetchRes.Content)); code != "" {
+		log.Printf("Unusable article content (story %d): %s", job.StoryID, code)
+		failure, failureDetail = code, fmt.Sprintf("HTTP %d, %d bytes of content", fetchRes.StatusCode, len(fetchRes.Content))
+	} else {
+		if fetchRes.Archived {
+			log.Printf("Summarizing story %d from its Wayback Machine snapshot %s", job.StoryID, fetchRes.ArchiveURL)
+		}
+		textContent = fetchRes.Content
+		lang = content.DetectLanguage(textContent)
+		if err := store.SetArticleText(ctx, job.StoryID, textContent); err != nil {
+			log.Printf("Failed to store article text (story %d): %v", job.StoryID, err)
+		}
+		hash, length := content.Fingerprint(textContent)
+		markStale, _ := store.GetSetting(ctx, storage.SettingStaleOnContentChange)
+		if changed, err := store.RecordContentVersion(ctx, job.StoryID, hash, length, markStale != "false"); err != nil {
+			log.Printf("Failed to record content version (story %d): %v", job.StoryID, err)
+		} else if changed {
+			log.Printf("Article for story %d changed since it was last fetched", job.StoryID)
+		}
 	}
 
-	if len(fetchRes.Content) < 100 {
-		log.Printf("Content too short (story %d)", job.ID)
-		return
+	// Without a usable article, summarize the title and top comments instead.
+	// If there's no discussion yet the failure stands and the job is retried
+	// later.
+	if failure != "" {
+		textContent = fallbackSummaryInput(ctx, store, job.StoryID, job.Title)
+		if textContent == "" {
+			recordSummaryFailure(ctx, store, job.StoryID, failure, failureDetail)
+			return fmt.Errorf("%s: %s", failure, failureDetail)
+		}
+		log.Printf("Summarizing story %d from its title and top comments", job.StoryID)
+	}
+
+	action := languages.Action(lang)
+	if action == ai.LanguageSkip {
+		log.Printf("Skipping summary of story %d: written in %s", job.StoryID, content.LanguageName(lang))
+		recordSummaryFailure(ctx, store, job.StoryID, storage.FailureLanguage, "written in "+content.LanguageName(lang))
+		return nil
 	}
 
 	// Truncate content for Llama3 success (8k chars)
-	textContent := fetchRes.Content
 	if len(textContent) > 8000 {
 		textContent = textContent[:8000] + "..."
 	}
 
 	// ─── Summarization Logic with Fallback ───
+	setSummaryStatus(ctx, store, job.StoryID, storage.SummaryGenerating)
+	providers, summarizeErr := aiProviders.Select(job.Provider)
 	var summary string
-	var topics []string
-	var summarizeErr error
-
-	// 1. Try Local Ollama if provider is "local" or "both"
-	if job.Provider == "local" || job.Provider == "both" {
-		responseStr, err := aiClient.GenerateSummary(workCtx, ollamaURL, job.Model, job.Title, textContent)
-		if err == nil {
-			// Success with local
-			summary, _ = parseOllamaResponse(responseStr) // topics extraction? ingest workers don't use the parsed version currently
-			// Actually the worker flow expects JSON parsing like it did before.
-			// Let's stick to the worker's own parsing for now but use the fallback mechanism.
-		} else {
-			summarizeErr = err
-			log.Printf("Worker: Ollama failed for story %d: %v", job.ID, err)
-		}
-	}
-
-	// 2. Fallback to Gemini if:
-	// - Local failed OR provider is "gemini"
-	// - AND provider is "gemini" or "both"
-	// - AND we have a system gemini key (ingest works with system keys)
-	if summary == "" && (job.Provider == "gemini" || job.Provider == "both") {
-		geminiKey := os.Getenv("GEMINI_API_KEY")
-		if geminiKey != "" {
-			log.Printf("Worker: Attempting fallback/primary Gemini summarization for story %d", job.ID)
-			geminiClient := ai.NewGeminiClient() // One-off client for now
-			resp, err := geminiClient.GenerateSummary(workCtx, geminiKey, textContent)
-			if err == nil {
-				summary = resp
-			} else {
+	for _, p := range providers {
+		req := ai.SummaryRequest{Title: job.Title, Text: textContent}
+		if action == ai.LanguageTranslate {
+			req.Language = content.LanguageName(lang)
+		}
+		switch p.Name() {
+		case ai.ProviderOllama:
+			req.Model = job.Model
+		case ai.ProviderGemini:
+			// Ingest works with the system Gemini key.
+			req.APIKey = geminiKey
+		}
+		resp, usage, err := p.Summarize(workCtx, req)
+		recordAIUsage(ctx, store, usage)
+		if err != nil {
+			// A missing key shouldn't hide why an earlier provider failed.
+			if summarizeErr == nil || !errors.Is(err, ai.ErrNoAPIKey) {
 				summarizeErr = err
-				log.Printf("Worker: Gemini failed for story %d: %v", job.ID, err)
 			}
+			log.Printf("Worker: %s failed for story %d: %v", p.Name(), job.StoryID, err)
+			continue
+		}
+		if resp != "" {
+			summary = resp
+			break
 		}
 	}
 
 	if summary == "" {
-		log.Printf("Worker: All summarization attempts failed for story %d. Last error: %v", job.ID, summarizeErr)
-		return
+		log.Printf("Worker: All summarization attempts failed for story %d. Last error: %v", job.StoryID, summarizeErr)
+		detail := "no AI provider available"
+		if summarizeErr != nil {
+			detail = summarizeErr.Error()
+		}
+		code := storage.FailureLLMError
+		if ai.IsSafetyBlocked(summarizeErr) {
+			code = storage.FailureSafetyBlocked
+		}
+		recordSummaryFailure(ctx, store, job.StoryID, code, detail)
+		if summarizeErr == nil {
+			summarizeErr = errors.New(detail)
+		}
+		return summarizeErr
 	}
 
-	// ─── Post-processing for Ollama format (Bullet points) ───
-	// If it was Gemini, it already returns text. If it was Ollama, it might be raw JSON.
-	// We need to parse it if it looks like JSON.
-	finalSummary := summary
-	if strings.Contains(summary, "{") && strings.Contains(summary, "}") {
-		// Re-use parseOllamaResponse logic
-		s, t := parseOllamaResponse(summary)
-		finalSummary = s
-		topics = t
+	parsed, err := ai.ParseSummaryResponse(summary)
+	if err != nil {
+		log.Printf("Worker: Failed to parse summary for story %d: %v. Raw: %s", job.StoryID, err, summary)
+		recordSummaryFailure(ctx, store, job.StoryID, storage.FailureJSONParse, err.Error())
+		return err
 	}
+	topics := parsed.Topics
 
-	if finalSummary == "" {
-		return
+	if err := store.UpdateStorySummaryAndTopics(workCtx, job.StoryID, parsed.Text(), topics); err != nil {
+		log.Printf("Failed to save summary/topics (story %d): %v", job.StoryID, err)
+		recordSummaryFailure(ctx, store, job.StoryID, storage.FailureInternal, err.Error())
+		return err
 	}
+	log.Printf("Successfully saved summary and %d topics for story %d", len(topics), job.StoryID)
+	return nil
+}
 
-	// Ensure bullet points
-	lines := strings.Split(finalSummary, "\n")
-	var bulletPoints []string
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l == "" {
-			continue
-		}
-		if !strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "•") {
-			l = "- " + l
-		}
-		bulletPoints = append(bulletPoints, l)
+// setSummaryStatus records pipeline progress for the frontend; failures to
+// record it don't stop the pipeline.
+func setSummaryStatus(ctx context.Context, store *storage.Store, id int, status string) {
+	if err := store.SetSummaryStatus(ctx, id, status); err != nil {
+		log.Printf("Failed to set summary status (story %d): %v", id, err)
 	}
-	finalSummary = strings.Join(bulletPoints, "\n")
+}
 
-	if err := store.UpdateStorySummaryAndTopics(workCtx, job.ID, finalSummary, topics); err != nil {
-		log.Printf("Failed to save summary/topics (story %d): %v", job.ID, err)
-	} else {
-		log.Printf("Successfully saved summary and %d topics for story %d", len(topics), job.ID)
+// recordSummaryFailure stores why a story couldn't be summarized.
+func recordSummaryFailure(ctx context.Context, store *storage.Store, id int, code, detail string) {
+	if err := store.RecordSummaryFailure(ctx, id, code, detail); err != nil {
+		log.Printf("Failed to record summary failure (story %d): %v", id, err)
 	}
 }
 
-// Re-implement parseOllamaResponse here or shared? Ingest is a separate binary.
-// I'll copy it for now.
-func parseOllamaResponse(responseStr string) (string, []string) {
-	cleanJSON := strings.TrimSpace(responseStr)
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```json")
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```")
-	cleanJSON = strings.TrimSuffix(cleanJSON, "```")
-	cleanJSON = strings.TrimSpace(cleanJSON)
-
-	// Robust JSON extraction: Find first { and last }
-	firstBrace := strings.Index(cleanJSON, "{")
-	lastBrace := strings.LastIndex(cleanJSON, "}")
-	if firstBrace != -1 && lastBrace != -1 && lastBrace > firstBrace {
-		cleanJSON = cleanJSON[firstBrace : lastBrace+1]
+// recordAIUsage stores a cloud AI call made on the system key with its
+// estimated cost, so admin stats include background jobs.
+func recordAIUsage(ctx context.Context, store *storage.Store, u ai.Usage) {
+	if u.PromptTokens == 0 && u.OutputTokens == 0 {
+		return
 	}
-
-	var intermediate struct {
-		Summary interface{} `json:"summary"`
-		Topics  []string    `json:"topics"`
+	err := store.RecordAIUsage(ctx, storage.AIUsage{
+		Provider:     u.Provider,
+		Model:        u.Model,
+		PromptTokens: u.PromptTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      u.EstimateCost(),
+	})
+	if err != nil {
+		log.Printf("Failed to record AI usage: %v", err)
 	}
+}
 
-	var summary string
-	var topics []string
-
-	if err := json.Unmarshal([]byte(cleanJSON), &intermediate); err != nil {
-		summary = responseStr // Fallback
-	} else {
-		switch v := intermediate.Summary.(type) {
-		case string:
-			summary = v
-		case []interface{}:
-			var parts []string
-			for _, part := range v {
-				if s, ok := part.(string); ok {
-					parts = append(parts, s)
-				}
-			}
-			summary = strings.Join(parts, "\n")
-		default:
-			summary = fmt.Sprintf("%v", v)
+// snapshotMetrics refreshes today's metrics snapshot, and yesterday's, which
+// may have missed the last hours of the day.
+func snapshotMetrics(ctx context.Context, store *storage.Store) {
+	now := time.Now()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if err := store.SnapshotMetrics(ctx, day); err != nil {
+			log.Println(err)
 		}
-		topics = intermediate.Topics
 	}
-	return summary, topics
 }
 
-func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store, aiClient *ai.OllamaClient, summaryQueue chan<- SummaryJob, disableAI bool) {
+// fallbackSummaryInput returns the story's title and top comments to summarize
+// when its article is unusable, or "" if it has no comments yet.
+func fallbackSummaryInput(ctx context.Context, store *storage.Store, id int, title string) string {
+	comments, err := store.GetTopComments(ctx, id, storage.FallbackComments)
+	if err != nil {
+		log.Printf("Failed to fetch top comments (story %d): %v", id, err)
+		return ""
+	}
+	if len(comments) == 0 {
+		return ""
+	}
+	return storage.FallbackSummaryInput(title, comments)
+}
+
+func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store, aiClient *ai.OllamaClient, ollamaURL string, disableAI bool, storyCount int, feeds []string, full bool, backpressure *summaryBackpressure, embedder ai.Embedder, embedBatch int) {
 	log.Println("Fetching top stories from HN front page...")
 
 	// Check if AI Summaries are enabled
@@ -314,17 +559,35 @@ func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store,
 	if aiProvider == "" {
 		aiProvider = "local"
 	}
+	freshness, err := store.GetFreshnessPolicy(ctx)
+	if err != nil {
+		log.Printf("Failed to fetch summary freshness policy: %v", err)
+	}
+	backpressure.reset(ctx, store)
+
+	// Load the model while HN is being fetched so the cycle's first summary
+	// doesn't wait for it. With a long OLLAMA_KEEP_ALIVE this is a no-op
+	// after the first cycle.
+	if aiEnabled && (aiProvider == "local" || aiProvider == "both") {
+		go func() {
+			start := time.Now()
+			if err := aiClient.Preload(ctx, ollamaURL, ollamaModel); err != nil {
+				log.Printf("Failed to preload Ollama model: %v", err)
+				return
+			}
+			log.Printf("Ollama model ready (%v)", time.Since(start).Round(time.Millisecond))
+		}()
+	}
 
-	// Fetch Top Stories (Ranked) - only top 20
+	// Fetch Top Stories (Ranked)
 	topIDs, err := client.GetTopStories(ctx)
 	if err != nil {
 		log.Printf("Failed to fetch top stories: %v", err)
 		return
 	}
 
-	// Limit to top 20 only
-	if len(topIDs) > TotalStories {
-		topIDs = topIDs[:TotalStories]
+	if len(topIDs) > storyCount {
+		topIDs = topIDs[:storyCount]
 	}
 	log.Printf("Processing top %d front-page stories", len(topIDs))
 
@@ -345,12 +608,151 @@ func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store,
 		log.Printf("Failed to update ranks: %v", err)
 	}
 
-	// Start jobs
-	jobs := make(chan int, len(topIDs))
+	// Other HN lists: their stories are ingested alongside the front page and
+	// ranked once they exist.
+	ids := append([]int(nil), topIDs...)
+	seen := make(map[int]bool, len(topIDs))
+	for _, id := range topIDs {
+		seen[id] = true
+	}
+	feedIDs := make(map[string][]int, len(feeds))
+	for _, feed := range feeds {
+		list, err := client.GetFeed(ctx, feed)
+		if err != nil {
+			log.Printf("Failed to fetch %s stories: %v", feed, err)
+			continue
+		}
+		if len(list) > storyCount {
+			list = list[:storyCount]
+		}
+		feedIDs[feed] = list
+		for _, id := range list {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) > len(topIDs) {
+		log.Printf("Processing %d more stories from the %s lists", len(ids)-len(topIDs), strings.Join(feeds, ", "))
+	}
+
+	// Between full syncs, stored stories are only refetched when HN reports
+	// them as changed; stored comments HN reports as changed are refetched
+	// on their own.
+	var delta *deltaSync
+	if !full {
+		delta, err = planDeltaSync(ctx, client, store, ids)
+		if err != nil {
+			log.Printf("Delta sync unavailable, refetching everything: %v", err)
+		} else {
+			log.Printf("Delta sync: %d new or changed stories, %d changed comments, %d changed profiles (skipping %d unchanged stories)",
+				len(delta.stories), len(delta.comments), len(delta.profiles), len(delta.unchanged))
+			ids = delta.stories
+		}
+	}
+
+	comments := newCommentPipeline(ctx, client, store)
+	var processedMu sync.Mutex
+	var processed []int
+	forEachItem(ctx, ids, func(workerID, id int) {
+		var rankPtr *int
+		if rank, ok := rankMap[id]; ok {
+			rankPtr = &rank
+		}
+		if err := processStory(ctx, client, store, comments, id, rankPtr, aiEnabled, ollamaModel, aiProvider, freshness, backpressure, delta != nil); err != nil {
+			log.Printf("Worker %d: Failed to process story %d: %v", workerID, id, err)
+			return
+		}
+		processedMu.Lock()
+		processed = append(processed, id)
+		processedMu.Unlock()
+	})
+	// Stories the delta skipped still get their summaries checked: a failed
+	// job's cooldown or the freshness policy may have come due since.
+	if delta != nil && aiEnabled {
+		forEachItem(ctx, delta.unchanged, func(_, id int) {
+			var rankPtr *int
+			if rank, ok := rankMap[id]; ok {
+				rankPtr = &rank
+			}
+			queueSummaryIfNeeded(ctx, store, id, rankPtr, ollamaModel, aiProvider, freshness, backpressure)
+		})
+	}
+	if delta != nil {
+		for _, id := range delta.comments {
+			comments.SyncComment(id, delta.known[id])
+		}
+		for _, username := range delta.profiles {
+			comments.AddUser(username)
+		}
+	}
+	comments.Close()
+	backpressure.report()
+
+	// Notify followers of HN users active on the stories, now that their
+	// comments are saved.
+	for _, id := range processed {
+		if err := store.NotifyHNUserActivity(ctx, id); err != nil {
+			log.Printf("Failed to queue HN user activity notifications (story %d): %v", id, err)
+		}
+	}
+
+	for feed, list := range feedIDs {
+		if err := store.SetFeedRanks(ctx, feed, list); err != nil {
+			log.Printf("Failed to update %s ranks: %v", feed, err)
+		}
+	}
+
+	// Summaries saved since the last cycle change their stories' embedding
+	// input, so they're re-embedded here too.
+	updateEmbeddings(ctx, store, embedder, embedBatch)
+
+	// Prune DB: keep stories from the last 7 days (protected: saved stories
+	// and the current front page)
+	log.Println("Pruning stories older than 7 days...")
+	if err := store.PruneStories(ctx, 7, storyCount); err != nil {
+		log.Printf("Failed to prune stories: %v", err)
+	}
+	if err := store.PruneSummaryJobs(ctx, 7); err != nil {
+		log.Printf("Failed to prune summary jobs: %v", err)
+	}
+	if err := store.PruneOutbox(ctx, 7); err != nil {
+		log.Printf("Failed to prune notification outbox: %v", err)
+	}
+	if err := store.PruneAnonInteractions(ctx, 30); err != nil {
+		log.Printf("Failed to prune anonymous interactions: %v", err)
+	}
+	if err := store.PruneAnonDevices(ctx, 30); err != nil {
+		log.Printf("Failed to prune anonymous devices: %v", err)
+	}
+	if err := store.PruneEmailChangeRequests(ctx); err != nil {
+		log.Printf("Failed to prune email change requests: %v", err)
+	}
+	if err := store.PruneAuthCodes(ctx); err != nil {
+		log.Printf("Failed to prune authorization codes: %v", err)
+	}
+	if err := store.PruneIngestRuns(ctx, 30); err != nil {
+		log.Printf("Failed to prune ingest runs: %v", err)
+	}
+	if err := store.PruneSummaryQueueEvents(ctx, 30); err != nil {
+		log.Printf("Failed to prune summary queue events: %v", err)
+	}
+	if err := store.PruneTopicRejections(ctx, 30); err != nil {
+		log.Printf("Failed to prune topic rejections: %v", err)
+	}
+
+	log.Println("Ingestion run completed.")
+}
+
+// forEachItem runs fn for each ID on a pool of storyWorkers goroutines and
+// waits for them to finish.
+func forEachItem(ctx context.Context, ids []int, fn func(workerID, id int)) {
+	jobs := make(chan int, len(ids))
 	var wg sync.WaitGroup
 
 	// Start workers
-	for i := 0; i < 5; i++ {
+	for i := 0; i < storyWorkers(len(ids)); i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
@@ -359,46 +761,75 @@ func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store,
 				case <-ctx.Done():
 					return
 				default:
-					rank := rankMap[id]
-					// Always summarize for top 20 in clean re-ingest
-					rankPtr := &rank
-					if err := processStory(ctx, client, store, id, rankPtr, summaryQueue, aiEnabled, ollamaModel, aiProvider); err != nil {
-						log.Printf("Worker %d: Failed to process story %d: %v", workerID, id, err)
-					}
+					fn(workerID, id)
 				}
 			}
 		}(i)
 	}
 
-	for _, id := range topIDs {
+	for _, id := range ids {
 		jobs <- id
 	}
 	close(jobs)
 	wg.Wait()
+}
 
-	// Prune DB: keep stories from the last 7 days (protected: saved stories)
-	log.Println("Pruning stories older than 7 days...")
-	if err := store.PruneStories(ctx, 7); err != nil {
-		log.Printf("Failed to prune stories: %v", err)
-	}
-
-	log.Println("Ingestion run completed.")
+// deltaSync is the work of an incremental run.
+type deltaSync struct {
+	stories   []int         // listed stories that are new or changed
+	unchanged []int         // listed stories that are stored and unchanged
+	comments  []int         // stored comments that changed
+	profiles  []string      // stored HN users whose profile changed
+	known     map[int]int64 // stored item -> its story
 }
 
-// cleanupOldStories is kept for compatibility but no longer used in main flow.
-func cleanupOldStories(ctx context.Context, store *storage.Store) {
-	if err := store.PruneStories(ctx, 7); err != nil {
-		log.Printf("Failed to prune old stories: %v", err)
+// planDeltaSync narrows a run over the listed story ids to what HN's updates
+// feed reports as changed, plus stories not stored yet.
+func planDeltaSync(ctx context.Context, client *hn.Client, store *storage.Store, ids []int) (*deltaSync, error) {
+	updates, err := client.GetUpdates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch updates: %w", err)
+	}
+	known, err := store.KnownItems(ctx, append(append([]int(nil), ids...), updates.Items...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up stored items: %w", err)
+	}
+	changed := make(map[int]bool, len(updates.Items))
+	for _, id := range updates.Items {
+		changed[id] = true
+	}
+
+	d := &deltaSync{known: known}
+	for _, id := range ids {
+		if _, ok := known[id]; !ok || changed[id] {
+			d.stories = append(d.stories, id)
+		} else {
+			d.unchanged = append(d.unchanged, id)
+		}
+	}
+	for _, id := range updates.Items {
+		if storyID, ok := known[id]; ok && storyID != int64(id) {
+			d.comments = append(d.comments, id)
+		}
 	}
+	d.profiles, err = store.KnownHNUsers(ctx, updates.Profiles)
+	if err != nil {
+		log.Printf("Failed to look up changed HN profiles: %v", err)
+	}
+	return d, nil
 }
 
-func processStory(ctx context.Context, client *hn.Client, store *storage.Store, id int, rank *int, summaryQueue chan<- SummaryJob, aiEnabled bool, ollamaModel string, aiProvider string) error {
+// processStory upserts a story and queues its author and comments on the
+// run's comment pipeline. With onlyNewComments set, comments already stored
+// are skipped; changed ones are synced separately.
+func processStory(ctx context.Context, client *hn.Client, store *storage.Store, comments *commentPipeline, id int, rank *int, aiEnabled bool, ollamaModel string, aiProvider string, freshness storage.FreshnessPolicy, backpressure *summaryBackpressure, onlyNewComments bool) error {
 	item, err := client.GetItem(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if item.Type != "story" {
+	// Job posts come from the jobs list (and occasionally the front page).
+	if item.Type != "story" && item.Type != "job" {
 		return nil
 	}
 
@@ -406,7 +837,9 @@ func processStory(ctx context.Context, client *hn.Client, store *storage.Store,
 	story := storage.Story{
 		ID:          int64(item.ID),
 		Title:       item.Title,
+		Type:        item.Type,
 		URL:         item.URL,
+		Text:        item.Text,
 		Score:       item.Score,
 		By:          item.By,
 		Descendants: item.Descendants,
@@ -419,106 +852,78 @@ func processStory(ctx context.Context, client *hn.Client, store *storage.Store,
 	}
 
 	// 1.5 Enqueue for Auto-Summarization
-	// CRITERIA:
-	// 1. Must have URL
-	// 2. Score > 10 (Filtering noise)
-	// 3. No existing summary (Checked by worker? Or here? Better here to save queue space)
-
-	if aiEnabled && item.URL != "" && item.Score > 10 {
-		// Queue for summarization if:
-		// 1. No summary exists yet, OR
-		// 2. Summary exists but topics are missing (re-process to get tags)
-		existing, err := store.GetStory(ctx, id)
-		needsSummary := err != nil || existing.Summary == nil || *existing.Summary == ""
-		needsTopics := err == nil && existing.Summary != nil && *existing.Summary != "" && len(existing.Topics) == 0
-		if needsSummary || needsTopics {
-			select {
-			case summaryQueue <- SummaryJob{ID: id, URL: item.URL, Title: item.Title, Model: ollamaModel, Provider: aiProvider}:
-				if needsTopics {
-					log.Printf("Re-queuing story %d for topic tagging", id)
-				}
-			default:
-				log.Printf("Summary queue full, skipping story %d", id)
-			}
-		}
-	}
-
-	// 2. Upsert Story Author
-	if item.By != "" {
-		go processUser(ctx, client, store, item.By)
+	if aiEnabled && (item.URL != "" || item.Text != "") && item.Score > 10 {
+		queueSummaryIfNeeded(ctx, store, id, rank, ollamaModel, aiProvider, freshness, backpressure)
 	}
 
-	// 3. Process Comments
-	if len(item.Kids) > 0 {
-		processComments(ctx, client, store, item.Kids, int64(item.ID), nil)
-	}
+	// 2. Queue the story's author and comments
+	comments.AddStory(ctx, item, onlyNewComments)
 
 	return nil
 }
 
-func processComments(ctx context.Context, client *hn.Client, store *storage.Store, kids []int, storyID int64, parentID *int64) {
-	// ... (unchanged) ...
-	// Need to copy the original body of processComments here or it will be lost if I don't include it in ReplacementContent
-	// Since I'm replacing from line 63 onwards, I need to include EVERYTHING after that.
-
-	// WAIT: replace_file_content replaces a chunk.
-	// I need to be careful. The original code has `processComments` at the end.
-	// I should only replace `main`, `runIngestion` and `processStory`.
-	// Leaving `processComments` and `processUser` alone if possible,
-	// BUT `processComments` is called by `processStory` and calls itself.
-	// The previous `processStory` implementation was ending around line 265.
-
-	// Let me rewrite the whole file content from main downwards to be safe,
-	// OR just target the block from `main` to `processStory` end.
-	// `processComments` starts at line 267.
-
-	// I will replace from line 63 (inside main) to line 265 (end of processStory).
-	// And I need to update `main` signature too, so I should start from line 62.
-
-	for _, kidID := range kids {
-		// Fetch comment item
-		item, err := client.GetItem(ctx, kidID)
-		if err != nil {
-			log.Printf("Failed to fetch comment %d: %v", kidID, err)
-			continue
-		}
-
-		if item.Type != "comment" || item.Deleted || item.Dead {
-			continue
-		}
+// queueSummaryIfNeeded queues a summary job for a stored story that lacks an
+// up-to-date summary. It works from the stored row, so it also covers stories
+// an incremental run didn't refetch.
+func queueSummaryIfNeeded(ctx context.Context, store *storage.Store, id int, rank *int, ollamaModel string, aiProvider string, freshness storage.FreshnessPolicy, backpressure *summaryBackpressure) {
+	existing, err := store.GetStory(ctx, id)
+	if err != nil {
+		log.Printf("Failed to load story %d for summarization: %v", id, err)
+		return
+	}
 
-		// Upsert Comment
-		comment := storage.Comment{
-			ID:       int64(item.ID),
-			StoryID:  storyID,
-			ParentID: parentID,
-			Text:     item.Text,
-			By:       item.By,
-			PostedAt: time.Unix(item.Time, 0),
-		}
+	// CRITERIA:
+	// 1. Must have URL or, for Ask/Tell HN posts, a body
+	// 2. Score > 10 (Filtering noise)
+	if (existing.URL == "" && existing.Text == "") || existing.Score <= 10 {
+		return
+	}
 
-		if err := store.UpsertComment(ctx, comment); err != nil {
-			log.Printf("Failed to upsert comment %d: %v", item.ID, err)
-		}
+	// Queue for summarization if:
+	// 1. No summary exists yet, OR
+	// 2. Summary exists but topics are missing (re-process to get tags), OR
+	// 3. The article changed since it was summarized, OR
+	// 4. The discussion grew past the freshness policy's threshold
+	needsSummary := existing.Summary == nil || *existing.Summary == "" || existing.SummaryStale
+	needsTopics := existing.Summary != nil && *existing.Summary != "" && len(existing.Topics) == 0
+	needsRefresh := !needsSummary && freshness.NeedsRefresh(existing)
+	if !needsSummary && !needsTopics && !needsRefresh {
+		return
+	}
 
-		// Upsert Comment Author
-		if item.By != "" {
-			go processUser(ctx, client, store, item.By)
+	// With the queue backed up, low-ranked stories wait behind the
+	// backlog, or keep the summary they have.
+	decision := backpressure.decide(rank, !needsSummary)
+	delay := time.Duration(0)
+	if decision == storage.QueueDeferred {
+		delay = summaryDeferDelay
+	}
+	queued := false
+	if decision == storage.QueueShed {
+		backpressure.record(ctx, store, id, decision, rank)
+	} else if queued, err = store.EnqueueSummaryJob(ctx, id, ollamaModel, aiProvider, delay, summaryJobCooldown); err != nil {
+		log.Printf("Failed to queue summary (story %d): %v", id, err)
+	}
+	if queued {
+		backpressure.queued()
+		if decision != "" {
+			backpressure.record(ctx, store, id, decision, rank)
 		}
-
-		// Recursively process replies
-		if len(item.Kids) > 0 {
-			pID := int64(item.ID)
-			processComments(ctx, client, store, item.Kids, storyID, &pID)
+		setSummaryStatus(ctx, store, id, storage.SummaryPending)
+		if needsRefresh {
+			log.Printf("Re-queuing story %d: discussion grew to %d comments since its summary", id, existing.Descendants)
+		} else if needsTopics {
+			log.Printf("Re-queuing story %d for topic tagging", id)
 		}
 	}
 }
 
-func processUser(ctx context.Context, client *hn.Client, store *storage.Store, username string) {
+// processUser refreshes an HN profile, reporting whether it succeeded.
+func processUser(ctx context.Context, client *hn.Client, store *storage.Store, username string) bool {
 	userItem, err := client.GetUser(ctx, username)
 	if err != nil {
 		log.Printf("Failed to fetch user %s: %v", username, err)
-		return
+		return false
 	}
 
 	user := storage.User{
@@ -531,37 +936,7 @@ func processUser(ctx context.Context, client *hn.Client, store *storage.Store, u
 
 	if err := store.UpsertUser(ctx, user); err != nil {
 		log.Printf("Failed to upsert user %s: %v", username, err)
+		return false
 	}
-}
-
-// flattenStringArray handles various hallucinated JSON formats from LLMs (e.g., nested arrays like [["string"]])
-func flattenStringArray(input interface{}) []string {
-	if input == nil {
-		return nil
-	}
-	var result []string
-	switch v := input.(type) {
-	case string:
-		return []string{v}
-	case []string:
-		return v
-	case []interface{}:
-		for _, item := range v {
-			if item == nil {
-				continue
-			}
-			switch tv := item.(type) {
-			case string:
-				result = append(result, tv)
-			case []interface{}:
-				// Handle nested array: [["string"]] -> take first element
-				if len(tv) > 0 {
-					if s, ok := tv[0].(string); ok {
-						result = append(result, s)
-					}
-				}
-			}
-		}
-	}
-	return result
+	return true
 }
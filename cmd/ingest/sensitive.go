@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// checkSensitiveStories has the configured AI providers confirm or clear up
+// to limit of the stories the keyword list flagged as sensitive, with Gemini
+// on geminiKey. Stories no provider could check stay flagged and are retried
+// next cycle.
+func checkSensitiveStories(ctx context.Context, store *storage.Store, aiProviders ai.Providers, geminiKey string, limit int) {
+	if limit == 0 {
+		return
+	}
+	stories, err := store.StoriesNeedingSensitiveCheck(ctx, limit)
+	if err != nil {
+		log.Printf("Failed to find stories to check for sensitive content: %v", err)
+		return
+	}
+	if len(stories) == 0 {
+		return
+	}
+	aiProvider, _ := store.GetSetting(ctx, "ai_provider")
+	ollamaModel, _ := store.GetSetting(ctx, "ollama_model")
+	providers, err := aiProviders.Select(aiProvider)
+	if err != nil {
+		log.Printf("Failed to check stories for sensitive content: %v", err)
+		return
+	}
+
+	checked := 0
+	for _, st := range stories {
+		if ctx.Err() != nil {
+			break
+		}
+		var text string
+		if st.Summary != nil {
+			text = *st.Summary
+		}
+		if len(st.Topics) > 0 {
+			text += "\n\nTopics: " + strings.Join(st.Topics, ", ")
+		}
+		for _, p := range providers {
+			var model, apiKey string
+			switch p.Name() {
+			case ai.ProviderOllama:
+				model = ollamaModel
+			case ai.ProviderGemini:
+				apiKey = geminiKey
+			}
+			sensitive, reason, usage, err := ai.CheckSensitive(ctx, p, model, apiKey, st.Title, text)
+			recordAIUsage(ctx, store, usage)
+			if err != nil {
+				if !errors.Is(err, ai.ErrNoAPIKey) {
+					log.Printf("Sensitive check: %s failed for story %d: %v", p.Name(), st.ID, err)
+				}
+				continue
+			}
+			if err := store.SetSensitiveVerdict(ctx, st.ID, sensitive, reason); err != nil {
+				log.Printf("Failed to save sensitive verdict (story %d): %v", st.ID, err)
+			} else {
+				checked++
+			}
+			break
+		}
+	}
+	log.Printf("Checked %d of %d keyword-flagged stories for sensitive content", checked, len(stories))
+}
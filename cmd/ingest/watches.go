@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// notifyStoryWatches queues a notification for every story watch whose story
+// reached its score or comment threshold, or got its summary, since the last
+// cycle. The ingest dispatcher delivers them over the user's alert channels.
+func notifyStoryWatches(ctx context.Context, store *storage.Store) {
+	alerts, err := store.GetDueWatchAlerts(ctx)
+	if err != nil {
+		log.Printf("Failed to check story watches: %v", err)
+		return
+	}
+
+	prefsByUser := make(map[string]*storage.NotificationPreferences)
+	queued := 0
+	for _, a := range alerts {
+		prefs, ok := prefsByUser[a.UserID]
+		if !ok {
+			if prefs, err = store.GetNotificationPreferences(ctx, a.UserID); err != nil {
+				log.Printf("Failed to load notification preferences for %s: %v", a.UserID, err)
+				continue
+			}
+			prefsByUser[a.UserID] = prefs
+		}
+		subject, body := renderWatchAlert(a)
+		if err := store.EnqueueWatchAlert(ctx, a, prefs, subject, body); err != nil {
+			log.Printf("Failed to queue %s alert on story %d for %s: %v", a.Reason, a.Story.ID, a.UserID, err)
+			continue
+		}
+		queued++
+	}
+	if queued > 0 {
+		log.Printf("Story watches: queued %d notifications", queued)
+	}
+}
+
+func renderWatchAlert(a storage.WatchAlert) (subject, body string) {
+	st := a.Story
+	switch a.Reason {
+	case storage.WatchScore:
+		subject = fmt.Sprintf("HN Station: %q reached %d points", st.Title, a.Threshold)
+	case storage.WatchComments:
+		subject = fmt.Sprintf("HN Station: %q reached %d comments", st.Title, a.Threshold)
+	default:
+		subject = fmt.Sprintf("HN Station: %q has been summarized", st.Title)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s\n%d points, %d comments\n", st.Title, st.Score, st.Descendants))
+	if a.Reason == storage.WatchSummary && st.Summary != nil {
+		sb.WriteString("\n" + strings.TrimSpace(*st.Summary) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nhttps://news.ycombinator.com/item?id=%d\n", st.ID))
+	if st.URL != "" {
+		sb.WriteString(st.URL + "\n")
+	}
+	sb.WriteString("\nYou're receiving this because you watch this story on HN Station.\n")
+	return subject, sb.String()
+}
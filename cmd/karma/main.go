@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Karma job: run from cron (e.g. hourly). Re-fetches the HN users we have
+// stored, least recently refreshed first, updates their profiles and records
+// a karma snapshot for /api/users/{username}/karma_history. Without it users
+// are only refreshed when they happen to comment on an ingested story.
+func main() {
+	maxAge := flag.Duration("max-age", 24*time.Hour, "Refresh users not updated for this long")
+	limit := flag.Int("limit", 2000, "Maximum users to refresh per run")
+	workers := flag.Int("workers", 8, "Concurrent HN API requests")
+	flag.Parse()
+
+	cfg := config.MustLoad()
+	httpclient.Configure(cfg.HTTP)
+
+	ctx := context.Background()
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v\n", err)
+	}
+	defer dbpool.Close()
+
+	store := storage.New(dbpool)
+	client := hn.NewClient()
+
+	names, err := store.GetStaleUsernames(ctx, time.Now().Add(-*maxAge), *limit)
+	if err != nil {
+		log.Fatalf("Failed to list users: %v", err)
+	}
+	log.Printf("Karma Job: refreshing %d users", len(names))
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	refreshed := 0
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				if snapshotUser(ctx, client, store, name) {
+					mu.Lock()
+					refreshed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, name := range names {
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
+
+	log.Printf("Karma Job Completed: %d/%d users refreshed", refreshed, len(names))
+}
+
+func snapshotUser(ctx context.Context, client *hn.Client, store *storage.Store, username string) bool {
+	item, err := client.GetUser(ctx, username)
+	if err != nil {
+		log.Printf("Failed to fetch user %s: %v", username, err)
+		return false
+	}
+	if item.ID == "" {
+		// HN returns null for deleted accounts.
+		log.Printf("User %s no longer exists on HN", username)
+		return false
+	}
+	user := storage.User{
+		ID:        item.ID,
+		Created:   item.Created,
+		Karma:     item.Karma,
+		About:     item.About,
+		Submitted: item.Submitted,
+	}
+	if err := store.SnapshotUser(ctx, user); err != nil {
+		log.Printf("Failed to snapshot user %s: %v", username, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Load and soak test harness for a running HN Station instance.
+//
+// Simulates a mix of story-list reads, summarize requests and interactions
+// from concurrent virtual users and reports latency percentiles per operation.
+//
+// usage: go run ./cmd/loadtest -base http://localhost:8080 -c 20 -d 2m
+//        go run ./cmd/loadtest -d 2h -report 5m   (soak)
+
+const (
+	OpListStories = "list_stories"
+	OpStoryDetail = "story_detail"
+	OpSummarize   = "summarize"
+	OpInteract    = "interact"
+)
+
+type sample struct {
+	op      string
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// recorder collects samples from all virtual users.
+type recorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	statuses  map[string]map[int]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+		statuses:  make(map[string]map[int]int),
+	}
+}
+
+func (r *recorder) add(s sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[s.op] = append(r.latencies[s.op], s.latency)
+	if s.err != nil || s.status >= 500 {
+		r.errors[s.op]++
+	}
+	if r.statuses[s.op] == nil {
+		r.statuses[s.op] = make(map[int]int)
+	}
+	r.statuses[s.op][s.status]++
+}
+
+// snapshot returns a copy of the collected samples and, if reset is true,
+// clears the recorder so the next window starts fresh.
+func (r *recorder) snapshot(reset bool) (map[string][]time.Duration, map[string]int, map[string]map[int]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lat := make(map[string][]time.Duration, len(r.latencies))
+	for op, l := range r.latencies {
+		lat[op] = append([]time.Duration(nil), l...)
+	}
+	errs := make(map[string]int, len(r.errors))
+	for op, n := range r.errors {
+		errs[op] = n
+	}
+	st := make(map[string]map[int]int, len(r.statuses))
+	for op, m := range r.statuses {
+		st[op] = make(map[int]int, len(m))
+		for code, n := range m {
+			st[op][code] = n
+		}
+	}
+	if reset {
+		r.latencies = make(map[string][]time.Duration)
+		r.errors = make(map[string]int)
+		r.statuses = make(map[string]map[int]int)
+	}
+	return lat, errs, st
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+func printReport(title string, elapsed time.Duration, lat map[string][]time.Duration, errs map[string]int, statuses map[string]map[int]int) {
+	fmt.Printf("\n=== %s (%v) ===\n", title, elapsed.Round(time.Second))
+	fmt.Printf("%-14s %8s %8s %7s %9s %9s %9s %9s %9s\n", "op", "count", "errors", "rps", "p50", "p90", "p95", "p99", "max")
+
+	ops := make([]string, 0, len(lat))
+	for op := range lat {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	for _, op := range ops {
+		l := lat[op]
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		rps := float64(len(l)) / elapsed.Seconds()
+		fmt.Printf("%-14s %8d %8d %7.1f %9v %9v %9v %9v %9v\n",
+			op, len(l), errs[op], rps,
+			percentile(l, 0.50).Round(time.Millisecond),
+			percentile(l, 0.90).Round(time.Millisecond),
+			percentile(l, 0.95).Round(time.Millisecond),
+			percentile(l, 0.99).Round(time.Millisecond),
+			percentile(l, 1.0).Round(time.Millisecond),
+		)
+	}
+
+	for _, op := range ops {
+		var parts []string
+		codes := make([]int, 0, len(statuses[op]))
+		for code := range statuses[op] {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			label := fmt.Sprintf("%d", code)
+			if code == 0 {
+				label = "neterr"
+			}
+			parts = append(parts, fmt.Sprintf("%s=%d", label, statuses[op][code]))
+		}
+		fmt.Printf("  %-12s %s\n", op, strings.Join(parts, " "))
+	}
+}
+
+type harness struct {
+	base     string
+	client   *http.Client
+	cookie   string
+	storyIDs []int64
+	rec      *recorder
+	weights  []weightedOp
+	total    int
+}
+
+type weightedOp struct {
+	op     string
+	weight int
+}
+
+// parseMix parses "list_stories=70,story_detail=20,interact=8,summarize=2".
+func parseMix(mix string) ([]weightedOp, int, error) {
+	var ops []weightedOp
+	total := 0
+	for _, part := range strings.Split(mix, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, 0, fmt.Errorf("invalid mix entry %q", part)
+		}
+		var w int
+		if _, err := fmt.Sscanf(kv[1], "%d", &w); err != nil || w < 0 {
+			return nil, 0, fmt.Errorf("invalid weight in %q", part)
+		}
+		switch kv[0] {
+		case OpListStories, OpStoryDetail, OpSummarize, OpInteract:
+		default:
+			return nil, 0, fmt.Errorf("unknown operation %q", kv[0])
+		}
+		if w == 0 {
+			continue
+		}
+		ops = append(ops, weightedOp{op: kv[0], weight: w})
+		total += w
+	}
+	if total == 0 {
+		return nil, 0, fmt.Errorf("mix has no operations")
+	}
+	return ops, total, nil
+}
+
+func (h *harness) pick(rng *rand.Rand) string {
+	n := rng.Intn(h.total)
+	for _, w := range h.weights {
+		if n < w.weight {
+			return w.op
+		}
+		n -= w.weight
+	}
+	return h.weights[0].op
+}
+
+func (h *harness) do(ctx context.Context, op, method, path string, body []byte) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, h.base+path, reader)
+	if err != nil {
+		h.rec.add(sample{op: op, err: err})
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.cookie != "" {
+		req.AddCookie(&http.Cookie{Name: "hn_session", Value: h.cookie})
+	}
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// Cancellation at the end of the run is not a server error.
+		if ctx.Err() != nil {
+			return
+		}
+		h.rec.add(sample{op: op, latency: time.Since(start), err: err})
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	h.rec.add(sample{op: op, latency: time.Since(start), status: resp.StatusCode})
+}
+
+func (h *harness) randomStory(rng *rand.Rand) int64 {
+	return h.storyIDs[rng.Intn(len(h.storyIDs))]
+}
+
+func (h *harness) run(ctx context.Context, op string, rng *rand.Rand) {
+	switch op {
+	case OpListStories:
+		sorts := []string{"default", "latest", "votes", "show"}
+		path := fmt.Sprintf("/api/stories?limit=10&offset=%d&sort=%s", rng.Intn(5)*10, sorts[rng.Intn(len(sorts))])
+		h.do(ctx, op, "GET", path, nil)
+	case OpStoryDetail:
+		h.do(ctx, op, "GET", fmt.Sprintf("/api/stories/%d", h.randomStory(rng)), nil)
+	case OpSummarize:
+		h.do(ctx, op, "POST", fmt.Sprintf("/api/stories/%d/summarize", h.randomStory(rng)), nil)
+	case OpInteract:
+		val := rng.Intn(2) == 0
+		var payload map[string]bool
+		switch rng.Intn(3) {
+		case 0:
+			payload = map[string]bool{"read": true}
+		case 1:
+			payload = map[string]bool{"saved": val}
+		default:
+			payload = map[string]bool{"hidden": val}
+		}
+		body, _ := json.Marshal(payload)
+		h.do(ctx, op, "POST", fmt.Sprintf("/api/stories/%d/interact", h.randomStory(rng)), body)
+	}
+}
+
+// discoverStories pulls story IDs from the running instance so detail,
+// summarize and interact requests hit real rows.
+func (h *harness) discoverStories(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.base+"/api/stories?limit=100", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Stories []struct {
+			ID int64 `json:"id"`
+		} `json:"stories"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return err
+	}
+	for _, s := range data.Stories {
+		h.storyIDs = append(h.storyIDs, s.ID)
+	}
+	if len(h.storyIDs) == 0 {
+		return fmt.Errorf("instance returned no stories")
+	}
+	return nil
+}
+
+func main() {
+	base := flag.String("base", "http://localhost:8080", "Base URL of the HN Station instance")
+	concurrency := flag.Int("c", 10, "Number of concurrent virtual users")
+	duration := flag.Duration("d", 1*time.Minute, "Total test duration (use hours for soak runs)")
+	rate := flag.Float64("rate", 0, "Max requests/sec per virtual user (0 = unthrottled)")
+	mix := flag.String("mix", "list_stories=70,story_detail=20,interact=8,summarize=2", "Weighted operation mix")
+	report := flag.Duration("report", 0, "Print an interim report every interval (soak mode); 0 disables")
+	timeout := flag.Duration("timeout", 2*time.Minute, "Per-request timeout")
+	flag.Parse()
+
+	weights, total, err := parseMix(*mix)
+	if err != nil {
+		log.Fatalf("Invalid -mix: %v", err)
+	}
+
+	// Interact and summarize need a session; pass the hn_session JWT via env
+	// so it doesn't end up in shell history.
+	cookie := os.Getenv("HN_SESSION")
+
+	h := &harness{
+		base: strings.TrimRight(*base, "/"),
+		client: &http.Client{
+			Timeout: *timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        *concurrency * 2,
+				MaxIdleConnsPerHost: *concurrency * 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		cookie:  cookie,
+		rec:     newRecorder(),
+		weights: weights,
+		total:   total,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
+		log.Println("Received shutdown signal, finishing run...")
+		cancel()
+	}()
+
+	if err := h.discoverStories(ctx); err != nil {
+		log.Fatalf("Failed to discover stories from %s: %v", h.base, err)
+	}
+	if cookie == "" {
+		log.Println("HN_SESSION not set: interact/summarize requests will run anonymously")
+	}
+
+	log.Printf("Starting load test against %s (users: %d, duration: %v, stories: %d, mix: %s)",
+		h.base, *concurrency, *duration, len(h.storyIDs), *mix)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(userID)))
+
+			var throttle <-chan time.Time
+			if *rate > 0 {
+				t := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+				defer t.Stop()
+				throttle = t.C
+			}
+
+			for {
+				if throttle != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-throttle:
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					h.run(ctx, h.pick(rng), rng)
+				}
+			}
+		}(i)
+	}
+
+	// Interim reports for soak runs; each window is reported independently so
+	// latency drift over time is visible.
+	var cumulative *recorder
+	reportDone := make(chan struct{})
+	if *report > 0 {
+		cumulative = newRecorder()
+		go func() {
+			defer close(reportDone)
+			ticker := time.NewTicker(*report)
+			defer ticker.Stop()
+			windowStart := time.Now()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					lat, errs, st := h.rec.snapshot(true)
+					mergeInto(cumulative, lat, errs, st)
+					printReport(fmt.Sprintf("window @ %v", time.Since(start).Round(time.Second)), time.Since(windowStart), lat, errs, st)
+					windowStart = time.Now()
+				}
+			}
+		}()
+	} else {
+		close(reportDone)
+	}
+
+	wg.Wait()
+	<-reportDone
+	elapsed := time.Since(start)
+
+	lat, errs, st := h.rec.snapshot(true)
+	if cumulative != nil {
+		mergeInto(cumulative, lat, errs, st)
+		lat, errs, st = cumulative.snapshot(false)
+	}
+	printReport("total", elapsed, lat, errs, st)
+}
+
+func mergeInto(dst *recorder, lat map[string][]time.Duration, errs map[string]int, statuses map[string]map[int]int) {
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	for op, l := range lat {
+		dst.latencies[op] = append(dst.latencies[op], l...)
+	}
+	for op, n := range errs {
+		dst.errors[op] += n
+	}
+	for op, m := range statuses {
+		if dst.statuses[op] == nil {
+			dst.statuses[op] = make(map[int]int)
+		}
+		for code, n := range m {
+			dst.statuses[op][code] += n
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Reindex job: rebuilds every story's search_vector. The storage layer keeps
+// it current on write; run this after changing how it's computed or to repair
+// a database restored from elsewhere.
+func main() {
+	cfg := config.MustLoad()
+
+	ctx := context.Background()
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v\n", err)
+	}
+	defer dbpool.Close()
+
+	store := storage.New(dbpool)
+	n, err := store.ReindexSearchVectors(ctx)
+	if err != nil {
+		log.Fatalf("Reindex failed: %v", err)
+	}
+	log.Printf("Reindex Job: updated search vectors for %d stories", n)
+}
@@ -6,59 +6,81 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
 	"github.com/rajeshkumarblr/hn_station/internal/api"
 	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/notify"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, relying on environment variables")
-	}
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL is not set")
-	}
+	cfg := config.MustLoad()
+	httpclient.Configure(cfg.HTTP)
+	content.Configure(cfg.Fetch)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Connect to database
-	dbpool, err := pgxpool.New(ctx, dbURL)
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
 	if err != nil {
 		log.Fatalf("Unable to create connection pool: %v\n", err)
 	}
 	defer dbpool.Close()
 
 	// Initialize auth
-	authCfg := auth.NewConfig()
+	authCfg := auth.NewConfig(cfg.OAuth)
 	log.Printf("OAuth2 callback URL: %s", authCfg.OAuth2Config.RedirectURL)
 
 	// Initialize AI clients
-	aiClient := ai.NewOllamaClient()
-	geminiClient := ai.NewGeminiClient()
+	aiClient := ai.NewOllamaClient(cfg.Ollama)
+	providers := ai.ProvidersFromConfig(aiClient, cfg)
+	if cfg.AI.Fake {
+		providers = ai.FakeProviders(ai.FakeOptions{Latency: cfg.AI.FakeLatency, FailureRate: cfg.AI.FakeFailureRate})
+		log.Println("AI: using the fake provider; responses are canned")
+	}
 	log.Println("AI clients initialized")
 
 	store := storage.New(dbpool)
-	server := api.NewServer(store, authCfg, aiClient, geminiClient, false /* cloud mode */)
+	server := api.NewServer(store, authCfg, aiClient, providers, cfg, false /* cloud mode */)
+
+	// A read-only mirror queues no summaries and has no /api/stream.
+	if cfg.Server.Mirror {
+		log.Printf("Serving a read-only mirror (cache max-age %s)", cfg.Server.MirrorMaxAge)
+	} else {
+		// Discussion summaries queued by POST /api/stories/{id}/summarize.
+		go server.RunSummaryJobs(ctx)
+
+		// Front-page changes pushed to GET /api/stream after each ingestion run.
+		go server.RunStoryStream(ctx)
+	}
+
+	// Admin alerts on summary failures and stalled ingest.
+	if alerter := notify.NewAlerterFromConfig(store, cfg.Notify); alerter != nil {
+		go alerter.Run(ctx)
+	}
 
 	srv := &http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + strconv.Itoa(cfg.Server.Port),
 		Handler: server,
 	}
+	srv.RegisterOnShutdown(server.CloseStreams)
 
 	// Handle graceful shutdown
 	go func() {
@@ -76,7 +98,7 @@ func main() {
 		cancel()
 	}()
 
-	log.Printf("Starting server on port %s", port)
+	log.Printf("Starting server on port %d", cfg.Server.Port)
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatalf("HTTP server error: %v", err)
 	}
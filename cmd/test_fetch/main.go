@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -10,7 +11,7 @@ import (
 
 func main() {
 	url := "https://blog.ivan.digital/nvidia-personaplex-7b-on-apple-silicon-full-duplex-speech-to-speech-in-native-swift-with-mlx-0aa5276f2e23"
-	res, err := content.FetchArticle(url)
+	res, err := content.FetchArticle(context.Background(), url)
 	if err != nil {
 		log.Fatalf("Error: %v", err)
 	}
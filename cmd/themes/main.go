@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/rajeshkumarblr/hn_station/internal/themes"
+)
+
+// Themes job: run from cron (e.g. daily). Clusters the embeddings of the past
+// week's stories into emergent themes, labels each with the AI provider and
+// stores them for the current week, replacing the previous run's themes.
+// They are served by /api/analytics/themes.
+func main() {
+	days := flag.Int("days", 7, "Cluster stories posted in the last N days")
+	k := flag.Int("k", 0, "Number of clusters (0 picks one from the story count)")
+	minSize := flag.Int("min-size", 3, "Drop clusters with fewer stories")
+	dryRun := flag.Bool("dry-run", false, "Print themes instead of storing them")
+	flag.Parse()
+
+	cfg := config.MustLoad()
+	httpclient.Configure(cfg.HTTP)
+
+	ctx := context.Background()
+	dbpool, err := storage.NewPool(ctx, cfg.Database.URL, storage.PoolConfig{
+		MaxConns:           int32(cfg.Database.MaxConns),
+		MinConns:           int32(cfg.Database.MinConns),
+		MaxConnLifetime:    cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.Database.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.Database.HealthCheckPeriod,
+		StatementCacheMode: cfg.Database.StatementCacheMode,
+		StatementTimeout:   cfg.Database.StatementTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v\n", err)
+	}
+	defer dbpool.Close()
+
+	store := storage.New(dbpool)
+	aiClient := ai.NewOllamaClient(cfg.Ollama)
+	geminiClient := ai.NewGeminiClient(cfg.AI.Gemini)
+	geminiKey := cfg.AI.Gemini.APIKey
+	ollamaURL := cfg.Ollama.URL
+
+	now := time.Now()
+	var stories []storage.StoryEmbedding
+	if err := store.EachStoryEmbedding(ctx, now.AddDate(0, 0, -*days), func(e storage.StoryEmbedding) error {
+		stories = append(stories, e)
+		return nil
+	}); err != nil {
+		log.Fatalf("Failed to load embeddings: %v", err)
+	}
+	log.Printf("Themes Job: clustering %d stories", len(stories))
+	if len(stories) < *minSize*2 {
+		log.Println("Themes Job: not enough stories with embeddings, nothing to do.")
+		return
+	}
+
+	clusters := *k
+	if clusters <= 0 {
+		clusters = int(math.Max(2, math.Min(12, math.Sqrt(float64(len(stories))/2))))
+	}
+
+	vectors := make([][]float32, len(stories))
+	for i, st := range stories {
+		vectors[i] = st.Embedding
+	}
+	weekStart := storage.WeekStart(now)
+	rng := rand.New(rand.NewSource(weekStart.Unix()))
+	assign, centroids := themes.KMeans(vectors, clusters, 50, rng)
+
+	members := make([][]int, len(centroids))
+	for i, c := range assign {
+		members[c] = append(members[c], i)
+	}
+
+	provider, _ := store.GetSetting(ctx, "ai_provider")
+	if provider == "" {
+		provider = "local"
+	}
+	model, _ := store.GetSetting(ctx, "ollama_model")
+
+	var result []storage.Theme
+	for c, idx := range members {
+		if len(idx) < *minSize {
+			continue
+		}
+		sim := make(map[int]float64, len(idx))
+		for _, i := range idx {
+			sim[i] = themes.Similarity(vectors[i], centroids[c])
+		}
+		sort.SliceStable(idx, func(a, b int) bool { return sim[idx[a]] > sim[idx[b]] })
+
+		theme := storage.Theme{TopTopics: topTopics(stories, idx, 3)}
+		var titles []string
+		for _, i := range idx {
+			theme.StoryIDs = append(theme.StoryIDs, stories[i].ID)
+			if len(titles) < 10 {
+				titles = append(titles, stories[i].Title)
+			}
+		}
+
+		label, err := generateLabel(ctx, store, aiClient, geminiClient, geminiKey, provider, ollamaURL, model, titles)
+		if err != nil {
+			log.Printf("Failed to label cluster %d, using its top topic/title: %v", c, err)
+			label = titles[0]
+			if len(theme.TopTopics) > 0 {
+				label = theme.TopTopics[0]
+			}
+		}
+		theme.Label = label
+		result = append(result, theme)
+	}
+
+	if *dryRun {
+		for _, t := range result {
+			fmt.Printf("%s (%d stories, topics: %s)\n", t.Label, len(t.StoryIDs), strings.Join(t.TopTopics, ", "))
+		}
+		return
+	}
+	if err := store.ReplaceWeeklyThemes(ctx, weekStart, result); err != nil {
+		log.Fatalf("Failed to store themes: %v", err)
+	}
+	log.Printf("Themes Job Completed: %d themes for the week of %s", len(result), weekStart.Format("2006-01-02"))
+}
+
+// topTopics returns the n most common LLM topic tags among the given stories.
+func topTopics(stories []storage.StoryEmbedding, idx []int, n int) []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, i := range idx {
+		for _, t := range stories[i].Topics {
+			if counts[t] == 0 {
+				order = append(order, t)
+			}
+			counts[t]++
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return counts[order[a]] > counts[order[b]] })
+	if len(order) > n {
+		order = order[:n]
+	}
+	return order
+}
+
+func generateLabel(ctx context.Context, store *storage.Store, aiClient *ai.OllamaClient, geminiClient *ai.GeminiClient, geminiKey, provider, ollamaURL, model string, titles []string) (string, error) {
+	contextText := "Hacker News stories:\n- " + strings.Join(titles, "\n- ")
+	prompt := "These stories were grouped together by similarity. Reply with a short label (2 to 5 words) for their common theme, and nothing else."
+
+	workCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	var resp string
+	var lastErr error
+	if provider == "local" || provider == "both" {
+		resp, lastErr = aiClient.GenerateChatResponse(workCtx, ollamaURL, model, contextText, nil, prompt, ai.GenerationOptions{})
+	}
+	if resp == "" && (provider == "gemini" || provider == "both") {
+		if geminiKey != "" {
+			var usage ai.Usage
+			resp, usage, lastErr = geminiClient.GenerateChatResponse(workCtx, geminiKey, contextText, nil, prompt, ai.GenerationOptions{})
+			recordAIUsage(ctx, store, usage)
+		}
+	}
+	label := strings.Trim(strings.TrimSpace(resp), `"'.`)
+	if label == "" {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no AI provider available for %q", provider)
+		}
+		return "", lastErr
+	}
+	if i := strings.IndexByte(label, '\n'); i >= 0 {
+		label = strings.TrimSpace(label[:i])
+	}
+	return label, nil
+}
+
+// recordAIUsage stores a cloud AI call made on the system key with its
+// estimated cost, so admin stats include background jobs.
+func recordAIUsage(ctx context.Context, store *storage.Store, u ai.Usage) {
+	if u.PromptTokens == 0 && u.OutputTokens == 0 {
+		return
+	}
+	err := store.RecordAIUsage(ctx, storage.AIUsage{
+		Provider:     u.Provider,
+		Model:        u.Model,
+		PromptTokens: u.PromptTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      u.EstimateCost(),
+	})
+	if err != nil {
+		log.Printf("Failed to record AI usage: %v", err)
+	}
+}
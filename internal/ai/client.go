@@ -2,50 +2,152 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/google/generative-ai-go/genai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
+// defaultGeminiModel is used for Gemini calls unless the configuration names
+// another model.
+const defaultGeminiModel = "gemini-2.5-flash"
+
 // GeminiClient handles interactions with Google's Gemini API.
-type GeminiClient struct{}
+type GeminiClient struct {
+	models ModelConfig
+}
+
+// NewGeminiClient creates a new instance of GeminiClient. cfg's models are
+// used (default gemini-2.5-flash), and its temperature unless the user's
+// generation settings override it.
+func NewGeminiClient(cfg config.Gemini) *GeminiClient {
+	return &GeminiClient{
+		models: modelConfig(cfg.Models, ModelConfig{SummaryModel: defaultGeminiModel, ChatModel: defaultGeminiModel}),
+	}
+}
+
+// summarySchema constrains Gemini summaries to the JSON shape the Ollama
+// prompt asks for, so both providers go through the same parsing.
+var summarySchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary": {
+			Type:        genai.TypeArray,
+			Items:       &genai.Schema{Type: genai.TypeString},
+			Description: "3 to 5 key points, each a single sentence",
+		},
+		"topics": {
+			Type:        genai.TypeArray,
+			Items:       &genai.Schema{Type: genai.TypeString},
+			Description: "up to 5 short topic tags",
+		},
+	},
+	Required: []string{"summary", "topics"},
+}
+
+// SafetyBlockedError is returned when Gemini refuses a prompt or withholds
+// its answer for safety or recitation reasons. Retrying won't help.
+type SafetyBlockedError struct {
+	Reason     string   // FinishReason of the candidate, or the prompt's BlockReason
+	Categories []string // harm categories rated as blocking, if any
+}
+
+func (e *SafetyBlockedError) Error() string {
+	if len(e.Categories) == 0 {
+		return "gemini blocked the response: " + e.Reason
+	}
+	return fmt.Sprintf("gemini blocked the response: %s (%s)", e.Reason, strings.Join(e.Categories, ", "))
+}
 
-// NewGeminiClient creates a new instance of GeminiClient.
-func NewGeminiClient() *GeminiClient {
-	return &GeminiClient{}
+// IsSafetyBlocked reports whether err is a SafetyBlockedError.
+func IsSafetyBlocked(err error) bool {
+	var blocked *SafetyBlockedError
+	return errors.As(err, &blocked)
 }
 
-// GenerateSummary generates a summary using the provided API key and text.
-func (c *GeminiClient) GenerateSummary(ctx context.Context, apiKey string, text string) (string, error) {
+// asSafetyBlocked converts the SDK's BlockedError to a SafetyBlockedError,
+// returning err unchanged otherwise.
+func asSafetyBlocked(err error) error {
+	var blocked *genai.BlockedError
+	if !errors.As(err, &blocked) {
+		return err
+	}
+	out := &SafetyBlockedError{}
+	var ratings []*genai.SafetyRating
+	if blocked.Candidate != nil {
+		out.Reason = blocked.Candidate.FinishReason.String()
+		ratings = blocked.Candidate.SafetyRatings
+	} else if blocked.PromptFeedback != nil {
+		out.Reason = "prompt " + blocked.PromptFeedback.BlockReason.String()
+		ratings = blocked.PromptFeedback.SafetyRatings
+	}
+	for _, r := range ratings {
+		if r.Blocked {
+			out.Categories = append(out.Categories, r.Category.String())
+		}
+	}
+	return out
+}
+
+// GenerateSummary summarizes text as a JSON object with "summary" and
+// "topics" arrays, using the provided API key. The returned Usage counts the
+// tokens of the successful call.
+func (c *GeminiClient) GenerateSummary(ctx context.Context, apiKey string, text string, opts GenerationOptions) (string, Usage, error) {
+	return c.generateSummary(ctx, SummaryRequest{Text: text, APIKey: apiKey, Options: opts})
+}
+
+// generateSummary is GenerateSummary for a SummaryRequest, which can also
+// stream the response and ask for a translation.
+func (c *GeminiClient) generateSummary(ctx context.Context, req SummaryRequest) (string, Usage, error) {
+	apiKey, text, opts, onToken := req.APIKey, req.Text, req.Options, req.OnToken
 	log.Printf("GeminiClient: Starting summarization. Input text length: %d", len(text))
+	modelName := c.models.SummaryModel
+	usage := Usage{Provider: "gemini", Model: modelName}
 
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
-		return "", fmt.Errorf("failed to create gemini client: %w", err)
+		return "", usage, fmt.Errorf("failed to create gemini client: %w", err)
 	}
 	defer client.Close()
 
 	// Wrap in retry logic
-	return c.generateWithRetry(ctx, func() (string, error) {
-		model, err := c.getBestModel(ctx, client)
+	result, err := c.generateWithRetry(ctx, func() (string, error) {
+		model, err := c.getBestModel(ctx, client, modelName, opts)
 		if err != nil {
 			return "", err
 		}
 
-		prompt := fmt.Sprintf("Summarize this Hacker News story/discussion in 3-5 bullet points. Focus on the unique technical details or controversy. Do not include any introductory text or conversational filler. Output the bullet points directly. Text: %s", text)
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = summarySchema
+
+		prompt := fmt.Sprintf("Summarize this Hacker News story/discussion as 3-5 key points, focusing on the unique technical details or controversy, and tag it with up to 5 topics. Text: %s", text) + translationNote(req.Language)
+
+		if onToken != nil {
+			result, resp, err := c.streamResponse(model.GenerateContentStream(ctx, genai.Text(prompt)), onToken)
+			if err != nil {
+				log.Printf("GeminiClient: Model failed: %v", err)
+				return "", fmt.Errorf("model failed: %w", err)
+			}
+			usage = geminiUsage(modelName, resp)
+			return result, nil
+		}
 
 		resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 		if err != nil {
 			log.Printf("GeminiClient: Model failed: %v", err)
-			return "", fmt.Errorf("model failed: %w", err)
+			return "", fmt.Errorf("model failed: %w", asSafetyBlocked(err))
 		}
 
+		usage = geminiUsage(modelName, resp)
 		return c.extractTextFromResponse(resp)
 	})
+	return result, usage, err
 }
 
 // ChatMessage represents a message in the chat history.
@@ -54,19 +156,28 @@ type ChatMessage struct {
 	Content string
 }
 
-// GenerateChatResponse generates a response to a user message, given context and history.
-func (c *GeminiClient) GenerateChatResponse(ctx context.Context, apiKey string, contextText string, history []ChatMessage, newMessage string) (string, error) {
+// GenerateChatResponse generates a response to a user message, given context
+// and history, along with the call's token usage.
+func (c *GeminiClient) GenerateChatResponse(ctx context.Context, apiKey string, contextText string, history []ChatMessage, newMessage string, opts GenerationOptions) (string, Usage, error) {
+	return c.generateChatResponse(ctx, apiKey, contextText, history, newMessage, opts, nil)
+}
+
+// generateChatResponse is GenerateChatResponse, streaming the response to
+// onToken if it's set.
+func (c *GeminiClient) generateChatResponse(ctx context.Context, apiKey string, contextText string, history []ChatMessage, newMessage string, opts GenerationOptions, onToken func(string)) (string, Usage, error) {
 	log.Printf("GeminiClient: Starting chat. History length: %d", len(history))
+	modelName := c.models.ChatModel
+	usage := Usage{Provider: "gemini", Model: modelName}
 
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
-		return "", fmt.Errorf("failed to create gemini client: %w", err)
+		return "", usage, fmt.Errorf("failed to create gemini client: %w", err)
 	}
 	defer client.Close()
 
 	// Wrap in retry logic
-	return c.generateWithRetry(ctx, func() (string, error) {
-		model, err := c.getBestModel(ctx, client)
+	result, err := c.generateWithRetry(ctx, func() (string, error) {
+		model, err := c.getBestModel(ctx, client, modelName, opts)
 		if err != nil {
 			return "", err
 		}
@@ -108,21 +219,40 @@ func (c *GeminiClient) GenerateChatResponse(ctx context.Context, apiKey string,
 			})
 		}
 
+		if onToken != nil {
+			result, resp, err := c.streamResponse(cs.SendMessageStream(ctx, genai.Text(newMessage)), onToken)
+			if err != nil {
+				log.Printf("GeminiClient: Chat failed: %v", err)
+				return "", fmt.Errorf("chat failed: %w", err)
+			}
+			usage = geminiUsage(modelName, resp)
+			return result, nil
+		}
+
 		resp, err := cs.SendMessage(ctx, genai.Text(newMessage))
 		if err != nil {
 			log.Printf("GeminiClient: Chat failed: %v", err)
-			return "", fmt.Errorf("chat failed: %w", err)
+			return "", fmt.Errorf("chat failed: %w", asSafetyBlocked(err))
 		}
 
+		usage = geminiUsage(modelName, resp)
 		return c.extractTextFromResponse(resp)
 	})
+	return result, usage, err
 }
 
-func (c *GeminiClient) getBestModel(ctx context.Context, client *genai.Client) (*genai.GenerativeModel, error) {
+func (c *GeminiClient) getBestModel(ctx context.Context, client *genai.Client, name string, opts GenerationOptions) (*genai.GenerativeModel, error) {
 	// Skip dynamic discovery to save quota/latency for now.
 	// Gemini Flash is generally available and best for this use case.
-	modelName := "gemini-2.5-flash"
-	return client.GenerativeModel(modelName), nil
+	model := client.GenerativeModel(name)
+	opts = opts.withDefaults(c.models.Options)
+	if opts.Temperature != nil {
+		model.SetTemperature(float32(*opts.Temperature))
+	}
+	if opts.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(opts.MaxTokens))
+	}
+	return model, nil
 }
 
 func (c *GeminiClient) extractTextFromResponse(resp *genai.GenerateContentResponse) (string, error) {
@@ -145,6 +275,37 @@ func (c *GeminiClient) extractTextFromResponse(resp *genai.GenerateContentRespon
 	return result, nil
 }
 
+// streamResponse reads a streamed response, passing each chunk's text to
+// onToken. It returns the whole text and the last chunk, which carries the
+// call's token counts.
+func (c *GeminiClient) streamResponse(iter *genai.GenerateContentResponseIterator, onToken func(string)) (string, *genai.GenerateContentResponse, error) {
+	var sb strings.Builder
+	var last *genai.GenerateContentResponse
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", nil, asSafetyBlocked(err)
+		}
+		last = resp
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if txt, ok := part.(genai.Text); ok && txt != "" {
+				sb.WriteString(string(txt))
+				onToken(string(txt))
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return "", nil, fmt.Errorf("empty text response from model")
+	}
+	return sb.String(), last, nil
+}
+
 // generateWithRetry executes a generation function with retries for quota errors.
 func (c *GeminiClient) generateWithRetry(ctx context.Context, operation func() (string, error)) (string, error) {
 	var lastErr error
@@ -0,0 +1,31 @@
+package ai
+
+import "github.com/rajeshkumarblr/hn_station/internal/config"
+
+// ModelConfig is the operator's choice of models and default generation
+// options for a provider.
+type ModelConfig struct {
+	SummaryModel string
+	ChatModel    string
+	// Options fill in whatever a request's own options leave unset.
+	Options GenerationOptions
+}
+
+// modelConfig applies the configured models and options over def. They've
+// been checked by config.Validate.
+func modelConfig(m config.Models, def ModelConfig) ModelConfig {
+	cfg := def
+	if m.SummaryModel != "" {
+		cfg.SummaryModel = m.SummaryModel
+	}
+	if m.ChatModel != "" {
+		cfg.ChatModel = m.ChatModel
+	}
+	if m.Temperature != nil {
+		cfg.Options.Temperature = m.Temperature
+	}
+	if m.NumCtx != 0 {
+		cfg.Options.NumCtx = m.NumCtx
+	}
+	return cfg
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"google.golang.org/api/option"
+)
+
+const (
+	// defaultOllamaEmbedModel and defaultGeminiEmbedModel both produce
+	// 768-dim vectors, matching the stories.embedding column.
+	defaultOllamaEmbedModel = "nomic-embed-text"
+	defaultGeminiEmbedModel = "text-embedding-004"
+)
+
+// Embedder turns text into vectors. Stories and search queries must be
+// embedded by the same model for their similarity to mean anything, and
+// some models embed documents and queries differently.
+type Embedder interface {
+	// Model identifies the vectors' space, e.g. "local:nomic-embed-text".
+	Model() string
+	EmbedDocument(ctx context.Context, text string) ([]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderFromConfig returns the embedder picked by cfg.AI.Embeddings:
+// "local" embeds with ollama and the configured Ollama server and embed
+// model, "gemini" with the Gemini embed model and the system API key. "off"
+// disables embeddings and returns nil.
+func EmbedderFromConfig(ollama *OllamaClient, cfg *config.Config) Embedder {
+	switch provider := cfg.AI.Embeddings; provider {
+	case "", ProviderOllama:
+		model := cfg.Ollama.EmbedModel
+		if model == "" {
+			model = defaultOllamaEmbedModel
+		}
+		return &OllamaEmbedder{client: ollama, url: cfg.Ollama.URL, model: model}
+	case ProviderGemini:
+		model := cfg.AI.Gemini.EmbedModel
+		if model == "" {
+			model = defaultGeminiEmbedModel
+		}
+		return &GeminiEmbedder{model: model, apiKey: cfg.AI.Gemini.APIKey}
+	case ProviderFake:
+		return FakeEmbedder{}
+	case "off":
+		return nil
+	default:
+		log.Printf("Unknown embedding provider %q, embeddings disabled", provider)
+		return nil
+	}
+}
+
+// OllamaEmbedder embeds with an Ollama server's /api/embeddings.
+type OllamaEmbedder struct {
+	client *OllamaClient
+	url    string
+	model  string
+}
+
+func (e *OllamaEmbedder) Model() string { return ProviderOllama + ":" + e.model }
+
+func (e *OllamaEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	return e.client.GenerateEmbedding(ctx, e.url, e.model, text)
+}
+
+func (e *OllamaEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.client.GenerateEmbedding(ctx, e.url, e.model, text)
+}
+
+// GeminiEmbedder embeds with a Gemini embedding model, telling it whether
+// the text is a document to retrieve or a query.
+type GeminiEmbedder struct {
+	model  string
+	apiKey string
+}
+
+func (e *GeminiEmbedder) Model() string { return ProviderGemini + ":" + e.model }
+
+func (e *GeminiEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	return e.embed(ctx, genai.TaskTypeRetrievalDocument, text)
+}
+
+func (e *GeminiEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.embed(ctx, genai.TaskTypeRetrievalQuery, text)
+}
+
+func (e *GeminiEmbedder) embed(ctx context.Context, task genai.TaskType, text string) ([]float32, error) {
+	if e.apiKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(e.apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gemini client: %w", err)
+	}
+	defer client.Close()
+
+	em := client.EmbeddingModel(e.model)
+	em.TaskType = task
+	res, err := em.EmbedContent(ctx, genai.Text(text))
+	if err != nil {
+		return nil, fmt.Errorf("gemini embedding failed: %w", err)
+	}
+	if res.Embedding == nil || len(res.Embedding.Values) == 0 {
+		return nil, errors.New("empty embedding from gemini")
+	}
+	return res.Embedding.Values, nil
+}
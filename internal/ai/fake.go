@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// ProviderFake is the fake provider's name, for the ai_provider setting and
+// EMBEDDING_PROVIDER.
+const ProviderFake = "fake"
+
+// fakeEmbedDims matches the stories.embedding column.
+const fakeEmbedDims = 768
+
+// ErrFakeFailure is the error the fake provider injects.
+var ErrFakeFailure = errors.New("fake AI provider: injected failure")
+
+// FakeOptions make the fake provider behave like a slow or flaky backend.
+type FakeOptions struct {
+	Latency time.Duration // each call takes this long, or until ctx is done
+	// FailureRate is the fraction of calls, 0-1, that fail with
+	// ErrFakeFailure. Failures are spread evenly over the calls (at 0.25,
+	// every fourth fails) so test runs repeat exactly.
+	FailureRate float64
+}
+
+// FakeProvider answers with canned, deterministic summaries and chat
+// replies, so the pipeline and the frontend can be run without a GPU or
+// API keys. The same title and text always get the same summary.
+type FakeProvider struct {
+	opts  FakeOptions
+	calls atomic.Uint64
+}
+
+func NewFakeProvider(opts FakeOptions) *FakeProvider {
+	return &FakeProvider{opts: opts}
+}
+
+// FakeProviders registers one fake provider under every provider name, so
+// whatever the ai_provider setting selects is the fake.
+func FakeProviders(opts FakeOptions) Providers {
+	p := NewFakeProvider(opts)
+	return Providers{ProviderOllama: p, ProviderGemini: p, ProviderOpenAI: p, ProviderFake: p}
+}
+
+func (p *FakeProvider) Name() string { return ProviderFake }
+
+func (p *FakeProvider) Summarize(ctx context.Context, req SummaryRequest) (string, Usage, error) {
+	if err := p.call(ctx); err != nil {
+		return "", Usage{}, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	words := len(strings.Fields(req.Text))
+	points := []string{
+		fmt.Sprintf("This is a canned summary of %q from the fake AI provider.", title),
+		fmt.Sprintf("The text it was given has %d words.", words),
+		"Point " + fakePick(title, []string{"A", "B", "C", "D"}) + " stands in for the article's key argument.",
+	}
+	if req.Language != "" {
+		points = append(points, "The original is in "+req.Language+".")
+	}
+	resp, err := json.Marshal(map[string][]string{"summary": points, "topics": fakeTopics(title)})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	return p.stream(string(resp), req.OnToken), Usage{}, nil
+}
+
+func (p *FakeProvider) Chat(ctx context.Context, req ChatRequest) (string, Usage, error) {
+	if err := p.call(ctx); err != nil {
+		return "", Usage{}, err
+	}
+	resp := fmt.Sprintf("This is a canned reply from the fake AI provider. You asked: %q. The context had %d words and the conversation %d earlier messages.",
+		strings.TrimSpace(req.Message), len(strings.Fields(req.Context)), len(req.History))
+	return p.stream(resp, req.OnToken), Usage{}, nil
+}
+
+// call waits out the configured latency and decides whether this call
+// fails.
+func (p *FakeProvider) call(ctx context.Context) error {
+	if p.opts.Latency > 0 {
+		t := time.NewTimer(p.opts.Latency)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	n := p.calls.Add(1)
+	if r := p.opts.FailureRate; r > 0 && math.Floor(float64(n)*r) > math.Floor(float64(n-1)*r) {
+		return ErrFakeFailure
+	}
+	return nil
+}
+
+// stream sends resp to onToken word by word, as a streaming model would.
+func (p *FakeProvider) stream(resp string, onToken func(string)) string {
+	if onToken != nil {
+		for _, w := range strings.SplitAfter(resp, " ") {
+			onToken(w)
+		}
+	}
+	return resp
+}
+
+// fakeTopics picks topics from the title's longest words.
+func fakeTopics(title string) []string {
+	var topics []string
+	seen := map[string]bool{}
+	for _, w := range strings.FieldsFunc(title, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if w = strings.ToLower(w); len(w) >= 5 && !seen[w] {
+			seen[w] = true
+			topics = append(topics, w)
+		}
+	}
+	if len(topics) > 3 {
+		topics = topics[:3]
+	}
+	return topics
+}
+
+func fakePick(key string, options []string) string {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return options[h.Sum32()%uint32(len(options))]
+}
+
+// FakeEmbedder hashes words into vectors, so texts sharing words come out
+// similar and semantic search behaves plausibly without a model.
+type FakeEmbedder struct{}
+
+func (FakeEmbedder) Model() string { return ProviderFake + ":words" }
+
+func (e FakeEmbedder) EmbedDocument(ctx context.Context, text string) ([]float32, error) {
+	return fakeEmbed(text), nil
+}
+
+func (e FakeEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return fakeEmbed(text), nil
+}
+
+func fakeEmbed(text string) []float32 {
+	v := make([]float32, fakeEmbedDims)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		v[h.Sum32()%fakeEmbedDims]++
+	}
+	var norm float64
+	for _, x := range v {
+		norm += float64(x * x)
+	}
+	if norm == 0 {
+		v[0] = 1 // a zero vector has no direction to compare
+		return v
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range v {
+		v[i] *= scale
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+)
+
+// What to do with a story in a language the models don't summarize well.
+const (
+	LanguageNative    = ""          // summarize as usual
+	LanguageTranslate = "translate" // ask for the summary in English
+	LanguageSkip      = "skip"      // don't summarize it
+)
+
+// LanguagePolicy says which languages the configured models summarize well,
+// and what happens to stories in other languages.
+type LanguagePolicy struct {
+	Native map[string]bool // ISO 639-1 codes; empty means every language
+	Skip   bool            // skip other languages rather than translate them
+}
+
+// LanguagePolicyFromConfig builds the policy for cfg.Languages, the
+// languages summarized as-is ("*" for all), and cfg.OtherLanguages.
+func LanguagePolicyFromConfig(cfg config.AI) LanguagePolicy {
+	p := LanguagePolicy{Skip: cfg.OtherLanguages == LanguageSkip}
+	if slices.Contains(cfg.Languages, "*") {
+		return p
+	}
+	p.Native = make(map[string]bool)
+	for _, l := range cfg.Languages {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			p.Native[l] = true
+		}
+	}
+	return p
+}
+
+// Action returns what to do with a story detected as lang, an ISO 639-1
+// code. Stories whose language isn't known are summarized as usual.
+func (p LanguagePolicy) Action(lang string) string {
+	if lang == "" || len(p.Native) == 0 || p.Native[lang] {
+		return LanguageNative
+	}
+	if p.Skip {
+		return LanguageSkip
+	}
+	return LanguageTranslate
+}
+
+// translationNote is appended to a summary prompt to have a text in language
+// summarized in English.
+func translationNote(language string) string {
+	if language == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nThe text is in %s. Write the summary and topics in English.", language)
+}
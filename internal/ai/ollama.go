@@ -10,24 +10,93 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+)
+
+// Default Ollama models, used when neither the request nor the configuration
+// names one.
+const (
+	defaultSummaryModel = "llama3:latest"
+	defaultChatModel    = "qwen2.5-coder:latest"
 )
 
 // OllamaClient handles interactions with a local Ollama server.
-type OllamaClient struct{}
+type OllamaClient struct {
+	http   *http.Client
+	models ModelConfig
+	// keepAlive is how long Ollama keeps each model loaded after a request,
+	// by model name; the "" entry applies to other models. Unset means
+	// Ollama's default (5m).
+	keepAlive map[string]string
+}
+
+// NewOllamaClient creates a new instance of OllamaClient. cfg's models are
+// used when a request doesn't name one (the admin's ollama_model setting
+// takes precedence for summaries), its temperature and context size unless
+// the user's generation settings override them, and its keep-alive for
+// every request.
+func NewOllamaClient(cfg config.Ollama) *OllamaClient {
+	keepAlive, err := config.ParseKeepAlive(cfg.KeepAlive)
+	if err != nil {
+		log.Printf("OllamaClient: ignoring keep_alive: %v", err)
+	}
+	return &OllamaClient{
+		http:      httpclient.New(httpclient.Ollama),
+		models:    modelConfig(cfg.Models, ModelConfig{SummaryModel: defaultSummaryModel, ChatModel: defaultChatModel}),
+		keepAlive: keepAlive,
+	}
+}
 
-// NewOllamaClient creates a new instance of OllamaClient.
-func NewOllamaClient() *OllamaClient {
-	return &OllamaClient{}
+func (c *OllamaClient) keepAliveFor(model string) string {
+	if v, ok := c.keepAlive[model]; ok {
+		return v
+	}
+	return c.keepAlive[""]
+}
+
+// Preload loads a model into memory without generating anything, so the
+// next real request doesn't wait for the model to load. It applies the
+// model's keep-alive.
+func (c *OllamaClient) Preload(ctx context.Context, apiURL string, model string) error {
+	if model == "" {
+		model = c.models.SummaryModel
+	}
+	jsonData, err := json.Marshal(OllamaGenerateRequest{
+		Model:     model,
+		KeepAlive: c.keepAliveFor(model),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal preload request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL+"/api/generate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
 }
 
 // CheckAvailability verifies if the Ollama server is reachable.
 func (c *OllamaClient) CheckAvailability(ctx context.Context, apiURL string) bool {
-	client := &http.Client{Timeout: 2 * time.Second}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return false
 	}
-	resp, err := client.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return false
 	}
@@ -36,10 +105,18 @@ func (c *OllamaClient) CheckAvailability(ctx context.Context, apiURL string) boo
 }
 
 // GenerateSummary generates a concise summary and tags using the provided local Ollama server URL and model.
-func (c *OllamaClient) GenerateSummary(ctx context.Context, apiURL string, model string, title string, text string) (string, error) {
+func (c *OllamaClient) GenerateSummary(ctx context.Context, apiURL string, model string, title string, text string, opts GenerationOptions) (string, error) {
+	return c.generateSummary(ctx, apiURL, SummaryRequest{Title: title, Text: text, Model: model, Options: opts})
+}
+
+// generateSummary is GenerateSummary for a SummaryRequest, which can also
+// stream the response and ask for a translation.
+func (c *OllamaClient) generateSummary(ctx context.Context, apiURL string, req SummaryRequest) (string, error) {
+	title, text, model, opts := req.Title, req.Text, req.Model, req.Options
 	if model == "" {
-		model = "llama3:latest"
+		model = c.models.SummaryModel
 	}
+	opts = opts.withDefaults(c.models.Options)
 	log.Printf("OllamaClient: Starting summarization for %q using model %q. Input text length: %d", title, model, len(text))
 
 	prompt := fmt.Sprintf(`Analyze this Hacker News story and provide a high-quality technical summary.
@@ -48,17 +125,19 @@ Return ONLY a JSON object with two keys:
 2. "topics": A FLAT JSON array of 5 relevant tags (plain strings).
 
 Title: %s
-Text: %s`, title, text)
+Text: %s`, title, text) + translationNote(req.Language)
 
-	return c.generateWithRetry(ctx, apiURL, model, prompt)
+	return c.generateWithRetry(ctx, apiURL, model, prompt, opts, req.OnToken)
 }
 
 // ChatMessage represents a message in the chat history.
 // We reuse the struct for compatibility but map it to Ollama's format.
 type OllamaChatRequest struct {
-	Model    string        `json:"model"`
-	Messages []MessagePart `json:"messages"`
-	Stream   bool          `json:"stream"`
+	Model     string                 `json:"model"`
+	Messages  []MessagePart          `json:"messages"`
+	Stream    bool                   `json:"stream"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive string                 `json:"keep_alive,omitempty"`
 }
 
 type MessagePart struct {
@@ -70,11 +149,27 @@ type OllamaChatResponse struct {
 	Message MessagePart `json:"message"`
 }
 
+// ollamaStreamChunk is one line of a streamed /api/generate or /api/chat
+// response.
+type ollamaStreamChunk struct {
+	Response string      `json:"response"` // /api/generate
+	Message  MessagePart `json:"message"`  // /api/chat
+	Done     bool        `json:"done"`
+	Error    string      `json:"error"`
+}
+
 // GenerateChatResponse generates a response to a user message, given context and history.
-func (c *OllamaClient) GenerateChatResponse(ctx context.Context, apiURL string, model string, contextText string, history []ChatMessage, newMessage string) (string, error) {
+func (c *OllamaClient) GenerateChatResponse(ctx context.Context, apiURL string, model string, contextText string, history []ChatMessage, newMessage string, opts GenerationOptions) (string, error) {
+	return c.generateChatResponse(ctx, apiURL, model, contextText, history, newMessage, opts, nil)
+}
+
+// generateChatResponse is GenerateChatResponse, streaming the response to
+// onToken if it's set.
+func (c *OllamaClient) generateChatResponse(ctx context.Context, apiURL string, model string, contextText string, history []ChatMessage, newMessage string, opts GenerationOptions, onToken func(string)) (string, error) {
 	if model == "" {
-		model = "qwen2.5-coder:latest"
+		model = c.models.ChatModel
 	}
+	opts = opts.withDefaults(c.models.Options)
 	log.Printf("OllamaClient: Starting chat using model %q. History length: %d", model, len(history))
 
 	messages := []MessagePart{
@@ -105,9 +200,11 @@ func (c *OllamaClient) GenerateChatResponse(ctx context.Context, apiURL string,
 	})
 
 	reqBody := OllamaChatRequest{
-		Model:    model,
-		Messages: messages,
-		Stream:   false,
+		Model:     model,
+		Messages:  messages,
+		Stream:    onToken != nil,
+		Options:   opts.ollamaOptions(),
+		KeepAlive: c.keepAliveFor(model),
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -115,27 +212,37 @@ func (c *OllamaClient) GenerateChatResponse(ctx context.Context, apiURL string,
 		return "", fmt.Errorf("failed to marshal chat request: %w", err)
 	}
 
+	if onToken != nil {
+		result, _, err := c.doOllamaStream(ctx, apiURL+"/api/chat", jsonData, onToken)
+		return result, err
+	}
 	return c.doOllamaRequest(ctx, apiURL+"/api/chat", jsonData)
 }
 
 type OllamaGenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-	Format string `json:"format,omitempty"`
+	Model     string                 `json:"model"`
+	Prompt    string                 `json:"prompt"`
+	Stream    bool                   `json:"stream"`
+	Format    string                 `json:"format,omitempty"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive string                 `json:"keep_alive,omitempty"`
 }
 
 type OllamaGenerateResponse struct {
 	Response string `json:"response"`
 }
 
-// generateWithRetry executes a JSON generation call with retries.
-func (c *OllamaClient) generateWithRetry(ctx context.Context, apiURL string, model string, prompt string) (string, error) {
+// generateWithRetry executes a JSON generation call with retries. With
+// onToken set the response is streamed, and a request that fails after
+// streaming part of it isn't retried, since onToken has already seen it.
+func (c *OllamaClient) generateWithRetry(ctx context.Context, apiURL string, model string, prompt string, opts GenerationOptions, onToken func(string)) (string, error) {
 	reqBody := OllamaGenerateRequest{
-		Model:  model,
-		Prompt: prompt,
-		Stream: false,
-		Format: "json",
+		Model:     model,
+		Prompt:    prompt,
+		Stream:    onToken != nil,
+		Format:    "json",
+		Options:   opts.ollamaOptions(),
+		KeepAlive: c.keepAliveFor(model),
 	}
 
 	// We can optionally force a JSON format output in recent Ollama versions depending on the LLM parsing.
@@ -150,10 +257,19 @@ func (c *OllamaClient) generateWithRetry(ctx context.Context, apiURL string, mod
 	maxRetries := 3
 
 	for retries := 0; retries < maxRetries; retries++ {
-		result, err := c.doOllamaRequest(ctx, apiURL+"/api/generate", jsonData)
+		var result string
+		var streamed bool
+		if onToken != nil {
+			result, streamed, err = c.doOllamaStream(ctx, apiURL+"/api/generate", jsonData, onToken)
+		} else {
+			result, err = c.doOllamaRequest(ctx, apiURL+"/api/generate", jsonData)
+		}
 		if err == nil {
 			return result, nil
 		}
+		if streamed {
+			return "", err
+		}
 
 		lastErr = err
 		log.Printf("OllamaClient: Request failed (attempt %d/%d), retrying in %v (Error: %v)...", retries+1, maxRetries, backoff, err)
@@ -176,8 +292,7 @@ func (c *OllamaClient) doOllamaRequest(ctx context.Context, endpoint string, req
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Minute}
-	resp, err := client.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("http request failed: %w", err)
 	}
@@ -217,15 +332,120 @@ func (c *OllamaClient) doOllamaRequest(ctx context.Context, endpoint string, req
 	return genResp.Response, nil
 }
 
+// doOllamaStream sends a streaming request and passes each chunk of the
+// response to onToken as it arrives, returning the whole response. streamed
+// reports whether onToken was called before an error.
+func (c *OllamaClient) doOllamaStream(ctx context.Context, endpoint string, reqBody []byte, onToken func(string)) (result string, streamed bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", false, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	// Ollama streams one JSON object per line.
+	var sb strings.Builder
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaStreamChunk
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", sb.Len() > 0, fmt.Errorf("failed to decode stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", sb.Len() > 0, fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		token := chunk.Response
+		if token == "" {
+			token = chunk.Message.Content
+		}
+		if token != "" {
+			sb.WriteString(token)
+			onToken(token)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if sb.Len() == 0 {
+		return "", false, fmt.Errorf("empty streamed response from ollama")
+	}
+	return sb.String(), true, nil
+}
+
+type OllamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// GenerateEmbedding returns the embedding vector for text. The default model
+// (nomic-embed-text) matches the 768-dim stories.embedding column.
+func (c *OllamaClient) GenerateEmbedding(ctx context.Context, apiURL string, model string, text string) ([]float32, error) {
+	if model == "" {
+		model = defaultOllamaEmbedModel
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	jsonData, err := json.Marshal(OllamaEmbeddingRequest{Model: model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL+"/api/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var embResp OllamaEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	if len(embResp.Embedding) == 0 {
+		return nil, fmt.Errorf("empty embedding from ollama")
+	}
+	return embResp.Embedding, nil
+}
+
 // ListModels returns a list of available models on the Ollama server.
 func (c *OllamaClient) ListModels(ctx context.Context, apiURL string) ([]string, error) {
-	client := &http.Client{Timeout: 5 * time.Second}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"/api/tags", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAIClient talks to any server implementing OpenAI's chat completions
+// API: OpenAI itself, Groq, Together, LM Studio, vLLM and the like.
+type OpenAIClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+// NewOpenAIClient configures a client for cfg's server (default OpenAI's)
+// and model (default gpt-4o-mini). It returns nil when neither a key nor a
+// base URL is set; local servers such as LM Studio need only the URL.
+func NewOpenAIClient(cfg config.OpenAI) *OpenAIClient {
+	baseURL, apiKey := cfg.BaseURL, cfg.APIKey
+	if baseURL == "" && apiKey == "" {
+		return nil
+	}
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	model := cfg.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	return &OpenAIClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		http:    httpclient.New(httpclient.OpenAI),
+	}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIStatusError is a non-200 response; 429s and 5xxs are retried.
+type openAIStatusError struct {
+	status  int
+	message string
+}
+
+func (e *openAIStatusError) Error() string {
+	return fmt.Sprintf("openai: status %d: %s", e.status, e.message)
+}
+
+func (c *OpenAIClient) Name() string { return ProviderOpenAI }
+
+// Summarize summarizes req.Text as the same JSON object the other providers
+// return. req.Model and req.APIKey override the configured ones if set.
+func (c *OpenAIClient) Summarize(ctx context.Context, req SummaryRequest) (string, Usage, error) {
+	log.Printf("OpenAIClient: Starting summarization for %q. Input text length: %d", req.Title, len(req.Text))
+	// Not every compatible server supports response_format, so the shape is
+	// asked for in the prompt instead.
+	return c.complete(ctx, req.Model, req.APIKey, req.Options, req.OnToken, []openAIMessage{
+		{
+			Role:    "system",
+			Content: `You summarize Hacker News stories. Reply with ONLY a JSON object with two keys: "summary", a flat array of 3 to 5 strings, each a single key point focusing on the unique technical details or controversy; and "topics", a flat array of up to 5 short topic tags.`,
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Title: %s\nText: %s", req.Title, req.Text) + translationNote(req.Language),
+		},
+	})
+}
+
+// Chat answers req.Message given the story context and the conversation so
+// far.
+func (c *OpenAIClient) Chat(ctx context.Context, req ChatRequest) (string, Usage, error) {
+	log.Printf("OpenAIClient: Starting chat. History length: %d", len(req.History))
+	messages := []openAIMessage{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("Here is the content of the Hacker News story and discussion we will talk about:\n\n%s\n\nPlease answer my future questions based on this context.", req.Context),
+		},
+	}
+	for _, msg := range req.History {
+		role := "user"
+		if msg.Role == "model" || msg.Role == "assistant" {
+			role = "assistant"
+		}
+		messages = append(messages, openAIMessage{Role: role, Content: msg.Content})
+	}
+	messages = append(messages, openAIMessage{Role: "user", Content: req.Message})
+	return c.complete(ctx, req.Model, req.APIKey, req.Options, req.OnToken, messages)
+}
+
+// complete runs a chat completion, retrying rate limits and server errors
+// with backoff. Responses aren't streamed, so onToken, if set, gets the
+// whole response at once.
+func (c *OpenAIClient) complete(ctx context.Context, model, apiKey string, opts GenerationOptions, onToken func(string), messages []openAIMessage) (string, Usage, error) {
+	if model == "" {
+		model = c.model
+	}
+	if apiKey == "" {
+		apiKey = c.apiKey
+	}
+	usage := Usage{Provider: ProviderOpenAI, Model: model}
+
+	body, err := json.Marshal(openAIRequest{
+		Model:       model,
+		Messages:    messages,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	})
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to marshal chat completion request: %w", err)
+	}
+
+	var lastErr error
+	backoff := 2 * time.Second
+	maxRetries := 3
+	for retries := 0; retries < maxRetries; retries++ {
+		resp, err := c.do(ctx, apiKey, body)
+		if err == nil {
+			if resp.Model != "" {
+				usage.Model = resp.Model
+			}
+			usage.PromptTokens = resp.Usage.PromptTokens
+			usage.OutputTokens = resp.Usage.CompletionTokens
+			if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+				return "", usage, fmt.Errorf("empty response from openai")
+			}
+			if onToken != nil {
+				onToken(resp.Choices[0].Message.Content)
+			}
+			return resp.Choices[0].Message.Content, usage, nil
+		}
+
+		lastErr = err
+		var statusErr *openAIStatusError
+		if errors.As(err, &statusErr) && statusErr.status != http.StatusTooManyRequests && statusErr.status < 500 {
+			return "", usage, err
+		}
+		log.Printf("OpenAIClient: Request failed (attempt %d/%d), retrying in %v (Error: %v)...", retries+1, maxRetries, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return "", usage, ctx.Err()
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+	return "", usage, fmt.Errorf("failed after retries: %w", lastErr)
+}
+
+func (c *OpenAIClient) do(ctx context.Context, apiKey string, body []byte) (*openAIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg := strings.TrimSpace(string(bodyBytes))
+		if json.Unmarshal(bodyBytes, &apiErr) == nil && apiErr.Error.Message != "" {
+			msg = apiErr.Error.Message
+		}
+		return nil, &openAIStatusError{status: resp.StatusCode, message: msg}
+	}
+
+	var out openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode chat completion response: %w", err)
+	}
+	return &out, nil
+}
@@ -0,0 +1,58 @@
+package ai
+
+import "fmt"
+
+// GenerationOptions tunes a generation call. Zero values keep the provider's
+// defaults.
+type GenerationOptions struct {
+	Temperature *float64 // 0-2
+	MaxTokens   int      // cap on generated tokens
+	NumCtx      int      // Ollama context window in tokens; Gemini ignores it
+}
+
+// Validate reports options outside the ranges the providers accept.
+func (o GenerationOptions) Validate() error {
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if o.MaxTokens < 0 || o.MaxTokens > 8192 {
+		return fmt.Errorf("max_tokens must be between 1 and 8192")
+	}
+	if o.NumCtx != 0 && (o.NumCtx < 512 || o.NumCtx > 131072) {
+		return fmt.Errorf("num_ctx must be between 512 and 131072")
+	}
+	return nil
+}
+
+// withDefaults fills the options o leaves unset from d.
+func (o GenerationOptions) withDefaults(d GenerationOptions) GenerationOptions {
+	if o.Temperature == nil {
+		o.Temperature = d.Temperature
+	}
+	if o.MaxTokens == 0 {
+		o.MaxTokens = d.MaxTokens
+	}
+	if o.NumCtx == 0 {
+		o.NumCtx = d.NumCtx
+	}
+	return o
+}
+
+// ollamaOptions maps the options to the "options" object of Ollama's
+// generate and chat requests.
+func (o GenerationOptions) ollamaOptions() map[string]interface{} {
+	opts := make(map[string]interface{})
+	if o.Temperature != nil {
+		opts["temperature"] = *o.Temperature
+	}
+	if o.MaxTokens > 0 {
+		opts["num_predict"] = o.MaxTokens
+	}
+	if o.NumCtx > 0 {
+		opts["num_ctx"] = o.NumCtx
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts
+}
@@ -0,0 +1,152 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+)
+
+// Provider names, as used by the ai_provider setting.
+const (
+	ProviderOllama = "local"
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai" // any OpenAI-compatible server
+)
+
+// ErrNoAPIKey is returned by providers that bill per key when the request
+// carries none.
+var ErrNoAPIKey = errors.New("no API key available")
+
+// SummaryRequest is a story or discussion to summarize.
+type SummaryRequest struct {
+	Title   string
+	Text    string
+	Model   string // empty for the provider's default
+	APIKey  string // for cloud providers; local ones ignore it
+	Options GenerationOptions
+	// Language names the text's language, e.g. "German", when the summary
+	// should be written in English instead; see LanguagePolicy.
+	Language string
+	// OnToken, if set, receives the response in chunks as it's generated;
+	// the whole response is still returned. Providers that can't stream
+	// send it as one chunk.
+	OnToken func(string)
+}
+
+// ChatRequest is a message about Context, following History.
+type ChatRequest struct {
+	Context string
+	History []ChatMessage
+	Message string
+	Model   string // empty for the provider's default
+	APIKey  string // for cloud providers; local ones ignore it
+	Options GenerationOptions
+	OnToken func(string) // as in SummaryRequest
+}
+
+// Summarizer summarizes text as a JSON object with "summary" and "topics"
+// arrays. The returned Usage is zero for providers that don't bill by token.
+type Summarizer interface {
+	Summarize(ctx context.Context, req SummaryRequest) (string, Usage, error)
+}
+
+// ChatProvider answers chat messages.
+type ChatProvider interface {
+	Chat(ctx context.Context, req ChatRequest) (string, Usage, error)
+}
+
+// Provider is an AI backend that can both summarize and chat.
+type Provider interface {
+	Name() string
+	Summarizer
+	ChatProvider
+}
+
+// Providers maps names to the configured providers.
+type Providers map[string]Provider
+
+// NewProviders registers providers under their names.
+func NewProviders(ps ...Provider) Providers {
+	out := make(Providers, len(ps))
+	for _, p := range ps {
+		out[p.Name()] = p
+	}
+	return out
+}
+
+// ProvidersFromConfig registers ollama with the configured Ollama server,
+// Gemini and, if configured (see NewOpenAIClient), an OpenAI-compatible
+// server.
+func ProvidersFromConfig(ollama *OllamaClient, cfg *config.Config) Providers {
+	ps := NewProviders(ollama.Provider(cfg.Ollama.URL), NewGeminiClient(cfg.AI.Gemini))
+	if openai := NewOpenAIClient(cfg.AI.OpenAI); openai != nil {
+		ps[ProviderOpenAI] = openai
+	}
+	return ps
+}
+
+// Select returns the providers to try, in order, for an ai_provider setting:
+// a provider name or a comma-separated fallback chain. "both" is the legacy
+// name for Ollama falling back to Gemini; empty means Ollama.
+func (ps Providers) Select(setting string) ([]Provider, error) {
+	switch setting {
+	case "":
+		setting = ProviderOllama
+	case "both":
+		setting = ProviderOllama + "," + ProviderGemini
+	}
+	var out []Provider
+	for _, name := range strings.Split(setting, ",") {
+		p, ok := ps[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown AI provider %q", strings.TrimSpace(name))
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// OllamaProvider is an Ollama server as a Provider.
+type OllamaProvider struct {
+	client *OllamaClient
+	url    string
+}
+
+// Provider returns the Ollama server at apiURL as a Provider.
+func (c *OllamaClient) Provider(apiURL string) *OllamaProvider {
+	return &OllamaProvider{client: c, url: apiURL}
+}
+
+func (p *OllamaProvider) Name() string { return ProviderOllama }
+
+func (p *OllamaProvider) Summarize(ctx context.Context, req SummaryRequest) (string, Usage, error) {
+	resp, err := p.client.generateSummary(ctx, p.url, req)
+	return resp, Usage{}, err
+}
+
+func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (string, Usage, error) {
+	resp, err := p.client.generateChatResponse(ctx, p.url, req.Model, req.Context, req.History, req.Message, req.Options, req.OnToken)
+	return resp, Usage{}, err
+}
+
+func (c *GeminiClient) Name() string { return ProviderGemini }
+
+// Summarize summarizes req.Text with the key in req.APIKey. Gemini uses its
+// configured summary model, so req.Model is ignored.
+func (c *GeminiClient) Summarize(ctx context.Context, req SummaryRequest) (string, Usage, error) {
+	if req.APIKey == "" {
+		return "", Usage{}, ErrNoAPIKey
+	}
+	return c.generateSummary(ctx, req)
+}
+
+// Chat answers req.Message with the key in req.APIKey.
+func (c *GeminiClient) Chat(ctx context.Context, req ChatRequest) (string, Usage, error) {
+	if req.APIKey == "" {
+		return "", Usage{}, ErrNoAPIKey
+	}
+	return c.generateChatResponse(ctx, req.APIKey, req.Context, req.History, req.Message, req.Options, req.OnToken)
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// sensitivePrompt asks a model whether a story flagged by the keyword list
+// really is sensitive.
+const sensitivePrompt = `You review stories for a Hacker News reader. A keyword filter flagged the story below as possibly sensitive (sexual content, graphic violence or self-harm). Decide whether it is: a story that merely discusses such topics in a news, technical or research context is NOT sensitive; explicit or graphic content, or content that could distress readers without warning, is.
+
+Reply with only a JSON object: {"sensitive": true or false, "reason": "<one short sentence>"}`
+
+// CheckSensitive asks p whether a keyword-flagged story is sensitive,
+// returning the verdict and the model's reason.
+func CheckSensitive(ctx context.Context, p ChatProvider, model, apiKey, title, text string) (bool, string, Usage, error) {
+	resp, usage, err := p.Chat(ctx, ChatRequest{
+		Context: fmt.Sprintf("Title: %s\n\n%s", title, text),
+		Message: sensitivePrompt,
+		Model:   model,
+		APIKey:  apiKey,
+	})
+	if err != nil {
+		return false, "", usage, err
+	}
+	start, end := strings.IndexByte(resp, '{'), strings.LastIndexByte(resp, '}')
+	if start < 0 || end < start {
+		return false, "", usage, fmt.Errorf("no verdict in model response: %q", resp)
+	}
+	var verdict struct {
+		Sensitive bool   `json:"sensitive"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &verdict); err != nil {
+		return false, "", usage, fmt.Errorf("failed to parse verdict: %w", err)
+	}
+	return verdict.Sensitive, strings.TrimSpace(verdict.Reason), usage, nil
+}
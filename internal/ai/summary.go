@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrNoSummary is returned when a model's response has no usable summary.
+var ErrNoSummary = errors.New("no summary in model response")
+
+// Summary is a parsed summarization response.
+type Summary struct {
+	Points []string // key points, without bullet markers
+	Topics []string
+}
+
+// Text renders the points as the Markdown bullet list stored as a story's
+// summary.
+func (s Summary) Text() string {
+	lines := make([]string, len(s.Points))
+	for i, p := range s.Points {
+		lines[i] = "- " + p
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bulletPrefix matches a list marker at the start of a line: "-", "•", "*",
+// "+" or a number like "1." or "2)".
+var bulletPrefix = regexp.MustCompile(`^(?:[-•*+]|\d{1,2}[.)])\s+`)
+
+// ParseSummaryResponse parses a Summarizer's response. Models are asked for
+// {"summary": [...], "topics": [...]} but also wrap it in Markdown fences or
+// prose, nest the arrays, or return the summary as one string; all of these
+// are accepted. A response with no JSON object is taken as the summary
+// itself, one point per line. It returns ErrNoSummary (possibly wrapped) if
+// there's nothing to use, including when the response is a JSON object that
+// can't be parsed or has no summary.
+func ParseSummaryResponse(raw string) (Summary, error) {
+	text := strings.TrimSpace(raw)
+	var decodeErr error
+	for i := strings.IndexByte(text, '{'); i >= 0; {
+		var obj struct {
+			Summary any `json:"summary"`
+			Topics  any `json:"topics"`
+		}
+		err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&obj)
+		if err == nil {
+			if points := summaryPoints(flattenStrings(obj.Summary)); len(points) > 0 {
+				return Summary{Points: points, Topics: summaryTopics(obj.Topics)}, nil
+			}
+		} else if decodeErr == nil {
+			decodeErr = err
+		}
+		next := strings.IndexByte(text[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+
+	// No object with a summary: if the response was meant to be JSON, it
+	// failed; otherwise it's plain text.
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(l), "```") {
+			lines = append(lines, l)
+		}
+	}
+	if body := strings.TrimSpace(strings.Join(lines, "\n")); strings.HasPrefix(body, "{") {
+		if decodeErr != nil {
+			return Summary{}, fmt.Errorf("%w: %v", ErrNoSummary, decodeErr)
+		}
+		return Summary{}, ErrNoSummary
+	}
+	points := summaryPoints(lines)
+	if len(points) == 0 {
+		return Summary{}, ErrNoSummary
+	}
+	return Summary{Points: points}, nil
+}
+
+// flattenStrings collects the strings in a decoded JSON value, flattening
+// nested arrays ([["a"], "b"]) and taking objects' values in key order.
+// Numbers, booleans and nulls are dropped.
+func flattenStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			out = append(out, flattenStrings(item)...)
+		}
+		return out
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var out []string
+		for _, k := range keys {
+			out = append(out, flattenStrings(v[k])...)
+		}
+		return out
+	}
+	return nil
+}
+
+// summaryPoints splits texts into lines and strips their bullet markers,
+// dropping empty ones.
+func summaryPoints(texts []string) []string {
+	var points []string
+	for _, t := range texts {
+		for _, l := range strings.Split(t, "\n") {
+			l = strings.TrimSpace(bulletPrefix.ReplaceAllString(strings.TrimSpace(l), ""))
+			if l != "" {
+				points = append(points, l)
+			}
+		}
+	}
+	return points
+}
+
+// summaryTopics returns the topic tags, trimmed and without duplicates. A
+// single string is taken as a comma-separated list.
+func summaryTopics(v any) []string {
+	var tags []string
+	if s, ok := v.(string); ok {
+		tags = strings.Split(s, ",")
+	} else {
+		tags = flattenStrings(v)
+	}
+	var topics []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#"))
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		topics = append(topics, t)
+	}
+	return topics
+}
@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSummaryResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		points []string
+		topics []string
+	}{
+		{
+			name:   "plain JSON",
+			raw:    `{"summary": ["First point.", "Second point."], "topics": ["Go", "Databases"]}`,
+			points: []string{"First point.", "Second point."},
+			topics: []string{"Go", "Databases"},
+		},
+		{
+			name:   "markdown fence",
+			raw:    "```json\n{\"summary\": [\"A\"], \"topics\": [\"x\"]}\n```",
+			points: []string{"A"},
+			topics: []string{"x"},
+		},
+		{
+			name:   "unclosed fence",
+			raw:    "```\n{\"summary\": [\"A\"]}",
+			points: []string{"A"},
+		},
+		{
+			name:   "prose around the object",
+			raw:    "Sure! Here is the {requested} summary:\n{\"summary\": [\"A\", \"B\"], \"topics\": []}\nLet me know if you need more.",
+			points: []string{"A", "B"},
+		},
+		{
+			name:   "nested arrays",
+			raw:    `{"summary": [["A"], ["B", "C"], [[["D"]]]], "topics": [["Rust"], "WASM"]}`,
+			points: []string{"A", "B", "C", "D"},
+			topics: []string{"Rust", "WASM"},
+		},
+		{
+			name:   "summary as one string with bullets",
+			raw:    `{"summary": "- A\n• B\n1. C\n\n*emphasis* stays", "topics": "AI, #ML, ai"}`,
+			points: []string{"A", "B", "C", "*emphasis* stays"},
+			topics: []string{"AI", "ML"},
+		},
+		{
+			name:   "objects in the array",
+			raw:    `{"summary": [{"point": "A"}, {"point": "B", "detail": 3}]}`,
+			points: []string{"A", "B"},
+		},
+		{
+			name:   "topics of the wrong type",
+			raw:    `{"summary": ["A"], "topics": 5}`,
+			points: []string{"A"},
+		},
+		{
+			name:   "plain text fallback",
+			raw:    "The post argues X.\n- It also shows Y.\n",
+			points: []string{"The post argues X.", "It also shows Y."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSummaryResponse(tt.raw)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.points, got.Points)
+			assert.Equal(t, tt.topics, got.Topics)
+		})
+	}
+}
+
+func TestParseSummaryResponseErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"   \n```\n```",
+		`{"summary": ["A", "B"`,
+		"```json\n{\"summary\": [\"A\",\n```",
+		`{"summary": [], "topics": ["x"]}`,
+		`{"points": ["A"]}`,
+		`{"summary": [1, true, null]}`,
+	} {
+		_, err := ParseSummaryResponse(raw)
+		assert.True(t, errors.Is(err, ErrNoSummary), "%q: got %v", raw, err)
+	}
+}
+
+func TestSummaryText(t *testing.T) {
+	s := Summary{Points: []string{"A", "B"}}
+	assert.Equal(t, "- A\n- B", s.Text())
+}
+
+func FuzzParseSummaryResponse(f *testing.F) {
+	for _, seed := range []string{
+		`{"summary": ["A", "B"], "topics": ["x"]}`,
+		"```json\n{\"summary\": [[\"A\"]]}\n```",
+		`{"summary": "- A\n- B", "topics": "x, y"}`,
+		`text {"summary": {"a": ["b"]}} more`,
+		`{"summary": ["A"`,
+		"plain\ntext",
+		"{{{",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		got, err := ParseSummaryResponse(raw)
+		if err != nil {
+			if !errors.Is(err, ErrNoSummary) {
+				t.Fatalf("error %v doesn't wrap ErrNoSummary", err)
+			}
+			return
+		}
+		if len(got.Points) == 0 {
+			t.Fatal("no points without an error")
+		}
+		for _, p := range got.Points {
+			if p == "" || strings.Contains(p, "\n") || p != strings.TrimSpace(p) {
+				t.Fatalf("bad point %q", p)
+			}
+		}
+		seen := make(map[string]bool)
+		for _, topic := range got.Topics {
+			if topic == "" || seen[strings.ToLower(topic)] {
+				t.Fatalf("bad or duplicate topic %q", topic)
+			}
+			seen[strings.ToLower(topic)] = true
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoTriage is returned when a model's response has no usable triage.
+var ErrNoTriage = errors.New("no triage in model response")
+
+// TriagePrompt asks a model to triage a reading list given as numbered
+// stories, e.g. "[1] Title ...", in the chat context.
+const TriagePrompt = `Triage my reading list above. Group the stories by theme, suggest the order to read them in (most worthwhile or timely first), and point out any I could skip, e.g. because another story covers the same ground or it's unlikely to still be relevant.
+
+Refer to stories by their number. Reply with only a JSON object:
+{"groups": [{"theme": "<short name>", "stories": [1, 4]}], "order": [4, 1], "skip": [{"story": 2, "reason": "<one short sentence>"}]}`
+
+// Triage is a parsed reading-list triage. Stories are the 1-based numbers
+// they were given in the prompt.
+type Triage struct {
+	Groups []TriageGroup
+	Order  []int // every story, in the suggested reading order
+	Skip   []TriageSkip
+}
+
+// TriageGroup is a theme and the stories under it.
+type TriageGroup struct {
+	Theme   string
+	Stories []int
+}
+
+// TriageSkip is a story the model suggests skipping, and why.
+type TriageSkip struct {
+	Story  int
+	Reason string
+}
+
+// ParseTriage parses a response to TriagePrompt about n stories. Numbers
+// outside 1..n and repeats are dropped. Stories the model left out are
+// appended to the order, and those in no (named) group are gathered under
+// "Other", so every story appears exactly once in each.
+func ParseTriage(raw string, n int) (Triage, error) {
+	start, end := strings.IndexByte(raw, '{'), strings.LastIndexByte(raw, '}')
+	if start < 0 || end < start {
+		return Triage{}, ErrNoTriage
+	}
+	var resp struct {
+		Groups []struct {
+			Theme   string `json:"theme"`
+			Stories []int  `json:"stories"`
+		} `json:"groups"`
+		Order []int `json:"order"`
+		Skip  []struct {
+			Story  int    `json:"story"`
+			Reason string `json:"reason"`
+		} `json:"skip"`
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &resp); err != nil {
+		return Triage{}, fmt.Errorf("%w: %v", ErrNoTriage, err)
+	}
+	if len(resp.Groups) == 0 && len(resp.Order) == 0 {
+		return Triage{}, ErrNoTriage
+	}
+
+	var t Triage
+	grouped := make(map[int]bool, n)
+	for _, g := range resp.Groups {
+		group := TriageGroup{Theme: strings.TrimSpace(g.Theme)}
+		if group.Theme == "" {
+			continue
+		}
+		for _, i := range g.Stories {
+			if i >= 1 && i <= n && !grouped[i] {
+				grouped[i] = true
+				group.Stories = append(group.Stories, i)
+			}
+		}
+		if len(group.Stories) > 0 {
+			t.Groups = append(t.Groups, group)
+		}
+	}
+	var other []int
+	for i := 1; i <= n; i++ {
+		if !grouped[i] {
+			other = append(other, i)
+		}
+	}
+	if len(other) > 0 {
+		t.Groups = append(t.Groups, TriageGroup{Theme: "Other", Stories: other})
+	}
+
+	ordered := make(map[int]bool, n)
+	for _, i := range resp.Order {
+		if i >= 1 && i <= n && !ordered[i] {
+			ordered[i] = true
+			t.Order = append(t.Order, i)
+		}
+	}
+	for i := 1; i <= n; i++ {
+		if !ordered[i] {
+			t.Order = append(t.Order, i)
+		}
+	}
+
+	skipped := make(map[int]bool)
+	for _, s := range resp.Skip {
+		if s.Story >= 1 && s.Story <= n && !skipped[s.Story] {
+			skipped[s.Story] = true
+			t.Skip = append(t.Skip, TriageSkip{Story: s.Story, Reason: strings.TrimSpace(s.Reason)})
+		}
+	}
+	return t, nil
+}
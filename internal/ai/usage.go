@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Usage is the token count of one cloud model call.
+type Usage struct {
+	Provider     string
+	Model        string
+	PromptTokens int
+	OutputTokens int
+}
+
+// modelPrices are list prices in USD per million input and output tokens.
+// Keys match model names by prefix, so dated variants share a price.
+var modelPrices = map[string][2]float64{
+	"gemini-2.5-pro":        {1.25, 10.00},
+	"gemini-2.5-flash-lite": {0.10, 0.40},
+	"gemini-2.5-flash":      {0.30, 2.50},
+	"gemini-2.0-flash-lite": {0.075, 0.30},
+	"gemini-2.0-flash":      {0.10, 0.40},
+	"gpt-4o-mini":           {0.15, 0.60},
+	"gpt-4o":                {2.50, 10.00},
+	"gpt-4.1-nano":          {0.10, 0.40},
+	"gpt-4.1-mini":          {0.40, 1.60},
+	"gpt-4.1":               {2.00, 8.00},
+}
+
+// EstimateCost returns the call's estimated cost in USD, or 0 for models
+// without a known price.
+func (u Usage) EstimateCost() float64 {
+	var price [2]float64
+	best := 0
+	for name, p := range modelPrices {
+		if strings.HasPrefix(u.Model, name) && len(name) > best {
+			price, best = p, len(name)
+		}
+	}
+	return (float64(u.PromptTokens)*price[0] + float64(u.OutputTokens)*price[1]) / 1e6
+}
+
+func geminiUsage(model string, resp *genai.GenerateContentResponse) Usage {
+	u := Usage{Provider: "gemini", Model: model}
+	if resp != nil && resp.UsageMetadata != nil {
+		u.PromptTokens = int(resp.UsageMetadata.PromptTokenCount)
+		u.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	}
+	return u
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetThemes serves GET /api/analytics/themes: this week's emergent
+// themes, found by clustering story embeddings (see cmd/themes). week=
+// (YYYY-MM-DD, any day of the week) selects an earlier week; by default the
+// latest clustered week is returned.
+func (s *Server) handleGetThemes(w http.ResponseWriter, r *http.Request) {
+	var week time.Time
+	if v := r.URL.Query().Get("week"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "week must be a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+		week = storage.WeekStart(t)
+	}
+
+	weekStart, themes, err := s.store.GetWeeklyThemes(r.Context(), week)
+	if err != nil {
+		log.Printf("Failed to fetch themes: %v", err)
+		http.Error(w, "Failed to fetch themes", http.StatusInternalServerError)
+		return
+	}
+	if themes == nil {
+		themes = []storage.Theme{}
+	}
+	if err := s.attachThemeStories(r.Context(), themes); err != nil {
+		log.Printf("Failed to load theme stories: %v", err)
+		http.Error(w, "Failed to fetch themes", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]interface{}{"themes": themes}
+	if !weekStart.IsZero() {
+		resp["week_start"] = weekStart.Format("2006-01-02")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// attachThemeStories loads every theme's stories with a single query.
+func (s *Server) attachThemeStories(ctx context.Context, themes []storage.Theme) error {
+	var ids []int64
+	for _, t := range themes {
+		ids = append(ids, t.StoryIDs...)
+	}
+	stories, err := s.store.GetStoriesByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]storage.Story, len(stories))
+	for _, st := range stories {
+		byID[st.ID] = st
+	}
+	for i := range themes {
+		for _, id := range themes[i].StoryIDs {
+			if st, ok := byID[id]; ok {
+				themes[i].Stories = append(themes[i].Stories, st)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	maxAnnouncementTitle = 200
+	maxAnnouncementBody  = 2000
+)
+
+var announcementLevels = map[string]bool{
+	storage.AnnouncementInfo:        true,
+	storage.AnnouncementWarning:     true,
+	storage.AnnouncementMaintenance: true,
+}
+
+// handleGetAnnouncements returns the active announcements. Signed-in users
+// don't see the ones they've dismissed; anonymous visitors get them all.
+func (s *Server) handleGetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	announcements, err := s.store.GetActiveAnnouncements(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch announcements: %v", err)
+		http.Error(w, "Failed to fetch announcements", http.StatusInternalServerError)
+		return
+	}
+	if announcements == nil {
+		announcements = []storage.Announcement{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"announcements": announcements})
+}
+
+func (s *Server) handleDismissAnnouncement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.DismissAnnouncement(r.Context(), userID, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Announcement not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to dismiss announcement %d: %v", id, err)
+		http.Error(w, "Failed to dismiss announcement", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleGetAdminAnnouncements(w http.ResponseWriter, r *http.Request) {
+	announcements, err := s.store.GetAllAnnouncements(r.Context())
+	if err != nil {
+		log.Printf("Failed to fetch announcements: %v", err)
+		http.Error(w, "Failed to fetch announcements", http.StatusInternalServerError)
+		return
+	}
+	if announcements == nil {
+		announcements = []storage.Announcement{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"announcements": announcements})
+}
+
+// handleCreateAnnouncement publishes an announcement. Body:
+// {"title", "body", "level", "starts_at", "ends_at"}; only title is required.
+func (s *Server) handleCreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title    string     `json:"title"`
+		Body     string     `json:"body"`
+		Level    string     `json:"level"`
+		StartsAt *time.Time `json:"starts_at"`
+		EndsAt   *time.Time `json:"ends_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	a := storage.Announcement{
+		Title:  strings.TrimSpace(body.Title),
+		Body:   strings.TrimSpace(body.Body),
+		Level:  body.Level,
+		EndsAt: body.EndsAt,
+	}
+	if a.Level == "" {
+		a.Level = storage.AnnouncementInfo
+	}
+	if body.StartsAt != nil {
+		a.StartsAt = *body.StartsAt
+	}
+	switch {
+	case a.Title == "":
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	case len(a.Title) > maxAnnouncementTitle:
+		http.Error(w, "Title is too long", http.StatusBadRequest)
+		return
+	case len(a.Body) > maxAnnouncementBody:
+		http.Error(w, "Body is too long", http.StatusBadRequest)
+		return
+	case !announcementLevels[a.Level]:
+		http.Error(w, "Level must be info, warning or maintenance", http.StatusBadRequest)
+		return
+	case a.EndsAt != nil && (!a.EndsAt.After(a.StartsAt) || !a.EndsAt.After(time.Now())):
+		http.Error(w, "ends_at must be in the future and after starts_at", http.StatusBadRequest)
+		return
+	}
+
+	created, err := s.store.CreateAnnouncement(r.Context(), s.auth.GetUserIDFromRequest(r), a)
+	if err != nil {
+		log.Printf("Failed to create announcement: %v", err)
+		http.Error(w, "Failed to create announcement", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (s *Server) handleDeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.DeleteAnnouncement(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Announcement not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete announcement %d: %v", id, err)
+		http.Error(w, "Failed to delete announcement", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -1,15 +1,17 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
 func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request) {
@@ -26,15 +28,17 @@ func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	user, err := s.store.GetAuthUser(r.Context(), userID)
+	user, err := s.getAuthUser(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "User not found", http.StatusInternalServerError)
 		return
 	}
 
 	if user.GeminiAPIKey == "" {
-		http.Error(w, "Please set your Gemini API Key in Settings to use this feature.", http.StatusBadRequest)
-		return
+		if _, _, err := s.store.GetWorkspaceGeminiKey(r.Context(), userID); err != nil {
+			http.Error(w, "Please set your Gemini API Key in Settings to use this feature.", http.StatusBadRequest)
+			return
+		}
 	}
 
 	story, err := s.store.GetStory(r.Context(), id)
@@ -43,155 +47,148 @@ func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 1. Check Global Cache (Short-circuit if already summarized)
-	if story.Summary != nil && *story.Summary != "" {
+	// 1. Check Global Cache (Short-circuit if already summarized and the
+	// article hasn't changed since)
+	if story.Summary != nil && *story.Summary != "" && !story.SummaryStale {
 		// Save to chat history so user sees it in their thread too
 		if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Article Summary of \"%s\":**\n\n%s", story.Title, *story.Summary)); err != nil {
 			log.Printf("Failed to save cached summary to history: %v", err)
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"summary": *story.Summary})
+		writeResult(w, newEventStream(w, r), map[string]string{"summary": *story.Summary})
 		return
 	}
 
-	// 2. Fetch and Parse Article
-	var textContent string
-	var errFetch error
-
-	if story.URL != "" {
-		content, _, _, _, err := s.fetchArticleContent(story.URL)
-		if err == nil {
-			// For summarization, we'd prefer text content, but Go-Readability's Content is HTML.
-			// Ideally we should strip tags for Gemini to save tokens, but Gemini handles HTML fine.
-			// Let's use the content we got.
-			textContent = content
-		} else {
-			errFetch = err
-		}
-	} else {
-		// Text-only post
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"summary": "This is a text-only post (Ask HN / Show HN) with no external link. Please use 'Summarize Discussion' to summarize the comments."})
+	es := newEventStream(w, r)
+	if story.URL == "" && story.Text == "" {
+		// Text-only post without a body
+		writeResult(w, es, map[string]string{"summary": "This is a text-only post (Ask HN / Show HN) with no external link. Please use 'Summarize Discussion' to summarize the comments."})
 		return
 	}
 
-	if errFetch != nil || len(textContent) < 100 {
-		http.Error(w, "Failed to fetch article content. It might be behind a paywall or inaccessible.", http.StatusBadGateway)
+	summary, topics, err := s.summarizeArticle(r.Context(), userID, story, es)
+	if errors.Is(err, errArticleUnavailable) {
+		writeStreamError(w, es, "Failed to fetch article content. It might be behind a paywall or inaccessible.", http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		writeStreamError(w, es, "Failed to generate summary: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// 3. Summarize with Gemini (now Ollama)
-	// Truncate content for CPU inference speed (6000 chars ~ 1500 words)
-	finalContent := textContent
-	if len(finalContent) > 20000 {
-		finalContent = finalContent[:20000] + "..."
+	// 2. Save to Global Cache
+	if err := s.store.UpdateStorySummaryAndTopics(r.Context(), id, summary, topics); err != nil {
+		log.Printf("Failed to update story summary/topics cache: %v", err)
 	}
-	// If it's raw HTML, we might want to strip script/style tags if possible, but Gemini handles it okay.
-	// For now, raw HTML is better than nothing.
 
-	// Determine provider preference
-	provider, _ := s.store.GetSetting(r.Context(), "ai_provider")
-	if provider == "" {
-		provider = "local"
+	// 3. Save to Chat History
+	if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Article Summary of \"%s\":**\n\n%s", story.Title, summary)); err != nil {
+		log.Printf("Failed to save summary to history: %v", err)
 	}
 
-	var responseStr string
-	var summarizeErr error
+	writeResult(w, es, map[string]interface{}{
+		"summary": summary,
+		"topics":  topics,
+	})
+}
 
-	// 1. Try Local Ollama if provider is "local" or "both"
-	if provider == "local" || provider == "both" {
-		ollamaURL := os.Getenv("OLLAMA_URL")
-		if ollamaURL == "" {
-			ollamaURL = "http://localhost:11434"
-		}
-		model, _ := s.store.GetSetting(r.Context(), "ollama_model")
-		responseStr, err = s.aiClient.GenerateSummary(r.Context(), ollamaURL, model, story.Title, finalContent)
+// errArticleUnavailable is returned when a story's article can't be fetched
+// or has too little text to summarize.
+var errArticleUnavailable = errors.New("article content unavailable")
+
+// summarizeArticle fetches the story's article, or takes the body of a text
+// post, and summarizes it with the configured AI provider, falling back to
+// Gemini, streaming the model's output to es if it's set. It doesn't cache
+// the result.
+func (s *Server) summarizeArticle(ctx context.Context, userID string, story *storage.Story, es *eventStream) (string, []string, error) {
+	id := int(story.ID)
+	if story.URL == "" {
+		// Ask/Tell HN: the post body stands in for the article.
+		s.setSummaryStatus(ctx, id, storage.SummaryGenerating)
+		summary, topics, err := s.summarizeText(ctx, userID, story.Title, content.PlainText(story.Text), es)
 		if err != nil {
-			summarizeErr = err
-			log.Printf("Ollama article summarization failed: %v", err)
+			s.recordSummaryFailure(ctx, id, llmFailure(err), err.Error())
 		}
+		return summary, topics, err
 	}
 
-	// 2. Fallback to Gemini if:
-	// - Local failed OR provider is "gemini"
-	// - AND provider is "gemini" or "both"
-	if responseStr == "" && (provider == "gemini" || provider == "both") {
-		geminiKey := user.GeminiAPIKey
-		if geminiKey == "" {
-			geminiKey = os.Getenv("GEMINI_API_KEY")
-		}
+	s.setSummaryStatus(ctx, id, storage.SummaryFetching)
+	res, err := content.FetchArticle(ctx, story.URL)
+	if err != nil {
+		s.recordSummaryFailure(ctx, id, storage.ClassifyFetch(err, 0, false, 0), err.Error())
+		return "", nil, errArticleUnavailable
+	}
+	if code := storage.ClassifyFetch(nil, res.StatusCode, res.Blocked, len(res.Content)); code != "" {
+		s.recordSummaryFailure(ctx, id, code, fmt.Sprintf("HTTP %d, %d bytes of content", res.StatusCode, len(res.Content)))
+		return "", nil, errArticleUnavailable
+	}
+	textContent := res.Content
+	s.recordContentVersion(ctx, id, textContent)
 
-		if geminiKey != "" {
-			log.Printf("Falling back to Gemini for article summary...")
-			// Gemini signature is (ctx, apiKey, text)
-			responseStr, err = s.geminiClient.GenerateSummary(r.Context(), geminiKey, finalContent)
-			if err != nil {
-				log.Printf("Gemini article summarization failed: %v", err)
-				summarizeErr = err
-			}
-		} else {
-			log.Printf("Gemini fallback skipped: No API Key available")
-		}
+	s.setSummaryStatus(ctx, id, storage.SummaryGenerating)
+	summary, topics, err := s.summarizeText(ctx, userID, story.Title, textContent, es)
+	if err != nil {
+		s.recordSummaryFailure(ctx, id, llmFailure(err), err.Error())
 	}
+	return summary, topics, err
+}
 
-	if responseStr == "" {
-		http.Error(w, "Failed to generate summary: "+summarizeErr.Error(), http.StatusInternalServerError)
-		return
+// llmFailure maps a summarization error to its failure code.
+func llmFailure(err error) string {
+	switch {
+	case ai.IsSafetyBlocked(err):
+		return storage.FailureSafetyBlocked
+	case errors.Is(err, ai.ErrNoSummary):
+		return storage.FailureJSONParse
 	}
+	return storage.FailureLLMError
+}
 
-	// Try to parse the JSON
-	cleanJSON := strings.TrimSpace(responseStr)
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```json")
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```")
-	cleanJSON = strings.TrimSuffix(cleanJSON, "```")
-	cleanJSON = strings.TrimSpace(cleanJSON)
-
-	var intermediate struct {
-		Summary interface{} `json:"summary"`
-		Topics  []string    `json:"topics"`
-	}
-
-	var result struct {
-		Summary string
-		Topics  []string
-	}
-
-	if err := json.Unmarshal([]byte(cleanJSON), &intermediate); err != nil {
-		log.Printf("Failed to parse JSON in article summary. Error: %v. Raw: %s", err, responseStr)
-		result.Summary = responseStr // Fallback
-		result.Topics = []string{}
-	} else {
-		// Handle Summary being either a string or an array of strings
-		switch v := intermediate.Summary.(type) {
-		case string:
-			result.Summary = v
-		case []interface{}:
-			var parts []string
-			for _, part := range v {
-				if s, ok := part.(string); ok {
-					parts = append(parts, s)
-				}
-			}
-			result.Summary = strings.Join(parts, " ")
-		default:
-			result.Summary = fmt.Sprintf("%v", v)
-		}
-		result.Topics = intermediate.Topics
+// setSummaryStatus records a story's summary pipeline progress, logging failures.
+func (s *Server) setSummaryStatus(ctx context.Context, id int, status string) {
+	if err := s.store.SetSummaryStatus(ctx, id, status); err != nil {
+		log.Printf("Failed to set summary status for story %d: %v", id, err)
 	}
+}
 
-	// 4. Save to Global Cache
-	if err := s.store.UpdateStorySummaryAndTopics(r.Context(), id, result.Summary, result.Topics); err != nil {
-		log.Printf("Failed to update story summary/topics cache: %v", err)
+// recordSummaryFailure records why a story's summary couldn't be generated,
+// logging failures.
+func (s *Server) recordSummaryFailure(ctx context.Context, id int, code, detail string) {
+	if err := s.store.RecordSummaryFailure(ctx, id, code, detail); err != nil {
+		log.Printf("Failed to record summary failure for story %d: %v", id, err)
 	}
+}
 
-	// 5. Save to Chat History
-	if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Article Summary of \"%s\":**\n\n%s", story.Title, result.Summary)); err != nil {
-		log.Printf("Failed to save summary to history: %v", err)
+// summarizeText summarizes fetched article text, streaming the model's
+// output to es if it's set.
+func (s *Server) summarizeText(ctx context.Context, userID, title, textContent string, es *eventStream) (string, []string, error) {
+	// Truncate content for CPU inference speed
+	finalContent := textContent
+	if len(finalContent) > 20000 {
+		finalContent = finalContent[:20000] + "..."
+	}
+	// If it's raw HTML, we might want to strip script/style tags if possible, but Gemini handles it okay.
+	// For now, raw HTML is better than nothing.
+
+	// Someone asked for this summary, so languages the policy would skip
+	// are translated too.
+	var language string
+	if lang := content.DetectLanguage(textContent); s.languages.Action(lang) != ai.LanguageNative {
+		language = content.LanguageName(lang)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"summary": result.Summary,
-		"topics":  result.Topics,
+	opts := s.generationOptions(ctx, userID)
+	responseStr, err := s.runAI(ctx, userID, true, func(p ai.Provider, model, apiKey string) (string, ai.Usage, error) {
+		es.attempt()
+		return p.Summarize(ctx, ai.SummaryRequest{Title: title, Text: finalContent, Model: model, APIKey: apiKey, Options: opts, Language: language, OnToken: es.onToken()})
 	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsed, err := ai.ParseSummaryResponse(responseStr)
+	if err != nil {
+		log.Printf("Failed to parse article summary: %v. Raw: %s", err, responseStr)
+		return "", nil, err
+	}
+	return parsed.Text(), parsed.Topics, nil
 }
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// recurringSeries are the monthly threads posted by the whoishiring account on
+// the first weekday of each month at 11:00 US Eastern.
+var recurringSeries = []struct {
+	uid    string
+	prefix string
+}{
+	{"who-is-hiring", "Ask HN: Who is hiring?"},
+	{"who-wants-to-be-hired", "Ask HN: Who wants to be hired?"},
+	{"freelancer", "Ask HN: Freelancer? Seeking freelancer?"},
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// vtimezoneNewYork is the America/New_York definition the recurring events
+// are anchored to.
+const vtimezoneNewYork = "BEGIN:VTIMEZONE\r\n" +
+	"TZID:America/New_York\r\n" +
+	"BEGIN:DAYLIGHT\r\n" +
+	"TZOFFSETFROM:-0500\r\n" +
+	"TZOFFSETTO:-0400\r\n" +
+	"TZNAME:EDT\r\n" +
+	"DTSTART:19700308T020000\r\n" +
+	"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU\r\n" +
+	"END:DAYLIGHT\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"TZOFFSETFROM:-0400\r\n" +
+	"TZOFFSETTO:-0500\r\n" +
+	"TZNAME:EST\r\n" +
+	"DTSTART:19701101T020000\r\n" +
+	"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n"
+
+// handleGetCalendarURL returns the user's personal calendar feed URL.
+func (s *Server) handleGetCalendarURL(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"url": s.publicBaseURL(r) + "/api/calendar.ics?token=" + url.QueryEscape(s.auth.CalendarToken(userID)),
+	})
+}
+
+// handleCalendarFeed serves an iCalendar feed of the recurring HN threads. With
+// a token from /api/me/calendar it also includes the Launch HN threads the
+// user has saved.
+func (s *Server) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	var userID string
+	if token := r.URL.Query().Get("token"); token != "" {
+		if userID = s.auth.UserIDFromCalendarToken(token); userID == "" {
+			http.Error(w, "Invalid calendar token", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	recurring, err := s.store.GetRecurringThreads(r.Context(), now.AddDate(0, -2, 0))
+	if err != nil {
+		log.Printf("Failed to fetch recurring threads: %v", err)
+		http.Error(w, "Failed to build calendar", http.StatusInternalServerError)
+		return
+	}
+	var launches []storage.Story
+	if userID != "" {
+		launches, err = s.store.GetSavedLaunchThreads(r.Context(), userID, now.AddDate(0, -3, 0))
+		if err != nil {
+			log.Printf("Failed to fetch launch threads: %v", err)
+			http.Error(w, "Failed to build calendar", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	base := s.publicBaseURL(r)
+	host := r.Host
+	stamp := now.Format(icsTimeFormat)
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\n")
+	sb.WriteString("VERSION:2.0\r\n")
+	sb.WriteString("PRODID:-//HN Station//Calendar//EN\r\n")
+	sb.WriteString("CALSCALE:GREGORIAN\r\n")
+	sb.WriteString("METHOD:PUBLISH\r\n")
+	sb.WriteString("X-WR-CALNAME:HN Station\r\n")
+	sb.WriteString(vtimezoneNewYork)
+
+	for _, series := range recurringSeries {
+		desc := "Monthly thread posted by " + storage.HiringAccount + " on Hacker News."
+		link := "https://news.ycombinator.com/submitted?id=" + storage.HiringAccount
+		for _, st := range recurring {
+			if strings.HasPrefix(st.Title, series.prefix) {
+				link = s.storyLink(base, st.ID)
+				desc += "\n\nLatest: " + st.Title + "\n" + link
+				break
+			}
+		}
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		writeICSLine(&sb, "UID", series.uid+"@"+host)
+		writeICSLine(&sb, "DTSTAMP", stamp)
+		// 2024-01-01 was the first weekday of its month; the rule carries it forward.
+		sb.WriteString("DTSTART;TZID=America/New_York:20240101T110000\r\n")
+		sb.WriteString("DURATION:PT1H\r\n")
+		sb.WriteString("RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1\r\n")
+		writeICSLine(&sb, "SUMMARY", series.prefix)
+		writeICSLine(&sb, "DESCRIPTION", desc)
+		writeICSLine(&sb, "URL", link)
+		sb.WriteString("END:VEVENT\r\n")
+	}
+
+	for _, st := range launches {
+		link := s.storyLink(base, st.ID)
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		writeICSLine(&sb, "UID", fmt.Sprintf("story-%d@%s", st.ID, host))
+		writeICSLine(&sb, "DTSTAMP", stamp)
+		writeICSLine(&sb, "DTSTART", st.PostedAt.UTC().Format(icsTimeFormat))
+		sb.WriteString("DURATION:PT1H\r\n")
+		writeICSLine(&sb, "SUMMARY", st.Title)
+		writeICSLine(&sb, "DESCRIPTION", fmt.Sprintf("%s\nhttps://news.ycombinator.com/item?id=%d", link, st.ID))
+		writeICSLine(&sb, "URL", link)
+		sb.WriteString("END:VEVENT\r\n")
+	}
+	sb.WriteString("END:VCALENDAR\r\n")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="hn-station.ics"`)
+	w.Write([]byte(sb.String()))
+}
+
+// storyLink is a link that opens the story in the HN Station frontend, or at
+// base if there's no separate frontend.
+func (s *Server) storyLink(base string, storyID int64) string {
+	front := s.cfg.FrontendURL
+	if front == "" {
+		front = base
+	}
+	return fmt.Sprintf("%s/?story=%d", strings.TrimRight(front, "/"), storyID)
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeICSLine writes an escaped content line, folded at 75 octets as
+// RFC 5545 requires.
+func writeICSLine(sb *strings.Builder, name, value string) {
+	line := name + ":" + value
+	if name != "DTSTAMP" && name != "DTSTART" && name != "URL" {
+		line = name + ":" + icsEscaper.Replace(value)
+	}
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 { // don't split a UTF-8 sequence
+			cut--
+		}
+		sb.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	sb.WriteString(line + "\r\n")
+}
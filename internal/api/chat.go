@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	pgvector "github.com/pgvector/pgvector-go"
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	frontPageChatMaxStories = 12
+	frontPageChatMaxChars   = 20000
+)
+
+type chatSource struct {
+	ID         int64    `json:"id"`
+	Title      string   `json:"title"`
+	URL        string   `json:"url"`
+	HNURL      string   `json:"hn_url"`
+	Similarity *float64 `json:"similarity,omitempty"`
+}
+
+// handleFrontPageChat answers a question across all recently summarized stories
+// ("ask the front page"). The most relevant summaries are retrieved by keyword
+// and, when story embeddings exist, by vector similarity, then passed to the
+// configured AI provider as context.
+func (s *Server) handleFrontPageChat(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" && !s.localMode {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Message string `json:"message"`
+		History []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"history"`
+		Days int `json:"days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Message = strings.TrimSpace(body.Message)
+	if body.Message == "" {
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	if body.Days <= 0 {
+		body.Days = 7
+	}
+	if body.Days > 30 {
+		body.Days = 30
+	}
+	since := time.Now().AddDate(0, 0, -body.Days)
+
+	stories, err := s.retrieveChatStories(r.Context(), body.Message, since)
+	if err != nil {
+		log.Printf("Front-page chat retrieval failed: %v", err)
+		http.Error(w, "Failed to search stories", http.StatusInternalServerError)
+		return
+	}
+	if len(stories) == 0 {
+		writeResult(w, newEventStream(w, r), map[string]interface{}{
+			"response": "There are no summarized stories from that period yet, so I can't answer that.",
+			"sources":  []chatSource{},
+		})
+		return
+	}
+
+	contextText, sources := buildFrontPageContext(stories)
+
+	history := make([]ai.ChatMessage, 0, len(body.History))
+	for _, m := range body.History {
+		history = append(history, ai.ChatMessage{Role: m.Role, Content: m.Content})
+	}
+
+	opts := s.generationOptions(r.Context(), userID)
+	es := newEventStream(w, r)
+	response, chatErr := s.runAI(r.Context(), userID, false, func(p ai.Provider, model, apiKey string) (string, ai.Usage, error) {
+		es.attempt()
+		return p.Chat(r.Context(), ai.ChatRequest{Context: contextText, History: history, Message: body.Message, Model: model, APIKey: apiKey, Options: opts, OnToken: es.onToken()})
+	})
+
+	if response == "" {
+		errMsg := "Failed to generate response"
+		if chatErr != nil {
+			errMsg += ": " + chatErr.Error()
+		}
+		if es.started() {
+			es.send("error", map[string]string{"error": errMsg})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": errMsg})
+		return
+	}
+
+	writeResult(w, es, map[string]interface{}{
+		"response": response,
+		"sources":  sources,
+	})
+}
+
+// retrieveChatStories merges keyword matches with semantic matches (if an
+// embedding model is reachable), keyword hits first, deduplicated by ID.
+func (s *Server) retrieveChatStories(ctx context.Context, question string, since time.Time) ([]storage.Story, error) {
+	stories, err := s.store.SearchSummarizedStories(ctx, question, since, frontPageChatMaxStories)
+	if err != nil {
+		return nil, err
+	}
+	if s.embedder == nil {
+		return stories, nil
+	}
+
+	embedCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	vec, err := s.embedder.EmbedQuery(embedCtx, question)
+	if err != nil {
+		// Embeddings are optional; keyword retrieval alone is still useful.
+		return stories, nil
+	}
+	semantic, _, err := s.store.SearchStories(ctx, pgvector.NewVector(vec), s.embedder.Model(), frontPageChatMaxStories, 0)
+	if err != nil {
+		log.Printf("Front-page chat semantic search failed: %v", err)
+		return stories, nil
+	}
+
+	seen := make(map[int64]bool, len(stories))
+	for _, st := range stories {
+		seen[st.ID] = true
+	}
+	for _, st := range semantic {
+		if len(stories) >= frontPageChatMaxStories {
+			break
+		}
+		if seen[st.ID] || st.Summary == nil || *st.Summary == "" || st.PostedAt.Before(since) {
+			continue
+		}
+		seen[st.ID] = true
+		stories = append(stories, st)
+	}
+	return stories, nil
+}
+
+// buildFrontPageContext renders the retrieved stories as numbered context
+// blocks the model can cite, capped at frontPageChatMaxChars.
+func buildFrontPageContext(stories []storage.Story) (string, []chatSource) {
+	var sb strings.Builder
+	sb.WriteString("You are answering questions about recent Hacker News stories using ONLY the story summaries below. ")
+	sb.WriteString("Cite the stories you use by their number, e.g. [2]. If the summaries don't cover the question, say so.\n\n")
+
+	sources := make([]chatSource, 0, len(stories))
+	for i, st := range stories {
+		hnURL := fmt.Sprintf("https://news.ycombinator.com/item?id=%d", st.ID)
+		block := fmt.Sprintf("[%d] %s (posted %s, %d points, %d comments) %s\n",
+			i+1, st.Title, st.PostedAt.Format("2006-01-02"), st.Score, st.Descendants, hnURL)
+		if len(st.Topics) > 0 {
+			block += "Topics: " + strings.Join(st.Topics, ", ") + "\n"
+		}
+		block += "Summary:\n" + *st.Summary + "\n\n"
+
+		if sb.Len()+len(block) > frontPageChatMaxChars {
+			break
+		}
+		sb.WriteString(block)
+		sources = append(sources, chatSource{
+			ID:         st.ID,
+			Title:      st.Title,
+			URL:        st.URL,
+			HNURL:      hnURL,
+			Similarity: st.Similarity,
+		})
+	}
+	return sb.String(), sources
+}
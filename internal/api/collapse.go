@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Thread collapsing heuristics. Each comment gets a signal score in [0, 1]
+// from its length, its author's karma and its overlap with the story's title
+// and AI-generated topics. A subtree is suggested collapsed when its mean
+// signal is low, or when it is a long back-and-forth between few authors.
+// A spam suspect (the same text posted on several stories) is collapsed
+// with its replies whatever their size.
+const (
+	collapseMinSize    = 4    // subtrees smaller than this are never collapsed
+	collapseCutoff     = 0.3  // mean signal below which a subtree is collapsed
+	flameMinSize       = 6    // a flame war needs at least this many comments...
+	flameMinDepth      = 4    // ...nested at least this deep...
+	flameAuthorShare   = 0.34 // ...written by at most this share of distinct authors
+	collapseFullWords  = 60   // comments this long get the full length score
+	collapseFullKarma  = 4.0  // log10 of the karma that gets the full karma score
+	collapseRelevantAt = 2    // story terms a comment must mention for full relevance
+)
+
+// suggestCollapsed sets SuggestedCollapsed on the roots of low-signal
+// subtrees. comments must hold whole threads in display order. Only the
+// topmost comment of a collapsed subtree is flagged.
+func (s *Server) suggestCollapsed(ctx context.Context, story *storage.Story, comments []storage.Comment) {
+	if len(comments) == 0 {
+		return
+	}
+	authors := make([]string, 0, len(comments))
+	for _, c := range comments {
+		if c.By != "" {
+			authors = append(authors, c.By)
+		}
+	}
+	karma, err := s.store.GetUserKarma(ctx, authors)
+	if err != nil {
+		log.Printf("Failed to load comment author karma for story %d: %v", story.ID, err)
+	}
+	markCollapsed(comments, storyTerms(story), karma)
+}
+
+// subtreeStats aggregates a comment and everything below it.
+type subtreeStats struct {
+	size    int
+	signal  float64 // sum over the subtree
+	depth   int     // longest reply chain below the root
+	authors map[string]bool
+}
+
+func markCollapsed(comments []storage.Comment, terms map[string]bool, karma map[string]int) {
+	children := make(map[int64][]int)
+	var roots []int
+	index := make(map[int64]int, len(comments))
+	for i, c := range comments {
+		index[c.ID] = i
+	}
+	for i, c := range comments {
+		if c.ParentID != nil {
+			if _, ok := index[*c.ParentID]; ok {
+				children[*c.ParentID] = append(children[*c.ParentID], i)
+				continue
+			}
+		}
+		roots = append(roots, i)
+	}
+
+	stats := make([]subtreeStats, len(comments))
+	var collect func(i int)
+	collect = func(i int) {
+		c := comments[i]
+		st := subtreeStats{size: 1, signal: commentSignal(c, terms, karma), authors: map[string]bool{c.By: true}}
+		for _, k := range children[c.ID] {
+			collect(k)
+			ks := stats[k]
+			st.size += ks.size
+			st.signal += ks.signal
+			if ks.depth+1 > st.depth {
+				st.depth = ks.depth + 1
+			}
+			for a := range ks.authors {
+				st.authors[a] = true
+			}
+		}
+		stats[i] = st
+	}
+	var mark func(i int)
+	mark = func(i int) {
+		st := stats[i]
+		if comments[i].SpamSuspect {
+			comments[i].SuggestedCollapsed = true
+			return
+		}
+		if st.size >= collapseMinSize {
+			lowSignal := st.signal/float64(st.size) < collapseCutoff
+			flame := st.size >= flameMinSize && st.depth >= flameMinDepth &&
+				float64(len(st.authors))/float64(st.size) <= flameAuthorShare
+			if lowSignal || flame {
+				comments[i].SuggestedCollapsed = true
+				return
+			}
+		}
+		for _, k := range children[comments[i].ID] {
+			mark(k)
+		}
+	}
+	for _, r := range roots {
+		collect(r)
+		mark(r)
+	}
+}
+
+// commentSignal scores one comment in [0, 1].
+func commentSignal(c storage.Comment, terms map[string]bool, karma map[string]int) float64 {
+	words := tokenize(content.PlainText(c.Text))
+	if len(words) == 0 {
+		return 0 // deleted or empty
+	}
+	length := math.Min(float64(len(words))/collapseFullWords, 1)
+
+	karmaScore := 0.5 // unknown authors are neither trusted nor penalized
+	if k, ok := karma[c.By]; ok {
+		karmaScore = math.Min(math.Log10(float64(max(k, 0))+1)/collapseFullKarma, 1)
+	}
+
+	relevance := 0.5
+	if len(terms) > 0 {
+		seen := make(map[string]bool)
+		for _, w := range words {
+			if terms[w] {
+				seen[w] = true
+			}
+		}
+		relevance = math.Min(float64(len(seen))/collapseRelevantAt, 1)
+	}
+	return 0.4*length + 0.3*karmaScore + 0.3*relevance
+}
+
+// storyTerms returns the distinctive words of a story's title and topics.
+func storyTerms(story *storage.Story) map[string]bool {
+	terms := make(map[string]bool)
+	text := story.Title + " " + strings.Join(story.Topics, " ")
+	for _, w := range tokenize(text) {
+		if len(w) >= 4 && !collapseStopwords[w] {
+			terms[w] = true
+		}
+	}
+	return terms
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var collapseStopwords = map[string]bool{
+	"about": true, "after": true, "their": true, "there": true, "these": true,
+	"this": true, "that": true, "what": true, "when": true, "where": true,
+	"which": true, "with": true, "from": true, "your": true, "have": true,
+	"show": true, "tell": true, "into": true, "more": true, "than": true,
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Comment pages hold whole threads; limits count top-level comments.
+const (
+	defaultCommentThreads = 50
+	maxCommentThreads     = 500
+)
+
+// commentWindow reads a thread limit and offset from the named query
+// parameters, capping the limit at maxCommentThreads.
+func commentWindow(r *http.Request, limitParam, offsetParam string, defaultLimit int) (limit, offset int) {
+	limit = defaultLimit
+	if v, err := strconv.Atoi(r.URL.Query().Get(limitParam)); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxCommentThreads {
+		limit = maxCommentThreads
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get(offsetParam)); err == nil && v >= 0 {
+		offset = v
+	}
+	return limit, offset
+}
+
+// handleGetStoryComments returns a page of a story's comment threads, for
+// long discussions that are too large to send at once.
+func (s *Server) handleGetStoryComments(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.GetStory(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	limit, offset := commentWindow(r, "limit", "offset", defaultCommentThreads)
+	page, err := s.store.GetCommentsPage(r.Context(), id, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch comments for story %d: %v", id, err)
+		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
+		return
+	}
+	s.suggestCollapsed(r.Context(), story, page.Comments)
+	s.hideMutedComments(r.Context(), s.auth.GetUserIDFromRequest(r), page.Comments)
+
+	nextOffset := offset + limit
+	if nextOffset >= page.TotalThreads {
+		nextOffset = 0
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		*storage.CommentPage
+		NextOffset int `json:"next_offset,omitempty"` // absent on the last page
+	}{page, nextOffset})
+}
+
+// handleGetComment resolves a bare HN comment ID to its story and thread
+// context, so links to news.ycombinator.com/item?id=<comment> can deep-link
+// into the story view.
+func (s *Server) handleGetComment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	thread, err := s.store.GetCommentThread(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to resolve comment %d: %v", id, err)
+		http.Error(w, "Failed to fetch comment", http.StatusInternalServerError)
+		return
+	}
+
+	target := []storage.Comment{thread.Comment}
+	s.hideMutedComments(r.Context(), s.auth.GetUserIDFromRequest(r), target, thread.Ancestors, thread.Replies)
+	thread.Comment = target[0]
+	story, err := s.store.GetStory(r.Context(), int(thread.Comment.StoryID))
+	if err != nil {
+		log.Printf("Failed to fetch story %d for comment %d: %v", thread.Comment.StoryID, id, err)
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Story *storage.Story `json:"story"`
+		*storage.CommentThread
+	}{story, thread})
+}
+
+// markNewSinceVisit records the user's visit to the story and reports what
+// changed since the previous one: the story's new comment count and a New
+// flag on comments posted after it.
+func (s *Server) markNewSinceVisit(ctx context.Context, userID string, story *storage.Story, comments []storage.Comment) {
+	prev, err := s.store.RecordStoryVisit(ctx, userID, int(story.ID), story.Descendants)
+	if err != nil {
+		log.Printf("Failed to record visit to story %d: %v", story.ID, err)
+		return
+	}
+	if prev.SeenAt == nil {
+		return
+	}
+	story.LastSeenAt = prev.SeenAt
+	newCount := 0
+	if prev.SeenDescendants != nil {
+		newCount = max(story.Descendants-*prev.SeenDescendants, 0)
+	}
+	story.NewCommentCount = &newCount
+	for i := range comments {
+		if comments[i].PostedAt.After(*prev.SeenAt) {
+			comments[i].New = true
+		}
+	}
+}
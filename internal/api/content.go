@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,9 +14,11 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+var httpClient = httpclient.New(httpclient.GitHub)
 
 // handleGetReadme fetches a GitHub repo's README.md and returns raw Markdown.
 func (s *Server) handleGetReadme(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +37,11 @@ func (s *Server) handleGetReadme(w http.ResponseWriter, r *http.Request) {
 	// Try main first, then master
 	for _, branch := range []string{"main", "master"} {
 		readmeURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/README.md", owner, repo, branch)
-		resp, err := httpClient.Get(readmeURL)
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, readmeURL, nil)
+		if err != nil {
+			continue
+		}
+		resp, err := httpClient.Do(req)
 		if err != nil {
 			continue
 		}
@@ -77,6 +84,19 @@ func parseGitHubURL(rawURL string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
+// articleContent is the response of GET /api/stories/{id}/content. The
+// archive fields are set when the article was unusable and its Wayback
+// Machine snapshot was read instead.
+type articleContent struct {
+	Content     string     `json:"content"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	CanIframe   bool       `json:"can_iframe"`
+	ContentType string     `json:"content_type"`
+	ArchiveURL  string     `json:"archive_url,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
 // handleGetArticleContent fetches the main content of a story's URL.
 func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
@@ -97,26 +117,25 @@ func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	content, title, canIframe, contentType, err := s.fetchArticleContent(story.URL)
+	res, err := content.FetchArticle(r.Context(), story.URL)
 	if err != nil {
 		log.Printf("Failed to fetch article content for %s: %v", story.URL, err)
 		http.Error(w, "Failed to fetch content", http.StatusBadGateway)
 		return
 	}
+	s.recordContentVersion(r.Context(), id, res.Content)
 
 	// Return simple JSON struct
-	response := struct {
-		Content     string `json:"content"`
-		Title       string `json:"title"`
-		URL         string `json:"url"`
-		CanIframe   bool   `json:"can_iframe"`
-		ContentType string `json:"content_type"`
-	}{
-		Content:     content,
-		Title:       title,
+	response := articleContent{
+		Content:     res.Content,
+		Title:       res.Title,
 		URL:         story.URL,
-		CanIframe:   canIframe,
-		ContentType: contentType,
+		CanIframe:   res.CanIframe,
+		ContentType: res.ContentType,
+	}
+	if res.Archived {
+		response.ArchiveURL = res.ArchiveURL
+		response.ArchivedAt = &res.ArchivedAt
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -124,10 +143,34 @@ func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request)
 }
 
 // fetchArticleContent uses the shared internal/content package to fetch and parse the article.
-func (s *Server) fetchArticleContent(urlStr string) (string, string, bool, string, error) {
-	result, err := content.FetchArticle(urlStr)
+func (s *Server) fetchArticleContent(ctx context.Context, urlStr string) (string, string, bool, string, error) {
+	result, err := content.FetchArticle(ctx, urlStr)
 	if err != nil {
 		return "", "", false, "", err
 	}
 	return result.Content, result.Title, result.CanIframe, result.ContentType, nil
 }
+
+// recordContentVersion stores the fingerprint of freshly fetched article
+// content, flagging the story's summary as stale if the article changed
+// (unless the stale_summary_on_content_change setting is "false"), and keeps
+// the text for search.
+func (s *Server) recordContentVersion(ctx context.Context, storyID int, text string) {
+	if err := s.store.SetArticleText(ctx, storyID, text); err != nil {
+		log.Printf("Failed to store article text for story %d: %v", storyID, err)
+	}
+
+	hash, length := content.Fingerprint(text)
+	markStale := true
+	if v, _ := s.store.GetSetting(ctx, storage.SettingStaleOnContentChange); v == "false" {
+		markStale = false
+	}
+	changed, err := s.store.RecordContentVersion(ctx, storyID, hash, length, markStale)
+	if err != nil {
+		log.Printf("Failed to record content version for story %d: %v", storyID, err)
+		return
+	}
+	if changed {
+		log.Printf("Article for story %d changed since it was last fetched", storyID)
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// pairingCodeTTL is how long a device pairing code can be redeemed.
+	pairingCodeTTL = 10 * time.Minute
+	// maxDeviceName caps the length of a device's display name.
+	maxDeviceName = 100
+)
+
+// deviceIDPattern matches the client-generated IDs devices register with.
+var deviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// anonSyncID returns the anonymous visitor ID that device sync is keyed by,
+// issuing a new anon cookie if the visitor has none. Signed-in users already
+// sync through their account, so it writes a 409 and returns false for them.
+func (s *Server) anonSyncID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if s.localMode || s.auth.GetUserIDFromRequest(r) != "" {
+		http.Error(w, "Signed-in users sync through their account", http.StatusConflict)
+		return "", false
+	}
+	anonID := s.auth.GetAnonymousIDFromRequest(r)
+	if anonID == "" {
+		anonID = auth.NewAnonymousID()
+		s.auth.SetAnonymousCookie(w, anonID, isSecureRequest(r))
+	}
+	return anonID, true
+}
+
+// deviceRequest is the body of device registration and pairing requests.
+type deviceRequest struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Code     string `json:"code"` // pairing only
+}
+
+// decodeDeviceRequest reads and validates a deviceRequest, writing a 400 and
+// returning false if it is invalid.
+func decodeDeviceRequest(w http.ResponseWriter, r *http.Request) (deviceRequest, bool) {
+	var body deviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return body, false
+	}
+	if !deviceIDPattern.MatchString(body.DeviceID) {
+		http.Error(w, "device_id must be 8-64 letters, digits, '-' or '_'", http.StatusBadRequest)
+		return body, false
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if len(body.Name) > maxDeviceName {
+		http.Error(w, "Device name is too long", http.StatusBadRequest)
+		return body, false
+	}
+	return body, true
+}
+
+// writeDevices responds with the devices sharing anonID.
+func (s *Server) writeDevices(w http.ResponseWriter, r *http.Request, anonID string) {
+	devices, err := s.store.GetAnonDevices(r.Context(), anonID)
+	if err != nil {
+		log.Printf("Failed to fetch devices: %v", err)
+		http.Error(w, "Failed to fetch devices", http.StatusInternalServerError)
+		return
+	}
+	if devices == nil {
+		devices = []storage.AnonDevice{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"devices": devices})
+}
+
+// handleRegisterDevice registers the calling device for read-state sync
+// without an account. Interactions are shared by every device holding the
+// same anon cookie; POST /api/devices/pair brings another device in.
+func (s *Server) handleRegisterDevice(w http.ResponseWriter, r *http.Request) {
+	anonID, ok := s.anonSyncID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeDeviceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.store.RegisterAnonDevice(r.Context(), anonID, body.DeviceID, body.Name)
+	if errors.Is(err, storage.ErrDeviceLimit) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to register device: %v", err)
+		http.Error(w, "Failed to register device", http.StatusInternalServerError)
+		return
+	}
+	s.writeDevices(w, r, anonID)
+}
+
+func (s *Server) handleGetDevices(w http.ResponseWriter, r *http.Request) {
+	anonID, ok := s.anonSyncID(w, r)
+	if !ok {
+		return
+	}
+	s.writeDevices(w, r, anonID)
+}
+
+func (s *Server) handleRemoveDevice(w http.ResponseWriter, r *http.Request) {
+	anonID, ok := s.anonSyncID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.store.RemoveAnonDevice(r.Context(), anonID, chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to remove device: %v", err)
+		http.Error(w, "Failed to remove device", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreatePairingCode issues a short-lived code to enter on another
+// device so it syncs with this one.
+func (s *Server) handleCreatePairingCode(w http.ResponseWriter, r *http.Request) {
+	anonID, ok := s.anonSyncID(w, r)
+	if !ok {
+		return
+	}
+
+	code, err := s.store.CreatePairingCode(r.Context(), anonID, pairingCodeTTL)
+	if err != nil {
+		log.Printf("Failed to create pairing code: %v", err)
+		http.Error(w, "Failed to create pairing code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":       code,
+		"expires_at": time.Now().Add(pairingCodeTTL),
+	})
+}
+
+// handlePairDevice redeems a pairing code: the calling device switches to the
+// code's anonymous identity, bringing its own read state along.
+func (s *Server) handlePairDevice(w http.ResponseWriter, r *http.Request) {
+	currentID, ok := s.anonSyncID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeDeviceRequest(w, r)
+	if !ok {
+		return
+	}
+	code := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(body.Code), "-", ""))
+
+	anonID, err := s.store.PairAnonDevice(r.Context(), code, currentID, body.DeviceID, body.Name)
+	switch {
+	case errors.Is(err, storage.ErrInvalidToken):
+		http.Error(w, "Pairing code is invalid or expired", http.StatusBadRequest)
+		return
+	case errors.Is(err, storage.ErrDeviceLimit):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Failed to pair device: %v", err)
+		http.Error(w, "Failed to pair device", http.StatusInternalServerError)
+		return
+	}
+
+	s.auth.SetAnonymousCookie(w, anonID, isSecureRequest(r))
+	s.writeDevices(w, r, anonID)
+}
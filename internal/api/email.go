@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const emailVerifyTTL = 24 * time.Hour
+
+// publicBaseURL is the externally reachable base URL of the API, used in links
+// sent by email. server.public_url overrides the request's own scheme and
+// host.
+func (s *Server) publicBaseURL(r *http.Request) string {
+	if u := s.cfg.PublicURL; u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	scheme := "http"
+	if isSecureRequest(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// handleRequestEmailChange sends a verification link to the new address. The
+// account email only changes once the link is opened.
+func (s *Server) handleRequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(body.Email))
+	if err != nil || addr.Address != strings.TrimSpace(body.Email) {
+		http.Error(w, "Invalid email address", http.StatusBadRequest)
+		return
+	}
+
+	base := s.publicBaseURL(r)
+	render := func(token string) (string, string) {
+		link := base + "/api/me/email/verify?token=" + url.QueryEscape(token)
+		return "Confirm your new HN Station email",
+			fmt.Sprintf("Someone (hopefully you) asked to change the email on your HN Station account to %s.\n\nConfirm the change by opening this link within 24 hours:\n%s\n\nIf you didn't ask for this, ignore this email.\n", addr.Address, link)
+	}
+
+	err = s.store.RequestEmailChange(r.Context(), userID, addr.Address, emailVerifyTTL, render)
+	if errors.Is(err, storage.ErrEmailTaken) {
+		http.Error(w, "That email is already used by another account", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to request email change: %v", err)
+		http.Error(w, "Failed to request email change", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "verification_sent"})
+}
+
+// handleVerifyEmailChange is the link target in the verification email. The
+// token itself is the proof, so no session is required.
+func (s *Server) handleVerifyEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "Missing token", http.StatusBadRequest)
+		return
+	}
+
+	userID, newEmail, err := s.store.ConfirmEmailChange(r.Context(), token)
+	switch {
+	case errors.Is(err, storage.ErrInvalidToken):
+		http.Error(w, "This link is invalid or has expired", http.StatusBadRequest)
+		return
+	case errors.Is(err, storage.ErrEmailTaken):
+		http.Error(w, "That email is already used by another account", http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Failed to confirm email change: %v", err)
+		http.Error(w, "Failed to change email", http.StatusInternalServerError)
+		return
+	}
+	s.users.invalidate(userID)
+	log.Printf("User %s changed email to %s", userID, newEmail)
+
+	http.Redirect(w, r, s.redirects.fallback+"?email_verified=1", http.StatusSeeOther)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// errExportFull stops an .npy export once the rows promised in its header
+// have been written.
+var errExportFull = errors.New("export full")
+
+// handleExportEmbeddings serves GET /api/admin/embeddings/export for offline
+// clustering and visualization. format=jsonl (the default) streams one story
+// per line with its metadata and embedding; format=npy streams a float32
+// matrix loadable with numpy.load, one row per story. Both are ordered by
+// story ID, so an .npy export lines up with a JSONL export of the same range.
+// days limits the export to recent stories.
+func (s *Server) handleExportEmbeddings(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "jsonl" && format != "npy" {
+		http.Error(w, "format must be jsonl or npy", http.StatusBadRequest)
+		return
+	}
+
+	since := time.Time{}
+	if days, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	var err error
+	if format == "npy" {
+		err = s.exportEmbeddingsNPY(w, r, since)
+	} else {
+		err = s.exportEmbeddingsJSONL(w, r, since)
+	}
+	if err != nil {
+		// Headers are already sent; the client sees a truncated file.
+		log.Printf("Embeddings export failed: %v", err)
+	}
+}
+
+func (s *Server) exportEmbeddingsJSONL(w http.ResponseWriter, r *http.Request, since time.Time) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="embeddings.jsonl"`)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := s.store.EachStoryEmbedding(r.Context(), since, func(e storage.StoryEmbedding) error {
+		return enc.Encode(e)
+	}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (s *Server) exportEmbeddingsNPY(w http.ResponseWriter, r *http.Request, since time.Time) error {
+	n, err := s.store.CountStoryEmbeddings(r.Context(), since)
+	if err != nil {
+		http.Error(w, "Failed to export embeddings", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="embeddings.npy"`)
+
+	bw := bufio.NewWriter(w)
+	if err := writeNPYHeader(bw, n, storage.EmbeddingDims); err != nil {
+		return err
+	}
+	written := 0
+	row := make([]byte, 4*storage.EmbeddingDims)
+	err = s.store.EachStoryEmbedding(r.Context(), since, func(e storage.StoryEmbedding) error {
+		if written == n {
+			return errExportFull
+		}
+		if len(e.Embedding) != storage.EmbeddingDims {
+			return fmt.Errorf("story %d has a %d-dim embedding", e.ID, len(e.Embedding))
+		}
+		for i, v := range e.Embedding {
+			binary.LittleEndian.PutUint32(row[4*i:], math.Float32bits(v))
+		}
+		written++
+		_, err := bw.Write(row)
+		return err
+	})
+	if err != nil && !errors.Is(err, errExportFull) {
+		return err
+	}
+	if written < n {
+		return fmt.Errorf("wrote %d of %d rows; stories were deleted during the export", written, n)
+	}
+	return bw.Flush()
+}
+
+// writeNPYHeader writes a version 1.0 .npy header for a little-endian
+// float32 matrix.
+func writeNPYHeader(w io.Writer, rows, cols int) error {
+	header := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }", rows, cols)
+	// Magic, version and header length take 10 bytes; the whole preamble
+	// must be a multiple of 64 bytes and end in a newline.
+	pad := (64 - (10+len(header)+1)%64) % 64
+	header += strings.Repeat(" ", pad) + "\n"
+
+	if _, err := io.WriteString(w, "\x93NUMPY\x01\x00"); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(header))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, header)
+	return err
+}
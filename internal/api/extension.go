@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// extensionPaths are the endpoints browser extensions may call cross-origin.
+var extensionPaths = map[string]bool{
+	"/api/lookup": true,
+	"/api/save":   true,
+}
+
+// isExtensionOrigin reports whether origin belongs to a browser extension.
+func isExtensionOrigin(origin string) bool {
+	for _, scheme := range []string{"chrome-extension://", "moz-extension://", "safari-web-extension://"} {
+		if strings.HasPrefix(origin, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyUserID authenticates a request by API key, sent as "X-API-Key" or as
+// an "Authorization: Bearer" token, falling back to the session cookie.
+func (s *Server) apiKeyUserID(r *http.Request) (string, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = strings.TrimSpace(bearer)
+		}
+	}
+	if key == "" || !strings.HasPrefix(key, storage.APIKeyPrefix) {
+		return s.auth.GetUserIDFromRequest(r), nil
+	}
+
+	userID, err := s.store.ResolveAPIKey(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return userID, err
+}
+
+// pageURLParam returns the "url" query parameter if it is an absolute
+// http(s) URL.
+func pageURLParam(r *http.Request) (string, bool) {
+	raw := r.URL.Query().Get("url")
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return raw, true
+}
+
+// handleLookup tells an extension whether the page it is showing has been
+// submitted to HN and whether a summary is available.
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	pageURL, ok := pageURLParam(r)
+	if !ok {
+		http.Error(w, "url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{"on_hn": false, "summary_available": false}
+	story, err := s.store.FindStoryByURL(r.Context(), pageURL)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		log.Printf("Failed to look up %s: %v", pageURL, err)
+		http.Error(w, "Failed to look up page", http.StatusInternalServerError)
+		return
+	default:
+		hasSummary := story.Summary != nil && *story.Summary != ""
+		resp["on_hn"] = true
+		resp["summary_available"] = hasSummary
+		resp["story"] = map[string]interface{}{
+			"id":          story.ID,
+			"title":       story.Title,
+			"score":       story.Score,
+			"descendants": story.Descendants,
+			"hn_url":      fmt.Sprintf("https://news.ycombinator.com/item?id=%d", story.ID),
+			"url":         s.storyLink(s.publicBaseURL(r), story.ID),
+		}
+		if hasSummary {
+			resp["summary"] = *story.Summary
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleExtensionSave saves the HN story for a page to the caller's library
+// and starts summarizing it in the background if it has no summary yet.
+func (s *Server) handleExtensionSave(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.apiKeyUserID(r)
+	if err != nil {
+		log.Printf("Failed to resolve API key: %v", err)
+		http.Error(w, "Failed to authenticate", http.StatusInternalServerError)
+		return
+	}
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	pageURL, ok := pageURLParam(r)
+	if !ok {
+		http.Error(w, "url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.FindStoryByURL(r.Context(), pageURL)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "This page hasn't been submitted to Hacker News", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to look up %s: %v", pageURL, err)
+		http.Error(w, "Failed to save page", http.StatusInternalServerError)
+		return
+	}
+
+	saved := true
+	if err := s.store.UpsertInteraction(r.Context(), userID, int(story.ID), nil, &saved, nil); err != nil {
+		log.Printf("Failed to save story %d: %v", story.ID, err)
+		http.Error(w, "Failed to save page", http.StatusInternalServerError)
+		return
+	}
+
+	summarizing := story.Summary == nil || *story.Summary == "" || story.SummaryStale
+	if summarizing {
+		go s.summarizeInBackground(userID, story)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"story_id":    story.ID,
+		"saved":       true,
+		"summarizing": summarizing,
+	})
+}
+
+// summarizeInBackground summarizes a story's article after the request that
+// asked for it has returned.
+func (s *Server) summarizeInBackground(userID string, story *storage.Story) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, topics, err := s.summarizeArticle(ctx, userID, story, nil)
+	if err != nil {
+		log.Printf("Background summarization failed for story %d: %v", story.ID, err)
+		return
+	}
+	if err := s.store.UpdateStorySummaryAndTopics(ctx, int(story.ID), summary, topics); err != nil {
+		log.Printf("Failed to update story summary/topics cache: %v", err)
+	}
+}
+
+func (s *Server) handleGetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := s.store.GetAPIKeys(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch API keys: %v", err)
+		http.Error(w, "Failed to fetch API keys", http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []storage.APIKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"api_keys": keys})
+}
+
+// handleCreateAPIKey creates an API key. The key is only shown in this response.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if len(body.Name) > 100 {
+		http.Error(w, "Name is too long", http.StatusBadRequest)
+		return
+	}
+
+	key, raw, err := s.store.CreateAPIKey(r.Context(), userID, body.Name)
+	if err != nil {
+		log.Printf("Failed to create API key: %v", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_key": key,
+		"key":     raw,
+	})
+}
+
+func (s *Server) handleDeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.DeleteAPIKey(r.Context(), userID, keyID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete API key %d: %v", keyID, err)
+		http.Error(w, "Failed to delete API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
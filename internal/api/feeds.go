@@ -0,0 +1,354 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	topicFeedDays = 30
+	// frontFeedStories is how many front-page stories /feed.xml and
+	// /feed.json list.
+	frontFeedStories = 30
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Comments    string   `xml:"comments"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"` // the feed's author stands in for dead posts
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// jsonFeed is a JSON Feed 1.1 document (https://jsonfeed.org/version/1.1).
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`                    // the HN discussion
+	ExternalURL   string           `json:"external_url,omitempty"` // the article; empty for text posts
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	HNStation     jsonFeedStory    `json:"_hn_station"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// jsonFeedStory is the feed's extension object: what HN Station knows about
+// the story beyond the standard fields.
+type jsonFeedStory struct {
+	StoryID  int64  `json:"story_id"`
+	Score    int    `json:"score"`
+	Comments int    `json:"comments"`
+	AppURL   string `json:"app_url"`
+}
+
+// handleTopicFeed serves /feed/topic/{name}.rss: summarized stories tagged
+// with the topic over the last 30 days, best first.
+func (s *Server) handleTopicFeed(w http.ResponseWriter, r *http.Request) {
+	topic := topicParam(r)
+	if topic == "" {
+		http.Error(w, "Invalid topic", http.StatusBadRequest)
+		return
+	}
+
+	stories, err := s.store.GetSummarizedStoriesByTopic(r.Context(), topic, time.Now().AddDate(0, 0, -topicFeedDays), 50)
+	if err != nil {
+		log.Printf("Failed to fetch stories for topic feed %q: %v", topic, err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.publicBaseURL(r)
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         fmt.Sprintf("HN Station: %s", topic),
+			Link:          base,
+			Description:   fmt.Sprintf("Summarized Hacker News stories about %s", topic),
+			SelfLink:      rssLink{Href: base + r.URL.Path, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+			Items:         []rssItem{},
+		},
+	}
+	for _, st := range stories {
+		hnURL := fmt.Sprintf("https://news.ycombinator.com/item?id=%d", st.ID)
+		link := st.URL
+		if link == "" {
+			link = hnURL
+		}
+		item := rssItem{
+			Title:      st.Title,
+			Link:       link,
+			GUID:       rssGUID{Value: hnURL, IsPermaLink: true},
+			PubDate:    st.PostedAt.UTC().Format(time.RFC1123Z),
+			Comments:   hnURL,
+			Categories: st.Topics,
+		}
+		if st.Summary != nil {
+			item.Description = fmt.Sprintf("%s\n\n%d points, %d comments. Read in HN Station: %s",
+				strings.TrimSpace(*st.Summary), st.Score, st.Descendants, s.storyLink(base, st.ID))
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=900")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		log.Printf("Failed to write topic feed %q: %v", topic, err)
+	}
+}
+
+// handleAtomFeed serves /feed.xml: the front page as an Atom feed, with each
+// story's summary in its entry.
+func (s *Server) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	stories, _, err := s.store.GetStories(r.Context(), frontFeedStories, 0, "default", nil, nil, "", false)
+	if err != nil {
+		log.Printf("Failed to fetch stories for Atom feed: %v", err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+	s.writeAtomFeed(w, r, "HN Station", "Top Hacker News stories with AI summaries", stories)
+}
+
+// handleTopicAtomFeed serves /feed/topic/{name}.xml, the Atom version of
+// handleTopicFeed.
+func (s *Server) handleTopicAtomFeed(w http.ResponseWriter, r *http.Request) {
+	topic := topicParam(r)
+	if topic == "" {
+		http.Error(w, "Invalid topic", http.StatusBadRequest)
+		return
+	}
+
+	stories, err := s.store.GetSummarizedStoriesByTopic(r.Context(), topic, time.Now().AddDate(0, 0, -topicFeedDays), 50)
+	if err != nil {
+		log.Printf("Failed to fetch stories for topic feed %q: %v", topic, err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+	s.writeAtomFeed(w, r, "HN Station: "+topic, fmt.Sprintf("Summarized Hacker News stories about %s", topic), stories)
+}
+
+// topicParam returns the normalized {name} of a topic feed's path, or "".
+func topicParam(r *http.Request) string {
+	name := chi.URLParam(r, "name")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return storage.NormalizeTopic(name)
+}
+
+func (s *Server) writeAtomFeed(w http.ResponseWriter, r *http.Request, title, subtitle string, stories []storage.Story) {
+	base := s.publicBaseURL(r)
+	now := time.Now().UTC().Format(time.RFC3339)
+	feed := atomFeed{
+		Title:    title,
+		Subtitle: subtitle,
+		ID:       base + r.URL.Path,
+		Updated:  now,
+		Links: []atomLink{
+			{Href: base + r.URL.Path, Rel: "self", Type: "application/atom+xml"},
+			{Href: base, Rel: "alternate", Type: "text/html"},
+		},
+		Author:  atomPerson{Name: "HN Station"},
+		Entries: []atomEntry{},
+	}
+	for _, st := range stories {
+		hnURL := storage.CommentPermalink(st.ID)
+		link := st.URL
+		if link == "" {
+			link = hnURL
+		}
+		posted := st.PostedAt.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			Title:     st.Title,
+			ID:        hnURL,
+			Links:     []atomLink{{Href: link, Rel: "alternate"}, {Href: hnURL, Rel: "replies", Type: "text/html"}},
+			Published: posted,
+			Updated:   posted,
+			Content:   atomContent{Type: "html", Body: s.atomEntryHTML(base, st)},
+		}
+		if st.By != "" {
+			entry.Author = &atomPerson{Name: st.By}
+		}
+		for _, t := range st.Topics {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=900")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		log.Printf("Failed to write Atom feed %s: %v", r.URL.Path, err)
+	}
+}
+
+// atomEntryHTML renders a story's summary as a bullet list, followed by its
+// points, comments and links.
+func (s *Server) atomEntryHTML(base string, st storage.Story) string {
+	var sb strings.Builder
+	if points := bullets(st.Summary); len(points) > 0 {
+		sb.WriteString("<ul>")
+		for _, p := range points {
+			sb.WriteString("<li>" + html.EscapeString(p) + "</li>")
+		}
+		sb.WriteString("</ul>")
+	} else {
+		sb.WriteString("<p><em>Not summarized yet.</em></p>")
+	}
+	fmt.Fprintf(&sb, `<p>%d points, <a href="%s">%d comments</a>. <a href="%s">Read in HN Station</a></p>`,
+		st.Score, html.EscapeString(storage.CommentPermalink(st.ID)), st.Descendants, html.EscapeString(s.storyLink(base, st.ID)))
+	return sb.String()
+}
+
+// handleJSONFeed serves /feed.json: the front page as a JSON Feed, each item
+// with its summary, topics, HN discussion and article links.
+func (s *Server) handleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	stories, _, err := s.store.GetStories(r.Context(), frontFeedStories, 0, "default", nil, nil, "", false)
+	if err != nil {
+		log.Printf("Failed to fetch stories for JSON feed: %v", err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.publicBaseURL(r)
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       "HN Station",
+		HomePageURL: base,
+		FeedURL:     base + r.URL.Path,
+		Description: "Top Hacker News stories with AI summaries",
+		Items:       []jsonFeedItem{},
+	}
+	for _, st := range stories {
+		hnURL := storage.CommentPermalink(st.ID)
+		item := jsonFeedItem{
+			ID:            hnURL,
+			URL:           hnURL,
+			ExternalURL:   st.URL,
+			Title:         st.Title,
+			ContentHTML:   s.atomEntryHTML(base, st),
+			Summary:       strings.Join(bullets(st.Summary), " "),
+			DatePublished: st.PostedAt.UTC().Format(time.RFC3339),
+			Tags:          st.Topics,
+			HNStation: jsonFeedStory{
+				StoryID:  st.ID,
+				Score:    st.Score,
+				Comments: st.Descendants,
+				AppURL:   s.storyLink(base, st.ID),
+			},
+		}
+		if st.By != "" {
+			item.Authors = []jsonFeedAuthor{{Name: st.By, URL: "https://news.ycombinator.com/user?id=" + url.QueryEscape(st.By)}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=900")
+	if err := json.NewEncoder(w).Encode(feed); err != nil {
+		log.Printf("Failed to write JSON feed: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetFollowingFeed serves GET /api/stories?feed=following: stories saved
+// by followed users who have made their saves public.
+func (s *Server) handleGetFollowingFeed(w http.ResponseWriter, r *http.Request, limit, offset int) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	stories, total, err := s.store.GetFollowingFeed(r.Context(), userID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch following feed: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"total":   total,
+	})
+}
+
+func (s *Server) handleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	users, err := s.store.GetFollowing(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch following: %v", err)
+		http.Error(w, "Failed to fetch following", http.StatusInternalServerError)
+		return
+	}
+	if users == nil {
+		users = []storage.PublicUser{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"following": users})
+}
+
+func (s *Server) handleFollowUser(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	followeeID := chi.URLParam(r, "id")
+	if followeeID == userID {
+		http.Error(w, "You can't follow yourself", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.FollowUser(r.Context(), userID, followeeID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to follow user %s: %v", followeeID, err)
+		http.Error(w, "Failed to follow user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.store.UnfollowUser(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		log.Printf("Failed to unfollow user: %v", err)
+		http.Error(w, "Failed to unfollow user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"log"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// generationOptions returns the user's generation settings for AI calls, or
+// the provider defaults for anonymous users.
+func (s *Server) generationOptions(ctx context.Context, userID string) ai.GenerationOptions {
+	if userID == "" {
+		return ai.GenerationOptions{}
+	}
+	g, err := s.store.GetGenerationSettings(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load generation settings for %s: %v", userID, err)
+		return ai.GenerationOptions{}
+	}
+	return toGenerationOptions(g)
+}
+
+func toGenerationOptions(g storage.GenerationSettings) ai.GenerationOptions {
+	opts := ai.GenerationOptions{Temperature: g.Temperature}
+	if g.MaxTokens != nil {
+		opts.MaxTokens = *g.MaxTokens
+	}
+	if g.NumCtx != nil {
+		opts.NumCtx = *g.NumCtx
+	}
+	return opts
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Following HN users: app users follow HN usernames, get a feed of stories
+// those users submitted or commented on, and are notified as ingestion sees
+// new activity (see Store.NotifyHNUserActivity).
+
+// handleGetHNFollowingFeed serves GET /api/stories?feed=hn_following.
+func (s *Server) handleGetHNFollowingFeed(w http.ResponseWriter, r *http.Request, limit, offset int) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	stories, total, err := s.store.GetHNFollowingFeed(r.Context(), userID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch HN following feed: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"total":   total,
+	})
+}
+
+func (s *Server) handleGetFollowedHNUsers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	users, err := s.store.GetFollowedHNUsers(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch followed HN users: %v", err)
+		http.Error(w, "Failed to fetch followed HN users", http.StatusInternalServerError)
+		return
+	}
+	if users == nil {
+		users = []storage.FollowedHNUser{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"following": users})
+}
+
+func (s *Server) handleFollowHNUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(body.Username)
+	if !hnUsername.MatchString(username) {
+		http.Error(w, "Invalid HN username", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.FollowHNUser(r.Context(), userID, username); err != nil {
+		log.Printf("Failed to follow HN user %s: %v", username, err)
+		http.Error(w, "Failed to follow HN user", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleUnfollowHNUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	username := chi.URLParam(r, "username")
+	if err := s.store.UnfollowHNUser(r.Context(), userID, username); err != nil {
+		log.Printf("Failed to unfollow HN user %s: %v", username, err)
+		http.Error(w, "Failed to unfollow HN user", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	defaultHNJobsLimit = 30
+	maxHNJobsLimit     = 100
+)
+
+// handleGetHNJobs serves GET /api/hnjobs: job posts from HN's jobs list,
+// newest first (?limit=, ?offset=).
+func (s *Server) handleGetHNJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := defaultHNJobsLimit
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = min(v, maxHNJobsLimit)
+	}
+	offset := 0
+	if v, err := strconv.Atoi(q.Get("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	jobs, total, err := s.store.GetHNJobs(r.Context(), limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch HN jobs: %v", err)
+		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs":  jobs,
+		"total": total,
+	})
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// hookMaxSkew is how far a hook's timestamp may be from now, so a
+	// captured request can't be replayed later.
+	hookMaxSkew = 5 * time.Minute
+	// hookRunTimeout bounds a triggered run.
+	hookRunTimeout = 30 * time.Minute
+	// maxHookBody caps the hook request body.
+	maxHookBody = 4 << 10
+)
+
+// hookJobs are the runs a hook can trigger: the binary next to the server
+// and its arguments.
+var hookJobs = map[string][]string{
+	"ingest":  {"ingest", "-one-shot"},
+	"catchup": {"catchup"},
+}
+
+// ingestHooks tracks runs started by hooks; each job runs at most once at a
+// time per server.
+type ingestHooks struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func (h *ingestHooks) start(job string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running[job] {
+		return false
+	}
+	if h.running == nil {
+		h.running = make(map[string]bool)
+	}
+	h.running[job] = true
+	return true
+}
+
+func (h *ingestHooks) done(job string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.running, job)
+}
+
+// verifyHookSignature checks X-HNStation-Signature, "sha256=" and the hex
+// HMAC-SHA256 of "<X-HNStation-Timestamp>.<body>" under secret.
+func verifyHookSignature(r *http.Request, body []byte, secret string, now time.Time) error {
+	ts, err := strconv.ParseInt(r.Header.Get("X-HNStation-Timestamp"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid X-HNStation-Timestamp")
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > hookMaxSkew || skew < -hookMaxSkew {
+		return fmt.Errorf("timestamp is too far from the server's clock")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(r.Header.Get("X-HNStation-Signature")), []byte(want)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// handleIngestHook starts an ingest or catch-up run for an external
+// scheduler (GitHub Actions, a cron service) in deployments that can't keep
+// the ingest daemon running. Requests are signed with
+// server.ingest_hook_secret (INGEST_HOOK_SECRET) instead of an admin session:
+//
+//	ts=$(date +%s); body='{"job":"ingest"}'
+//	sig=$(printf '%s.%s' "$ts" "$body" | openssl dgst -sha256 -hmac "$INGEST_HOOK_SECRET" -hex | cut -d' ' -f2)
+//	curl -X POST -H "X-HNStation-Timestamp: $ts" -H "X-HNStation-Signature: sha256=$sig" -d "$body" .../api/admin/hooks/ingest
+//
+// The run is the one-shot ingest (or catch-up) binary installed next to the
+// server; the hook returns as soon as it starts.
+func (s *Server) handleIngestHook(w http.ResponseWriter, r *http.Request) {
+	secret := s.cfg.IngestHookSecret
+	if secret == "" {
+		http.Error(w, "Ingest hook is not configured", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookBody))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if err := verifyHookSignature(r, body, secret, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req := struct {
+		Job string `json:"job"`
+	}{Job: "ingest"}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	args, ok := hookJobs[req.Job]
+	if !ok {
+		http.Error(w, "job must be ingest or catchup", http.StatusBadRequest)
+		return
+	}
+
+	bin, err := hookBinary(s.cfg.IngestHookDir, args[0])
+	if err != nil {
+		log.Printf("Ingest hook: %v", err)
+		http.Error(w, "Job is not installed on this server", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.hooks.start(req.Job) {
+		http.Error(w, "A "+req.Job+" run is already in progress", http.StatusConflict)
+		return
+	}
+	go s.runHookJob(req.Job, bin, args[1:])
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "started", "job": req.Job})
+}
+
+// hookBinary finds a job's binary in dir, by default the server's own
+// directory.
+func hookBinary(dir, name string) (string, error) {
+	if dir == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Dir(exe)
+	}
+	bin := filepath.Join(dir, name)
+	if _, err := os.Stat(bin); err != nil {
+		return "", err
+	}
+	return bin, nil
+}
+
+// runHookJob runs a job to completion, outliving the request that started
+// it, and logs its output.
+func (s *Server) runHookJob(job, bin string, args []string) {
+	defer s.hooks.done(job)
+	ctx, cancel := context.WithTimeout(context.Background(), hookRunTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Printf("Ingest hook: failed to start %s: %v", job, err)
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+
+	start := time.Now()
+	log.Printf("Ingest hook: starting %s", job)
+	if err := cmd.Start(); err != nil {
+		log.Printf("Ingest hook: failed to start %s: %v", job, err)
+		return
+	}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		log.Printf("[%s] %s", job, scanner.Text())
+	}
+	io.Copy(io.Discard, out) // past an overlong line, so the job never blocks on output
+	if err := cmd.Wait(); err != nil {
+		log.Printf("Ingest hook: %s failed after %v: %v", job, time.Since(start).Round(time.Second), err)
+		return
+	}
+	log.Printf("Ingest hook: %s finished in %v", job, time.Since(start).Round(time.Second))
+}
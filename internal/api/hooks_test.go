@@ -0,0 +1,83 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signHook(secret string, ts int64, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestIngestHook(t *testing.T) {
+	const secret = "hook-secret"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catchup"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+
+	cfg := config.Default()
+	cfg.Server.IngestHookSecret = secret
+	cfg.Server.IngestHookDir = dir
+	server := NewServer(nil, nil, nil, nil, cfg, false)
+
+	const body = `{"job":"catchup"}`
+	now := time.Now().Unix()
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		status    int
+	}{
+		{"valid signature", strconv.FormatInt(now, 10), signHook(secret, now, body), http.StatusAccepted},
+		{"wrong secret", strconv.FormatInt(now, 10), signHook("other-secret", now, body), http.StatusUnauthorized},
+		{"signed for another body", strconv.FormatInt(now, 10), signHook(secret, now, `{"job":"ingest"}`), http.StatusUnauthorized},
+		{"stale timestamp", strconv.FormatInt(now-360, 10), signHook(secret, now-360, body), http.StatusUnauthorized},
+		{"future timestamp", strconv.FormatInt(now+360, 10), signHook(secret, now+360, body), http.StatusUnauthorized},
+		{"missing timestamp", "", signHook(secret, now, body), http.StatusUnauthorized},
+		{"malformed timestamp", "yesterday", signHook(secret, now, body), http.StatusUnauthorized},
+		{"missing signature", strconv.FormatInt(now, 10), "", http.StatusUnauthorized},
+		{"signature without prefix", strconv.FormatInt(now, 10), strings.TrimPrefix(signHook(secret, now, body), "sha256="), http.StatusUnauthorized},
+		{"signature not hex", strconv.FormatInt(now, 10), "sha256=not-hex", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/admin/hooks/ingest", strings.NewReader(body))
+			req.Header.Set("X-HNStation-Timestamp", tt.timestamp)
+			req.Header.Set("X-HNStation-Signature", tt.signature)
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			assert.Equal(t, tt.status, rr.Code, rr.Body.String())
+
+			// Let a started run finish before the next request.
+			assert.Eventually(t, func() bool { return server.hooks.start("catchup") }, 5*time.Second, 10*time.Millisecond)
+			server.hooks.done("catchup")
+		})
+	}
+}
+
+func TestIngestHookDisabled(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil, nil, false)
+
+	now := time.Now().Unix()
+	req := httptest.NewRequest("POST", "/api/admin/hooks/ingest", strings.NewReader("{}"))
+	req.Header.Set("X-HNStation-Timestamp", strconv.FormatInt(now, 10))
+	req.Header.Set("X-HNStation-Signature", signHook("", now, "{}"))
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "an empty secret disables the hook")
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// completeLogin finishes an OAuth callback: it resolves the provider identity
+// to an account (linking it to the signed-in user, if any), merges anonymous
+// interactions, sets the session cookie and redirects to the frontend (or,
+// for a native app, back to the app with an authorization code).
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request, ident storage.Identity) {
+	linkUserID := s.auth.GetUserIDFromRequest(r)
+
+	user, err := s.store.ResolveIdentity(r.Context(), ident, linkUserID)
+	if errors.Is(err, storage.ErrIdentityInUse) {
+		http.Error(w, fmt.Sprintf("This %s account is already linked to another HN Station account. Ask an admin to merge them.", ident.Provider), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error resolving %s identity: %v", ident.Provider, err)
+		http.Error(w, "Failed to save user", http.StatusInternalServerError)
+		return
+	}
+	s.users.put(user)
+	if linkUserID != "" {
+		log.Printf("Linked %s identity to user %s", ident.Provider, user.ID)
+	}
+
+	// Carry over anything the visitor read or saved before signing in
+	if anonID := s.auth.GetAnonymousIDFromRequest(r); anonID != "" {
+		if n, err := s.store.MergeAnonInteractions(r.Context(), anonID, user.ID); err != nil {
+			log.Printf("Failed to merge anonymous interactions for user %s: %v", user.ID, err)
+		} else {
+			log.Printf("Merged %d anonymous interactions into user %s", n, user.ID)
+			auth.ClearAnonymousCookie(w, isSecureRequest(r))
+		}
+	}
+
+	// Native apps get an authorization code to exchange for a token.
+	if pending, ok := s.pendingMobileLogin(w, r); ok {
+		s.completeMobileLogin(w, r, user.ID, pending)
+		return
+	}
+
+	// Generate JWT
+	jwtToken, err := s.auth.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		log.Printf("Error generating JWT: %v", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	// Set session cookie
+	auth.SetSessionCookie(w, jwtToken, isSecureRequest(r))
+
+	// Redirect to frontend, or wherever the login asked to return to
+	http.Redirect(w, r, s.loginRedirect(w, r), http.StatusTemporaryRedirect)
+}
+
+// handleGitHubLogin starts GitHub sign-in. When the user is already signed in
+// the callback links the GitHub account to the current account instead.
+func (s *Server) handleGitHubLogin(w http.ResponseWriter, r *http.Request) {
+	if s.auth.GitHubConfig == nil {
+		http.Error(w, "GitHub login is not configured", http.StatusNotFound)
+		return
+	}
+	if !s.rememberRedirect(w, r) {
+		return
+	}
+	state := auth.GenerateStateToken()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_state",
+		Value:    state,
+		Path:     "/",
+		MaxAge:   300, // 5 minutes
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, s.auth.GitHubConfig.AuthCodeURL(state), http.StatusTemporaryRedirect)
+}
+
+func (s *Server) handleGitHubCallback(w http.ResponseWriter, r *http.Request) {
+	if s.auth.GitHubConfig == nil {
+		http.Error(w, "GitHub login is not configured", http.StatusNotFound)
+		return
+	}
+	stateCookie, err := r.Cookie("oauth_state")
+	if err != nil || stateCookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:   "oauth_state",
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+
+	ctx := auth.OAuthContext(r.Context())
+	token, err := s.auth.GitHubConfig.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		log.Printf("Error exchanging GitHub code for token: %v", err)
+		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+		return
+	}
+	client := s.auth.GitHubConfig.Client(ctx, token)
+
+	var ghUser struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(client, "https://api.github.com/user", &ghUser); err != nil {
+		log.Printf("Error fetching GitHub user: %v", err)
+		http.Error(w, "Failed to get user info", http.StatusInternalServerError)
+		return
+	}
+
+	// Only a verified primary email may be used to match existing accounts.
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(client, "https://api.github.com/user/emails", &emails); err != nil {
+		log.Printf("Error fetching GitHub emails: %v", err)
+		http.Error(w, "Failed to get user info", http.StatusInternalServerError)
+		return
+	}
+	email := ""
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			email = e.Email
+		}
+	}
+	if email == "" {
+		http.Error(w, "Your GitHub account needs a verified primary email", http.StatusBadRequest)
+		return
+	}
+
+	name := ghUser.Name
+	if name == "" {
+		name = ghUser.Login
+	}
+	s.completeLogin(w, r, storage.Identity{
+		Provider:  "github",
+		Subject:   strconv.FormatInt(ghUser.ID, 10),
+		Email:     email,
+		Name:      name,
+		AvatarURL: ghUser.AvatarURL,
+	})
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (s *Server) handleGetIdentities(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	idents, err := s.store.GetIdentities(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch identities: %v", err)
+		http.Error(w, "Failed to fetch identities", http.StatusInternalServerError)
+		return
+	}
+	if idents == nil {
+		idents = []storage.Identity{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"identities": idents})
+}
+
+func (s *Server) handleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	err := s.store.UnlinkIdentity(r.Context(), userID, chi.URLParam(r, "provider"))
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Identity not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrLastIdentity):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Failed to unlink identity: %v", err)
+		http.Error(w, "Failed to unlink identity", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleAdminMergeUsers folds one account into another, e.g. when someone
+// signed up separately with Google and GitHub before linking existed.
+func (s *Server) handleAdminMergeUsers(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		SourceUserID string `json:"source_user_id"`
+		TargetUserID string `json:"target_user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.SourceUserID == "" || body.TargetUserID == "" || body.SourceUserID == body.TargetUserID {
+		http.Error(w, "source_user_id and target_user_id must be two different users", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.MergeUsers(r.Context(), body.SourceUserID, body.TargetUserID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to merge user %s into %s: %v", body.SourceUserID, body.TargetUserID, err)
+		http.Error(w, "Failed to merge users", http.StatusInternalServerError)
+		return
+	}
+	s.users.invalidate(body.SourceUserID, body.TargetUserID)
+	log.Printf("Admin merged user %s into %s", body.SourceUserID, body.TargetUserID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
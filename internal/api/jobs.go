@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Discussion summary jobs share the ingest summary queue (summary_jobs) but
+// are run by the API server, which has the user's keys and settings.
+const (
+	discussionJobLease       = 2 * aiTimeout // a crashed server's jobs are picked up after this
+	discussionJobPoll        = 5 * time.Second
+	discussionJobMaxAttempts = 3
+)
+
+// errNoDiscussion is returned for stories without comments to summarize.
+var errNoDiscussion = errors.New("no discussion to summarize")
+
+// RunSummaryJobs runs queued discussion summaries until ctx is cancelled.
+func (s *Server) RunSummaryJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.DiscussionWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.discussionJobWorker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// wakeJobWorkers tells an idle worker that a job was queued, rather than
+// leaving it to the next poll.
+func (s *Server) wakeJobWorkers() {
+	select {
+	case s.jobWake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) discussionJobWorker(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.store.ClaimSummaryJob(ctx, storage.JobDiscussion, discussionJobLease)
+		if err != nil {
+			log.Printf("Failed to claim discussion job: %v", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-s.jobWake:
+			case <-time.After(discussionJobPoll):
+			}
+			continue
+		}
+		s.runDiscussionJob(ctx, job)
+	}
+}
+
+// runDiscussionJob summarizes a claimed job's discussion, retrying failures
+// a few times with back-off.
+func (s *Server) runDiscussionJob(ctx context.Context, job *storage.SummaryJob) {
+	jobCtx, cancel := context.WithTimeout(ctx, aiTimeout)
+	defer cancel()
+
+	story, err := s.store.GetStory(jobCtx, job.StoryID)
+	if err == nil {
+		_, _, err = s.summarizeDiscussion(jobCtx, job.UserID, story, nil)
+	}
+	if err == nil {
+		if err := s.store.MarkSummaryJobDone(ctx, job.ID); err != nil {
+			log.Printf("Failed to complete discussion job %d: %v", job.ID, err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the job is claimable again once its lease expires.
+		return
+	}
+
+	dead := job.Attempts >= discussionJobMaxAttempts || ai.IsSafetyBlocked(err) || errors.Is(err, errNoDiscussion)
+	retryAt := time.Now().Add(time.Duration(job.Attempts) * 30 * time.Second)
+	log.Printf("Discussion job %d (story %d) failed, attempt %d: %v", job.ID, job.StoryID, job.Attempts, err)
+	if err := s.store.MarkSummaryJobFailed(ctx, job.ID, err.Error(), retryAt, dead); err != nil {
+		log.Printf("Failed to record discussion job %d failure: %v", job.ID, err)
+	}
+}
+
+// handleGetJob reports a summary job's progress, and its summary once it's
+// done. Jobs someone queued are only visible to them.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.store.GetSummaryJob(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load job %d: %v", id, err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	if job.UserID != "" && job.UserID != s.auth.GetUserIDFromRequest(r) && !s.localMode {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	result := struct {
+		*storage.SummaryJob
+		Summary string   `json:"summary,omitempty"`
+		Topics  []string `json:"topics,omitempty"`
+	}{SummaryJob: job}
+	if job.Status == storage.JobDone {
+		if story, err := s.store.GetStory(r.Context(), job.StoryID); err == nil {
+			summary := story.Summary
+			if job.Kind == storage.JobDiscussion {
+				summary = story.DiscussionSummary
+			}
+			if summary != nil {
+				result.Summary, result.Topics = *summary, story.Topics
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetKarmaHistory returns an HN user's karma snapshots for charting.
+// ?days= limits the window (default 90, at most 3650).
+func (s *Server) handleGetKarmaHistory(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	days := 90
+	if v, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && v > 0 {
+		days = min(v, 3650)
+	}
+
+	history, err := s.store.GetKarmaHistory(r.Context(), username, time.Now().AddDate(0, 0, -days))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to fetch karma history for %s: %v", username, err)
+		http.Error(w, "Failed to fetch karma history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username": username,
+		"history":  history,
+	})
+}
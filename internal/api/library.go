@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleBookmarkletSave serves GET /save?url=, the target of the "Save to HN
+// Station" bookmarklet. Pages submitted to HN are saved as stories; any other
+// page goes into the user's library. Either way summarizing starts in the
+// background and the browser is sent back to the page.
+func (s *Server) handleBookmarkletSave(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	pageURL, ok := pageURLParam(r)
+	if !ok {
+		http.Error(w, "url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.FindStoryByURL(r.Context(), pageURL)
+	switch {
+	case err == nil:
+		saved := true
+		if err := s.store.UpsertInteraction(r.Context(), userID, int(story.ID), nil, &saved, nil); err != nil {
+			log.Printf("Failed to save story %d: %v", story.ID, err)
+			http.Error(w, "Failed to save page", http.StatusInternalServerError)
+			return
+		}
+		if story.Summary == nil || *story.Summary == "" || story.SummaryStale {
+			go s.summarizeInBackground(userID, story)
+		}
+	case errors.Is(err, storage.ErrNotFound):
+		item, err := s.store.SaveLibraryItem(r.Context(), userID, pageURL)
+		if err != nil {
+			log.Printf("Failed to save %s to library: %v", pageURL, err)
+			http.Error(w, "Failed to save page", http.StatusInternalServerError)
+			return
+		}
+		if item.Status == storage.LibraryPending {
+			go s.summarizeLibraryItem(userID, item)
+		}
+	default:
+		log.Printf("Failed to look up %s: %v", pageURL, err)
+		http.Error(w, "Failed to save page", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, pageURL, http.StatusSeeOther)
+}
+
+// summarizeLibraryItem fetches and summarizes a library page in the background.
+func (s *Server) summarizeLibraryItem(userID string, item *storage.LibraryItem) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	text, title, _, _, err := s.fetchArticleContent(ctx, item.URL)
+	if err != nil || len(text) < 100 {
+		if err := s.store.FailLibraryItem(ctx, item.ID, title, errArticleUnavailable.Error()); err != nil {
+			log.Printf("Failed to update library item %d: %v", item.ID, err)
+		}
+		return
+	}
+	if title == "" {
+		title = item.URL
+	}
+
+	summary, topics, err := s.summarizeText(ctx, userID, title, text, nil)
+	if err != nil {
+		log.Printf("Summarization failed for library item %d: %v", item.ID, err)
+		if err := s.store.FailLibraryItem(ctx, item.ID, title, err.Error()); err != nil {
+			log.Printf("Failed to update library item %d: %v", item.ID, err)
+		}
+		return
+	}
+	if err := s.store.CompleteLibraryItem(ctx, item.ID, title, summary, topics); err != nil {
+		log.Printf("Failed to update library item %d: %v", item.ID, err)
+	}
+}
+
+func (s *Server) handleGetLibrary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	offset := 0
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 {
+		limit = val
+	}
+	if val, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && val >= 0 {
+		offset = val
+	}
+
+	items, total, err := s.store.GetLibraryItems(r.Context(), userID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch library: %v", err)
+		http.Error(w, "Failed to fetch library", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []storage.LibraryItem{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+		"total": total,
+	})
+}
+
+func (s *Server) handleDeleteLibraryItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.DeleteLibraryItem(r.Context(), userID, itemID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete library item %d: %v", itemID, err)
+		http.Error(w, "Failed to delete item", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	listTitleMaxLen       = 120
+	listDescriptionMaxLen = 2000
+	listNoteMaxLen        = 2000
+)
+
+// handleGetPublicList renders a list by slug. Private lists are only visible to
+// their owner; everyone else gets a 404 so slugs of private lists don't leak.
+func (s *Server) handleGetPublicList(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	list, items, err := s.store.GetListBySlug(r.Context(), slug)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "List not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to fetch list %q: %v", slug, err)
+		http.Error(w, "Failed to fetch list", http.StatusInternalServerError)
+		return
+	}
+	if !list.IsPublic && !s.canViewList(r, list) {
+		http.Error(w, "List not found", http.StatusNotFound)
+		return
+	}
+	if items == nil {
+		items = []storage.ListItem{}
+	}
+
+	if list.IsPublic {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"list":  list,
+		"items": items,
+	})
+}
+
+func (s *Server) handleGetMyLists(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	lists, err := s.store.GetUserLists(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch lists: %v", err)
+		http.Error(w, "Failed to fetch lists", http.StatusInternalServerError)
+		return
+	}
+	if lists == nil {
+		lists = []storage.List{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"lists": lists})
+}
+
+func (s *Server) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		IsPublic    bool   `json:"is_public"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Title = strings.TrimSpace(body.Title)
+	if body.Title == "" || len(body.Title) > listTitleMaxLen {
+		http.Error(w, "title must be 1-120 characters", http.StatusBadRequest)
+		return
+	}
+	if len(body.Description) > listDescriptionMaxLen {
+		http.Error(w, "description is too long", http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.store.CreateList(r.Context(), userID, body.Title, body.Description, body.IsPublic, nil)
+	if err != nil {
+		log.Printf("Failed to create list: %v", err)
+		http.Error(w, "Failed to create list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *Server) handleUpdateList(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	listID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid list ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		IsPublic    *bool   `json:"is_public"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Title != nil {
+		t := strings.TrimSpace(*body.Title)
+		if t == "" || len(t) > listTitleMaxLen {
+			http.Error(w, "title must be 1-120 characters", http.StatusBadRequest)
+			return
+		}
+		body.Title = &t
+	}
+	if body.Description != nil && len(*body.Description) > listDescriptionMaxLen {
+		http.Error(w, "description is too long", http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.store.UpdateList(r.Context(), userID, listID, body.Title, body.Description, body.IsPublic)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "List not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update list %d: %v", listID, err)
+		http.Error(w, "Failed to update list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *Server) handleDeleteList(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	listID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid list ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.DeleteList(r.Context(), userID, listID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "List not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete list %d: %v", listID, err)
+		http.Error(w, "Failed to delete list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleAddListItem adds a story to a list; posting an existing story again
+// replaces its note.
+func (s *Server) handleAddListItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	listID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid list ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		StoryID int    `json:"story_id"`
+		Note    string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.StoryID <= 0 {
+		http.Error(w, "story_id is required", http.StatusBadRequest)
+		return
+	}
+	if len(body.Note) > listNoteMaxLen {
+		http.Error(w, "note is too long", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.store.GetStory(r.Context(), body.StoryID); err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	err = s.store.AddListItem(r.Context(), userID, listID, body.StoryID, strings.TrimSpace(body.Note))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "List not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to add story %d to list %d: %v", body.StoryID, listID, err)
+		http.Error(w, "Failed to add story to list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleRemoveListItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	listID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid list ID", http.StatusBadRequest)
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "storyID"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.RemoveListItem(r.Context(), userID, listID, storyID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "List item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to remove story %d from list %d: %v", storyID, listID, err)
+		http.Error(w, "Failed to remove story from list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// canViewList reports whether the requester owns a private list or belongs to
+// the workspace it is shared with.
+func (s *Server) canViewList(r *http.Request, list *storage.List) bool {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		return false
+	}
+	if userID == list.UserID {
+		return true
+	}
+	if list.WorkspaceID == nil {
+		return false
+	}
+	_, err := s.store.GetWorkspace(r.Context(), *list.WorkspaceID, userID)
+	return err == nil
+}
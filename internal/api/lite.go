@@ -0,0 +1,232 @@
+package api
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// liteStoriesPerPage and liteThreadsPerPage size the pages of /lite/.
+	liteStoriesPerPage = 30
+	liteThreadsPerPage = 20
+	// maxLiteIndent caps comment indentation so deep threads stay readable
+	// on narrow screens.
+	maxLiteIndent = 10
+)
+
+// liteFeeds are the lists linked from the /lite/ header.
+var liteFeeds = []string{"new", "best", "ask", "show", "jobs"}
+
+//go:embed templates/lite_*.html
+var liteFS embed.FS
+
+var liteFuncs = template.FuncMap{
+	"ago":        timeAgo,
+	"domain":     hostOf,
+	"hnLink":     func(id int64) string { return storage.CommentPermalink(id) },
+	"paragraphs": paragraphs,
+	"bullets":    bullets,
+	"indent":     func(depth int) int { return 2 * min(depth, maxLiteIndent) },
+}
+
+// liteTemplates are the server-rendered pages of /lite/, each combined with
+// the shared layout.
+var liteTemplates = map[string]*template.Template{
+	"front": template.Must(template.New("").Funcs(liteFuncs).ParseFS(liteFS, "templates/lite_layout.html", "templates/lite_front.html")),
+	"story": template.Must(template.New("").Funcs(liteFuncs).ParseFS(liteFS, "templates/lite_layout.html", "templates/lite_story.html")),
+}
+
+// litePage is the data every /lite/ page gets.
+type litePage struct {
+	Title     string
+	Canonical string
+	Feeds     []string
+	PrevPage  string // link to the previous page, if any
+	NextPage  string // link to the next page, if any
+}
+
+// renderLite writes a /lite/ page. Rendering into a buffer first keeps a
+// template error from producing half a page with a 200.
+func renderLite(w http.ResponseWriter, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := liteTemplates[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		log.Printf("Failed to render lite %s page: %v", name, err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// litePageNumber returns the 1-based ?p= page.
+func litePageNumber(r *http.Request) int {
+	if p, err := strconv.Atoi(r.URL.Query().Get("p")); err == nil && p > 1 {
+		return p
+	}
+	return 1
+}
+
+// litePageLinks returns links to the pages around page, keeping the
+// request's other parameters.
+func litePageLinks(r *http.Request, page int, hasNext bool) (prev, next string) {
+	q := r.URL.Query()
+	if page > 1 {
+		if page == 2 {
+			q.Del("p")
+		} else {
+			q.Set("p", strconv.Itoa(page-1))
+		}
+		prev = (&url.URL{Path: r.URL.Path, RawQuery: q.Encode()}).String()
+	}
+	if hasNext {
+		q.Set("p", strconv.Itoa(page+1))
+		next = (&url.URL{Path: r.URL.Path, RawQuery: q.Encode()}).String()
+	}
+	return prev, next
+}
+
+// handleLiteFront renders the front page, or an HN list with ?feed=, as
+// plain HTML: no JavaScript, for terminal browsers, slow connections and
+// crawlers.
+func (s *Server) handleLiteFront(w http.ResponseWriter, r *http.Request) {
+	page := litePageNumber(r)
+	offset := (page - 1) * liteStoriesPerPage
+
+	sortParam := "default"
+	title := ""
+	if feed := r.URL.Query().Get("feed"); storage.IsRankedFeed(feed) {
+		sortParam = feed
+		title = feed
+	}
+	userID := s.auth.GetUserIDFromRequest(r)
+
+	stories, total, err := s.store.GetStories(r.Context(), liteStoriesPerPage, offset, sortParam, nil, nil, userID, false)
+	if err != nil {
+		log.Printf("Failed to fetch stories for lite page: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		litePage
+		Stories []storage.Story
+		Start   int
+	}{
+		litePage: litePage{Title: title, Feeds: liteFeeds},
+		Stories:  stories,
+		Start:    offset + 1,
+	}
+	data.PrevPage, data.NextPage = litePageLinks(r, page, offset+len(stories) < total)
+	renderLite(w, "front", data)
+}
+
+// handleLiteStory renders a story with its summary and comments as plain
+// HTML, liteThreadsPerPage top-level threads at a time.
+func (s *Server) handleLiteStory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.GetStory(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	page := litePageNumber(r)
+	comments, err := s.store.GetCommentsPage(r.Context(), id, liteThreadsPerPage, (page-1)*liteThreadsPerPage)
+	if err != nil {
+		log.Printf("Failed to fetch comments for lite story %d: %v", id, err)
+		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
+		return
+	}
+	s.hideMutedComments(r.Context(), s.auth.GetUserIDFromRequest(r), comments.Comments)
+
+	data := struct {
+		litePage
+		Story    *storage.Story
+		Comments []storage.Comment
+	}{
+		litePage: litePage{
+			Title:     story.Title,
+			Canonical: fmt.Sprintf("/lite/item/%d", id),
+			Feeds:     liteFeeds,
+		},
+		Story:    story,
+		Comments: comments.Comments,
+	}
+	data.PrevPage, data.NextPage = litePageLinks(r, page, page*liteThreadsPerPage < comments.TotalThreads)
+	renderLite(w, "story", data)
+}
+
+// timeAgo formats t relative to now, HN style.
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute") + " ago"
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour") + " ago"
+	default:
+		return plural(int(d.Hours()/24), "day") + " ago"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// hostOf returns the host of a story URL without "www.", or "".
+func hostOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// paragraphs splits HN's HTML text into plain-text paragraphs; markup is
+// dropped rather than trusted.
+func paragraphs(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, "<p>") {
+		if p = strings.TrimSpace(content.PlainText(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// bullets splits a stored summary into its bullet points.
+func bullets(summary *string) []string {
+	if summary == nil {
+		return nil
+	}
+	var out []string
+	for _, l := range strings.Split(*summary, "\n") {
+		l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "-•*"))
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const localCommentMaxLen = 10000
+
+// Local comments are an instance-only discussion layer, separate from the
+// mirrored HN threads, so members of a self-hosted instance can talk about a
+// story among themselves. They are only visible to signed-in users.
+
+func (s *Server) handleGetLocalComments(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireUserID(w, r); !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	comments, err := s.store.GetLocalComments(r.Context(), storyID)
+	if err != nil {
+		log.Printf("Failed to fetch local comments for story %d: %v", storyID, err)
+		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
+		return
+	}
+	if comments == nil {
+		comments = []storage.LocalComment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"comments": comments})
+}
+
+func (s *Server) handleAddLocalComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Text     string `json:"text"`
+		ParentID *int64 `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Text = strings.TrimSpace(body.Text)
+	if body.Text == "" || len(body.Text) > localCommentMaxLen {
+		http.Error(w, "text must be 1-10000 characters", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.store.GetStory(r.Context(), storyID); err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	comment, err := s.store.AddLocalComment(r.Context(), storyID, userID, body.ParentID, body.Text)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Parent comment not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to add local comment on story %d: %v", storyID, err)
+		http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (s *Server) handleUpdateLocalComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Text = strings.TrimSpace(body.Text)
+	if body.Text == "" || len(body.Text) > localCommentMaxLen {
+		http.Error(w, "text must be 1-10000 characters", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.UpdateLocalComment(r.Context(), commentID, userID, body.Text)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update local comment %d: %v", commentID, err)
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleDeleteLocalComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	isAdmin := s.localMode
+	if u, err := s.getAuthUser(r.Context(), userID); err == nil {
+		isAdmin = u.IsAdmin
+	}
+
+	err = s.store.DeleteLocalComment(r.Context(), commentID, userID, isAdmin)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete local comment %d: %v", commentID, err)
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// mirrorRefused are GET routes a read-only mirror (server.mirror) refuses
+// although they need no session: they take another credential (a token in the
+// URL, a device ID) whose response a CDN mustn't share, or go beyond what's
+// stored (article fetching and the live stream).
+var mirrorRefused = map[string]bool{
+	"GET /api/calendar.ics":         true,
+	"GET /api/me/email/verify":      true,
+	"GET /api/devices":              true,
+	"GET /api/stream":               true,
+	"GET /api/stories/{id}/content": true,
+	"GET /api/content/readme":       true,
+}
+
+// mirrorAllowed reports whether a read-only mirror serves a request for a
+// route pattern: the static files and SPA routes, and API reads that need no
+// credentials. Writes, sign-in, AI, routes for signed-in users or admins, and
+// mirrorRefused are refused.
+func mirrorAllowed(method, pattern string) bool {
+	if method != http.MethodGet {
+		return false
+	}
+	if pattern == "/*" {
+		return true
+	}
+	key := method + " " + pattern
+	doc, ok := apiDocs[key]
+	if !ok || mirrorRefused[key] || doc.Tag == "auth" || doc.Tag == "ai" {
+		return false
+	}
+	return doc.Access == accessPublic || doc.Access == accessOptional
+}
+
+// mirrorOnly restricts the server to the routes mirrorAllowed. Requests are served
+// anonymously, whatever credentials they carry, so every visitor gets the
+// same response and a CDN can cache it for MirrorMaxAge.
+func (s *Server) mirrorOnly(next http.Handler) http.Handler {
+	maxAge := int(s.cfg.MirrorMaxAge.Seconds())
+	cacheControl := fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d", maxAge, maxAge, maxAge)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// CORS preflights are answered by the CORS middleware.
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		pattern := s.router.Find(chi.NewRouteContext(), method, r.URL.Path)
+		if !mirrorAllowed(method, pattern) {
+			http.Error(w, "Not available on this read-only mirror", http.StatusForbidden)
+			return
+		}
+
+		r.Header.Del("Cookie")
+		r.Header.Del("Authorization")
+		r.Header.Del("X-API-Key")
+		next.ServeHTTP(&mirrorCacheWriter{ResponseWriter: w, cacheControl: cacheControl}, r)
+	})
+}
+
+// mirrorCacheWriter replaces a response's cache headers with the mirror's,
+// except on server errors, which aren't cached.
+type mirrorCacheWriter struct {
+	http.ResponseWriter
+	cacheControl string
+	wroteHeader  bool
+}
+
+func (w *mirrorCacheWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		h := w.Header()
+		h.Del("Set-Cookie")
+		if code >= 500 {
+			h.Set("Cache-Control", "no-store")
+		} else {
+			h.Set("Cache-Control", w.cacheControl)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *mirrorCacheWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *mirrorCacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
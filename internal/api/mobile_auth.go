@@ -0,0 +1,208 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// mobileAuthCookie carries a native app's PKCE parameters through the
+	// OAuth round trip.
+	mobileAuthCookie = "mobile_auth"
+	// authCodeTTL is how long an app has to redeem its authorization code.
+	authCodeTTL = 5 * time.Minute
+)
+
+// handleMobileLogin starts sign-in for a native app, as an OAuth 2.0
+// authorization endpoint with PKCE (RFC 7636): GET /auth/mobile/{provider}
+// ?redirect_uri=&code_challenge=&code_challenge_method=S256&state=. After the
+// provider's sign-in the browser is sent to redirect_uri with a one-time code
+// instead of getting a session cookie; the app exchanges the code and its
+// verifier for an API token at POST /auth/token. redirect_uri must be on
+// REDIRECT_ALLOWLIST, e.g. "hnstation://auth".
+func (s *Server) handleMobileLogin(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if !s.validAppRedirect(redirectURI) {
+		http.Error(w, "redirect_uri not allowed", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != auth.PKCEMethod || !auth.ValidPKCEChallenge(q.Get("code_challenge")) {
+		http.Error(w, "code_challenge must be an S256 PKCE challenge", http.StatusBadRequest)
+		return
+	}
+
+	var login http.HandlerFunc
+	switch chi.URLParam(r, "provider") {
+	case "google":
+		login = s.handleGoogleLogin
+	case "github":
+		login = s.handleGitHubLogin
+	default:
+		http.Error(w, "Unknown provider", http.StatusNotFound)
+		return
+	}
+
+	pending := url.Values{
+		"redirect_uri":   {redirectURI},
+		"code_challenge": {q.Get("code_challenge")},
+		"state":          {q.Get("state")},
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     mobileAuthCookie,
+		Value:    url.QueryEscape(pending.Encode()),
+		Path:     "/",
+		MaxAge:   300, // 5 minutes, like the OAuth state
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	login(w, r)
+}
+
+// validAppRedirect reports whether raw is an absolute, allowlisted redirect
+// URI for a native app.
+func (s *Server) validAppRedirect(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return false
+	}
+	_, ok := s.redirects.resolve(raw)
+	return ok
+}
+
+// pendingMobileLogin returns the PKCE parameters remembered by
+// handleMobileLogin, consuming them, or false for a browser sign-in.
+func (s *Server) pendingMobileLogin(w http.ResponseWriter, r *http.Request) (url.Values, bool) {
+	c, err := r.Cookie(mobileAuthCookie)
+	if err != nil {
+		return nil, false
+	}
+	http.SetCookie(w, &http.Cookie{Name: mobileAuthCookie, Value: "", Path: "/", MaxAge: -1})
+	raw, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return nil, false
+	}
+	pending, err := url.ParseQuery(raw)
+	// Re-check: the cookie came back from the client.
+	if err != nil || !s.validAppRedirect(pending.Get("redirect_uri")) || !auth.ValidPKCEChallenge(pending.Get("code_challenge")) {
+		return nil, false
+	}
+	return pending, true
+}
+
+// completeMobileLogin sends the browser back to the app with a one-time
+// authorization code for userID.
+func (s *Server) completeMobileLogin(w http.ResponseWriter, r *http.Request, userID string, pending url.Values) {
+	redirectURI := pending.Get("redirect_uri")
+	code, err := s.store.CreateAuthCode(r.Context(), userID, pending.Get("code_challenge"), redirectURI, authCodeTTL)
+	if err != nil {
+		log.Printf("Failed to create authorization code for user %s: %v", userID, err)
+		http.Error(w, "Failed to complete sign-in", http.StatusInternalServerError)
+		return
+	}
+
+	target, _ := url.Parse(redirectURI)
+	params := target.Query()
+	params.Set("code", code)
+	if state := pending.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusTemporaryRedirect)
+}
+
+// handleTokenExchange is the OAuth 2.0 token endpoint for native apps: it
+// redeems an authorization code from handleMobileLogin, checked against the
+// PKCE code verifier, for an API token. The token is an API key named
+// "Mobile app", listed and revocable under /api/me/api_keys.
+func (s *Server) handleTokenExchange(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", "malformed form body")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+	code, verifier := r.PostForm.Get("code"), r.PostForm.Get("code_verifier")
+	if code == "" || verifier == "" {
+		tokenError(w, "invalid_request", "code and code_verifier are required")
+		return
+	}
+
+	userID, challenge, err := s.store.RedeemAuthCode(r.Context(), code, r.PostForm.Get("redirect_uri"))
+	if errors.Is(err, storage.ErrInvalidToken) {
+		tokenError(w, "invalid_grant", "authorization code is invalid, expired or already used")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to redeem authorization code: %v", err)
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	if !auth.VerifyPKCE(verifier, challenge) {
+		tokenError(w, "invalid_grant", "code_verifier does not match code_challenge")
+		return
+	}
+
+	key, token, err := s.store.CreateAPIKey(r.Context(), userID, "Mobile app")
+	if err != nil {
+		log.Printf("Failed to create API token for user %s: %v", userID, err)
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Issued mobile API token %d to user %s", key.ID, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"key_id":       key.ID,
+	})
+}
+
+// tokenError writes an OAuth 2.0 error response (RFC 6749, section 5.2).
+func tokenError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": description})
+}
+
+// tokenAuth signs in requests that carry an API token as an
+// "Authorization: Bearer" header, so native apps can use every endpoint
+// without a session cookie. Unknown tokens are rejected rather than treated
+// as anonymous.
+func (s *Server) tokenAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token := strings.TrimSpace(bearer)
+		if !ok || !strings.HasPrefix(token, storage.APIKeyPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := s.store.ResolveAPIKey(r.Context(), token)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Invalid API token", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to resolve API token: %v", err)
+			http.Error(w, "Failed to authenticate", http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), userID)))
+	})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRedirect  = "hnstation://auth"
+	testVerifier  = "dBjftJeZ4CVP-mJrmEh6wzSWd2iUZf1lTkYhr0dgxzk"
+	testChallenge = "hM1b8rbEl8ogyJnSgXEP-j6simAfw6ZZzyUSK5CkiW4" // S256 of testVerifier
+)
+
+// authCodeStore keeps authorization codes in memory, with the single-use,
+// expiry and redirect URI rules of the Postgres store.
+type authCodeStore struct {
+	storage.DB
+	codes map[string]authCode
+	keys  int64
+}
+
+type authCode struct {
+	userID, challenge, redirectURI string
+	expires                        time.Time
+}
+
+func (f *authCodeStore) CreateAuthCode(_ context.Context, userID, challenge, redirectURI string, ttl time.Duration) (string, error) {
+	code := "code-" + userID + "-" + ttl.String()
+	f.codes[code] = authCode{userID, challenge, redirectURI, time.Now().Add(ttl)}
+	return code, nil
+}
+
+func (f *authCodeStore) RedeemAuthCode(_ context.Context, code, redirectURI string) (string, string, error) {
+	c, ok := f.codes[code]
+	if !ok || c.redirectURI != redirectURI || !time.Now().Before(c.expires) {
+		return "", "", storage.ErrInvalidToken
+	}
+	delete(f.codes, code)
+	return c.userID, c.challenge, nil
+}
+
+func (f *authCodeStore) CreateAPIKey(_ context.Context, userID, name string) (*storage.APIKey, string, error) {
+	f.keys++
+	return &storage.APIKey{ID: f.keys, Name: name}, storage.APIKeyPrefix + "token-" + userID, nil
+}
+
+func TestMobileLoginRequiresS256(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.RedirectAllowlist = []string{testRedirect}
+	server := NewServer(nil, nil, nil, nil, cfg, false)
+
+	tests := []struct {
+		name   string
+		params url.Values
+	}{
+		{"plain method", url.Values{"redirect_uri": {testRedirect}, "code_challenge": {testVerifier}, "code_challenge_method": {"plain"}}},
+		{"no method", url.Values{"redirect_uri": {testRedirect}, "code_challenge": {testChallenge}}},
+		{"malformed challenge", url.Values{"redirect_uri": {testRedirect}, "code_challenge": {"short"}, "code_challenge_method": {"S256"}}},
+		{"redirect not allowed", url.Values{"redirect_uri": {"evilapp://auth"}, "code_challenge": {testChallenge}, "code_challenge_method": {"S256"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, httptest.NewRequest("GET", "/auth/mobile/google?"+tt.params.Encode(), nil))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Empty(t, rr.Result().Cookies(), "no sign-in was started")
+		})
+	}
+}
+
+func TestTokenExchange(t *testing.T) {
+	store := &authCodeStore{codes: map[string]authCode{}}
+	server := NewServer(store, nil, nil, nil, nil, false)
+	ctx := context.Background()
+
+	exchange := func(code, verifier, redirectURI string) (int, map[string]interface{}) {
+		form := url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"code_verifier": {verifier},
+			"redirect_uri":  {redirectURI},
+		}
+		req := httptest.NewRequest("POST", "/auth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body), rr.Body.String())
+		return rr.Code, body
+	}
+
+	code, err := store.CreateAuthCode(ctx, "u1", testChallenge, testRedirect, time.Minute)
+	require.NoError(t, err)
+
+	status, body := exchange(code, testVerifier, "hnstation://other")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "redirect_uri mismatch")
+
+	status, body = exchange(code, testVerifier, testRedirect)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, storage.APIKeyPrefix+"token-u1", body["access_token"])
+	assert.Equal(t, "Bearer", body["token_type"])
+
+	status, body = exchange(code, testVerifier, testRedirect)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "a code can only be exchanged once")
+
+	// A wrong verifier fails, and uses the code up.
+	code, err = store.CreateAuthCode(ctx, "u2", testChallenge, testRedirect, time.Minute)
+	require.NoError(t, err)
+	status, body = exchange(code, strings.Replace(testVerifier, "d", "e", 1), testRedirect)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+	status, _ = exchange(code, testVerifier, testRedirect)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	expired, err := store.CreateAuthCode(ctx, "u3", testChallenge, testRedirect, -time.Second)
+	require.NoError(t, err)
+	status, body = exchange(expired, testVerifier, testRedirect)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "expired")
+
+	status, body = exchange("", testVerifier, testRedirect)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_request", body["error"])
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// hnUsername matches the usernames HN allows.
+var hnUsername = regexp.MustCompile(`^[A-Za-z0-9_-]{2,15}$`)
+
+func (s *Server) handleGetMutedAuthors(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	muted, err := s.store.GetMutedAuthors(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch muted authors: %v", err)
+		http.Error(w, "Failed to fetch muted authors", http.StatusInternalServerError)
+		return
+	}
+	if muted == nil {
+		muted = []storage.MutedAuthor{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"muted": muted})
+}
+
+func (s *Server) handleMuteAuthor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(body.Username)
+	if !hnUsername.MatchString(username) {
+		http.Error(w, "Invalid HN username", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.MuteAuthor(r.Context(), userID, username); err != nil {
+		log.Printf("Failed to mute %s: %v", username, err)
+		http.Error(w, "Failed to mute author", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleUnmuteAuthor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	username := chi.URLParam(r, "username")
+	if err := s.store.UnmuteAuthor(r.Context(), userID, username); err != nil {
+		log.Printf("Failed to unmute %s: %v", username, err)
+		http.Error(w, "Failed to unmute author", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// hideMutedComments flags comments by authors the signed-in user has muted
+// and blanks their text. The comments stay in place so replies to them keep
+// their thread.
+func (s *Server) hideMutedComments(ctx context.Context, userID string, groups ...[]storage.Comment) {
+	if userID == "" {
+		return
+	}
+	muted, err := s.store.GetMutedAuthors(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to fetch muted authors for %s: %v", userID, err)
+		return
+	}
+	if len(muted) == 0 {
+		return
+	}
+	names := make(map[string]bool, len(muted))
+	for _, m := range muted {
+		names[m.Username] = true
+	}
+	for _, comments := range groups {
+		for i := range comments {
+			if names[comments[i].By] {
+				comments[i].Muted = true
+				comments[i].Text = ""
+			}
+		}
+	}
+}
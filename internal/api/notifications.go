@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+func (s *Server) handleGetNotificationPrefs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := s.store.GetNotificationPreferences(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch notification preferences: %v", err)
+		http.Error(w, "Failed to fetch notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// handleUpdateNotificationPrefs replaces the user's notification preferences.
+// Omitted fields keep their current value; "topics" toggles digests for
+// already-subscribed topics.
+func (s *Server) handleUpdateNotificationPrefs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := s.store.GetNotificationPreferences(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch notification preferences: %v", err)
+		http.Error(w, "Failed to update notification preferences", http.StatusInternalServerError)
+		return
+	}
+	current := prefs.Topics
+	prefs.Topics = nil
+	if err := json.NewDecoder(r.Body).Decode(prefs); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if prefs.Topics == nil {
+		prefs.Topics = current
+	}
+
+	switch prefs.DigestFrequency {
+	case storage.DigestOff, storage.DigestDaily, storage.DigestWeekly:
+	default:
+		http.Error(w, "digest_frequency must be off, daily or weekly", http.StatusBadRequest)
+		return
+	}
+	for _, c := range prefs.Channels {
+		if c != "email" && c != "push" && c != "webhook" {
+			http.Error(w, "channels may only contain email, push and webhook", http.StatusBadRequest)
+			return
+		}
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = []string{}
+	}
+	if prefs.WebhookURL != "" {
+		u, err := url.Parse(prefs.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			http.Error(w, "webhook_url must be an http(s) URL", http.StatusBadRequest)
+			return
+		}
+		if err := httpclient.CheckURL(u); err != nil {
+			http.Error(w, "webhook_url must be a public address", http.StatusBadRequest)
+			return
+		}
+	}
+	if (prefs.QuietStart == nil) != (prefs.QuietEnd == nil) {
+		http.Error(w, "quiet_start and quiet_end must be set together", http.StatusBadRequest)
+		return
+	}
+	for _, h := range []*int{prefs.QuietStart, prefs.QuietEnd} {
+		if h != nil && (*h < 0 || *h > 23) {
+			http.Error(w, "quiet hours must be between 0 and 23", http.StatusBadRequest)
+			return
+		}
+	}
+	if prefs.Timezone == "" {
+		prefs.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		http.Error(w, "Unknown timezone", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.UpdateNotificationPreferences(r.Context(), userID, *prefs); err != nil {
+		log.Printf("Failed to update notification preferences: %v", err)
+		http.Error(w, "Failed to update notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
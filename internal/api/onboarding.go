@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	onboardingTopicDays  = 14 // suggestions come from stories posted this recently
+	onboardingTopicLimit = 30
+	maxFollowTopics      = 50 // per request
+)
+
+// handleGetOnboardingTopics suggests topics to follow, the most common tags
+// on recent stories, and says whether the user still has to pick some after
+// signing up. Anonymous users get the suggestions alone.
+func (s *Server) handleGetOnboardingTopics(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+
+	topics, err := s.store.SuggestTopics(r.Context(), userID, onboardingTopicDays, onboardingTopicLimit)
+	if err != nil {
+		log.Printf("Failed to suggest topics: %v", err)
+		http.Error(w, "Failed to suggest topics", http.StatusInternalServerError)
+		return
+	}
+	if topics == nil {
+		topics = []storage.TopicSuggestion{}
+	}
+
+	needed := false
+	if userID != "" {
+		if needed, err = s.store.NeedsOnboarding(r.Context(), userID); err != nil {
+			log.Printf("Failed to check onboarding for %s: %v", userID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"needed": needed,
+		"topics": topics,
+	})
+}
+
+// handleFollowTopics follows the topics in {"topics": [...]} and finishes
+// onboarding; an empty list skips it.
+func (s *Server) handleFollowTopics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Topics []string `json:"topics"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body.Topics) > maxFollowTopics {
+		http.Error(w, "Too many topics", http.StatusBadRequest)
+		return
+	}
+	topics := make([]string, 0, len(body.Topics))
+	for _, t := range body.Topics {
+		t = storage.NormalizeTopic(t)
+		if t == "" || len(t) > 64 {
+			http.Error(w, "topic must be 1-64 characters", http.StatusBadRequest)
+			return
+		}
+		topics = append(topics, t)
+	}
+
+	if err := s.store.FollowTopics(r.Context(), userID, topics); err != nil {
+		log.Printf("Failed to follow topics: %v", err)
+		http.Error(w, "Failed to follow topics", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "topics": topics})
+}
+
+func (s *Server) handleGetFollowedTopics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	topics, err := s.store.GetFollowedTopics(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch followed topics: %v", err)
+		http.Error(w, "Failed to fetch followed topics", http.StatusInternalServerError)
+		return
+	}
+	if topics == nil {
+		topics = []storage.FollowedTopic{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"topics": topics})
+}
+
+func (s *Server) handleUnfollowTopic(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	topic := chi.URLParam(r, "topic")
+	if err := s.store.UnfollowTopic(r.Context(), userID, topic); err != nil {
+		log.Printf("Failed to unfollow topic %q: %v", topic, err)
+		http.Error(w, "Failed to unfollow topic", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleGetTopicsFeed serves GET /api/stories?feed=topics, the stories
+// tagged with topics the user follows.
+func (s *Server) handleGetTopicsFeed(w http.ResponseWriter, r *http.Request, limit, offset int) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	stories, total, err := s.store.GetTopicsFeed(r.Context(), userID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch topics feed: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"total":   total,
+	})
+}
@@ -0,0 +1,846 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// The OpenAPI description is built from the router itself, so every route
+// is listed; apiDocs adds what the router can't know. Request and response
+// schemas are reflected from the Go types the handlers decode and encode.
+
+const openAPIVersion = "3.0.3"
+
+//go:embed templates/api_docs.html
+var apiDocsPage []byte
+
+// access is who may call a route.
+type access int
+
+const (
+	accessPublic   access = iota
+	accessOptional        // anyone; signed-in callers get their own flags and settings
+	accessUser
+	accessAdmin
+)
+
+// apiParam is a path or query parameter.
+type apiParam struct {
+	Name        string
+	Type        string // "string" (the default), "integer" or "boolean"
+	Description string
+	Required    bool
+	Repeated    bool // may be given more than once
+}
+
+// apiDoc describes a route. Body and Response are values whose types give
+// the JSON request and response; a jsonObject stands for a map the handler
+// builds by hand.
+type apiDoc struct {
+	Summary  string
+	Tag      string
+	Access   access
+	Path     []apiParam // path parameters that aren't plain strings
+	Query    []apiParam
+	Body     any
+	Response any
+	Produces string // content type of a response that isn't JSON
+	Redirect bool   // answers with a redirect rather than a body
+}
+
+// jsonObject is a JSON object described by an example value per field.
+type jsonObject map[string]any
+
+var (
+	storyIDParam = apiParam{Name: "id", Type: "integer", Description: "HN item ID"}
+	idParam      = apiParam{Name: "id", Type: "integer"}
+	limitParam   = apiParam{Name: "limit", Type: "integer"}
+	offsetParam  = apiParam{Name: "offset", Type: "integer"}
+	daysParam    = apiParam{Name: "days", Type: "integer", Description: "How many days back to look"}
+	pageURLQuery = apiParam{Name: "url", Description: "Absolute http(s) URL of the page", Required: true}
+
+	statusOK  = jsonObject{"status": ""}
+	storyList = jsonObject{"stories": []storage.Story{}, "total": 0}
+)
+
+// apiDocs describes the routes, keyed by method and route pattern.
+var apiDocs = map[string]apiDoc{
+	"GET /healthc":          {Summary: "Health check", Tag: "meta", Produces: "text/plain"},
+	"GET /api/openapi.json": {Summary: "This OpenAPI description", Tag: "meta", Response: jsonObject{}},
+	"GET /api/docs":         {Summary: "Swagger UI for this API", Tag: "meta", Produces: "text/html"},
+
+	// Stories
+	"GET /api/stories": {
+		Summary: "List stories", Tag: "stories", Access: accessOptional,
+		Query: []apiParam{limitParam, offsetParam,
+			{Name: "sort", Description: "default, latest (or new), votes or show"},
+			{Name: "feed", Description: "An HN list (new, best, ask, show, jobs) in its order, or following, hn_following or topics for signed-in users"},
+			{Name: "topic", Description: "Only stories tagged with the topic", Repeated: true},
+			{Name: "lang", Description: "Comma-separated ISO 639-1 codes; stories of unknown language are kept", Repeated: true},
+			{Name: "type", Description: "semantic ranks by similarity to q"},
+			{Name: "q", Description: "Query for type=semantic"},
+			{Name: "show_hidden", Type: "boolean"},
+		},
+		Response: storyList,
+	},
+	"GET /api/stories.txt": {
+		Summary: "Front page as plain text", Tag: "stories",
+		Query:    []apiParam{limitParam, {Name: "feed", Description: "new, best, ask, show or jobs"}},
+		Produces: "text/plain",
+	},
+	"GET /api/stories/popular": {
+		Summary: "Stories saved or read by the most users", Tag: "stories",
+		Query:    []apiParam{{Name: "by", Description: "saves (default) or reads"}, daysParam, limitParam},
+		Response: jsonObject{"stories": []storage.Story{}, "by": "", "days": 0},
+	},
+	"GET /api/hnjobs": {
+		Summary: "HN job posts, newest first", Tag: "stories",
+		Query:    []apiParam{limitParam, offsetParam},
+		Response: jsonObject{"jobs": []storage.Story{}, "total": 0},
+	},
+	"GET /api/stream": {
+		Summary: "Front-page changes after each ingestion run, as Server-Sent Events: rank, score and summary events for the stories that changed, then an ingest event", Tag: "stories",
+		Produces: "text/event-stream",
+	},
+	"GET /api/stories/{id}": {
+		Summary: "A story with its comments, title history and earlier submissions", Tag: "stories", Access: accessOptional,
+		Path:  []apiParam{storyIDParam},
+		Query: []apiParam{{Name: "comment_limit", Type: "integer"}, {Name: "comment_offset", Type: "integer"}},
+		Response: struct {
+			Story *storage.Story `json:"story"`
+			*storage.CommentPage
+			TitleHistory        []storage.TitleChange `json:"title_history"`
+			PreviousSubmissions []previousSubmission  `json:"previous_submissions"`
+		}{},
+	},
+	"GET /api/stories/{id}/content": {
+		Summary: "The story's article, extracted for reading; from its Wayback Machine snapshot if the article is paywalled or gone", Tag: "stories",
+		Path:     []apiParam{storyIDParam},
+		Response: articleContent{},
+	},
+	"GET /api/content/readme": {Summary: "A GitHub repository's README", Tag: "stories", Query: []apiParam{pageURLQuery}, Produces: "text/html"},
+	"GET /api/analytics/themes": {
+		Summary: "The week's recurring themes", Tag: "stories",
+		Query:    []apiParam{{Name: "week", Description: "Any date in the week, as YYYY-MM-DD"}},
+		Response: jsonObject{"themes": []storage.Theme{}, "week_start": ""},
+	},
+	"GET /api/lookup": {
+		Summary: "Whether a page has been posted to HN, for the browser extension", Tag: "stories", Access: accessOptional,
+		Query:    []apiParam{pageURLQuery},
+		Response: jsonObject{"on_hn": false, "summary_available": false, "summary": "", "story": jsonObject{"id": 0, "title": "", "score": 0, "descendants": 0, "hn_url": "", "url": ""}},
+	},
+
+	// Search
+	"GET /api/search": {
+		Summary: "Search stories", Tag: "search",
+		Query: []apiParam{{Name: "q", Required: true}, limitParam,
+			{Name: "type", Description: "keyword (default) or hybrid; the response says which was used"}},
+		Response: jsonObject{"stories": []storage.Story{}, "type": ""},
+	},
+
+	// Comments
+	"GET /api/stories/{id}/comments": {
+		Summary: "A page of a story's comment threads", Tag: "comments", Access: accessOptional,
+		Path:  []apiParam{storyIDParam},
+		Query: []apiParam{limitParam, offsetParam},
+		Response: struct {
+			*storage.CommentPage
+			NextOffset int `json:"next_offset,omitempty"`
+		}{},
+	},
+	"GET /api/comments/{id}": {
+		Summary: "An HN comment with its story and thread", Tag: "comments", Access: accessOptional,
+		Path: []apiParam{storyIDParam},
+		Response: struct {
+			Story *storage.Story `json:"story"`
+			*storage.CommentThread
+		}{},
+	},
+	"GET /api/stories/{id}/local_comments": {
+		Summary: "Comments posted here rather than on HN", Tag: "comments",
+		Path: []apiParam{storyIDParam}, Response: jsonObject{"comments": []storage.LocalComment{}},
+	},
+	"POST /api/stories/{id}/local_comments": {
+		Summary: "Post a local comment", Tag: "comments", Access: accessUser,
+		Path: []apiParam{storyIDParam},
+		Body: struct {
+			Text     string `json:"text"`
+			ParentID *int64 `json:"parent_id"`
+		}{},
+		Response: storage.LocalComment{},
+	},
+	"PUT /api/local_comments/{id}": {
+		Summary: "Edit your local comment", Tag: "comments", Access: accessUser,
+		Path: []apiParam{idParam}, Body: jsonObject{"text": ""}, Response: statusOK,
+	},
+	"DELETE /api/local_comments/{id}": {
+		Summary: "Delete your local comment", Tag: "comments", Access: accessUser,
+		Path: []apiParam{idParam}, Response: statusOK,
+	},
+
+	// Interactions
+	"POST /api/stories/{id}/interact": {
+		Summary: "Mark a story read, saved or hidden, or set your note on it", Tag: "interactions", Access: accessOptional,
+		Path: []apiParam{storyIDParam},
+		Body: struct {
+			Read   *bool   `json:"read,omitempty"`
+			Saved  *bool   `json:"saved,omitempty"`
+			Hidden *bool   `json:"hidden,omitempty"`
+			Note   *string `json:"note,omitempty"`
+		}{},
+		Response: statusOK,
+	},
+	"GET /api/stories/saved": {
+		Summary: "Your saved stories", Tag: "interactions", Access: accessUser,
+		Query:    []apiParam{{Name: "q", Description: "Filter by title or note"}, limitParam, offsetParam},
+		Response: storyList,
+	},
+	"POST /api/save": {
+		Summary: "Save a page from the browser extension, ingesting it if needed", Tag: "interactions", Access: accessUser,
+		Query:    []apiParam{pageURLQuery},
+		Response: jsonObject{"story_id": 0, "saved": false, "summarizing": false},
+	},
+	"GET /save": {Summary: "Bookmarklet: save the page at url", Tag: "interactions", Access: accessUser, Query: []apiParam{pageURLQuery}, Produces: "text/html"},
+	"GET /api/me/library": {
+		Summary: "Pages you saved that aren't on HN", Tag: "interactions", Access: accessUser,
+		Query: []apiParam{limitParam, offsetParam}, Response: jsonObject{"items": []storage.LibraryItem{}, "total": 0},
+	},
+	"DELETE /api/me/library/{id}":            {Summary: "Remove a library page", Tag: "interactions", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"GET /api/me/muted":                      {Summary: "Authors you muted", Tag: "interactions", Access: accessUser, Response: jsonObject{"muted": []storage.MutedAuthor{}}},
+	"POST /api/me/muted":                     {Summary: "Mute an author", Tag: "interactions", Access: accessUser, Body: jsonObject{"username": ""}, Response: statusOK},
+	"DELETE /api/me/muted/{username}":        {Summary: "Unmute an author", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"GET /api/me/following":                  {Summary: "Users you follow", Tag: "interactions", Access: accessUser, Response: jsonObject{"following": []storage.PublicUser{}}},
+	"POST /api/users/{id}/follow":            {Summary: "Follow a user", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"DELETE /api/users/{id}/follow":          {Summary: "Unfollow a user", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"GET /api/me/hn_following":               {Summary: "HN users you follow", Tag: "interactions", Access: accessUser, Response: jsonObject{"following": []storage.FollowedHNUser{}}},
+	"POST /api/me/hn_following":              {Summary: "Follow an HN user", Tag: "interactions", Access: accessUser, Body: jsonObject{"username": ""}, Response: statusOK},
+	"DELETE /api/me/hn_following/{username}": {Summary: "Unfollow an HN user", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"GET /api/onboarding/topics": {
+		Summary: "Topics to suggest following", Tag: "interactions", Access: accessOptional,
+		Response: jsonObject{"needed": false, "topics": []storage.TopicSuggestion{}},
+	},
+	"GET /api/me/topics": {Summary: "Topics you follow", Tag: "interactions", Access: accessUser, Response: jsonObject{"topics": []storage.FollowedTopic{}}},
+	"POST /api/me/topics": {
+		Summary: "Follow topics", Tag: "interactions", Access: accessUser,
+		Body: jsonObject{"topics": []string{}}, Response: jsonObject{"status": "", "topics": []string{}},
+	},
+	"DELETE /api/me/topics/{topic}": {Summary: "Unfollow a topic", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"GET /api/me/subscriptions": {
+		Summary: "Topics you get notified about", Tag: "interactions", Access: accessUser,
+		Response: jsonObject{"subscriptions": []storage.TopicSubscription{}},
+	},
+	"POST /api/me/subscriptions": {
+		Summary: "Subscribe to a topic", Tag: "interactions", Access: accessUser,
+		Body: jsonObject{"topic": ""}, Response: jsonObject{"status": "", "topic": ""},
+	},
+	"DELETE /api/me/subscriptions/{topic}": {Summary: "Unsubscribe from a topic", Tag: "interactions", Access: accessUser, Response: statusOK},
+	"GET /api/me/watches":                  {Summary: "Stories you watch", Tag: "interactions", Access: accessUser, Response: jsonObject{"watches": []storage.StoryWatch{}}},
+	"PUT /api/stories/{id}/watch": {
+		Summary: "Watch a story: get notified once when it reaches a score or comment count, and when its summary arrives", Tag: "interactions", Access: accessUser,
+		Path: []apiParam{storyIDParam},
+		Body: struct {
+			ScoreThreshold   *int  `json:"score_threshold,omitempty"`
+			CommentThreshold *int  `json:"comment_threshold,omitempty"`
+			NotifySummary    *bool `json:"notify_summary,omitempty"`
+		}{},
+		Response: statusOK,
+	},
+	"DELETE /api/stories/{id}/watch": {Summary: "Stop watching a story", Tag: "interactions", Access: accessUser, Path: []apiParam{storyIDParam}, Response: statusOK},
+	"POST /api/stories/{id}/share": {
+		Summary: "Email a story's link and summary to someone, with an optional note (rate limited per user)", Tag: "interactions", Access: accessUser,
+		Path: []apiParam{storyIDParam},
+		Body: struct {
+			Email string `json:"email"`
+			Note  string `json:"note,omitempty"`
+		}{},
+		Response: jsonObject{"status": "queued"},
+	},
+	"GET /api/users/{username}/karma_history": {
+		Summary: "An HN user's karma over time", Tag: "interactions",
+		Query: []apiParam{daysParam}, Response: jsonObject{"username": "", "history": []storage.KarmaSnapshot{}},
+	},
+
+	// Lists
+	"GET /api/me/lists": {Summary: "Your reading lists", Tag: "lists", Access: accessUser, Response: jsonObject{"lists": []storage.List{}}},
+	"POST /api/me/lists": {
+		Summary: "Create a reading list", Tag: "lists", Access: accessUser,
+		Body: struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			IsPublic    bool   `json:"is_public"`
+		}{},
+		Response: storage.List{},
+	},
+	"PUT /api/me/lists/{id}": {
+		Summary: "Update a reading list", Tag: "lists", Access: accessUser,
+		Path: []apiParam{idParam},
+		Body: struct {
+			Title       *string `json:"title,omitempty"`
+			Description *string `json:"description,omitempty"`
+			IsPublic    *bool   `json:"is_public,omitempty"`
+		}{},
+		Response: storage.List{},
+	},
+	"DELETE /api/me/lists/{id}": {Summary: "Delete a reading list", Tag: "lists", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"POST /api/me/lists/{id}/items": {
+		Summary: "Add a story to a list", Tag: "lists", Access: accessUser,
+		Path: []apiParam{idParam}, Body: jsonObject{"story_id": 0, "note": ""}, Response: statusOK,
+	},
+	"DELETE /api/me/lists/{id}/items/{storyID}": {
+		Summary: "Remove a story from a list", Tag: "lists", Access: accessUser,
+		Path: []apiParam{idParam, {Name: "storyID", Type: "integer"}}, Response: statusOK,
+	},
+	"GET /api/lists/{slug}": {
+		Summary: "A public reading list", Tag: "lists",
+		Response: jsonObject{"list": storage.List{}, "items": []storage.ListItem{}},
+	},
+
+	// Workspaces
+	"GET /api/workspaces":  {Summary: "Workspaces you belong to", Tag: "workspaces", Access: accessUser, Response: jsonObject{"workspaces": []storage.Workspace{}}},
+	"POST /api/workspaces": {Summary: "Create a workspace", Tag: "workspaces", Access: accessUser, Body: jsonObject{"name": ""}, Response: storage.Workspace{}},
+	"GET /api/workspaces/{id}": {
+		Summary: "A workspace and its members", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam}, Response: jsonObject{"workspace": storage.Workspace{}, "members": []storage.WorkspaceMember{}},
+	},
+	"PUT /api/workspaces/{id}": {
+		Summary: "Rename a workspace or set its AI key and quota", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam},
+		Body: struct {
+			Name           *string `json:"name,omitempty"`
+			GeminiAPIKey   *string `json:"gemini_api_key,omitempty"`
+			MonthlyAIQuota *int    `json:"monthly_ai_quota,omitempty"`
+		}{},
+		Response: statusOK,
+	},
+	"DELETE /api/workspaces/{id}": {Summary: "Delete a workspace", Tag: "workspaces", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"POST /api/workspaces/{id}/members": {
+		Summary: "Add a member by email", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam}, Body: jsonObject{"email": "", "role": ""}, Response: storage.WorkspaceMember{},
+	},
+	"PUT /api/workspaces/{id}/members/{userID}": {
+		Summary: "Change a member's role", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam}, Body: jsonObject{"role": ""}, Response: statusOK,
+	},
+	"DELETE /api/workspaces/{id}/members/{userID}": {Summary: "Remove a member", Tag: "workspaces", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"GET /api/workspaces/{id}/lists":               {Summary: "A workspace's shared lists", Tag: "workspaces", Access: accessUser, Path: []apiParam{idParam}, Response: jsonObject{"lists": []storage.List{}}},
+	"POST /api/workspaces/{id}/lists": {
+		Summary: "Create a shared list", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam}, Body: jsonObject{"title": "", "description": "", "is_public": false}, Response: storage.List{},
+	},
+	"GET /api/workspaces/{id}/notes/{storyID}": {
+		Summary: "The workspace's note on a story", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam, {Name: "storyID", Type: "integer"}}, Response: storage.WorkspaceNote{},
+	},
+	"PUT /api/workspaces/{id}/notes/{storyID}": {
+		Summary: "Set the workspace's note on a story", Tag: "workspaces", Access: accessUser,
+		Path: []apiParam{idParam, {Name: "storyID", Type: "integer"}}, Body: jsonObject{"note": ""}, Response: statusOK,
+	},
+
+	// Account
+	"GET /api/me": {
+		Summary: "The signed-in user and their settings", Tag: "account", Access: accessUser,
+		Response: struct {
+			*storage.AuthUser
+			AISummariesEnabled bool                       `json:"ai_summaries_enabled"`
+			OllamaAvailable    bool                       `json:"ollama_available"`
+			OllamaModel        string                     `json:"ollama_model"`
+			OllamaModels       []string                   `json:"ollama_models"`
+			AIProvider         string                     `json:"ai_provider"`
+			Generation         storage.GenerationSettings `json:"generation"`
+			SensitiveContent   string                     `json:"sensitive_content"`
+			NeedsOnboarding    bool                       `json:"needs_onboarding"`
+		}{},
+	},
+	"GET /api/me/usage": {Summary: "Your AI usage and its estimated cost", Tag: "account", Access: accessUser, Response: storage.UserUsage{}},
+	"POST /api/settings": {
+		Summary: "Update your settings", Tag: "account", Access: accessUser,
+		Body: struct {
+			GeminiAPIKey            string                      `json:"gemini_api_key,omitempty"`
+			AISummariesEnabled      *bool                       `json:"ai_summaries_enabled,omitempty"`
+			OllamaModel             string                      `json:"ollama_model,omitempty"`
+			AIProvider              string                      `json:"ai_provider,omitempty"`
+			SavesPublic             *bool                       `json:"saves_public,omitempty"`
+			SummaryRefreshGrowthPct *int                        `json:"summary_refresh_growth_pct,omitempty"`
+			SummaryRefreshMax       *int                        `json:"summary_refresh_max,omitempty"`
+			Generation              *storage.GenerationSettings `json:"generation,omitempty"`
+			SensitiveContent        string                      `json:"sensitive_content,omitempty"`
+		}{},
+	},
+	"GET /api/me/notifications": {Summary: "Your notification preferences", Tag: "account", Access: accessUser, Response: storage.NotificationPreferences{}},
+	"PUT /api/me/notifications": {
+		Summary: "Update your notification preferences", Tag: "account", Access: accessUser,
+		Body: storage.NotificationPreferences{}, Response: storage.NotificationPreferences{},
+	},
+	"GET /api/me/api_keys": {Summary: "Your API keys", Tag: "account", Access: accessUser, Response: jsonObject{"api_keys": []storage.APIKey{}}},
+	"POST /api/me/api_keys": {
+		Summary: "Create an API key; the key itself is only returned here", Tag: "account", Access: accessUser,
+		Body: jsonObject{"name": ""}, Response: jsonObject{"api_key": storage.APIKey{}, "key": ""},
+	},
+	"DELETE /api/me/api_keys/{id}": {Summary: "Revoke an API key", Tag: "account", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"GET /api/me/calendar":         {Summary: "Your private calendar feed URL", Tag: "account", Access: accessUser, Response: jsonObject{"url": ""}},
+	"GET /api/calendar.ics": {
+		Summary: "Calendar of recurring HN threads", Tag: "feeds",
+		Query: []apiParam{{Name: "token", Description: "From /api/me/calendar, to include your saved Launch HN threads"}}, Produces: "text/calendar",
+	},
+	"GET /api/me/identities":               {Summary: "Sign-in providers linked to your account", Tag: "account", Access: accessUser, Response: jsonObject{"identities": []storage.Identity{}}},
+	"DELETE /api/me/identities/{provider}": {Summary: "Unlink a sign-in provider", Tag: "account", Access: accessUser, Response: statusOK},
+	"POST /api/me/email":                   {Summary: "Change your email; a link is sent to the new address", Tag: "account", Access: accessUser, Body: jsonObject{"email": ""}, Response: statusOK},
+	"GET /api/me/email/verify":             {Summary: "Confirm an email change", Tag: "account", Query: []apiParam{{Name: "token", Required: true}}, Redirect: true},
+	"GET /api/devices":                     {Summary: "Devices sharing your anonymous history", Tag: "account", Response: jsonObject{"devices": []storage.AnonDevice{}}},
+	"POST /api/devices":                    {Summary: "Register this device", Tag: "account", Response: jsonObject{"devices": []storage.AnonDevice{}}},
+	"DELETE /api/devices/{id}":             {Summary: "Forget a device", Tag: "account", Response: jsonObject{"devices": []storage.AnonDevice{}}},
+	"POST /api/devices/pairing_code":       {Summary: "Create a code to pair another device", Tag: "account", Response: jsonObject{"code": "", "expires_at": time.Time{}}},
+	"POST /api/devices/pair":               {Summary: "Pair this device using a code", Tag: "account", Body: jsonObject{"code": ""}, Response: jsonObject{"devices": []storage.AnonDevice{}}},
+	"GET /api/announcements":               {Summary: "Announcements you haven't dismissed", Tag: "account", Access: accessOptional, Response: jsonObject{"announcements": []storage.Announcement{}}},
+	"POST /api/announcements/{id}/dismiss": {Summary: "Dismiss an announcement", Tag: "account", Access: accessUser, Path: []apiParam{idParam}, Response: statusOK},
+	"GET /api/download/latest":             {Summary: "Download the latest desktop release", Tag: "meta", Redirect: true},
+
+	// AI
+	"GET /api/models/ollama": {Summary: "Models the Ollama server has", Tag: "ai", Response: jsonObject{"models": []string{}}},
+	"POST /api/stories/{id}/summarize": {
+		Summary: "Queue a summary of the discussion; poll status_url for it", Tag: "ai", Access: accessUser,
+		Path: []apiParam{storyIDParam}, Response: jsonObject{"job_id": "", "status_url": ""},
+	},
+	"GET /api/jobs/{id}": {
+		Summary: "A summary job's status, with the summary once it's done", Tag: "ai", Access: accessOptional,
+		Path: []apiParam{idParam},
+		Response: struct {
+			*storage.SummaryJob
+			Summary string   `json:"summary,omitempty"`
+			Topics  []string `json:"topics,omitempty"`
+		}{},
+	},
+	"POST /api/stories/{id}/summarize_article": {
+		Summary: "Summarize the story's article; streams progress as server-sent events when asked to", Tag: "ai", Access: accessUser,
+		Path: []apiParam{storyIDParam},
+	},
+	"POST /api/chat": {
+		Summary: "Ask about recent front-page stories", Tag: "ai", Access: accessUser,
+		Body: struct {
+			Message string `json:"message"`
+			History []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"history"`
+			Days int `json:"days"`
+		}{},
+	},
+	"POST /api/stories/saved/triage": {
+		Summary: "Group your unread saved stories and suggest an order", Tag: "ai", Access: accessUser,
+		Response: jsonObject{"groups": []triageGroup{}, "order": []int64{}, "skip": []triageSkip{}},
+	},
+
+	// Feeds
+	"GET /feed.xml":              {Summary: "Front page as an Atom feed", Tag: "feeds", Produces: "application/atom+xml"},
+	"GET /feed.json":             {Summary: "Front page as a JSON Feed", Tag: "feeds", Produces: "application/feed+json"},
+	"GET /feed/topic/{name}.rss": {Summary: "Summarized stories on a topic, as RSS", Tag: "feeds", Produces: "application/rss+xml"},
+	"GET /feed/topic/{name}.xml": {Summary: "Summarized stories on a topic, as Atom", Tag: "feeds", Produces: "application/atom+xml"},
+	"GET /lite":                  {Summary: "Redirects to /lite/", Tag: "pages", Redirect: true},
+	"GET /lite/":                 {Summary: "Front page without JavaScript", Tag: "pages", Query: []apiParam{{Name: "feed"}, {Name: "p", Type: "integer"}}, Produces: "text/html"},
+	"GET /lite/item/{id}":        {Summary: "A story and its comments without JavaScript", Tag: "pages", Path: []apiParam{storyIDParam}, Produces: "text/html"},
+
+	// Auth
+	"GET /auth/google":          {Summary: "Sign in with Google", Tag: "auth", Query: []apiParam{{Name: "redirect", Description: "Where to go afterwards"}}, Redirect: true},
+	"GET /auth/google/callback": {Summary: "Google OAuth callback", Tag: "auth", Redirect: true},
+	"GET /auth/github":          {Summary: "Sign in with GitHub", Tag: "auth", Redirect: true},
+	"GET /auth/github/callback": {Summary: "GitHub OAuth callback", Tag: "auth", Redirect: true},
+	"GET /auth/logout":          {Summary: "Sign out", Tag: "auth", Redirect: true},
+	"GET /auth/mobile/{provider}": {
+		Summary: "Start a native app sign-in (OAuth 2.0 with PKCE)", Tag: "auth",
+		Query: []apiParam{{Name: "redirect_uri", Required: true}, {Name: "code_challenge", Required: true}, {Name: "state"}}, Redirect: true,
+	},
+	"POST /auth/token": {
+		Summary: "Exchange a sign-in code for an API token (form-encoded, RFC 6749)", Tag: "auth",
+		Response: jsonObject{"access_token": "", "token_type": "", "key_id": ""},
+	},
+
+	// Admin
+	"GET /api/admin/stats": {Summary: "Usage and pipeline statistics", Tag: "admin", Access: accessAdmin, Response: storage.AppStats{}},
+	"GET /api/admin/stats/history": {
+		Summary: "Daily statistics", Tag: "admin", Access: accessAdmin,
+		Query: []apiParam{daysParam}, Response: jsonObject{"days": []storage.MetricSnapshot{}},
+	},
+	"GET /api/admin/users": {Summary: "All users", Tag: "admin", Access: accessAdmin, Response: []storage.AuthUser{}},
+	"POST /api/admin/users/merge": {
+		Summary: "Merge one account into another", Tag: "admin", Access: accessAdmin,
+		Body: jsonObject{"source_user_id": "", "target_user_id": ""}, Response: statusOK,
+	},
+	"GET /api/admin/summary-queue/events": {
+		Summary: "Recent summary queue decisions", Tag: "admin", Access: accessAdmin,
+		Query: []apiParam{limitParam}, Response: jsonObject{"events": []storage.SummaryQueueEvent{}},
+	},
+	"GET /api/admin/topics/rejected": {
+		Summary: "Topic tags the quality gate dropped", Tag: "admin", Access: accessAdmin,
+		Query: []apiParam{daysParam, limitParam}, Response: jsonObject{"topics": []storage.RejectedTopic{}},
+	},
+	"GET /api/admin/http-clients": {Summary: "Outbound HTTP client statistics", Tag: "admin", Access: accessAdmin, Response: jsonObject{"clients": jsonObject{}}},
+	"GET /api/admin/stories/sensitive": {
+		Summary: "Stories classified by the sensitive-content check", Tag: "admin", Access: accessAdmin,
+		Query:    []apiParam{{Name: "source", Description: "keyword, ai or admin"}, {Name: "flagged", Type: "boolean"}, limitParam},
+		Response: jsonObject{"stories": []storage.SensitiveStory{}},
+	},
+	"PATCH /api/admin/stories/{id}": {
+		Summary: "Pin, freeze or flag a story", Tag: "admin", Access: accessAdmin,
+		Path: []apiParam{storyIDParam},
+		Body: struct {
+			Pinned    *bool `json:"pinned,omitempty"`
+			Frozen    *bool `json:"frozen,omitempty"`
+			Sensitive *bool `json:"sensitive,omitempty"`
+		}{},
+		Response: storage.Story{},
+	},
+	"POST /api/admin/stories/{id}/reingest": {
+		Summary: "Re-fetch a story's comments and their authors from HN", Tag: "admin", Access: accessAdmin,
+		Path: []apiParam{storyIDParam}, Response: reingestResult{},
+	},
+	"GET /api/admin/announcements": {Summary: "All announcements", Tag: "admin", Access: accessAdmin, Response: jsonObject{"announcements": []storage.Announcement{}}},
+	"POST /api/admin/announcements": {
+		Summary: "Create an announcement", Tag: "admin", Access: accessAdmin,
+		Body: struct {
+			Title    string     `json:"title"`
+			Body     string     `json:"body"`
+			Level    string     `json:"level"`
+			StartsAt *time.Time `json:"starts_at"`
+			EndsAt   *time.Time `json:"ends_at"`
+		}{},
+		Response: storage.Announcement{},
+	},
+	"DELETE /api/admin/announcements/{id}": {Summary: "Delete an announcement", Tag: "admin", Access: accessAdmin, Path: []apiParam{idParam}, Response: statusOK},
+	"GET /api/admin/embeddings/export": {
+		Summary: "Export story embeddings", Tag: "admin", Access: accessAdmin,
+		Query:    []apiParam{{Name: "format", Description: "jsonl (default) or npy"}, daysParam},
+		Produces: "application/octet-stream",
+	},
+	"POST /api/admin/hooks/ingest": {
+		Summary: "Start an ingest job; signed with the hook secret instead of a session", Tag: "admin",
+		Response: jsonObject{"status": "", "job": ""},
+	},
+}
+
+// securitySchemes are the ways to sign a request in.
+var securitySchemes = map[string]any{
+	"session": map[string]any{"type": "apiKey", "in": "cookie", "name": auth.CookieName},
+	"token": map[string]any{
+		"type": "http", "scheme": "bearer",
+		"description": "An API key from /api/me/api_keys or a token from /auth/token",
+	},
+}
+
+var signedIn = []any{map[string]any{"session": []string{}}, map[string]any{"token": []string{}}}
+
+var routeParamRe = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// handleOpenAPI serves an OpenAPI 3 description of every route.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	spec, err := s.openAPISpec(s.publicBaseURL(r))
+	if err != nil {
+		log.Printf("Failed to build OpenAPI spec: %v", err)
+		http.Error(w, "Failed to build OpenAPI spec", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spec)
+}
+
+// handleAPIDocs serves Swagger UI pointed at /api/openapi.json.
+func (s *Server) handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(apiDocsPage)
+}
+
+func (s *Server) openAPISpec(baseURL string) (map[string]any, error) {
+	schemas := newSchemaSet()
+	paths := map[string]map[string]any{}
+	err := chi.Walk(s.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.Contains(route, "*") {
+			return nil // the static file server
+		}
+		p := routeParamRe.ReplaceAllString(route, "{$1}")
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+		paths[p][strings.ToLower(method)] = schemas.operation(route, apiDocs[method+" "+route])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "HN Station API",
+			"version":     "1",
+			"description": "Hacker News stories with AI summaries. Sign in with the session cookie the web app uses or with an API key as a bearer token.",
+		},
+		"servers": []any{map[string]any{"url": baseURL}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas":         schemas.named,
+			"securitySchemes": securitySchemes,
+		},
+	}, nil
+}
+
+// operation describes one route.
+func (b *schemaSet) operation(route string, doc apiDoc) map[string]any {
+	tag := doc.Tag
+	if tag == "" {
+		tag = defaultTag(route)
+	}
+	op := map[string]any{"tags": []string{tag}}
+	if doc.Summary != "" {
+		op["summary"] = doc.Summary
+	}
+
+	var params []any
+	for _, m := range routeParamRe.FindAllStringSubmatch(route, -1) {
+		p := apiParam{Name: m[1]}
+		for _, dp := range doc.Path {
+			if dp.Name == p.Name {
+				p = dp
+			}
+		}
+		p.Required = true
+		params = append(params, paramSchema(p, "path"))
+	}
+	for _, p := range doc.Query {
+		params = append(params, paramSchema(p, "query"))
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if doc.Body != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": b.of(doc.Body)}},
+		}
+	}
+
+	responses := map[string]any{}
+	ok := map[string]any{"description": "OK"}
+	switch {
+	case doc.Redirect:
+		responses["302"] = map[string]any{"description": "Redirect"}
+		ok = nil
+	case doc.Produces != "":
+		ok["content"] = map[string]any{doc.Produces: map[string]any{"schema": map[string]any{"type": "string"}}}
+	case doc.Response != nil:
+		ok["content"] = map[string]any{"application/json": map[string]any{"schema": b.of(doc.Response)}}
+	}
+	if ok != nil {
+		responses["200"] = ok
+	}
+	switch doc.Access {
+	case accessOptional:
+		op["security"] = append([]any{map[string]any{}}, signedIn...)
+	case accessUser:
+		op["security"] = signedIn
+		responses["401"] = map[string]any{"description": "Not signed in"}
+	case accessAdmin:
+		op["security"] = signedIn
+		responses["401"] = map[string]any{"description": "Not signed in"}
+		responses["403"] = map[string]any{"description": "Not an admin"}
+	default:
+		op["security"] = []any{}
+	}
+	op["responses"] = responses
+	return op
+}
+
+func paramSchema(p apiParam, in string) map[string]any {
+	typ := p.Type
+	if typ == "" {
+		typ = "string"
+	}
+	var schema map[string]any
+	if p.Repeated {
+		schema = map[string]any{"type": "array", "items": map[string]any{"type": typ}}
+	} else {
+		schema = map[string]any{"type": typ}
+	}
+	param := map[string]any{"name": p.Name, "in": in, "schema": schema}
+	if p.Required {
+		param["required"] = true
+	}
+	if p.Description != "" {
+		param["description"] = p.Description
+	}
+	return param
+}
+
+// defaultTag groups a route missing from apiDocs by its path.
+func defaultTag(route string) string {
+	switch {
+	case strings.HasPrefix(route, "/api/admin/"):
+		return "admin"
+	case strings.HasPrefix(route, "/api/me"):
+		return "account"
+	case strings.HasPrefix(route, "/auth/"):
+		return "auth"
+	default:
+		return "other"
+	}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaSet turns Go values into JSON schemas, collecting named structs as
+// components so each is described once.
+type schemaSet struct {
+	named map[string]any
+	names map[reflect.Type]string
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{named: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+func (b *schemaSet) of(v any) map[string]any {
+	if obj, ok := v.(jsonObject); ok {
+		props := map[string]any{}
+		for name, fv := range obj {
+			props[name] = b.of(fv)
+		}
+		return map[string]any{"type": "object", "properties": props}
+	}
+	if v == nil {
+		return map[string]any{}
+	}
+	return b.forType(reflect.TypeOf(v))
+}
+
+func (b *schemaSet) forType(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.forType(t.Elem())
+		if ref, ok := s["$ref"]; ok {
+			// OpenAPI 3.0 ignores a $ref's siblings.
+			return map[string]any{"allOf": []any{map[string]any{"$ref": ref}}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.forType(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + b.component(t)}
+	}
+	return map[string]any{}
+}
+
+// component registers a named struct and returns its component name.
+func (b *schemaSet) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if _, taken := b.named[name]; taken {
+		name = exportedName(path.Base(t.PkgPath())) + name
+	}
+	b.names[t] = name
+	b.named[name] = nil // reserved while the fields, which may refer back, are described
+	b.named[name] = b.structSchema(t)
+	return name
+}
+
+func (b *schemaSet) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	required := map[string]bool{}
+	b.addFields(t, props, required, false)
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		names := make([]string, 0, len(required))
+		for name := range required {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		s["required"] = names
+	}
+	return s
+}
+
+// addFields adds t's JSON fields, flattening embedded structs the way
+// encoding/json does: a field of the outer struct wins over an embedded one.
+func (b *schemaSet) addFields(t reflect.Type, props map[string]any, required map[string]bool, embedded bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(ft, props, required, true)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := props[name]; ok && embedded {
+			continue
+		}
+		props[name] = b.forType(ft)
+		if !strings.Contains(opts, "omitempty") {
+			required[name] = true
+		} else {
+			delete(required, name)
+		}
+	}
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	defaultPopularDays  = 7
+	maxPopularDays      = 90
+	defaultPopularLimit = 20
+	maxPopularLimit     = 100
+)
+
+// handleGetPopularStories serves GET /api/stories/popular: the stories this
+// instance's users saved (by=saves, default) or read (by=reads) the most
+// among those posted in the last days= days (default 7).
+func (s *Server) handleGetPopularStories(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	by := q.Get("by")
+	switch by {
+	case "":
+		by = storage.PopularBySaves
+	case storage.PopularBySaves, storage.PopularByReads:
+	default:
+		http.Error(w, "by must be saves or reads", http.StatusBadRequest)
+		return
+	}
+
+	days := defaultPopularDays
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxPopularDays {
+			http.Error(w, "days must be between 1 and 90", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	limit := defaultPopularLimit
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = min(v, maxPopularLimit)
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	stories, err := s.store.GetPopularStories(r.Context(), by, since, limit)
+	if err != nil {
+		log.Printf("Failed to fetch popular stories: %v", err)
+		http.Error(w, "Failed to fetch popular stories", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"by":      by,
+		"days":    days,
+	})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+)
+
+// aiAttempt makes one provider's attempt at a request; model and apiKey are
+// the parts of the request that depend on the provider.
+type aiAttempt func(p ai.Provider, model, apiKey string) (string, ai.Usage, error)
+
+// runAI tries attempt against the providers selected by the ai_provider
+// setting, in order, until one returns a response, and records cloud usage
+// against userID. With systemKey set, Gemini falls back to the system key
+// when the user has no key of their own.
+func (s *Server) runAI(ctx context.Context, userID string, systemKey bool, attempt aiAttempt) (string, error) {
+	setting, _ := s.store.GetSetting(ctx, "ai_provider")
+	providers, err := s.providers.Select(setting)
+	if err != nil {
+		return "", err
+	}
+
+	var lastErr error
+	for _, p := range providers {
+		model, apiKey, workspaceID, err := s.providerParams(ctx, p, userID, systemKey)
+		if err != nil {
+			lastErr = err
+			log.Printf("Skipping AI provider %s: %v", p.Name(), err)
+			continue
+		}
+		resp, usage, err := attempt(p, model, apiKey)
+		s.recordAIUsage(ctx, userID, usage)
+		if workspaceID != 0 {
+			if err := s.store.ConsumeWorkspaceQuota(ctx, workspaceID); err != nil {
+				log.Printf("Failed to count AI request against workspace %d: %v", workspaceID, err)
+			}
+		}
+		if err != nil {
+			lastErr = err
+			log.Printf("AI provider %s failed: %v", p.Name(), err)
+			continue
+		}
+		if resp != "" {
+			return resp, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no AI provider available for %q", setting)
+	}
+	return "", lastErr
+}
+
+// providerParams resolves the model and API key a provider is called with:
+// the admin's Ollama model, or the user's Gemini key. workspaceID is set when
+// the key is a workspace's shared key, so the request is counted against the
+// workspace's quota once it is made.
+func (s *Server) providerParams(ctx context.Context, p ai.Provider, userID string, systemKey bool) (model, apiKey string, workspaceID int64, err error) {
+	switch p.Name() {
+	case ai.ProviderOllama:
+		model, _ = s.store.GetSetting(ctx, "ollama_model")
+	case ai.ProviderGemini:
+		apiKey, workspaceID, err = s.geminiKeyFor(ctx, userID)
+		if err != nil {
+			return "", "", 0, err
+		}
+		if apiKey == "" && systemKey {
+			apiKey = s.geminiKey
+		}
+		if apiKey == "" {
+			return "", "", 0, ai.ErrNoAPIKey
+		}
+	}
+	return model, apiKey, workspaceID, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+)
+
+// redirectCookie carries a validated post-login redirect target through the
+// OAuth round trip.
+const redirectCookie = "oauth_redirect"
+
+// redirectPolicy decides where auth flows may send the browser afterwards:
+// the frontend URL by default, or a ?redirect= target that is a same-site
+// path or falls under the frontend URL or one of the redirect allowlist's
+// prefixes (e.g. "https://beta.hnstation.dev" or "hnstation://auth" for a
+// mobile deep link). Anything else is refused, so the server can't be used
+// as an open redirect.
+type redirectPolicy struct {
+	fallback string
+	allowed  []*url.URL
+}
+
+// newRedirectPolicy builds the policy for cfg's frontend URL and redirect
+// allowlist.
+func newRedirectPolicy(cfg config.Server) redirectPolicy {
+	p := redirectPolicy{fallback: cfg.FrontendURL}
+	entries := slices.Clone(cfg.RedirectAllowlist)
+	if p.fallback != "" {
+		entries = append(entries, p.fallback)
+	} else {
+		p.fallback = "/"
+	}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		u, err := url.Parse(e)
+		if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque != "") {
+			log.Printf("Ignoring invalid redirect allowlist entry %q", e)
+			continue
+		}
+		p.allowed = append(p.allowed, u)
+	}
+	return p
+}
+
+// resolve returns the target to redirect to for a requested one, and whether
+// the request was acceptable. An empty request resolves to the fallback.
+func (p redirectPolicy) resolve(raw string) (string, bool) {
+	if raw == "" {
+		return p.fallback, true
+	}
+	// Same-site paths; "//host" and "/\host" are protocol-relative URLs to
+	// browsers.
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") && !strings.HasPrefix(raw, `/\`) {
+		return raw, true
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.User != nil || u.Scheme == "" {
+		return "", false
+	}
+	for _, a := range p.allowed {
+		if strings.EqualFold(u.Scheme, a.Scheme) && strings.EqualFold(u.Host, a.Host) && underPath(u.Path, a.Path) {
+			return u.String(), true
+		}
+	}
+	return "", false
+}
+
+// underPath reports whether path is prefix or below it.
+func underPath(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// rememberRedirect validates the ?redirect= target of a login request and
+// stores it for the callback. It writes a 400 and returns false if the target
+// isn't allowed.
+func (s *Server) rememberRedirect(w http.ResponseWriter, r *http.Request) bool {
+	raw := r.URL.Query().Get("redirect")
+	if raw == "" {
+		return true
+	}
+	target, ok := s.redirects.resolve(raw)
+	if !ok {
+		http.Error(w, "Redirect target not allowed", http.StatusBadRequest)
+		return false
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     redirectCookie,
+		Value:    url.QueryEscape(target),
+		Path:     "/",
+		MaxAge:   300, // 5 minutes, like the OAuth state
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return true
+}
+
+// loginRedirect returns where to send the browser after a login callback,
+// consuming the target remembered by rememberRedirect.
+func (s *Server) loginRedirect(w http.ResponseWriter, r *http.Request) string {
+	c, err := r.Cookie(redirectCookie)
+	if err != nil {
+		return s.redirects.fallback
+	}
+	http.SetCookie(w, &http.Cookie{Name: redirectCookie, Value: "", Path: "/", MaxAge: -1})
+	raw, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return s.redirects.fallback
+	}
+	// Re-check: the cookie came back from the client.
+	if target, ok := s.redirects.resolve(raw); ok {
+		return target
+	}
+	return s.redirects.fallback
+}
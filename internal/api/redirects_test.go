@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	p := newRedirectPolicy(config.Server{
+		FrontendURL:       "https://hnstation.dev",
+		RedirectAllowlist: []string{"https://beta.hnstation.dev/app", "hnstation://auth", "not a url"},
+	})
+
+	tests := []struct {
+		raw    string
+		target string
+		ok     bool
+	}{
+		{"", "https://hnstation.dev", true},
+		{"/saved", "/saved", true},
+		{"/story/1?tab=comments", "/story/1?tab=comments", true},
+		{"https://hnstation.dev/saved", "https://hnstation.dev/saved", true},
+		{"HTTPS://HNSTATION.DEV/", "https://HNSTATION.DEV/", true},
+		{"https://beta.hnstation.dev/app", "https://beta.hnstation.dev/app", true},
+		{"https://beta.hnstation.dev/app/settings", "https://beta.hnstation.dev/app/settings", true},
+		{"hnstation://auth?x=1", "hnstation://auth?x=1", true},
+
+		// Protocol-relative URLs.
+		{"//evil.com", "", false},
+		{"//evil.com/saved", "", false},
+		{`/\evil.com`, "", false},
+		// Absolute URLs off the allowlist.
+		{"https://evil.com/", "", false},
+		{"http://hnstation.dev/", "", false},
+		{"https://hnstation.dev.evil.com/", "", false},
+		{"https://hnstation.dev@evil.com/", "", false},
+		{"https://user@hnstation.dev/", "", false},
+		{"javascript:alert(1)", "", false},
+		{"evilapp://auth", "", false},
+		// Outside an entry's path prefix.
+		{"https://beta.hnstation.dev/", "", false},
+		{"https://beta.hnstation.dev/application", "", false},
+		{"hnstation://other", "", false},
+		// Neither a path nor a URL.
+		{"saved", "", false},
+		{"evil.com", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			target, ok := p.resolve(tt.raw)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.target, target)
+		})
+	}
+}
+
+func TestRedirectPolicyWithoutFrontend(t *testing.T) {
+	p := newRedirectPolicy(config.Server{})
+
+	target, ok := p.resolve("")
+	assert.True(t, ok)
+	assert.Equal(t, "/", target)
+
+	_, ok = p.resolve("https://hnstation.dev/")
+	assert.False(t, ok)
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// reingestConcurrency caps concurrent HN API requests during a re-ingest.
+const reingestConcurrency = 16
+
+// reingestResult reports what a re-ingest fetched.
+type reingestResult struct {
+	StoryID    int   `json:"story_id"`
+	Comments   int   `json:"comments"`
+	Users      int   `json:"users"`
+	Failures   int   `json:"failures"` // items or users that couldn't be fetched or saved
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// handleAdminReingestStory re-fetches a story, its full comment tree and its
+// participants' profiles from HN right away, for threads that were ingested
+// mid-flight and are missing late comments.
+func (s *Server) handleAdminReingestStory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	start := time.Now()
+	item, err := s.hnClient.GetItem(ctx, id)
+	if err != nil {
+		log.Printf("Reingest: failed to fetch story %d: %v", id, err)
+		http.Error(w, "Failed to fetch story from HN", http.StatusBadGateway)
+		return
+	}
+	if item.Type != "story" {
+		http.Error(w, "Item is not a story", http.StatusBadRequest)
+		return
+	}
+
+	story := storage.Story{
+		ID:          int64(item.ID),
+		Title:       item.Title,
+		URL:         item.URL,
+		Text:        item.Text,
+		Score:       item.Score,
+		By:          item.By,
+		Descendants: item.Descendants,
+		PostedAt:    time.Unix(item.Time, 0),
+	}
+	// Keep the story's front-page rank; only the ingest cycle assigns ranks.
+	if existing, err := s.store.GetStory(ctx, id); err == nil {
+		story.HNRank = existing.HNRank
+	}
+	if err := s.store.UpsertStory(ctx, story); err != nil {
+		log.Printf("Reingest: failed to save story %d: %v", id, err)
+		http.Error(w, "Failed to save story", http.StatusInternalServerError)
+		return
+	}
+
+	res := s.reingestComments(ctx, item)
+	res.StoryID = id
+	if err := s.store.NotifyHNUserActivity(ctx, id); err != nil {
+		log.Printf("Reingest: failed to queue HN user activity notifications (story %d): %v", id, err)
+	}
+	res.DurationMS = time.Since(start).Milliseconds()
+	log.Printf("Reingest: story %d refreshed with %d comments and %d users (%d failures) in %dms", id, res.Comments, res.Users, res.Failures, res.DurationMS)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// reingestComments walks the story's comment tree level by level, saving
+// every live comment, then refreshes the profiles of everyone involved.
+func (s *Server) reingestComments(ctx context.Context, story *hn.Item) reingestResult {
+	type ref struct {
+		id     int
+		parent *int64
+	}
+	var (
+		res     reingestResult
+		mu      sync.Mutex
+		authors = map[string]bool{}
+		sem     = make(chan struct{}, reingestConcurrency)
+	)
+	if story.By != "" {
+		authors[story.By] = true
+	}
+
+	level := make([]ref, 0, len(story.Kids))
+	for _, k := range story.Kids {
+		level = append(level, ref{id: k})
+	}
+	for len(level) > 0 && ctx.Err() == nil {
+		var next []ref
+		var wg sync.WaitGroup
+		for _, c := range level {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(c ref) {
+				defer func() { <-sem; wg.Done() }()
+				item, err := s.hnClient.GetItem(ctx, c.id)
+				if err != nil {
+					log.Printf("Reingest: failed to fetch comment %d: %v", c.id, err)
+					mu.Lock()
+					res.Failures++
+					mu.Unlock()
+					return
+				}
+				if item.Type != "comment" || item.Deleted || item.Dead {
+					return
+				}
+				err = s.store.UpsertComment(ctx, storage.Comment{
+					ID:       int64(item.ID),
+					StoryID:  int64(story.ID),
+					ParentID: c.parent,
+					Text:     item.Text,
+					By:       item.By,
+					PostedAt: time.Unix(item.Time, 0),
+				})
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					log.Printf("Reingest: failed to save comment %d: %v", item.ID, err)
+					res.Failures++
+					return
+				}
+				res.Comments++
+				if item.By != "" {
+					authors[item.By] = true
+				}
+				parent := int64(item.ID)
+				for _, k := range item.Kids {
+					next = append(next, ref{id: k, parent: &parent})
+				}
+			}(c)
+		}
+		wg.Wait()
+		level = next
+	}
+
+	var wg sync.WaitGroup
+	for name := range authors {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() { <-sem; wg.Done() }()
+			err := s.reingestUser(ctx, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Reingest: failed to refresh user %s: %v", name, err)
+				res.Failures++
+				return
+			}
+			res.Users++
+		}(name)
+	}
+	wg.Wait()
+	return res
+}
+
+func (s *Server) reingestUser(ctx context.Context, username string) error {
+	u, err := s.hnClient.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.store.UpsertUser(ctx, storage.User{
+		ID:        u.ID,
+		Created:   u.Created,
+		Karma:     u.Karma,
+		About:     u.About,
+		Submitted: u.Submitted,
+	})
+}
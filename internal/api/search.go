@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pgvector "github.com/pgvector/pgvector-go"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// searchCandidates is how many hits each ranking contributes to a
+	// hybrid search before fusion.
+	searchCandidates = 50
+	// rrfK dampens the weight of top ranks in reciprocal rank fusion; 60 is
+	// the value from the original RRF paper.
+	rrfK = 60
+)
+
+// handleSearch serves GET /api/search?q=&type=. The default "keyword" type
+// ranks by full-text relevance; "hybrid" fuses that ranking with embedding
+// similarity, falling back to keyword results if no embedding model is
+// reachable. The response's "type" reports which ranking was used.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= searchCandidates {
+		limit = val
+	}
+
+	searchType := r.URL.Query().Get("type")
+	if searchType == "" {
+		searchType = "keyword"
+	}
+	if searchType != "keyword" && searchType != "hybrid" {
+		http.Error(w, "type must be keyword or hybrid", http.StatusBadRequest)
+		return
+	}
+
+	var stories []storage.Story
+	var err error
+	if searchType == "hybrid" {
+		stories, searchType, err = s.hybridSearch(r.Context(), query, limit)
+	} else {
+		stories, err = s.store.SearchKeyword(r.Context(), query, limit)
+	}
+	if err != nil {
+		log.Printf("Search for %q failed: %v", query, err)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"type":    searchType,
+	})
+}
+
+// handleSemanticStories serves GET /api/stories?type=semantic&q=: stories
+// ranked by the similarity of their embedding to the query's, each with its
+// similarity score. It answers 503 when no embedding model is configured or
+// reachable.
+func (s *Server) handleSemanticStories(w http.ResponseWriter, r *http.Request, limit, offset int) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	if s.embedder == nil {
+		http.Error(w, "Semantic search is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	limit = min(limit, searchCandidates)
+
+	embedCtx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	vec, err := s.embedder.EmbedQuery(embedCtx, query)
+	if err != nil {
+		log.Printf("Semantic search: embedding %q failed: %v", query, err)
+		http.Error(w, "Embedding model unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	stories, total, err := s.store.SearchStories(r.Context(), pgvector.NewVector(vec), s.embedder.Model(), limit, offset)
+	if err != nil {
+		log.Printf("Semantic search for %q failed: %v", query, err)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stories": stories,
+		"total":   total,
+	})
+}
+
+// hybridSearch fuses keyword and semantic rankings. It returns the ranking
+// type actually used: "keyword" if the query couldn't be embedded.
+func (s *Server) hybridSearch(ctx context.Context, query string, limit int) ([]storage.Story, string, error) {
+	keyword, err := s.store.SearchKeyword(ctx, query, searchCandidates)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if s.embedder == nil {
+		return truncateStories(keyword, limit), "keyword", nil
+	}
+	embedCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	vec, err := s.embedder.EmbedQuery(embedCtx, query)
+	if err != nil {
+		log.Printf("Hybrid search: embedding failed, using keyword ranking: %v", err)
+		return truncateStories(keyword, limit), "keyword", nil
+	}
+	semantic, _, err := s.store.SearchStories(ctx, pgvector.NewVector(vec), s.embedder.Model(), searchCandidates, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return truncateStories(reciprocalRankFusion(keyword, semantic), limit), "hybrid", nil
+}
+
+// reciprocalRankFusion merges rankings by summing 1/(rrfK+rank) for each
+// story across the lists it appears in. Ties keep first-seen order.
+func reciprocalRankFusion(rankings ...[]storage.Story) []storage.Story {
+	scores := make(map[int64]float64)
+	index := make(map[int64]int)
+	var merged []storage.Story
+	for _, ranking := range rankings {
+		for rank, st := range ranking {
+			i, ok := index[st.ID]
+			if !ok {
+				i = len(merged)
+				index[st.ID] = i
+				merged = append(merged, st)
+			}
+			if st.Similarity != nil {
+				merged[i].Similarity = st.Similarity
+			}
+			scores[st.ID] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return scores[merged[i].ID] > scores[merged[j].ID]
+	})
+	return merged
+}
+
+func truncateStories(stories []storage.Story, limit int) []storage.Story {
+	if len(stories) > limit {
+		return stories[:limit]
+	}
+	return stories
+}
@@ -3,10 +3,12 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,27 +18,60 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
 	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 	"golang.org/x/oauth2"
 )
 
 type Server struct {
-	store        storage.DB
-	router       *chi.Mux
-	auth         *auth.Config
-	aiClient     *ai.OllamaClient
-	geminiClient *ai.GeminiClient
-	localMode    bool // true = SQLite local mode, auth disabled
+	store       storage.DB
+	router      *chi.Mux
+	auth        *auth.Config
+	aiClient    *ai.OllamaClient // model listing
+	providers   ai.Providers     // summaries and chat, chosen by the ai_provider setting
+	embedder    ai.Embedder      // search queries; nil if embeddings are off
+	languages   ai.LanguagePolicy
+	jobWake     chan struct{} // signals RunSummaryJobs that a job was queued
+	hnClient    *hn.Client
+	submissions submissionsCache
+	users       authUserCache
+	redirects   redirectPolicy
+	hooks       ingestHooks
+	stream      *storyStream // /api/stream clients; fed by RunStoryStream
+	cfg         config.Server
+	geminiKey   string // the system Gemini key
+	ollamaURL   string
+	fakeAI      bool // providers are fakes; Ollama is reported available
+	localMode   bool // true = SQLite local mode, auth disabled
 }
 
-func NewServer(store storage.DB, authCfg *auth.Config, aiClient *ai.OllamaClient, geminiClient *ai.GeminiClient, localMode bool) *Server {
+// NewServer creates the API server; a nil cfg uses the default settings.
+func NewServer(store storage.DB, authCfg *auth.Config, aiClient *ai.OllamaClient, providers ai.Providers, cfg *config.Config, localMode bool) *Server {
+	if cfg == nil {
+		cfg = config.Default()
+	}
 	s := &Server{
-		store:        store,
-		router:       chi.NewRouter(),
-		auth:         authCfg,
-		aiClient:     aiClient,
-		geminiClient: geminiClient,
-		localMode:    localMode,
+		store:     store,
+		router:    chi.NewRouter(),
+		auth:      authCfg,
+		aiClient:  aiClient,
+		providers: providers,
+		embedder:  ai.EmbedderFromConfig(aiClient, cfg),
+		languages: ai.LanguagePolicyFromConfig(cfg.AI),
+		jobWake:   make(chan struct{}, 1),
+		stream:    newStoryStream(),
+		hnClient:  hn.NewClient(),
+		redirects: newRedirectPolicy(cfg.Server),
+		cfg:       cfg.Server,
+		geminiKey: cfg.AI.Gemini.APIKey,
+		ollamaURL: cfg.Ollama.URL,
+		fakeAI:    cfg.AI.Fake,
+		localMode: localMode,
+	}
+	if s.fakeAI && s.embedder != nil {
+		s.embedder = ai.FakeEmbedder{}
 	}
 
 	s.middlewares()
@@ -45,21 +80,40 @@ func NewServer(store storage.DB, authCfg *auth.Config, aiClient *ai.OllamaClient
 	return s
 }
 
+// Route timeouts. Most routes only touch Postgres; article routes wait on
+// remote sites and AI routes on model generation. The deadline is carried by
+// the request context into storage, HTTP and AI calls.
+const (
+	readTimeout  = 30 * time.Second
+	fetchTimeout = 90 * time.Second
+	aiTimeout    = 5 * time.Minute
+)
+
 func (s *Server) middlewares() {
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.Timeout(10 * time.Minute))
+	if s.cfg.Mirror {
+		// Ahead of tokenAuth, which it strips the credentials for.
+		s.router.Use(s.mirrorOnly)
+	}
+	s.router.Use(s.tokenAuth)
 
-	allowedOrigins := []string{"http://localhost:5173", "http://localhost:5174", "https://hnstation.dev"}
+	allowedOrigins := slices.Clone(s.cfg.CORSOrigins)
 	if s.localMode {
 		allowedOrigins = append(allowedOrigins, "http://127.0.0.1")
 	}
 	s.router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		// Browser extensions may call the extension endpoints from their own origins.
+		AllowOriginFunc: func(r *http.Request, origin string) bool {
+			if extensionPaths[r.URL.Path] && isExtensionOrigin(origin) {
+				return true
+			}
+			return slices.Contains(allowedOrigins, origin)
+		},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -67,36 +121,153 @@ func (s *Server) middlewares() {
 }
 
 func (s *Server) routes() {
+	read := s.router.With(middleware.Timeout(readTimeout))
+	fetch := s.router.With(middleware.Timeout(fetchTimeout))
+	slow := s.router.With(middleware.Timeout(aiTimeout))
+
 	// Health check
-	s.router.Get("/healthc", s.handleHealthCheck)
+	read.Get("/healthc", s.handleHealthCheck)
+
+	// API description; see openapi.go
+	read.Get("/api/openapi.json", s.handleOpenAPI)
+	read.Get("/api/docs", s.handleAPIDocs)
 
 	// API routes
-	s.router.Get("/api/stories", s.handleGetStories)
-	s.router.Get("/api/stories/saved", s.handleGetSavedStories)
-	s.router.Get("/api/stories/{id}", s.handleGetStoryDetails)
-	s.router.Post("/api/stories/{id}/interact", s.handleInteract)
-	s.router.Get("/api/content/readme", s.handleGetReadme)
-	s.router.Get("/api/stories/{id}/content", s.handleGetArticleContent)
-	s.router.Get("/api/me", s.handleGetMe)
-	s.router.Post("/api/settings", s.handleUpdateSettings)
-	s.router.Get("/api/download/latest", s.handleDownloadLatest)
+	read.Get("/api/stories", s.handleGetStories)
+	read.Get("/api/search", s.handleSearch)
+	read.Get("/api/analytics/themes", s.handleGetThemes)
+	read.Get("/api/stories.txt", s.handleStoriesText)
+	read.Get("/api/stories/saved", s.handleGetSavedStories)
+	read.Get("/api/stories/popular", s.handleGetPopularStories)
+	read.Get("/api/hnjobs", s.handleGetHNJobs)
+	// Long-lived, so without a timeout; see stream.go
+	s.router.Get("/api/stream", s.handleStream)
+	read.Get("/api/stories/{id}", s.handleGetStoryDetails)
+	read.Post("/api/stories/{id}/interact", s.handleInteract)
+	read.Get("/api/devices", s.handleGetDevices)
+	read.Post("/api/devices", s.handleRegisterDevice)
+	read.Delete("/api/devices/{id}", s.handleRemoveDevice)
+	read.Post("/api/devices/pairing_code", s.handleCreatePairingCode)
+	read.Post("/api/devices/pair", s.handlePairDevice)
+	read.Get("/api/stories/{id}/comments", s.handleGetStoryComments)
+	read.Get("/api/comments/{id}", s.handleGetComment)
+	read.Get("/api/stories/{id}/local_comments", s.handleGetLocalComments)
+	read.Post("/api/stories/{id}/local_comments", s.handleAddLocalComment)
+	read.Put("/api/local_comments/{id}", s.handleUpdateLocalComment)
+	read.Delete("/api/local_comments/{id}", s.handleDeleteLocalComment)
+	fetch.Get("/api/content/readme", s.handleGetReadme)
+	fetch.Get("/api/stories/{id}/content", s.handleGetArticleContent)
+	read.Get("/api/me", s.handleGetMe)
+	read.Get("/api/me/usage", s.handleGetMyUsage)
+	read.Post("/api/settings", s.handleUpdateSettings)
+	read.Get("/api/download/latest", s.handleDownloadLatest)
+	read.Get("/api/me/subscriptions", s.handleGetSubscriptions)
+	read.Post("/api/me/subscriptions", s.handleSubscribeTopic)
+	read.Delete("/api/me/subscriptions/{topic}", s.handleUnsubscribeTopic)
+	read.Get("/api/me/watches", s.handleGetWatches)
+	read.Put("/api/stories/{id}/watch", s.handleWatchStory)
+	read.Delete("/api/stories/{id}/watch", s.handleUnwatchStory)
+	read.Post("/api/stories/{id}/share", s.handleShareStory)
+	read.Get("/api/me/notifications", s.handleGetNotificationPrefs)
+	read.Put("/api/me/notifications", s.handleUpdateNotificationPrefs)
+	read.Get("/api/me/api_keys", s.handleGetAPIKeys)
+	read.Post("/api/me/api_keys", s.handleCreateAPIKey)
+	read.Delete("/api/me/api_keys/{id}", s.handleDeleteAPIKey)
+	read.Get("/api/lookup", s.handleLookup)
+	read.Post("/api/save", s.handleExtensionSave)
+	read.Get("/api/me/library", s.handleGetLibrary)
+	read.Delete("/api/me/library/{id}", s.handleDeleteLibraryItem)
+	read.Get("/save", s.handleBookmarkletSave)
+	read.Get("/feed/topic/{name}.rss", s.handleTopicFeed)
+	read.Get("/feed/topic/{name}.xml", s.handleTopicAtomFeed)
+	read.Get("/feed.xml", s.handleAtomFeed)
+	read.Get("/feed.json", s.handleJSONFeed)
+	read.Get("/api/me/calendar", s.handleGetCalendarURL)
+	read.Get("/api/calendar.ics", s.handleCalendarFeed)
+	read.Get("/api/me/lists", s.handleGetMyLists)
+	read.Post("/api/me/lists", s.handleCreateList)
+	read.Put("/api/me/lists/{id}", s.handleUpdateList)
+	read.Delete("/api/me/lists/{id}", s.handleDeleteList)
+	read.Post("/api/me/lists/{id}/items", s.handleAddListItem)
+	read.Delete("/api/me/lists/{id}/items/{storyID}", s.handleRemoveListItem)
+	read.Get("/api/lists/{slug}", s.handleGetPublicList)
+	read.Get("/api/me/following", s.handleGetFollowing)
+	read.Get("/api/me/hn_following", s.handleGetFollowedHNUsers)
+	read.Post("/api/me/hn_following", s.handleFollowHNUser)
+	read.Delete("/api/me/hn_following/{username}", s.handleUnfollowHNUser)
+	read.Get("/api/onboarding/topics", s.handleGetOnboardingTopics)
+	read.Get("/api/me/topics", s.handleGetFollowedTopics)
+	read.Post("/api/me/topics", s.handleFollowTopics)
+	read.Delete("/api/me/topics/{topic}", s.handleUnfollowTopic)
+	read.Get("/api/me/muted", s.handleGetMutedAuthors)
+	read.Post("/api/me/muted", s.handleMuteAuthor)
+	read.Delete("/api/me/muted/{username}", s.handleUnmuteAuthor)
+	read.Post("/api/users/{id}/follow", s.handleFollowUser)
+	read.Delete("/api/users/{id}/follow", s.handleUnfollowUser)
+	read.Get("/api/users/{username}/karma_history", s.handleGetKarmaHistory)
+	read.Get("/api/announcements", s.handleGetAnnouncements)
+	read.Post("/api/announcements/{id}/dismiss", s.handleDismissAnnouncement)
+	read.Get("/api/workspaces", s.handleGetWorkspaces)
+	read.Post("/api/workspaces", s.handleCreateWorkspace)
+	read.Get("/api/workspaces/{id}", s.handleGetWorkspace)
+	read.Put("/api/workspaces/{id}", s.handleUpdateWorkspace)
+	read.Delete("/api/workspaces/{id}", s.handleDeleteWorkspace)
+	read.Post("/api/workspaces/{id}/members", s.handleAddWorkspaceMember)
+	read.Put("/api/workspaces/{id}/members/{userID}", s.handleUpdateWorkspaceMember)
+	read.Delete("/api/workspaces/{id}/members/{userID}", s.handleRemoveWorkspaceMember)
+	read.Get("/api/workspaces/{id}/lists", s.handleGetWorkspaceLists)
+	read.Post("/api/workspaces/{id}/lists", s.handleCreateWorkspaceList)
+	read.Get("/api/workspaces/{id}/notes/{storyID}", s.handleGetWorkspaceNote)
+	read.Put("/api/workspaces/{id}/notes/{storyID}", s.handleSetWorkspaceNote)
 
 	// Auth routes
-	s.router.Get("/auth/google", s.handleGoogleLogin)
-	s.router.Get("/auth/google/callback", s.handleGoogleCallback)
-	s.router.Get("/auth/logout", s.handleLogout)
+	read.Get("/auth/google", s.handleGoogleLogin)
+	read.Get("/auth/google/callback", s.handleGoogleCallback)
+	read.Get("/auth/logout", s.handleLogout)
+	read.Get("/auth/github", s.handleGitHubLogin)
+	read.Get("/auth/github/callback", s.handleGitHubCallback)
+	read.Get("/auth/mobile/{provider}", s.handleMobileLogin)
+	read.Post("/auth/token", s.handleTokenExchange)
+	read.Get("/api/me/identities", s.handleGetIdentities)
+	read.Delete("/api/me/identities/{provider}", s.handleUnlinkIdentity)
+	read.Post("/api/me/email", s.handleRequestEmailChange)
+	read.Get("/api/me/email/verify", s.handleVerifyEmailChange)
+
+	// No-JS HTML pages
+	read.Get("/lite", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/lite/", http.StatusMovedPermanently)
+	})
+	read.Get("/lite/", s.handleLiteFront)
+	read.Get("/lite/item/{id}", s.handleLiteStory)
 
 	// AI routes
-	s.router.Get("/api/models/ollama", s.handleListOllamaModels)
-	s.router.Post("/api/stories/{id}/summarize", s.handleSummarizeStory)
-	s.router.Post("/api/stories/{id}/summarize_article", s.handleSummarizeArticle)
+	read.Get("/api/models/ollama", s.handleListOllamaModels)
+	slow.Post("/api/stories/{id}/summarize", s.handleSummarizeStory)
+	read.Get("/api/jobs/{id}", s.handleGetJob)
+	slow.Post("/api/stories/{id}/summarize_article", s.handleSummarizeArticle)
+	slow.Post("/api/chat", s.handleFrontPageChat)
+	slow.Post("/api/stories/saved/triage", s.handleTriageSaved)
 
 	// Admin routes
-	s.router.Group(func(r chi.Router) {
-		r.Use(s.adminMiddleware)
-		r.Get("/api/admin/stats", s.handleGetAdminStats)
-		r.Get("/api/admin/users", s.handleGetAdminUsers)
-	})
+	admin := s.router.With(middleware.Timeout(readTimeout), s.adminMiddleware)
+	admin.Get("/api/admin/stats", s.handleGetAdminStats)
+	admin.Get("/api/admin/stats/history", s.handleGetStatsHistory)
+	admin.Get("/api/admin/users", s.handleGetAdminUsers)
+	admin.Get("/api/admin/summary-queue/events", s.handleGetSummaryQueueEvents)
+	admin.Get("/api/admin/topics/rejected", s.handleGetRejectedTopics)
+	admin.Get("/api/admin/http-clients", s.handleGetHTTPClientStats)
+	admin.Post("/api/admin/users/merge", s.handleAdminMergeUsers)
+	admin.Get("/api/admin/stories/sensitive", s.handleGetSensitiveStories)
+	admin.Patch("/api/admin/stories/{id}", s.handleAdminUpdateStoryFlags)
+	admin.Get("/api/admin/announcements", s.handleGetAdminAnnouncements)
+	admin.Post("/api/admin/announcements", s.handleCreateAnnouncement)
+	admin.Delete("/api/admin/announcements/{id}", s.handleDeleteAnnouncement)
+	// Signed by a shared secret rather than an admin session, for schedulers.
+	read.Post("/api/admin/hooks/ingest", s.handleIngestHook)
+	// Exports stream every embedding; re-ingest walks a whole comment tree.
+	adminSlow := s.router.With(middleware.Timeout(aiTimeout), s.adminMiddleware)
+	adminSlow.Get("/api/admin/embeddings/export", s.handleExportEmbeddings)
+	adminSlow.Post("/api/admin/stories/{id}/reingest", s.handleAdminReingestStory)
 
 	// SPA catch-all
 	// Serve index.html for any other route that doesn't match API or static files
@@ -175,9 +346,26 @@ func isSecureRequest(r *http.Request) bool {
 	return r.Header.Get("X-Forwarded-Proto") == "https"
 }
 
+// requireUserID returns the authenticated user ID, falling back to the local
+// user in local mode. It writes a 401 and returns false otherwise.
+func (s *Server) requireUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		if s.localMode {
+			return "local-user", true
+		}
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return "", false
+	}
+	return userID, true
+}
+
 // ─── Auth Handlers ───
 
 func (s *Server) handleGoogleLogin(w http.ResponseWriter, r *http.Request) {
+	if !s.rememberRedirect(w, r) {
+		return
+	}
 	state := auth.GenerateStateToken()
 
 	// Store state in a short-lived cookie for verification on callback
@@ -213,7 +401,8 @@ func (s *Server) handleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 
 	// Exchange code for token
 	code := r.URL.Query().Get("code")
-	token, err := s.auth.OAuth2Config.Exchange(context.Background(), code)
+	ctx := auth.OAuthContext(r.Context())
+	token, err := s.auth.OAuth2Config.Exchange(ctx, code)
 	if err != nil {
 		log.Printf("Error exchanging code for token: %v", err)
 		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
@@ -221,7 +410,7 @@ func (s *Server) handleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user info from Google
-	client := s.auth.OAuth2Config.Client(context.Background(), token)
+	client := s.auth.OAuth2Config.Client(ctx, token)
 	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
 		log.Printf("Error fetching user info: %v", err)
@@ -242,52 +431,30 @@ func (s *Server) handleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Upsert user in database
-	user, err := s.store.UpsertAuthUser(r.Context(), googleUser.ID, googleUser.Email, googleUser.Name, googleUser.Picture)
-	if err != nil {
-		log.Printf("Error upserting user: %v", err)
-		http.Error(w, "Failed to save user", http.StatusInternalServerError)
-		return
-	}
-
-	// Generate JWT
-	jwtToken, err := s.auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		log.Printf("Error generating JWT: %v", err)
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
-		return
-	}
-
-	// Set session cookie
-	auth.SetSessionCookie(w, jwtToken, isSecureRequest(r))
-
-	// Redirect to frontend
-	redirectURL := os.Getenv("FRONTEND_URL")
-	if redirectURL == "" {
-		redirectURL = "/"
-	}
-	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	s.completeLogin(w, r, storage.Identity{
+		Provider:  "google",
+		Subject:   googleUser.ID,
+		Email:     googleUser.Email,
+		Name:      googleUser.Name,
+		AvatarURL: googleUser.Picture,
+	})
 }
 
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
-	auth.ClearSessionCookie(w, isSecureRequest(r))
-
-	redirectURL := os.Getenv("FRONTEND_URL")
-	if redirectURL == "" {
-		redirectURL = "/"
+	target, ok := s.redirects.resolve(r.URL.Query().Get("redirect"))
+	if !ok {
+		http.Error(w, "Redirect target not allowed", http.StatusBadRequest)
+		return
 	}
-	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	auth.ClearSessionCookie(w, isSecureRequest(r))
+	http.Redirect(w, r, target, http.StatusTemporaryRedirect)
 }
 
 func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 	userID := s.auth.GetUserIDFromRequest(r)
 
 	// Determine Ollama availability
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://localhost:11434"
-	}
-	ollamaAvailable := s.aiClient.CheckAvailability(r.Context(), ollamaURL)
+	ollamaAvailable := s.fakeAI || s.aiClient.CheckAvailability(r.Context(), s.ollamaURL)
 
 	// Get AI enabled setting
 	aiEnabled := false
@@ -303,8 +470,10 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 
 	// Get available models if Ollama is available
 	var ollamaModels []string
-	if ollamaAvailable {
-		ollamaModels, _ = s.aiClient.ListModels(r.Context(), ollamaURL)
+	if s.fakeAI {
+		ollamaModels = []string{ai.ProviderFake}
+	} else if ollamaAvailable {
+		ollamaModels, _ = s.aiClient.ListModels(r.Context(), s.ollamaURL)
 	}
 
 	// In local mode, if not authenticated, return a default mock user
@@ -320,6 +489,7 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 			"ollama_available":     ollamaAvailable,
 			"ollama_model":         ollamaModel,
 			"ollama_models":        ollamaModels,
+			"sensitive_content":    storage.SensitiveBlur,
 		})
 		return
 	}
@@ -331,7 +501,7 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.store.GetAuthUser(r.Context(), userID)
+	user, err := s.getAuthUser(r.Context(), userID)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
@@ -339,14 +509,31 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	generation, err := s.store.GetGenerationSettings(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to load generation settings for %s: %v", userID, err)
+	}
+	sensitive, err := s.store.GetSensitivePreference(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to load sensitive content preference for %s: %v", userID, err)
+		sensitive = storage.SensitiveBlur
+	}
+	needsOnboarding, err := s.store.NeedsOnboarding(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to check onboarding for %s: %v", userID, err)
+	}
+
 	// Map to response struct that includes the extra fields
 	resp := struct {
 		*storage.AuthUser
-		AISummariesEnabled bool     `json:"ai_summaries_enabled"`
-		OllamaAvailable    bool     `json:"ollama_available"`
-		OllamaModel        string   `json:"ollama_model"`
-		OllamaModels       []string `json:"ollama_models"`
-		AIProvider         string   `json:"ai_provider"`
+		AISummariesEnabled bool                       `json:"ai_summaries_enabled"`
+		OllamaAvailable    bool                       `json:"ollama_available"`
+		OllamaModel        string                     `json:"ollama_model"`
+		OllamaModels       []string                   `json:"ollama_models"`
+		AIProvider         string                     `json:"ai_provider"`
+		Generation         storage.GenerationSettings `json:"generation"`
+		SensitiveContent   string                     `json:"sensitive_content"`
+		NeedsOnboarding    bool                       `json:"needs_onboarding"` // show topic suggestions; see GET /api/onboarding/topics
 	}{
 		AuthUser:           user,
 		AISummariesEnabled: aiEnabled,
@@ -354,6 +541,9 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 		OllamaModel:        ollamaModel,
 		OllamaModels:       ollamaModels,
 		AIProvider:         aiProvider,
+		Generation:         generation,
+		SensitiveContent:   sensitive,
+		NeedsOnboarding:    needsOnboarding,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -380,10 +570,20 @@ func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Semantic search path - DISABLED for Gemini BYOK MVP
-	searchType := r.URL.Query().Get("type")
-	if searchType == "semantic" {
-		http.Error(w, "Semantic search is currently disabled in BYOK mode", http.StatusServiceUnavailable)
+	switch r.URL.Query().Get("feed") {
+	case "following":
+		s.handleGetFollowingFeed(w, r, limit, offset)
+		return
+	case "hn_following":
+		s.handleGetHNFollowingFeed(w, r, limit, offset)
+		return
+	case "topics":
+		s.handleGetTopicsFeed(w, r, limit, offset)
+		return
+	}
+
+	if r.URL.Query().Get("type") == "semantic" {
+		s.handleSemanticStories(w, r, limit, offset)
 		return
 	}
 
@@ -395,6 +595,10 @@ func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
 	if sortParam != "latest" && sortParam != "votes" && sortParam != "default" && sortParam != "show" {
 		sortParam = "default"
 	}
+	// feed=new|best|ask|show|jobs lists stories in that HN list's order.
+	if feed := r.URL.Query().Get("feed"); storage.IsRankedFeed(feed) {
+		sortParam = feed
+	}
 
 	topicParams := r.URL.Query()["topic"]
 	var topics []string
@@ -404,16 +608,35 @@ func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// lang=en,de keeps stories in those languages (and those whose
+	// language isn't known).
+	var languages []string
+	for _, v := range r.URL.Query()["lang"] {
+		for _, l := range strings.Split(v, ",") {
+			if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+				languages = append(languages, l)
+			}
+		}
+	}
+
 	// Pass user ID for interaction flags (empty string = anonymous)
 	userID := s.auth.GetUserIDFromRequest(r)
 	showHidden := r.URL.Query().Get("show_hidden") == "true"
 
-	stories, total, err := s.store.GetStories(r.Context(), limit, offset, sortParam, topics, userID, showHidden)
+	stories, total, err := s.store.GetStories(r.Context(), limit, offset, sortParam, topics, languages, userID, showHidden)
 	if err != nil {
 		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
 		return
 	}
 
+	if userID == "" {
+		if anonID := s.auth.GetAnonymousIDFromRequest(r); anonID != "" {
+			if err := s.store.ApplyAnonInteractions(r.Context(), anonID, stories); err != nil {
+				log.Printf("Failed to load anonymous interactions: %v", err)
+			}
+		}
+	}
+
 	if stories == nil {
 		stories = []storage.Story{}
 	}
@@ -439,22 +662,44 @@ func (s *Server) handleGetStoryDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comments, err := s.store.GetComments(r.Context(), id)
+	// Every thread by default, for clients that predate comment paging.
+	limit, offset := commentWindow(r, "comment_limit", "comment_offset", 0)
+	comments, err := s.store.GetCommentsPage(r.Context(), id, limit, offset)
 	if err != nil {
 		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
 		return
 	}
+	userID := s.auth.GetUserIDFromRequest(r)
+	s.suggestCollapsed(r.Context(), story, comments.Comments)
+	s.hideMutedComments(r.Context(), userID, comments.Comments)
+	if userID != "" {
+		s.markNewSinceVisit(r.Context(), userID, story, comments.Comments)
+	}
+
+	titleHistory, err := s.store.GetTitleHistory(r.Context(), id)
+	if err != nil {
+		log.Printf("Failed to fetch title history for story %d: %v", id, err)
+	}
+	if titleHistory == nil {
+		titleHistory = []storage.TitleChange{}
+	}
 
-	if comments == nil {
-		comments = []storage.Comment{}
+	previous, err := s.previousSubmissions(r.Context(), story)
+	if err != nil {
+		log.Printf("Failed to look up previous submissions for story %d: %v", id, err)
+		previous = []previousSubmission{}
 	}
 
 	response := struct {
-		Story    *storage.Story    `json:"story"`
-		Comments []storage.Comment `json:"comments"`
+		Story *storage.Story `json:"story"`
+		*storage.CommentPage
+		TitleHistory        []storage.TitleChange `json:"title_history"`
+		PreviousSubmissions []previousSubmission  `json:"previous_submissions"`
 	}{
-		Story:    story,
-		Comments: comments,
+		Story:               story,
+		CommentPage:         comments,
+		TitleHistory:        titleHistory,
+		PreviousSubmissions: previous,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -463,14 +708,23 @@ func (s *Server) handleGetStoryDetails(w http.ResponseWriter, r *http.Request) {
 
 // ─── Interaction Handlers ───
 
+// maxInteractionNote caps the length of a user's note on a story.
+const maxInteractionNote = 2000
+
 func (s *Server) handleInteract(w http.ResponseWriter, r *http.Request) {
+	// Anonymous visitors get a signed anon cookie; their interactions are
+	// merged into their account when they sign in.
 	userID := s.auth.GetUserIDFromRequest(r)
+	anonID := ""
 	if userID == "" {
 		if s.localMode {
 			userID = "local-user"
 		} else {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
-			return
+			anonID = s.auth.GetAnonymousIDFromRequest(r)
+			if anonID == "" {
+				anonID = auth.NewAnonymousID()
+				s.auth.SetAnonymousCookie(w, anonID, isSecureRequest(r))
+			}
 		}
 	}
 
@@ -482,16 +736,35 @@ func (s *Server) handleInteract(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		Read   *bool `json:"read"`
-		Saved  *bool `json:"saved"`
-		Hidden *bool `json:"hidden"`
+		Read   *bool   `json:"read"`
+		Saved  *bool   `json:"saved"`
+		Hidden *bool   `json:"hidden"`
+		Note   *string `json:"note"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if body.Note != nil {
+		if anonID != "" {
+			http.Error(w, "Sign in to add notes", http.StatusUnauthorized)
+			return
+		}
+		if len(*body.Note) > maxInteractionNote {
+			http.Error(w, fmt.Sprintf("Note must be at most %d bytes", maxInteractionNote), http.StatusBadRequest)
+			return
+		}
+	}
 
-	if err := s.store.UpsertInteraction(r.Context(), userID, storyID, body.Read, body.Saved, body.Hidden); err != nil {
+	if anonID != "" {
+		err = s.store.UpsertAnonInteraction(r.Context(), anonID, storyID, body.Read, body.Saved, body.Hidden)
+	} else {
+		err = s.store.UpsertInteraction(r.Context(), userID, storyID, body.Read, body.Saved, body.Hidden)
+		if err == nil && body.Note != nil {
+			err = s.store.SetInteractionNote(r.Context(), userID, storyID, strings.TrimSpace(*body.Note))
+		}
+	}
+	if err != nil {
 		log.Printf("Error upserting interaction: %v", err)
 		http.Error(w, "Failed to update interaction", http.StatusInternalServerError)
 		return
@@ -528,7 +801,7 @@ func (s *Server) handleGetSavedStories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stories, total, err := s.store.GetSavedStories(r.Context(), userID, limit, offset)
+	stories, total, err := s.store.GetSavedStories(r.Context(), userID, r.URL.Query().Get("q"), limit, offset)
 	if err != nil {
 		http.Error(w, "Failed to fetch saved stories", http.StatusInternalServerError)
 		return
@@ -561,15 +834,14 @@ func (s *Server) handleSummarizeStory(w http.ResponseWriter, r *http.Request) {
 
 	// 1. Check Global Cache (Short-circuit if already summarized)
 	// This part is allowed for anonymous users.
-	if story.Summary != nil && *story.Summary != "" {
+	if story.DiscussionSummary != nil && *story.DiscussionSummary != "" {
 		userID := s.auth.GetUserIDFromRequest(r)
 		if userID != "" {
-			if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, *story.Summary)); err != nil {
+			if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, *story.DiscussionSummary)); err != nil {
 				log.Printf("Failed to save cached summary to history: %v", err)
 			}
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"summary": *story.Summary})
+		writeResult(w, newEventStream(w, r), map[string]string{"summary": *story.DiscussionSummary})
 		return
 	}
 
@@ -580,24 +852,80 @@ func (s *Server) handleSummarizeStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Streaming clients watch the summary being written; everyone else gets
+	// a job to poll.
+	es := newEventStream(w, r)
+	if es != nil {
+		summary, topics, err := s.summarizeDiscussion(r.Context(), userID, story, es)
+		switch {
+		case errors.Is(err, errNoDiscussion):
+			writeResult(w, es, map[string]string{"summary": "No discussion to summarize."})
+		case err != nil && es.started():
+			es.send("error", map[string]string{"error": "Failed to generate summary: " + err.Error()})
+		case err != nil:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate summary: " + err.Error()})
+		default:
+			writeResult(w, es, map[string]interface{}{
+				"summary": summary,
+				"topics":  topics,
+			})
+		}
+		return
+	}
+
 	comments, err := s.store.GetComments(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
 		return
 	}
-
 	if len(comments) == 0 {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"summary": "No discussion to summarize."})
 		return
 	}
 
+	jobID, err := s.store.EnqueueDiscussionJob(r.Context(), id, userID)
+	if err != nil {
+		log.Printf("Failed to queue discussion summary for story %d: %v", id, err)
+		http.Error(w, "Failed to queue summary", http.StatusInternalServerError)
+		return
+	}
+	s.wakeJobWorkers()
+
+	statusURL := fmt.Sprintf("/api/jobs/%d", jobID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", statusURL)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"job_id":     jobID,
+		"status_url": statusURL,
+	})
+}
+
+// summarizeDiscussion summarizes a story's comments with the configured AI
+// provider, streaming the model's output to es if it's set, and saves the
+// summary to the story and to userID's chat history.
+func (s *Server) summarizeDiscussion(ctx context.Context, userID string, story *storage.Story, es *eventStream) (string, []string, error) {
+	id := int(story.ID)
+	comments, err := s.store.GetComments(ctx, id)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	if len(comments) == 0 {
+		return "", nil, errNoDiscussion
+	}
+
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Title: %s\n\nDiscussion:\n", story.Title))
 
 	totalChars := 0
 	maxChars := 20000 // Increased for local GPU
 	for _, c := range comments {
+		if c.SpamSuspect {
+			continue
+		}
 		text := fmt.Sprintf("- %s: %s\n", c.By, c.Text)
 		if totalChars+len(text) > maxChars {
 			break
@@ -606,96 +934,35 @@ func (s *Server) handleSummarizeStory(w http.ResponseWriter, r *http.Request) {
 		totalChars += len(text)
 	}
 
-	// Determine provider preference
-	provider, _ := s.store.GetSetting(r.Context(), "ai_provider")
-	if provider == "" {
-		provider = "local"
-	}
-
-	var summary string
-	var topics []string
-	var summarizeErr error
-
-	// 1. Try Local Ollama if provider is "local" or "both"
-	if provider == "local" || provider == "both" {
-		ollamaURL := os.Getenv("OLLAMA_URL")
-		if ollamaURL == "" {
-			ollamaURL = "http://localhost:11434"
-		}
-		model, _ := s.store.GetSetting(r.Context(), "ollama_model")
-		responseStr, err := s.aiClient.GenerateSummary(r.Context(), ollamaURL, model, story.Title, sb.String())
-		if err == nil {
-			// Success with local
-			summary, topics = parseOllamaResponse(responseStr)
-		} else {
-			summarizeErr = err
-			log.Printf("Ollama summarization failed: %v", err)
-		}
-	}
-
-	// 2. Fallback to Gemini if:
-	// - Local failed OR provider is "gemini"
-	// - AND provider is "gemini" or "both"
-	// - AND user has gemini key
-	if summary == "" && (provider == "gemini" || provider == "both") {
-		// Get Gemini API Key
-		var geminiKey string
-		if s.localMode {
-			geminiKey = os.Getenv("GEMINI_API_KEY") // System key fallback
-		}
-		if u, err := s.store.GetAuthUser(r.Context(), userID); err == nil && u.GeminiAPIKey != "" {
-			geminiKey = u.GeminiAPIKey
-		}
-
-		if geminiKey != "" {
-			log.Printf("Attempting fallback/primary Gemini summarization for story %d", id)
-			resp, err := s.geminiClient.GenerateSummary(r.Context(), geminiKey, sb.String())
-			if err == nil {
-				summary = resp
-				// topics? Gemini client doesn't explicitly return topics yet, but we can extract them if they are in bullet points
-				// or just leave them empty for now.
-			} else {
-				summarizeErr = err
-				log.Printf("Gemini summarization failed: %v", err)
-			}
-		}
-	}
-
-	if summary == "" {
-		log.Printf("All summarization attempts failed for story %d", id)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		errMsg := "Failed to generate summary"
-		if summarizeErr != nil {
-			errMsg += ": " + summarizeErr.Error()
+	// The article summary's status and failure fields are left alone: they
+	// describe the article pipeline, and a discussion job reports its own.
+	opts := s.generationOptions(ctx, userID)
+	resp, err := s.runAI(ctx, userID, false, func(p ai.Provider, model, apiKey string) (string, ai.Usage, error) {
+		es.attempt()
+		return p.Summarize(ctx, ai.SummaryRequest{Title: story.Title, Text: sb.String(), Model: model, APIKey: apiKey, Options: opts, OnToken: es.onToken()})
+	})
+	var parsed ai.Summary
+	if err == nil {
+		if parsed, err = ai.ParseSummaryResponse(resp); err != nil {
+			log.Printf("Failed to parse summary for story %d: %v. Raw: %s", id, err, resp)
 		}
-		json.NewEncoder(w).Encode(map[string]string{"error": errMsg})
-		return
 	}
-
-	result := struct {
-		Summary string
-		Topics  []string
-	}{
-		Summary: summary,
-		Topics:  topics,
+	if err != nil {
+		log.Printf("All discussion summary attempts failed for story %d: %v", id, err)
+		return "", nil, err
 	}
+	summary, topics := parsed.Text(), parsed.Topics
 
-	// 2. Save both Summary and Topics to Global Cache
-	if err := s.store.UpdateStorySummaryAndTopics(r.Context(), id, result.Summary, result.Topics); err != nil {
-		log.Printf("Failed to update story summary/topics cache: %v", err)
+	// Cache it on the story, next to (not over) the article summary
+	if err := s.store.UpdateStoryDiscussionSummary(ctx, id, summary, topics); err != nil {
+		log.Printf("Failed to save discussion summary (story %d): %v", id, err)
 	}
 
 	// Save summary to chat history
-	if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, result.Summary)); err != nil {
+	if err := s.store.SaveChatMessage(ctx, userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, summary)); err != nil {
 		log.Printf("Failed to save summary to history: %v", err)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"summary": result.Summary,
-		"topics":  result.Topics,
-	})
+	return summary, topics, nil
 }
 
 func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
@@ -710,11 +977,42 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		AISummariesEnabled *bool  `json:"ai_summaries_enabled"`
 		OllamaModel        string `json:"ollama_model"`
 		AIProvider         string `json:"ai_provider"`
+		SavesPublic        *bool  `json:"saves_public"`
+		// Summary freshness policy (admins only): regenerate after this much comment growth
+		// (percent, 0 disables), at most this many times per story.
+		SummaryRefreshGrowthPct *int `json:"summary_refresh_growth_pct"`
+		SummaryRefreshMax       *int `json:"summary_refresh_max"`
+		// The caller's generation knobs; replaces them as a whole, so
+		// omitted fields go back to the provider default.
+		Generation *storage.GenerationSettings `json:"generation"`
+		// How to show stories flagged as sensitive: "show", "blur" or "hide".
+		SensitiveContent string `json:"sensitive_content"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if body.SensitiveContent != "" && !storage.IsSensitivePreference(body.SensitiveContent) {
+		http.Error(w, "sensitive_content must be show, blur or hide", http.StatusBadRequest)
+		return
+	}
+	if body.AIProvider != "" {
+		if _, err := s.providers.Select(body.AIProvider); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// The freshness policy applies to every story on the instance.
+	if body.SummaryRefreshGrowthPct != nil || body.SummaryRefreshMax != nil {
+		isAdmin := s.localMode
+		if u, err := s.getAuthUser(r.Context(), userID); err == nil {
+			isAdmin = u.IsAdmin
+		}
+		if !isAdmin {
+			http.Error(w, "Only admins can change the summary refresh policy", http.StatusForbidden)
+			return
+		}
+	}
 
 	if body.GeminiAPIKey != "" {
 		if err := s.store.UpdateUserGeminiKey(r.Context(), userID, body.GeminiAPIKey); err != nil {
@@ -722,6 +1020,7 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Failed to update settings", http.StatusInternalServerError)
 			return
 		}
+		s.users.invalidate(userID)
 	}
 
 	if body.AISummariesEnabled != nil {
@@ -744,6 +1043,53 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for key, val := range map[string]*int{
+		storage.SettingSummaryRefreshGrowthPct: body.SummaryRefreshGrowthPct,
+		storage.SettingSummaryRefreshMax:       body.SummaryRefreshMax,
+	} {
+		if val == nil {
+			continue
+		}
+		if *val < 0 {
+			http.Error(w, key+" must not be negative", http.StatusBadRequest)
+			return
+		}
+		if err := s.store.SetSetting(r.Context(), key, strconv.Itoa(*val)); err != nil {
+			log.Printf("Failed to update %s setting: %v", key, err)
+			http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if body.Generation != nil && userID != "" {
+		if err := toGenerationOptions(*body.Generation).Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.store.UpdateGenerationSettings(r.Context(), userID, *body.Generation); err != nil {
+			log.Printf("Failed to update generation settings: %v", err)
+			http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if body.SavesPublic != nil && userID != "" {
+		if err := s.store.UpdateUserSavesPublic(r.Context(), userID, *body.SavesPublic); err != nil {
+			log.Printf("Failed to update saves privacy: %v", err)
+			http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+			return
+		}
+		s.users.invalidate(userID)
+	}
+
+	if body.SensitiveContent != "" && userID != "" {
+		if err := s.store.UpdateSensitivePreference(r.Context(), userID, body.SensitiveContent); err != nil {
+			log.Printf("Failed to update sensitive content preference: %v", err)
+			http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -753,45 +1099,6 @@ func (s *Server) handleDownloadLatest(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "https://github.com/rajeshkumarblr/hn_station", http.StatusTemporaryRedirect)
 }
 
-// parseOllamaResponse handles the logic moved out of handleSummarizeStory for reuse
-func parseOllamaResponse(responseStr string) (string, []string) {
-	cleanJSON := strings.TrimSpace(responseStr)
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```json")
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```")
-	cleanJSON = strings.TrimSuffix(cleanJSON, "```")
-	cleanJSON = strings.TrimSpace(cleanJSON)
-
-	var intermediate struct {
-		Summary interface{} `json:"summary"`
-		Topics  []string    `json:"topics"`
-	}
-
-	var summary string
-	var topics []string
-
-	if err := json.Unmarshal([]byte(cleanJSON), &intermediate); err != nil {
-		log.Printf("Failed to parse Ollama JSON. Error: %v. Raw: %s", err, responseStr)
-		summary = responseStr // Fallback
-	} else {
-		switch v := intermediate.Summary.(type) {
-		case string:
-			summary = v
-		case []interface{}:
-			var parts []string
-			for _, part := range v {
-				if s, ok := part.(string); ok {
-					parts = append(parts, s)
-				}
-			}
-			summary = strings.Join(parts, "\n")
-		default:
-			summary = fmt.Sprintf("%v", v)
-		}
-		topics = intermediate.Topics
-	}
-	return summary, topics
-}
-
 // ─── Admin Handlers ───
 
 func (s *Server) adminMiddleware(next http.Handler) http.Handler {
@@ -802,7 +1109,7 @@ func (s *Server) adminMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		user, err := s.store.GetAuthUser(r.Context(), userID)
+		user, err := s.getAuthUser(r.Context(), userID)
 		if err != nil {
 			http.Error(w, "User not found", http.StatusUnauthorized)
 			return
@@ -813,7 +1120,7 @@ func (s *Server) adminMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(withAuthUser(r.Context(), user)))
 	})
 }
 
@@ -829,6 +1136,80 @@ func (s *Server) handleGetAdminStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+// handleGetHTTPClientStats reports this server's outbound HTTP requests by
+// client (hn, fetcher, ollama, ...) since it started.
+func (s *Server) handleGetHTTPClientStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"clients": httpclient.Snapshot()})
+}
+
+// handleGetSummaryQueueEvents lists the summary queue's recent backpressure
+// decisions: jobs ingest shed or deferred while the queue was backed up.
+func (s *Server) handleGetSummaryQueueEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 500 {
+		limit = val
+	}
+	events, err := s.store.GetSummaryQueueEvents(r.Context(), limit)
+	if err != nil {
+		log.Printf("Failed to fetch summary queue events: %v", err)
+		http.Error(w, "Failed to fetch summary queue events", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []storage.SummaryQueueEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"events": events})
+}
+
+// handleGetRejectedTopics lists the topic tags the quality gate dropped most
+// often in the last ?days= (default 7, up to 30).
+func (s *Server) handleGetRejectedTopics(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if val, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && val > 0 && val <= 30 {
+		days = val
+	}
+	limit := 100
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 500 {
+		limit = val
+	}
+	topics, err := s.store.GetRejectedTopics(r.Context(), days, limit)
+	if err != nil {
+		log.Printf("Failed to fetch rejected topics: %v", err)
+		http.Error(w, "Failed to fetch rejected topics", http.StatusInternalServerError)
+		return
+	}
+	if topics == nil {
+		topics = []storage.RejectedTopic{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"topics": topics})
+}
+
+// handleGetStatsHistory returns daily metric snapshots for the last ?days=
+// (default 30, up to 365), oldest first.
+func (s *Server) handleGetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if val, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && val > 0 && val <= 365 {
+		days = val
+	}
+	history, err := s.store.GetMetricHistory(r.Context(), days)
+	if err != nil {
+		log.Printf("Failed to fetch stats history: %v", err)
+		http.Error(w, "Failed to fetch stats history", http.StatusInternalServerError)
+		return
+	}
+	if history == nil {
+		history = []storage.MetricSnapshot{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"days": history})
+}
+
 func (s *Server) handleGetAdminUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := s.store.GetAllUsers(r.Context())
 	if err != nil {
@@ -842,15 +1223,13 @@ func (s *Server) handleGetAdminUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleListOllamaModels(w http.ResponseWriter, r *http.Request) {
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://localhost:11434"
-	}
-
-	models, err := s.aiClient.ListModels(r.Context(), ollamaURL)
-	if err != nil {
-		http.Error(w, "Failed to list models: "+err.Error(), http.StatusInternalServerError)
-		return
+	models := []string{ai.ProviderFake}
+	if !s.fakeAI {
+		var err error
+		if models, err = s.aiClient.ListModels(r.Context(), s.ollamaURL); err != nil {
+			http.Error(w, "Failed to list models: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,7 +24,7 @@ import (
 
 func TestHealthCheck(t *testing.T) {
 	// server with nil store is fine for health check
-	server := NewServer(nil, nil, nil, false)
+	server := NewServer(nil, nil, nil, nil, nil, false)
 
 	req, _ := http.NewRequest("GET", "/healthc", nil)
 	rr := httptest.NewRecorder()
@@ -50,7 +53,7 @@ func TestGetStories_Integration(t *testing.T) {
 	}
 
 	store := storage.New(pool)
-	server := NewServer(store, nil, nil, false)
+	server := NewServer(store, nil, nil, nil, nil, false)
 
 	// Seed a story for testing?
 	// We assume data exists from ingestion or we can insert one.
@@ -75,3 +78,104 @@ func TestGetStories_Integration(t *testing.T) {
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, len(stories), 1)
 }
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil, nil, false)
+
+	req, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spec))
+	assert.Equal(t, openAPIVersion, spec.OpenAPI)
+	assert.Contains(t, spec.Paths["/api/stories"], "get")
+	assert.Contains(t, spec.Paths["/api/stories/{id}/interact"], "post")
+
+	// Every route is described, and every description is of a route.
+	described := map[string]bool{}
+	for p, ops := range spec.Paths {
+		for method := range ops {
+			key := strings.ToUpper(method) + " " + p
+			described[key] = true
+			assert.Contains(t, apiDocs, key, "route has no apiDocs entry")
+		}
+	}
+	for key := range apiDocs {
+		assert.True(t, described[key], "apiDocs entry %q matches no route", key)
+	}
+}
+
+func TestDiffFrontPage(t *testing.T) {
+	rank := func(n int) *int { return &n }
+	summary := "A summary."
+	before := []storage.Story{
+		{ID: 1, HNRank: rank(1), Score: 100, Descendants: 10},
+		{ID: 2, HNRank: rank(2), Score: 50},
+		{ID: 3, HNRank: rank(3), Score: 20},
+	}
+	after := []storage.Story{
+		{ID: 2, HNRank: rank(1), Score: 80},
+		{ID: 1, HNRank: rank(2), Score: 100, Descendants: 10, Summary: &summary},
+		{ID: 4, HNRank: rank(3), Score: 5},
+	}
+
+	events := diffFrontPage(frontPageSnapshot(before), frontPageSnapshot(after), after)
+	var got []string
+	for _, evt := range events {
+		got = append(got, evt.name+":"+strconv.FormatInt(evt.data.(map[string]interface{})["id"].(int64), 10))
+	}
+	assert.Equal(t, []string{"rank:2", "score:2", "rank:1", "summary:1", "rank:4", "rank:3"}, got)
+	assert.Nil(t, events[len(events)-1].data.(map[string]interface{})["hn_rank"], "story 3 left the front page")
+}
+
+func TestMirrorMode(t *testing.T) {
+	for key := range mirrorRefused {
+		_, ok := apiDocs[key]
+		assert.True(t, ok, "mirror route %q is not a route", key)
+	}
+	for key, doc := range apiDocs {
+		method, pattern, _ := strings.Cut(key, " ")
+		if mirrorAllowed(method, pattern) {
+			assert.Equal(t, "GET", method, key)
+			assert.Contains(t, []access{accessPublic, accessOptional}, doc.Access, key)
+			assert.NotContains(t, []string{"ai", "auth"}, doc.Tag, key)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Server.Mirror = true
+	server := NewServer(nil, nil, nil, nil, cfg, false)
+
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest("GET", "/healthc", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=300, s-maxage=300, stale-while-revalidate=300", rr.Header().Get("Cache-Control"))
+
+	rr = httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "public API reads are served")
+
+	// Static files and SPA routes are served; there's no web/dist here.
+	for _, path := range []string{"/", "/nowhere", "/story/1", "/assets/app.js"} {
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, path)
+	}
+
+	for _, target := range []string{
+		"POST /api/save", "PUT /api/me/notifications", "DELETE /api/me/api_keys/1",
+		"GET /auth/google", "GET /api/me", "GET /api/admin/stats", "GET /api/me/lists",
+		"GET /api/stream", "GET /api/calendar.ics", "GET /api/stories/1/content", "GET /api/models/ollama",
+		"POST /nowhere",
+	} {
+		method, path, _ := strings.Cut(target, " ")
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		assert.Equal(t, http.StatusForbidden, rr.Code, target)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// A user may share shareEmailLimit stories by email per shareEmailWindow.
+const (
+	shareEmailLimit  = 20
+	shareEmailWindow = time.Hour
+	shareNoteMaxLen  = 1000
+)
+
+// handleShareStory emails a story's link and summary, with an optional note,
+// to {"email": ...}. The email goes out through the notification outbox.
+func (s *Server) handleShareStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Email string `json:"email"`
+		Note  string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(body.Email))
+	if err != nil || addr.Address != strings.TrimSpace(body.Email) {
+		http.Error(w, "Invalid email address", http.StatusBadRequest)
+		return
+	}
+	note := strings.TrimSpace(body.Note)
+	if len(note) > shareNoteMaxLen {
+		http.Error(w, fmt.Sprintf("Note must be at most %d bytes", shareNoteMaxLen), http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.GetStory(r.Context(), storyID)
+	if err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	user, err := s.getAuthUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	subject, text := s.renderShareEmail(user, story, note, s.publicBaseURL(r))
+	err = s.store.ShareStoryByEmail(r.Context(), userID, storyID, addr.Address, subject, text, shareEmailLimit, shareEmailWindow)
+	switch {
+	case errors.Is(err, storage.ErrRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(shareEmailWindow.Seconds())))
+		http.Error(w, fmt.Sprintf("You can share up to %d stories per hour", shareEmailLimit), http.StatusTooManyRequests)
+		return
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("Failed to share story %d: %v", storyID, err)
+		http.Error(w, "Failed to share story", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "queued"})
+}
+
+func (s *Server) renderShareEmail(from *storage.AuthUser, st *storage.Story, note, base string) (subject, body string) {
+	sender := from.Name
+	if sender == "" {
+		sender = from.Email
+	}
+	subject = fmt.Sprintf("%s shared: %s", sender, st.Title)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s) shared a Hacker News story with you.\n\n", sender, from.Email)
+	if note != "" {
+		sb.WriteString(note + "\n\n")
+	}
+	sb.WriteString(st.Title + "\n")
+	if st.URL != "" {
+		sb.WriteString(st.URL + "\n")
+	}
+	if st.Summary != nil && *st.Summary != "" {
+		sb.WriteString("\n" + strings.TrimSpace(*st.Summary) + "\n")
+	}
+	fmt.Fprintf(&sb, "\n%d points, %d comments: %s\n", st.Score, st.Descendants, storage.CommentPermalink(st.ID))
+	fmt.Fprintf(&sb, "Read it on HN Station: %s\n", s.storyLink(base, st.ID))
+	return subject, sb.String()
+}
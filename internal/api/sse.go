@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// eventStream writes a response as Server-Sent Events, for AI endpoints
+// whose clients ask for one with "Accept: text/event-stream". The model's
+// output arrives as "token" events ({"text": ...}) while it's generated,
+// then the endpoint's usual JSON response as a "done" event, or {"error":
+// ...} as an "error" event. A "reset" event means the provider failed part
+// way and the next one starts over, so the client should discard the tokens
+// so far.
+//
+// The stream opens on the first event, so a handler can still fail with a
+// plain HTTP error until the model starts answering. All methods are no-ops
+// on a nil *eventStream, which handlers use for ordinary JSON requests.
+type eventStream struct {
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	open     bool
+	streamed bool // tokens were sent since the last reset
+}
+
+// newEventStream returns a stream for r's response, or nil if the client
+// didn't ask for one.
+func newEventStream(w http.ResponseWriter, r *http.Request) *eventStream {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return nil
+	}
+	return &eventStream{w: w, rc: http.NewResponseController(w)}
+}
+
+// started reports whether the response has been committed to the stream.
+func (es *eventStream) started() bool {
+	return es != nil && es.open
+}
+
+// send writes one event and flushes it to the client.
+func (es *eventStream) send(event string, data interface{}) {
+	if es == nil {
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event, err)
+		return
+	}
+	if !es.open {
+		h := es.w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no") // nginx would otherwise hold the events back
+		es.w.WriteHeader(http.StatusOK)
+		es.open = true
+	}
+	// A failed write means the client went away; the request context is
+	// cancelled then, which stops generation.
+	fmt.Fprintf(es.w, "event: %s\ndata: %s\n\n", event, payload)
+	es.rc.Flush()
+}
+
+// ping writes a comment line, which clients ignore, to keep an idle stream
+// open through proxies.
+func (es *eventStream) ping() {
+	if es == nil || !es.open {
+		return
+	}
+	fmt.Fprint(es.w, ": ping\n\n")
+	es.rc.Flush()
+}
+
+// onToken returns the callback that streams the model's output, or nil to
+// generate without streaming.
+func (es *eventStream) onToken() func(string) {
+	if es == nil {
+		return nil
+	}
+	return func(text string) {
+		es.streamed = true
+		es.send("token", map[string]string{"text": text})
+	}
+}
+
+// attempt is called as each AI provider is tried, telling the client to
+// drop the tokens of an earlier one that failed.
+func (es *eventStream) attempt() {
+	if es != nil && es.streamed {
+		es.streamed = false
+		es.send("reset", struct{}{})
+	}
+}
+
+// writeResult sends the endpoint's response: as the "done" event when
+// streaming, or as JSON.
+func writeResult(w http.ResponseWriter, es *eventStream, result interface{}) {
+	if es != nil {
+		es.send("done", result)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeStreamError sends msg as the "error" event once the stream is open,
+// or as a plain HTTP error with status.
+func writeStreamError(w http.ResponseWriter, es *eventStream, msg string, status int) {
+	if es.started() {
+		es.send("error", map[string]string{"error": msg})
+		return
+	}
+	http.Error(w, msg, status)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	// textWidth is the column plain-text output wraps at.
+	textWidth = 78
+	// maxTextStories caps ?limit= on /api/stories.txt.
+	maxTextStories = 100
+)
+
+// handleStoriesText serves the front page, or an HN list with ?feed=, as
+// plain text with summaries, for piping into less or a TUI:
+//
+//	curl -s https://hnstation.dev/api/stories.txt | less
+func (s *Server) handleStoriesText(w http.ResponseWriter, r *http.Request) {
+	limit := liteStoriesPerPage
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, maxTextStories)
+	}
+	sortParam := "default"
+	heading := "HN Station: front page"
+	if feed := r.URL.Query().Get("feed"); storage.IsRankedFeed(feed) {
+		sortParam = feed
+		heading = "HN Station: " + feed
+	}
+
+	stories, _, err := s.store.GetStories(r.Context(), limit, 0, sortParam, nil, nil, s.auth.GetUserIDFromRequest(r), false)
+	if err != nil {
+		log.Printf("Failed to fetch stories for text page: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n%s\n\n", heading, strings.Repeat("=", len(heading)))
+	for i, st := range stories {
+		prefix := fmt.Sprintf("%2d. ", i+1)
+		indent := strings.Repeat(" ", len(prefix))
+		writeWrapped(&buf, prefix, indent, st.Title)
+		if host := hostOf(st.URL); host != "" {
+			fmt.Fprintf(&buf, "%s(%s)\n", indent, host)
+		}
+		fmt.Fprintf(&buf, "%s%d points by %s %s | %d comments\n", indent, st.Score, st.By, timeAgo(st.PostedAt), st.Descendants)
+		if st.URL != "" {
+			fmt.Fprintf(&buf, "%s%s\n", indent, st.URL)
+		}
+		fmt.Fprintf(&buf, "%s%s\n", indent, storage.CommentPermalink(st.ID))
+		for _, b := range bullets(st.Summary) {
+			writeWrapped(&buf, indent+"  - ", indent+"    ", b)
+		}
+		buf.WriteString("\n")
+	}
+	if len(stories) == 0 {
+		buf.WriteString("No stories yet.\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// writeWrapped writes text word-wrapped at textWidth, starting with first and
+// continuing lines with rest.
+func writeWrapped(buf *bytes.Buffer, first, rest, text string) {
+	line := first
+	empty := true
+	for _, word := range strings.Fields(text) {
+		if !empty && len(line)+1+len(word) > textWidth {
+			buf.WriteString(line + "\n")
+			line, empty = rest, true
+		}
+		if !empty {
+			line += " "
+		}
+		line += word
+		empty = false
+	}
+	buf.WriteString(line + "\n")
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleAdminUpdateStoryFlags pins a story to the front page, freezes it
+// from pruning and/or reviews whether it's sensitive. Body: {"pinned": bool,
+// "frozen": bool, "sensitive": bool}; omitted fields are left unchanged.
+func (s *Server) handleAdminUpdateStoryFlags(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Pinned    *bool `json:"pinned"`
+		Frozen    *bool `json:"frozen"`
+		Sensitive *bool `json:"sensitive"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Pinned == nil && body.Frozen == nil && body.Sensitive == nil {
+		http.Error(w, "Nothing to update", http.StatusBadRequest)
+		return
+	}
+
+	if body.Pinned != nil || body.Frozen != nil {
+		err = s.store.SetStoryFlags(r.Context(), id, body.Pinned, body.Frozen)
+	}
+	if err == nil && body.Sensitive != nil {
+		err = s.store.ReviewSensitive(r.Context(), id, *body.Sensitive, s.auth.GetUserIDFromRequest(r))
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update flags for story %d: %v", id, err)
+		http.Error(w, "Failed to update story", http.StatusInternalServerError)
+		return
+	}
+
+	story, err := s.store.GetStory(r.Context(), id)
+	if err != nil {
+		log.Printf("Failed to reload story %d: %v", id, err)
+		http.Error(w, "Failed to load story", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(story)
+}
+
+// handleGetSensitiveStories lists stories the sensitive-content check has
+// classified, for review. ?source= filters by what classified them
+// ("keyword", "ai" or "admin"), ?flagged= by the outcome.
+func (s *Server) handleGetSensitiveStories(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	source := q.Get("source")
+	switch source {
+	case "", storage.SensitiveKeyword, storage.SensitiveAI, storage.SensitiveAdmin:
+	default:
+		http.Error(w, "Invalid source", http.StatusBadRequest)
+		return
+	}
+	var flagged *bool
+	if v := q.Get("flagged"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid flagged", http.StatusBadRequest)
+			return
+		}
+		flagged = &b
+	}
+	limit := 100
+	if val, err := strconv.Atoi(q.Get("limit")); err == nil && val > 0 && val <= 500 {
+		limit = val
+	}
+
+	stories, err := s.store.GetSensitiveStories(r.Context(), source, flagged, limit)
+	if err != nil {
+		log.Printf("Failed to fetch sensitive stories: %v", err)
+		http.Error(w, "Failed to fetch sensitive stories", http.StatusInternalServerError)
+		return
+	}
+	if stories == nil {
+		stories = []storage.SensitiveStory{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"stories": stories})
+}
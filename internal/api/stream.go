@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Live front-page updates for GET /api/stream. Ingest runs in its own
+// process, so RunStoryStream polls for newly finished ingestion runs and
+// diffs the front page against the one it saw after the previous run.
+const (
+	streamPoll       = 15 * time.Second
+	streamKeepAlive  = 30 * time.Second // comment lines that keep proxies from closing idle streams
+	streamStories    = 100              // front-page stories diffed after each run
+	streamMaxClients = 1000
+	streamBuffer     = 256 // events queued per client; a client that falls further behind is dropped
+)
+
+// streamEvent is one Server-Sent Event for /api/stream clients.
+type streamEvent struct {
+	name string
+	data interface{}
+}
+
+// frontPageEntry is what the stream remembers of a front-page story.
+type frontPageEntry struct {
+	rank        *int
+	score       int
+	descendants int
+	summarized  bool
+}
+
+// storyStream fans the events of each ingestion run out to the connected
+// clients.
+type storyStream struct {
+	mu      sync.Mutex
+	clients map[chan streamEvent]struct{}
+}
+
+func newStoryStream() *storyStream {
+	return &storyStream{clients: make(map[chan streamEvent]struct{})}
+}
+
+// subscribe registers a client, or returns nil if there are too many.
+func (ss *storyStream) subscribe() chan streamEvent {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(ss.clients) >= streamMaxClients {
+		return nil
+	}
+	ch := make(chan streamEvent, streamBuffer)
+	ss.clients[ch] = struct{}{}
+	return ch
+}
+
+func (ss *storyStream) unsubscribe(ch chan streamEvent) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if _, ok := ss.clients[ch]; ok {
+		delete(ss.clients, ch)
+		close(ch)
+	}
+}
+
+// publish queues events for every client. A client whose queue is full is
+// disconnected rather than sent a partial run; EventSource reconnects and
+// the client reloads the list.
+func (ss *storyStream) publish(events []streamEvent) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for ch := range ss.clients {
+		if cap(ch)-len(ch) < len(events) {
+			delete(ss.clients, ch)
+			close(ch)
+			continue
+		}
+		for _, evt := range events {
+			ch <- evt
+		}
+	}
+}
+
+// CloseStreams disconnects the /api/stream clients, so that a graceful
+// shutdown doesn't wait on them.
+func (s *Server) CloseStreams() {
+	ss := s.stream
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for ch := range ss.clients {
+		delete(ss.clients, ch)
+		close(ch)
+	}
+}
+
+// RunStoryStream publishes front-page changes to /api/stream clients after
+// each ingestion run, until ctx is cancelled.
+func (s *Server) RunStoryStream(ctx context.Context) {
+	var lastRun time.Time
+	var prev map[int64]frontPageEntry
+	ticker := time.NewTicker(streamPoll)
+	defer ticker.Stop()
+	for {
+		finished, err := s.store.LastIngestSuccess(ctx)
+		if err != nil {
+			log.Printf("Stream: failed to check for ingestion runs: %v", err)
+		} else if finished.After(lastRun) {
+			stories, _, err := s.store.GetStories(ctx, streamStories, 0, "default", nil, nil, "", false)
+			if err != nil {
+				log.Printf("Stream: failed to load the front page: %v", err)
+			} else {
+				cur := frontPageSnapshot(stories)
+				// The first run seen only sets the baseline.
+				if prev != nil {
+					events := diffFrontPage(prev, cur, stories)
+					events = append(events, streamEvent{"ingest", map[string]interface{}{"finished_at": finished}})
+					s.stream.publish(events)
+				}
+				prev, lastRun = cur, finished
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func frontPageSnapshot(stories []storage.Story) map[int64]frontPageEntry {
+	snap := make(map[int64]frontPageEntry, len(stories))
+	for _, st := range stories {
+		snap[st.ID] = frontPageEntry{
+			rank:        st.HNRank,
+			score:       st.Score,
+			descendants: st.Descendants,
+			summarized:  st.Summary != nil && *st.Summary != "",
+		}
+	}
+	return snap
+}
+
+// diffFrontPage returns the events between two snapshots, in front-page
+// order: "rank" when a story's HN rank changed (a null rank means it left
+// the front page), "score" when its score or comment count changed, and
+// "summary" when its summary became ready.
+func diffFrontPage(prev, cur map[int64]frontPageEntry, stories []storage.Story) []streamEvent {
+	var events []streamEvent
+	for _, st := range stories {
+		now := cur[st.ID]
+		was, seen := prev[st.ID]
+		if !seen || !sameRank(was.rank, now.rank) {
+			events = append(events, streamEvent{"rank", map[string]interface{}{"id": st.ID, "hn_rank": now.rank, "previous_rank": was.rank}})
+		}
+		if seen && (was.score != now.score || was.descendants != now.descendants) {
+			events = append(events, streamEvent{"score", map[string]interface{}{"id": st.ID, "score": now.score, "descendants": now.descendants}})
+		}
+		if now.summarized && !was.summarized {
+			events = append(events, streamEvent{"summary", map[string]interface{}{"id": st.ID, "summary": *st.Summary}})
+		}
+	}
+	for id, was := range prev {
+		if _, ok := cur[id]; !ok && was.rank != nil {
+			events = append(events, streamEvent{"rank", map[string]interface{}{"id": id, "hn_rank": nil, "previous_rank": was.rank}})
+		}
+	}
+	return events
+}
+
+func sameRank(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// handleStream streams front-page changes as Server-Sent Events after each
+// ingestion run: "rank", "score" and "summary" events for the stories that
+// changed, then an "ingest" event. A client that sees an unknown story or
+// reconnects should reload the list.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	ch := s.stream.subscribe()
+	if ch == nil {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "Too many stream clients", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.stream.unsubscribe(ch)
+
+	es := &eventStream{w: w, rc: http.NewResponseController(w)}
+	es.send("ready", struct{}{})
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			es.send(evt.name, evt.data)
+		case <-keepAlive.C:
+			es.ping()
+		}
+	}
+}
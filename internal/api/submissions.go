@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// previousSubmission points at another HN submission of a story's URL.
+type previousSubmission struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Score       int       `json:"score"`
+	By          string    `json:"by"`
+	Descendants int       `json:"descendants"`
+	PostedAt    time.Time `json:"time"`
+	HNURL       string    `json:"hn_url"`
+	Local       bool      `json:"local"` // ingested here, so it can be opened in HN Station
+}
+
+const submissionsCacheTTL = time.Hour
+
+// submissionsCache remembers HN Search results per story so opening a story
+// doesn't query Algolia every time.
+type submissionsCache struct {
+	mu      sync.Mutex
+	entries map[int64]submissionsEntry
+}
+
+type submissionsEntry struct {
+	subs    []previousSubmission
+	fetched time.Time
+}
+
+func (c *submissionsCache) get(id int64) ([]previousSubmission, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok || time.Since(e.fetched) > submissionsCacheTTL {
+		return nil, false
+	}
+	return e.subs, true
+}
+
+func (c *submissionsCache) put(id int64, subs []previousSubmission) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[int64]submissionsEntry)
+	}
+	for k, e := range c.entries {
+		if time.Since(e.fetched) > submissionsCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[id] = submissionsEntry{subs: subs, fetched: time.Now()}
+}
+
+// previousSubmissions returns the other HN submissions of the story's URL,
+// combining stories we have ingested with HN Search, most discussed first.
+// HN Search failures only drop its results.
+func (s *Server) previousSubmissions(ctx context.Context, story *storage.Story) ([]previousSubmission, error) {
+	if story.URL == "" {
+		return []previousSubmission{}, nil
+	}
+
+	local, err := s.store.GetSubmissionsByURL(ctx, story.URL, story.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]previousSubmission)
+	for _, st := range local {
+		byID[st.ID] = previousSubmission{
+			ID:          st.ID,
+			Title:       st.Title,
+			Score:       st.Score,
+			By:          st.By,
+			Descendants: st.Descendants,
+			PostedAt:    st.PostedAt,
+			Local:       true,
+		}
+	}
+
+	remote, ok := s.submissions.get(story.ID)
+	if !ok && s.hnClient != nil {
+		searchCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		hits, err := s.hnClient.SearchByURL(searchCtx, story.URL)
+		cancel()
+		if err != nil {
+			log.Printf("HN Search lookup failed for story %d: %v", story.ID, err)
+		} else {
+			remote = []previousSubmission{}
+			for _, h := range hits {
+				if int64(h.ID) == story.ID || !storage.SameStoryURL(story.URL, h.URL) {
+					continue
+				}
+				remote = append(remote, previousSubmission{
+					ID:          int64(h.ID),
+					Title:       h.Title,
+					Score:       h.Score,
+					By:          h.By,
+					Descendants: h.Descendants,
+					PostedAt:    time.Unix(h.Time, 0),
+				})
+			}
+			s.submissions.put(story.ID, remote)
+		}
+	}
+	for _, sub := range remote {
+		if _, ok := byID[sub.ID]; !ok {
+			byID[sub.ID] = sub
+		}
+	}
+
+	subs := make([]previousSubmission, 0, len(byID))
+	for _, sub := range byID {
+		sub.HNURL = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", sub.ID)
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].Descendants != subs[j].Descendants {
+			return subs[i].Descendants > subs[j].Descendants
+		}
+		return subs[i].PostedAt.After(subs[j].PostedAt)
+	})
+	return subs, nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetSubscriptions lists the topics the user receives weekly digests for.
+func (s *Server) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	subs, err := s.store.GetTopicSubscriptions(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch subscriptions: %v", err)
+		http.Error(w, "Failed to fetch subscriptions", http.StatusInternalServerError)
+		return
+	}
+	if subs == nil {
+		subs = []storage.TopicSubscription{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"subscriptions": subs})
+}
+
+func (s *Server) handleSubscribeTopic(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Topic string `json:"topic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	topic := storage.NormalizeTopic(body.Topic)
+	if topic == "" || len(topic) > 64 {
+		http.Error(w, "topic must be 1-64 characters", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.SubscribeTopic(r.Context(), userID, topic); err != nil {
+		log.Printf("Failed to subscribe to topic %q: %v", topic, err)
+		http.Error(w, "Failed to subscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "topic": topic})
+}
+
+func (s *Server) handleUnsubscribeTopic(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	topic := chi.URLParam(r, "topic")
+	if err := s.store.UnsubscribeTopic(r.Context(), userID, topic); err != nil {
+		log.Printf("Failed to unsubscribe from topic %q: %v", topic, err)
+		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	triageMaxStories   = 50
+	triageMaxChars     = 24000
+	triageSummaryChars = 600 // per story, so one long summary can't crowd out the rest
+	triageMinStories   = 2
+)
+
+type triageGroup struct {
+	Theme   string          `json:"theme"`
+	Stories []storage.Story `json:"stories"`
+}
+
+type triageSkip struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// handleTriageSaved has the configured AI provider triage the user's unread
+// saved stories: group them by theme, suggest a reading order and what to
+// skip. It works from stored titles, summaries and notes; articles aren't
+// fetched again.
+func (s *Server) handleTriageSaved(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	stories, err := s.store.GetUnreadSavedStories(r.Context(), userID, triageMaxStories)
+	if err != nil {
+		log.Printf("Failed to fetch unread saved stories for %s: %v", userID, err)
+		http.Error(w, "Failed to fetch saved stories", http.StatusInternalServerError)
+		return
+	}
+	contextText, stories := buildTriageContext(stories)
+	if len(stories) < triageMinStories {
+		// Nothing to triage; one story is its own reading order.
+		groups, order := []triageGroup{}, []int64{}
+		if len(stories) == 1 {
+			groups = append(groups, triageGroup{Theme: "Other", Stories: stories})
+			order = append(order, stories[0].ID)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"groups": groups,
+			"order":  order,
+			"skip":   []triageSkip{},
+		})
+		return
+	}
+
+	opts := s.generationOptions(r.Context(), userID)
+	response, err := s.runAI(r.Context(), userID, false, func(p ai.Provider, model, apiKey string) (string, ai.Usage, error) {
+		return p.Chat(r.Context(), ai.ChatRequest{Context: contextText, Message: ai.TriagePrompt, Model: model, APIKey: apiKey, Options: opts})
+	})
+	if response == "" {
+		log.Printf("Reading list triage failed for %s: %v", userID, err)
+		http.Error(w, "Failed to triage saved stories", http.StatusBadGateway)
+		return
+	}
+	triage, err := ai.ParseTriage(response, len(stories))
+	if err != nil {
+		log.Printf("Failed to parse reading list triage for %s: %v. Raw: %s", userID, err, response)
+		http.Error(w, "Failed to triage saved stories", http.StatusBadGateway)
+		return
+	}
+
+	groups := make([]triageGroup, 0, len(triage.Groups))
+	for _, g := range triage.Groups {
+		group := triageGroup{Theme: g.Theme}
+		for _, i := range g.Stories {
+			group.Stories = append(group.Stories, stories[i-1])
+		}
+		groups = append(groups, group)
+	}
+	order := make([]int64, 0, len(triage.Order))
+	for _, i := range triage.Order {
+		order = append(order, stories[i-1].ID)
+	}
+	skip := make([]triageSkip, 0, len(triage.Skip))
+	for _, sk := range triage.Skip {
+		st := stories[sk.Story-1]
+		skip = append(skip, triageSkip{ID: st.ID, Title: st.Title, Reason: sk.Reason})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"groups": groups,
+		"order":  order,
+		"skip":   skip,
+	})
+}
+
+// buildTriageContext renders saved stories as the numbered list TriagePrompt
+// refers to, capped at triageMaxChars, and returns the stories that fit.
+func buildTriageContext(stories []storage.Story) (string, []storage.Story) {
+	var sb strings.Builder
+	sb.WriteString("My reading list of saved Hacker News stories, most recently saved first:\n\n")
+
+	var included []storage.Story
+	for _, st := range stories {
+		block := fmt.Sprintf("[%d] %s (posted %s, %d points, %d comments)\n",
+			len(included)+1, st.Title, st.PostedAt.Format("2006-01-02"), st.Score, st.Descendants)
+		if len(st.Topics) > 0 {
+			block += "Topics: " + strings.Join(st.Topics, ", ") + "\n"
+		}
+		if st.Summary != nil && *st.Summary != "" {
+			summary := *st.Summary
+			if len(summary) > triageSummaryChars {
+				summary = strings.ToValidUTF8(summary[:triageSummaryChars], "") + "..."
+			}
+			block += "Summary:\n" + summary + "\n"
+		}
+		if st.Note != "" {
+			block += "My note: " + st.Note + "\n"
+		}
+		block += "\n"
+
+		if sb.Len()+len(block) > triageMaxChars {
+			break
+		}
+		sb.WriteString(block)
+		included = append(included, st)
+	}
+	return sb.String(), included
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// recordAIUsage stores a cloud AI call with its estimated cost, logging
+// failures. Calls that never reached the model are skipped.
+func (s *Server) recordAIUsage(ctx context.Context, userID string, u ai.Usage) {
+	if u.PromptTokens == 0 && u.OutputTokens == 0 {
+		return
+	}
+	err := s.store.RecordAIUsage(ctx, storage.AIUsage{
+		UserID:       userID,
+		Provider:     u.Provider,
+		Model:        u.Model,
+		PromptTokens: u.PromptTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      u.EstimateCost(),
+	})
+	if err != nil {
+		log.Printf("Failed to record AI usage for %q: %v", userID, err)
+	}
+}
+
+// handleGetMyUsage returns the user's cumulative cloud AI usage and its
+// estimated cost, so BYOK users can budget.
+func (s *Server) handleGetMyUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	usage, err := s.store.GetUserAIUsage(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to get AI usage for %s: %v", userID, err)
+		http.Error(w, "Failed to get usage", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
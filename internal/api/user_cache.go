@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// authUserCacheTTL bounds how long a cached account row may be served. Writes
+// through the API invalidate it right away; the TTL covers changes made
+// elsewhere (e.g. granting admin in SQL).
+const authUserCacheTTL = 30 * time.Second
+
+// authUserCache keeps recently loaded accounts so the admin middleware and
+// the handlers behind it don't query the same auth_users row on every call.
+type authUserCache struct {
+	mu      sync.Mutex
+	entries map[string]authUserEntry
+}
+
+type authUserEntry struct {
+	user    storage.AuthUser
+	fetched time.Time
+}
+
+func (c *authUserCache) get(userID string) (*storage.AuthUser, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[userID]
+	if !ok || time.Since(e.fetched) > authUserCacheTTL {
+		return nil, false
+	}
+	u := e.user
+	return &u, true
+}
+
+func (c *authUserCache) put(u *storage.AuthUser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]authUserEntry)
+	}
+	for k, e := range c.entries {
+		if time.Since(e.fetched) > authUserCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[u.ID] = authUserEntry{user: *u, fetched: time.Now()}
+}
+
+func (c *authUserCache) invalidate(userIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range userIDs {
+		delete(c.entries, id)
+	}
+}
+
+type authUserKey struct{}
+
+// withAuthUser returns ctx carrying u for the rest of the request.
+func withAuthUser(ctx context.Context, u *storage.AuthUser) context.Context {
+	return context.WithValue(ctx, authUserKey{}, u)
+}
+
+// getAuthUser loads an account, preferring the copy already attached to the
+// request and then the short-lived cache over Postgres.
+func (s *Server) getAuthUser(ctx context.Context, userID string) (*storage.AuthUser, error) {
+	if u, ok := ctx.Value(authUserKey{}).(*storage.AuthUser); ok && u.ID == userID {
+		copied := *u
+		return &copied, nil
+	}
+	if u, ok := s.users.get(userID); ok {
+		return u, nil
+	}
+	u, err := s.store.GetAuthUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.users.put(u)
+	return u, nil
+}
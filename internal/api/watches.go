@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetWatches lists the stories the user watches.
+func (s *Server) handleGetWatches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	watches, err := s.store.GetStoryWatches(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch story watches: %v", err)
+		http.Error(w, "Failed to fetch watches", http.StatusInternalServerError)
+		return
+	}
+	if watches == nil {
+		watches = []storage.StoryWatch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"watches": watches})
+}
+
+// handleWatchStory watches a story, or changes the watch: the user is
+// notified once its score reaches score_threshold, once its comment count
+// reaches comment_threshold and, unless notify_summary is false, once its
+// summary arrives. Ingest checks the watches every run.
+func (s *Server) handleWatchStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		ScoreThreshold   *int  `json:"score_threshold"`
+		CommentThreshold *int  `json:"comment_threshold"`
+		NotifySummary    *bool `json:"notify_summary"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if (body.ScoreThreshold != nil && *body.ScoreThreshold <= 0) || (body.CommentThreshold != nil && *body.CommentThreshold <= 0) {
+		http.Error(w, "Thresholds must be positive", http.StatusBadRequest)
+		return
+	}
+	notifySummary := body.NotifySummary == nil || *body.NotifySummary
+	if body.ScoreThreshold == nil && body.CommentThreshold == nil && !notifySummary {
+		http.Error(w, "Nothing to watch", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.WatchStory(r.Context(), userID, storyID, body.ScoreThreshold, body.CommentThreshold, notifySummary)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to watch story %d: %v", storyID, err)
+		http.Error(w, "Failed to watch story", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleUnwatchStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUserID(w, r)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.UnwatchStory(r.Context(), userID, storyID); err != nil {
+		log.Printf("Failed to unwatch story %d: %v", storyID, err)
+		http.Error(w, "Failed to unwatch story", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
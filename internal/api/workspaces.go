@@ -0,0 +1,464 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// geminiKeyFor resolves the Gemini key for a user: their own key first, then a
+// shared workspace key, then the system key in local mode. It returns "" if
+// none applies. For a workspace key it also returns the workspace, whose
+// monthly quota the caller counts the request against once it is made; a
+// workspace that has used its quota is an error.
+func (s *Server) geminiKeyFor(ctx context.Context, userID string) (string, int64, error) {
+	if userID != "" {
+		if u, err := s.getAuthUser(ctx, userID); err == nil && u.GeminiAPIKey != "" {
+			return u.GeminiAPIKey, 0, nil
+		}
+		wsID, key, err := s.store.GetWorkspaceGeminiKey(ctx, userID)
+		if err == nil {
+			if err := s.store.CheckWorkspaceQuota(ctx, wsID); err != nil {
+				return "", 0, err
+			}
+			return key, wsID, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to look up workspace Gemini key: %v", err)
+		}
+	}
+	if s.localMode {
+		return s.geminiKey, 0, nil
+	}
+	return "", 0, nil
+}
+
+// requireWorkspaceRole loads the workspace for the authenticated user and
+// checks they hold at least minRole. Non-members get a 404.
+func (s *Server) requireWorkspaceRole(w http.ResponseWriter, r *http.Request, minRole string) (*storage.Workspace, string, bool) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, "", false
+	}
+	wsID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	ws, err := s.store.GetWorkspace(r.Context(), wsID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return nil, "", false
+	}
+	if err != nil {
+		log.Printf("Failed to fetch workspace %d: %v", wsID, err)
+		http.Error(w, "Failed to fetch workspace", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	if !storage.RoleAtLeast(ws.Role, minRole) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, "", false
+	}
+	return ws, userID, true
+}
+
+func (s *Server) handleGetWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	workspaces, err := s.store.GetUserWorkspaces(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch workspaces: %v", err)
+		http.Error(w, "Failed to fetch workspaces", http.StatusInternalServerError)
+		return
+	}
+	if workspaces == nil {
+		workspaces = []storage.Workspace{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"workspaces": workspaces})
+}
+
+func (s *Server) handleCreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" || len(body.Name) > 100 {
+		http.Error(w, "name must be 1-100 characters", http.StatusBadRequest)
+		return
+	}
+
+	ws, err := s.store.CreateWorkspace(r.Context(), userID, body.Name)
+	if err != nil {
+		log.Printf("Failed to create workspace: %v", err)
+		http.Error(w, "Failed to create workspace", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ws)
+}
+
+func (s *Server) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+
+	members, err := s.store.GetWorkspaceMembers(r.Context(), ws.ID)
+	if err != nil {
+		log.Printf("Failed to fetch workspace members: %v", err)
+		http.Error(w, "Failed to fetch workspace", http.StatusInternalServerError)
+		return
+	}
+	if members == nil {
+		members = []storage.WorkspaceMember{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workspace": ws,
+		"members":   members,
+	})
+}
+
+// handleUpdateWorkspace lets admins rename the workspace, set or clear the
+// shared Gemini key and change the monthly AI quota (0 = unlimited).
+func (s *Server) handleUpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name           *string `json:"name"`
+		GeminiAPIKey   *string `json:"gemini_api_key"`
+		MonthlyAIQuota *int    `json:"monthly_ai_quota"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Name != nil {
+		n := strings.TrimSpace(*body.Name)
+		if n == "" || len(n) > 100 {
+			http.Error(w, "name must be 1-100 characters", http.StatusBadRequest)
+			return
+		}
+		body.Name = &n
+	}
+	if body.MonthlyAIQuota != nil && *body.MonthlyAIQuota < 0 {
+		http.Error(w, "monthly_ai_quota must be >= 0", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.UpdateWorkspace(r.Context(), ws.ID, body.Name, body.GeminiAPIKey, body.MonthlyAIQuota); err != nil {
+		log.Printf("Failed to update workspace %d: %v", ws.ID, err)
+		http.Error(w, "Failed to update workspace", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleDeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteWorkspace(r.Context(), ws.ID); err != nil {
+		log.Printf("Failed to delete workspace %d: %v", ws.ID, err)
+		http.Error(w, "Failed to delete workspace", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleAddWorkspaceMember adds an existing user by email. Only owners can
+// grant the owner or admin role.
+func (s *Server) handleAddWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Role == "" {
+		body.Role = storage.RoleMember
+	}
+	if !validWorkspaceRole(body.Role) {
+		http.Error(w, "role must be owner, admin or member", http.StatusBadRequest)
+		return
+	}
+	if body.Role != storage.RoleMember && ws.Role != storage.RoleOwner {
+		http.Error(w, "Only owners can grant admin roles", http.StatusForbidden)
+		return
+	}
+
+	member, err := s.store.AddWorkspaceMember(r.Context(), ws.ID, strings.TrimSpace(body.Email), body.Role)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "No user with that email has signed in yet", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to add workspace member: %v", err)
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// handleUpdateWorkspaceMember changes a member's role. A workspace always
+// keeps at least one owner.
+func (s *Server) handleUpdateWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validWorkspaceRole(body.Role) {
+		http.Error(w, "role must be owner, admin or member", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.UpdateWorkspaceMemberRole(r.Context(), ws.ID, chi.URLParam(r, "userID"), body.Role)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, storage.ErrLastOwner) {
+		http.Error(w, "The workspace's only owner can't be demoted; make another member an owner first", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update workspace member: %v", err)
+		http.Error(w, "Failed to update member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleRemoveWorkspaceMember removes a member. Admins can remove members,
+// owners anyone, and every member can remove themselves (leave), except the
+// workspace's last owner.
+func (s *Server) handleRemoveWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	ws, userID, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+	target := chi.URLParam(r, "userID")
+
+	if target != userID {
+		members, err := s.store.GetWorkspaceMembers(r.Context(), ws.ID)
+		if err != nil {
+			http.Error(w, "Failed to fetch workspace", http.StatusInternalServerError)
+			return
+		}
+		targetRole := ""
+		for _, m := range members {
+			if m.UserID == target {
+				targetRole = m.Role
+			}
+		}
+		if targetRole == "" {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			return
+		}
+		if !storage.RoleAtLeast(ws.Role, storage.RoleAdmin) || (targetRole != storage.RoleMember && ws.Role != storage.RoleOwner) {
+			http.Error(w, "Access denied", http.StatusForbidden)
+			return
+		}
+	}
+
+	err := s.store.RemoveWorkspaceMember(r.Context(), ws.ID, target)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, storage.ErrLastOwner) {
+		http.Error(w, "The workspace's only owner can't leave; make another member an owner or delete the workspace", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to remove workspace member: %v", err)
+		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleGetWorkspaceLists(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+
+	lists, err := s.store.GetWorkspaceLists(r.Context(), ws.ID)
+	if err != nil {
+		log.Printf("Failed to fetch workspace lists: %v", err)
+		http.Error(w, "Failed to fetch lists", http.StatusInternalServerError)
+		return
+	}
+	if lists == nil {
+		lists = []storage.List{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"lists": lists})
+}
+
+// handleCreateWorkspaceList creates a shared list; items are then managed via
+// the regular /api/me/lists/{id}/items endpoints by any member.
+func (s *Server) handleCreateWorkspaceList(w http.ResponseWriter, r *http.Request) {
+	ws, userID, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		IsPublic    bool   `json:"is_public"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Title = strings.TrimSpace(body.Title)
+	if body.Title == "" || len(body.Title) > listTitleMaxLen {
+		http.Error(w, "title must be 1-120 characters", http.StatusBadRequest)
+		return
+	}
+	if len(body.Description) > listDescriptionMaxLen {
+		http.Error(w, "description is too long", http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.store.CreateList(r.Context(), userID, body.Title, body.Description, body.IsPublic, &ws.ID)
+	if err != nil {
+		log.Printf("Failed to create workspace list: %v", err)
+		http.Error(w, "Failed to create list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *Server) handleGetWorkspaceNote(w http.ResponseWriter, r *http.Request) {
+	ws, _, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "storyID"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	note, err := s.store.GetWorkspaceNote(r.Context(), ws.ID, storyID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Note not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to fetch workspace note: %v", err)
+		http.Error(w, "Failed to fetch note", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(note)
+}
+
+func (s *Server) handleSetWorkspaceNote(w http.ResponseWriter, r *http.Request) {
+	ws, userID, ok := s.requireWorkspaceRole(w, r, storage.RoleMember)
+	if !ok {
+		return
+	}
+	storyID, err := strconv.Atoi(chi.URLParam(r, "storyID"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.Note = strings.TrimSpace(body.Note)
+	if len(body.Note) > listNoteMaxLen {
+		http.Error(w, "note is too long", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.store.GetStory(r.Context(), storyID); err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.store.SetWorkspaceNote(r.Context(), ws.ID, storyID, userID, body.Note); err != nil {
+		log.Printf("Failed to save workspace note: %v", err)
+		http.Error(w, "Failed to save note", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func validWorkspaceRole(role string) bool {
+	return role == storage.RoleOwner || role == storage.RoleAdmin || role == storage.RoleMember
+}
@@ -1,42 +1,57 @@
 package auth
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
 	"golang.org/x/oauth2/google"
 )
 
 const (
 	CookieName   = "hn_session"
 	CookieMaxAge = 30 * 24 * 60 * 60 // 30 days
+
+	// AnonCookieName identifies an anonymous visitor so their reads and saves
+	// can be merged into their account when they sign in.
+	AnonCookieName = "hn_anon"
 )
 
 type Config struct {
 	OAuth2Config *oauth2.Config
+	GitHubConfig *oauth2.Config // nil unless GITHUB_CLIENT_ID is set
 	JWTSecret    []byte
 }
 
+// oauthClient carries token exchanges and the authenticated userinfo calls.
+var oauthClient = httpclient.New(httpclient.OAuth)
+
+// OAuthContext makes the OAuth calls made with ctx (Exchange and the clients
+// from Client) use the shared outbound HTTP client.
+func OAuthContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, oauthClient)
+}
+
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	jwt.RegisteredClaims
 }
 
-// NewConfig initializes OAuth2 and JWT config from environment variables.
-func NewConfig() *Config {
-	callbackURL := os.Getenv("OAUTH_CALLBACK_URL")
-	if callbackURL == "" {
-		callbackURL = "http://localhost:8080/auth/google/callback"
-	}
-
-	jwtSecret := os.Getenv("JWT_SECRET")
+// NewConfig initializes OAuth2 and JWT config from the OAuth settings.
+func NewConfig(c config.OAuth) *Config {
+	jwtSecret := c.JWTSecret
 	if jwtSecret == "" {
 		// Generate a random secret for dev (will change on restart)
 		b := make([]byte, 32)
@@ -44,15 +59,27 @@ func NewConfig() *Config {
 		jwtSecret = hex.EncodeToString(b)
 	}
 
+	var githubCfg *oauth2.Config
+	if c.GitHub.ClientID != "" {
+		githubCfg = &oauth2.Config{
+			ClientID:     c.GitHub.ClientID,
+			ClientSecret: c.GitHub.ClientSecret,
+			RedirectURL:  c.GitHub.CallbackURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		}
+	}
+
 	return &Config{
 		OAuth2Config: &oauth2.Config{
-			ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-			RedirectURL:  callbackURL,
+			ClientID:     c.Google.ClientID,
+			ClientSecret: c.Google.ClientSecret,
+			RedirectURL:  c.Google.CallbackURL,
 			Scopes:       []string{"openid", "email", "profile"},
 			Endpoint:     google.Endpoint,
 		},
-		JWTSecret: []byte(jwtSecret),
+		GitHubConfig: githubCfg,
+		JWTSecret:    []byte(jwtSecret),
 	}
 }
 
@@ -106,9 +133,21 @@ func (c *Config) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-// GetUserIDFromRequest extracts the user ID from the session cookie.
+type userIDKey struct{}
+
+// WithUserID returns a context authenticated as userID, for requests signed
+// in by means other than the session cookie, such as API tokens.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// GetUserIDFromRequest extracts the user ID from the request context (see
+// WithUserID) or the session cookie.
 // Returns empty string if not authenticated (not an error — anonymous usage is OK).
 func (c *Config) GetUserIDFromRequest(r *http.Request) string {
+	if userID, ok := r.Context().Value(userIDKey{}).(string); ok {
+		return userID
+	}
 	cookie, err := r.Cookie(CookieName)
 	if err != nil {
 		return ""
@@ -148,6 +187,83 @@ func ClearSessionCookie(w http.ResponseWriter, secure bool) {
 	})
 }
 
+// NewAnonymousID returns a random anonymous visitor ID.
+func NewAnonymousID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (c *Config) signAnonymousID(id string) string {
+	mac := hmac.New(sha256.New, c.JWTSecret)
+	mac.Write([]byte("anon:" + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetAnonymousIDFromRequest returns the anonymous visitor ID from the signed
+// anon cookie, or "" if it is missing or has been tampered with.
+func (c *Config) GetAnonymousIDFromRequest(r *http.Request) string {
+	cookie, err := r.Cookie(AnonCookieName)
+	if err != nil {
+		return ""
+	}
+	id, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || id == "" {
+		return ""
+	}
+	if !hmac.Equal([]byte(sig), []byte(c.signAnonymousID(id))) {
+		return ""
+	}
+	return id
+}
+
+// SetAnonymousCookie stores a signed anonymous visitor ID.
+func (c *Config) SetAnonymousCookie(w http.ResponseWriter, id string, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AnonCookieName,
+		Value:    id + "." + c.signAnonymousID(id),
+		Path:     "/",
+		MaxAge:   CookieMaxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearAnonymousCookie removes the anonymous visitor cookie.
+func ClearAnonymousCookie(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AnonCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CalendarToken returns a long-lived token that identifies the user in their
+// calendar feed URL, since calendar apps can't send the session cookie.
+func (c *Config) CalendarToken(userID string) string {
+	mac := hmac.New(sha256.New, c.JWTSecret)
+	mac.Write([]byte("ics:" + userID))
+	return userID + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// UserIDFromCalendarToken verifies a token from CalendarToken and returns the
+// user ID, or "" if it is invalid.
+func (c *Config) UserIDFromCalendarToken(token string) string {
+	userID, _, ok := strings.Cut(token, ".")
+	if !ok || userID == "" {
+		return ""
+	}
+	if !hmac.Equal([]byte(token), []byte(c.CalendarToken(userID))) {
+		return ""
+	}
+	return userID
+}
+
 // GenerateStateToken generates a random state token for CSRF protection.
 func GenerateStateToken() string {
 	b := make([]byte, 16)
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+// PKCEMethod is the only PKCE code challenge method accepted (RFC 7636).
+const PKCEMethod = "S256"
+
+// pkceChars are the characters allowed in verifiers and challenges.
+const pkceChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+// ValidPKCEChallenge reports whether challenge is a well-formed S256 code
+// challenge: the unpadded base64url SHA-256 of a verifier.
+func ValidPKCEChallenge(challenge string) bool {
+	return len(challenge) == base64.RawURLEncoding.EncodedLen(sha256.Size) && onlyPKCEChars(challenge)
+}
+
+// VerifyPKCE reports whether verifier is the code verifier for challenge.
+func VerifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 || !onlyPKCEChars(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func onlyPKCEChars(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune(pkceChars, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testVerifier  = "dBjftJeZ4CVP-mJrmEh6wzSWd2iUZf1lTkYhr0dgxzk"
+	testChallenge = "hM1b8rbEl8ogyJnSgXEP-j6simAfw6ZZzyUSK5CkiW4" // S256 of testVerifier
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		ok        bool
+	}{
+		{"S256 match", testVerifier, testChallenge, true},
+		{"wrong verifier", strings.Replace(testVerifier, "d", "e", 1), testChallenge, false},
+		{"plain method", testVerifier, testVerifier, false}, // the challenge is the verifier itself
+		{"verifier too short", testVerifier[:42], testChallenge, false},
+		{"verifier too long", strings.Repeat("a", 129), testChallenge, false},
+		{"verifier with bad characters", testVerifier[:42] + "+", testChallenge, false},
+		{"empty challenge", testVerifier, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.ok, VerifyPKCE(tt.verifier, tt.challenge))
+		})
+	}
+}
+
+func TestValidPKCEChallenge(t *testing.T) {
+	assert.True(t, ValidPKCEChallenge(testChallenge))
+	assert.False(t, ValidPKCEChallenge(testChallenge+"A"), "too long to be a SHA-256")
+	assert.False(t, ValidPKCEChallenge(testChallenge[:42]), "too short to be a SHA-256")
+	assert.False(t, ValidPKCEChallenge(strings.Replace(testChallenge, "-", "+", 1)), "standard base64")
+	assert.False(t, ValidPKCEChallenge(testChallenge[:42]+"="), "padded")
+}
@@ -0,0 +1,644 @@
+// Package config loads the settings of the HN Station binaries: the
+// database, the API server, OAuth, the AI providers, ingest, notifications,
+// and outbound HTTP and article fetching. It is the only place they're read
+// from the environment.
+//
+// Settings come from, in increasing precedence: the defaults below, a YAML
+// file named by CONFIG_FILE, and environment variables (including a .env
+// file).
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxStoryCount bounds Ingest.Stories; HN's top list holds 500 stories.
+const MaxStoryCount = 500
+
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	Ollama   Ollama   `yaml:"ollama"`
+	OAuth    OAuth    `yaml:"oauth"`
+	Ingest   Ingest   `yaml:"ingest"`
+	AI       AI       `yaml:"ai"`
+	Notify   Notify   `yaml:"notify"`
+	HTTP     HTTP     `yaml:"http"`
+	Fetch    Fetch    `yaml:"fetch"`
+}
+
+// Server holds the API server's settings.
+type Server struct {
+	Port              int      `yaml:"port"`
+	CORSOrigins       []string `yaml:"cors_origins"`       // allowed browser origins, besides extensions
+	DiscussionWorkers int      `yaml:"discussion_workers"` // run queued discussion summaries
+	// Mirror serves the instance as a read-only public mirror: only the
+	// routes that read stories and summaries, anonymously, with
+	// MirrorMaxAge cache headers for a CDN.
+	Mirror       bool          `yaml:"mirror"`
+	MirrorMaxAge time.Duration `yaml:"mirror_max_age"`
+	// FrontendURL is where auth flows return to and story links point;
+	// empty means the API's own origin.
+	FrontendURL string `yaml:"frontend_url"`
+	// RedirectAllowlist holds further prefixes auth flows may redirect to,
+	// e.g. "hnstation://auth" for a mobile deep link.
+	RedirectAllowlist []string `yaml:"redirect_allowlist"`
+	// PublicURL is the API's externally reachable base URL, for links in
+	// emails; empty means the request's own scheme and host.
+	PublicURL        string `yaml:"public_url"`
+	IngestHookSecret string `yaml:"ingest_hook_secret"` // signs POST /api/admin/hooks/ingest; empty disables it
+	IngestHookDir    string `yaml:"ingest_hook_dir"`    // where the hook finds the ingest binaries; empty = the server's own
+}
+
+// Database holds the connection string and pool tuning; zero pool values
+// leave the pgxpool defaults in place.
+type Database struct {
+	URL                string        `yaml:"url"`
+	MaxConns           int           `yaml:"max_conns"`
+	MinConns           int           `yaml:"min_conns"`
+	MaxConnLifetime    time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime    time.Duration `yaml:"max_conn_idle_time"`
+	HealthCheckPeriod  time.Duration `yaml:"health_check_period"`
+	StatementCacheMode string        `yaml:"statement_cache_mode"`
+	StatementTimeout   time.Duration `yaml:"statement_timeout"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+}
+
+type Ollama struct {
+	URL    string `yaml:"url"`
+	Models `yaml:",inline"`
+	// KeepAlive is how long models stay loaded between requests, as a
+	// duration ("30m", "-1" for forever) optionally overridden per model:
+	// "30m,llama3:latest=2h". Empty means Ollama's default.
+	KeepAlive string `yaml:"keep_alive"`
+}
+
+// Models picks a provider's models and the generation options used unless
+// a user's own settings override them. Empty fields mean the provider's
+// defaults.
+type Models struct {
+	SummaryModel string   `yaml:"summary_model"`
+	ChatModel    string   `yaml:"chat_model"`
+	EmbedModel   string   `yaml:"embed_model"` // must produce 768-dim vectors
+	Temperature  *float64 `yaml:"temperature"`
+	NumCtx       int      `yaml:"num_ctx"`
+}
+
+type OAuth struct {
+	// JWTSecret signs session tokens; empty generates one per process, so
+	// sessions don't survive restarts.
+	JWTSecret string        `yaml:"jwt_secret"`
+	Google    OAuthProvider `yaml:"google"`
+	GitHub    OAuthProvider `yaml:"github"` // sign-in is offered only with a client ID
+}
+
+type OAuthProvider struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	CallbackURL  string `yaml:"callback_url"`
+}
+
+// Ingest holds the ingest service's settings; its flags override them.
+type Ingest struct {
+	Interval       time.Duration `yaml:"interval"`  // between ingestion runs
+	FullSync       time.Duration `yaml:"full_sync"` // between full refetches; 0 = every run
+	Stories        int           `yaml:"stories"`   // front-page stories ingested
+	Feeds          string        `yaml:"feeds"`     // other HN lists ingested, comma-separated; none by default
+	SummaryWorkers int           `yaml:"summary_workers"`
+	DisableAI      bool          `yaml:"disable_ai"` // no summaries, embeddings or sensitive checks
+	// SensitiveCheck has the ai_provider models confirm or clear
+	// keyword-flagged stories, SensitiveBatch of them per cycle.
+	SensitiveCheck bool `yaml:"sensitive_check"`
+	SensitiveBatch int  `yaml:"sensitive_batch"`
+	EmbeddingBatch int  `yaml:"embedding_batch"` // stories embedded per cycle; 0 disables
+	// Past QueueMaxDepth pending summary jobs (0 = no limit), stories
+	// ranked below QueueKeepRank are deferred or shed.
+	QueueMaxDepth int  `yaml:"summary_queue_max_depth"`
+	QueueKeepRank int  `yaml:"summary_queue_keep_rank"`
+	Dispatcher    bool `yaml:"dispatcher"` // deliver the notification outbox
+}
+
+// AI holds the cloud providers' settings and how the models are used.
+type AI struct {
+	Gemini Gemini `yaml:"gemini"`
+	OpenAI OpenAI `yaml:"openai"`
+	// Embeddings picks the embedding provider: "local" (Ollama), "gemini",
+	// "fake" or "off".
+	Embeddings string `yaml:"embeddings"`
+	// Languages are the ISO 639-1 codes summarized as-is ("*" for all);
+	// OtherLanguages says what happens to the rest: "translate" or "skip".
+	Languages      []string `yaml:"languages"`
+	OtherLanguages string   `yaml:"other_languages"`
+	// Fake replaces every AI provider with canned, deterministic responses,
+	// for end-to-end tests and frontend work without a GPU or API keys.
+	Fake            bool          `yaml:"fake"`
+	FakeLatency     time.Duration `yaml:"fake_latency"`      // each fake call takes this long
+	FakeFailureRate float64       `yaml:"fake_failure_rate"` // fraction of fake calls that fail, 0-1
+}
+
+type Gemini struct {
+	APIKey string `yaml:"api_key"` // the system key, for background jobs and users without their own
+	Models `yaml:",inline"`
+}
+
+// OpenAI is an OpenAI-compatible server; it's offered as a provider when
+// either field is set. Local servers such as LM Studio need only BaseURL.
+type OpenAI struct {
+	BaseURL string `yaml:"base_url"` // empty = OpenAI's
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+}
+
+// Notify holds the notification channels' settings.
+type Notify struct {
+	SMTP          SMTP     `yaml:"smtp"`
+	WebhookURLs   []string `yaml:"webhook_urls"`   // receive every broadcast event
+	WebhookSecret string   `yaml:"webhook_secret"` // signs webhook deliveries
+	PushURL       string   `yaml:"push_url"`       // push gateway; empty disables push
+	PushToken     string   `yaml:"push_token"`
+	Alerts        Alerts   `yaml:"alerts"`
+}
+
+// SMTP is the relay email goes through; email is off unless Host and From
+// are set.
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	From     string `yaml:"from"`
+	Username string `yaml:"username"` // empty = no authentication
+	Password string `yaml:"password"`
+}
+
+// Alerts holds the admin alert thresholds. Alerts go to WebhookURL and
+// Emails, or to the admins' emails if both are empty.
+type Alerts struct {
+	Interval      time.Duration `yaml:"interval"`       // between checks
+	Repeat        time.Duration `yaml:"repeat"`         // a firing alert is resent this often
+	FailurePct    float64       `yaml:"failure_pct"`    // summary jobs given up on; 0 disables the check
+	FailureWindow time.Duration `yaml:"failure_window"` // the jobs finished in this window are counted
+	IngestStale   time.Duration `yaml:"ingest_stale"`   // since the last completed ingest run; 0 disables the check
+	WebhookURL    string        `yaml:"webhook_url"`
+	Emails        []string      `yaml:"emails"`
+}
+
+// HTTP tunes the transport shared by the outbound HTTP clients.
+type HTTP struct {
+	MaxIdleConns        int           `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`
+	DialTimeout         time.Duration `yaml:"dial_timeout"`
+	DNSCacheTTL         time.Duration `yaml:"dns_cache_ttl"` // 0 disables the cache
+	// AllowPrivateNetworks lets the article fetcher reach loopback and
+	// private addresses, e.g. to summarize pages on a development machine.
+	AllowPrivateNetworks bool `yaml:"allow_private_networks"`
+}
+
+// Fetch caps how much of each article is read, per kind.
+type Fetch struct {
+	MaxHTMLBytes int64 `yaml:"max_html_bytes"` // pages are truncated to this before parsing
+	MaxTextBytes int64 `yaml:"max_text_bytes"` // plain text and Markdown; truncated
+	MaxPDFBytes  int64 `yaml:"max_pdf_bytes"`  // larger PDFs aren't read
+	// WaybackFallback replaces unusable articles with their latest Wayback
+	// Machine snapshot.
+	WaybackFallback bool `yaml:"wayback_fallback"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              8080,
+			CORSOrigins:       []string{"http://localhost:5173", "http://localhost:5174", "https://hnstation.dev"},
+			DiscussionWorkers: 2,
+			MirrorMaxAge:      5 * time.Minute,
+		},
+		Database: Database{SlowQueryThreshold: 500 * time.Millisecond},
+		Ollama:   Ollama{URL: "http://localhost:11434"},
+		OAuth: OAuth{
+			Google: OAuthProvider{CallbackURL: "http://localhost:8080/auth/google/callback"},
+			GitHub: OAuthProvider{CallbackURL: "http://localhost:8080/auth/github/callback"},
+		},
+		Ingest: Ingest{
+			Interval:       time.Minute,
+			FullSync:       time.Hour,
+			Stories:        20,
+			SummaryWorkers: 5,
+			SensitiveBatch: 20,
+			EmbeddingBatch: 100,
+			QueueMaxDepth:  100,
+			QueueKeepRank:  10,
+			Dispatcher:     true,
+		},
+		AI: AI{
+			Embeddings:     "local",
+			Languages:      []string{"en"},
+			OtherLanguages: "translate",
+		},
+		Notify: Notify{
+			SMTP: SMTP{Port: 587},
+			Alerts: Alerts{
+				Interval:      5 * time.Minute,
+				Repeat:        6 * time.Hour,
+				FailurePct:    20,
+				FailureWindow: time.Hour,
+				IngestStale:   time.Hour,
+			},
+		},
+		HTTP: HTTP{
+			MaxIdleConns: 100,
+			// Ingest fetches comments from HN on dozens of workers at once;
+			// Go's default of 2 idle connections per host would redial
+			// constantly.
+			MaxIdleConnsPerHost: 32,
+			IdleConnTimeout:     90 * time.Second,
+			DialTimeout:         10 * time.Second,
+			DNSCacheTTL:         time.Minute,
+		},
+		Fetch: Fetch{
+			MaxHTMLBytes:    2 << 20,
+			MaxTextBytes:    1 << 20,
+			MaxPDFBytes:     20 << 20,
+			WaybackFallback: true,
+		},
+	}
+}
+
+// Load reads the configuration and validates it. A missing .env file is
+// fine; a missing CONFIG_FILE is not.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, relying on environment variables")
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded configuration from %s", path)
+	}
+	if err := errors.Join(cfg.applyEnv(), cfg.Validate()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// MustLoad is Load for main functions: it exits on an invalid configuration.
+func MustLoad() *Config {
+	cfg, err := Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	return cfg
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true) // a misspelt key is an error, not a silent default
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides settings with the environment variables set. The
+// variable names predate the config file and are kept for existing
+// deployments.
+func (c *Config) applyEnv() error {
+	var e envReader
+	e.int(&c.Server.Port, "PORT")
+	e.list(&c.Server.CORSOrigins, "CORS_ORIGINS")
+	e.int(&c.Server.DiscussionWorkers, "DISCUSSION_JOB_WORKERS")
+	e.bool(&c.Server.Mirror, "MIRROR_MODE")
+	e.duration(&c.Server.MirrorMaxAge, "MIRROR_MAX_AGE")
+	e.str(&c.Server.FrontendURL, "FRONTEND_URL")
+	e.list(&c.Server.RedirectAllowlist, "REDIRECT_ALLOWLIST")
+	e.str(&c.Server.PublicURL, "PUBLIC_URL")
+	e.str(&c.Server.IngestHookSecret, "INGEST_HOOK_SECRET")
+	e.str(&c.Server.IngestHookDir, "INGEST_HOOK_DIR")
+
+	e.str(&c.Database.URL, "DATABASE_URL")
+	e.int(&c.Database.MaxConns, "DB_MAX_CONNS")
+	e.int(&c.Database.MinConns, "DB_MIN_CONNS")
+	e.duration(&c.Database.MaxConnLifetime, "DB_MAX_CONN_LIFETIME")
+	e.duration(&c.Database.MaxConnIdleTime, "DB_MAX_CONN_IDLE_TIME")
+	e.duration(&c.Database.HealthCheckPeriod, "DB_HEALTH_CHECK_PERIOD")
+	e.str(&c.Database.StatementCacheMode, "DB_STATEMENT_CACHE_MODE")
+	e.duration(&c.Database.StatementTimeout, "DB_STATEMENT_TIMEOUT")
+	e.duration(&c.Database.SlowQueryThreshold, "DB_SLOW_QUERY_THRESHOLD")
+
+	e.str(&c.Ollama.URL, "OLLAMA_URL")
+	e.models(&c.Ollama.Models, "OLLAMA")
+	e.str(&c.Ollama.KeepAlive, "OLLAMA_KEEP_ALIVE")
+
+	e.str(&c.OAuth.JWTSecret, "JWT_SECRET")
+	e.str(&c.OAuth.Google.ClientID, "GOOGLE_CLIENT_ID")
+	e.str(&c.OAuth.Google.ClientSecret, "GOOGLE_CLIENT_SECRET")
+	e.str(&c.OAuth.Google.CallbackURL, "OAUTH_CALLBACK_URL")
+	e.str(&c.OAuth.GitHub.ClientID, "GITHUB_CLIENT_ID")
+	e.str(&c.OAuth.GitHub.ClientSecret, "GITHUB_CLIENT_SECRET")
+	e.str(&c.OAuth.GitHub.CallbackURL, "GITHUB_CALLBACK_URL")
+
+	e.duration(&c.Ingest.Interval, "INGEST_INTERVAL")
+	e.duration(&c.Ingest.FullSync, "INGEST_FULL_SYNC")
+	e.int(&c.Ingest.Stories, "INGEST_STORY_COUNT")
+	e.str(&c.Ingest.Feeds, "INGEST_FEEDS")
+	e.int(&c.Ingest.SummaryWorkers, "SUMMARY_WORKERS")
+	e.bool(&c.Ingest.DisableAI, "DISABLE_AI")
+	e.bool(&c.Ingest.SensitiveCheck, "SENSITIVE_AI_CHECK")
+	e.int(&c.Ingest.SensitiveBatch, "SENSITIVE_CHECK_BATCH_SIZE")
+	e.int(&c.Ingest.EmbeddingBatch, "EMBEDDING_BATCH_SIZE")
+	e.int(&c.Ingest.QueueMaxDepth, "SUMMARY_QUEUE_MAX_DEPTH")
+	e.int(&c.Ingest.QueueKeepRank, "SUMMARY_QUEUE_KEEP_RANK")
+	e.bool(&c.Ingest.Dispatcher, "NOTIFY_DISPATCHER")
+
+	e.str(&c.AI.Gemini.APIKey, "GEMINI_API_KEY")
+	e.models(&c.AI.Gemini.Models, "GEMINI")
+	e.str(&c.AI.OpenAI.BaseURL, "OPENAI_BASE_URL")
+	e.str(&c.AI.OpenAI.APIKey, "OPENAI_API_KEY")
+	e.str(&c.AI.OpenAI.Model, "OPENAI_MODEL")
+	e.str(&c.AI.Embeddings, "EMBEDDING_PROVIDER")
+	e.list(&c.AI.Languages, "SUMMARY_LANGUAGES")
+	e.str(&c.AI.OtherLanguages, "SUMMARY_OTHER_LANGUAGES")
+	e.bool(&c.AI.Fake, "AI_FAKE")
+	e.duration(&c.AI.FakeLatency, "AI_FAKE_LATENCY")
+	e.float(&c.AI.FakeFailureRate, "AI_FAKE_FAILURE_RATE")
+
+	e.str(&c.Notify.SMTP.Host, "SMTP_HOST")
+	e.int(&c.Notify.SMTP.Port, "SMTP_PORT")
+	e.str(&c.Notify.SMTP.From, "SMTP_FROM")
+	e.str(&c.Notify.SMTP.Username, "SMTP_USERNAME")
+	e.str(&c.Notify.SMTP.Password, "SMTP_PASSWORD")
+	e.list(&c.Notify.WebhookURLs, "NOTIFY_WEBHOOK_URLS")
+	e.str(&c.Notify.WebhookSecret, "NOTIFY_WEBHOOK_SECRET")
+	e.str(&c.Notify.PushURL, "PUSH_GATEWAY_URL")
+	e.str(&c.Notify.PushToken, "PUSH_GATEWAY_TOKEN")
+	e.duration(&c.Notify.Alerts.Interval, "ALERT_CHECK_INTERVAL")
+	e.duration(&c.Notify.Alerts.Repeat, "ALERT_REPEAT")
+	e.float(&c.Notify.Alerts.FailurePct, "ALERT_SUMMARY_FAILURE_PCT")
+	e.duration(&c.Notify.Alerts.FailureWindow, "ALERT_SUMMARY_FAILURE_WINDOW")
+	e.duration(&c.Notify.Alerts.IngestStale, "ALERT_INGEST_STALE")
+	e.str(&c.Notify.Alerts.WebhookURL, "ALERT_WEBHOOK_URL")
+	e.list(&c.Notify.Alerts.Emails, "ALERT_EMAILS")
+
+	e.int(&c.HTTP.MaxIdleConns, "HTTP_MAX_IDLE_CONNS")
+	e.int(&c.HTTP.MaxIdleConnsPerHost, "HTTP_MAX_IDLE_CONNS_PER_HOST")
+	e.duration(&c.HTTP.IdleConnTimeout, "HTTP_IDLE_CONN_TIMEOUT")
+	e.duration(&c.HTTP.DialTimeout, "HTTP_DIAL_TIMEOUT")
+	e.duration(&c.HTTP.DNSCacheTTL, "HTTP_DNS_CACHE_TTL")
+	e.bool(&c.HTTP.AllowPrivateNetworks, "HTTP_ALLOW_PRIVATE_NETWORKS")
+
+	e.int64(&c.Fetch.MaxHTMLBytes, "FETCH_MAX_HTML_BYTES")
+	e.int64(&c.Fetch.MaxTextBytes, "FETCH_MAX_TEXT_BYTES")
+	e.int64(&c.Fetch.MaxPDFBytes, "FETCH_MAX_PDF_BYTES")
+	e.bool(&c.Fetch.WaybackFallback, "FETCH_WAYBACK_FALLBACK")
+	return errors.Join(e.errs...)
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port %d is not a valid port", c.Server.Port)
+	for _, o := range c.Server.CORSOrigins {
+		u, err := url.Parse(o)
+		check(err == nil && u.Scheme != "" && u.Host != "" && (u.Path == "" || u.Path == "/"),
+			"server.cors_origins: %q is not an origin like https://example.com", o)
+	}
+	check(c.Server.DiscussionWorkers >= 1 && c.Server.DiscussionWorkers <= 32, "server.discussion_workers must be 1-32, got %d", c.Server.DiscussionWorkers)
+	check(c.Server.MirrorMaxAge >= time.Second, "server.mirror_max_age must be at least 1s, got %s", c.Server.MirrorMaxAge)
+	check(c.Server.FrontendURL == "" || isHTTPURL(c.Server.FrontendURL), "server.frontend_url: %q is not an http(s) URL", c.Server.FrontendURL)
+	for _, e := range c.Server.RedirectAllowlist {
+		u, err := url.Parse(e)
+		check(err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque == ""),
+			"server.redirect_allowlist: %q is not a URL prefix like https://beta.example.com or app://auth", e)
+	}
+	check(c.Server.PublicURL == "" || isHTTPURL(c.Server.PublicURL), "server.public_url: %q is not an http(s) URL", c.Server.PublicURL)
+
+	check(c.Database.URL != "", "database.url (DATABASE_URL) is not set")
+	check(c.Database.MaxConns >= 0 && c.Database.MinConns >= 0, "database pool sizes can't be negative")
+	check(c.Database.MaxConns == 0 || c.Database.MinConns <= c.Database.MaxConns,
+		"database.min_conns (%d) exceeds database.max_conns (%d)", c.Database.MinConns, c.Database.MaxConns)
+	check(c.Database.MaxConnLifetime >= 0 && c.Database.MaxConnIdleTime >= 0 && c.Database.HealthCheckPeriod >= 0 &&
+		c.Database.StatementTimeout >= 0 && c.Database.SlowQueryThreshold >= 0, "database durations can't be negative")
+
+	check(isHTTPURL(c.Ollama.URL), "ollama.url: %q is not an http(s) URL", c.Ollama.URL)
+	errs = append(errs, c.Ollama.Models.validate("ollama"), c.AI.Gemini.Models.validate("ai.gemini"))
+	if _, err := ParseKeepAlive(c.Ollama.KeepAlive); err != nil {
+		errs = append(errs, fmt.Errorf("ollama.keep_alive: %w", err))
+	}
+
+	for name, p := range map[string]OAuthProvider{"google": c.OAuth.Google, "github": c.OAuth.GitHub} {
+		check((p.ClientID == "") == (p.ClientSecret == ""), "oauth.%s: client_id and client_secret must be set together", name)
+		check(isHTTPURL(p.CallbackURL), "oauth.%s.callback_url: %q is not an http(s) URL", name, p.CallbackURL)
+	}
+
+	check(c.Ingest.Interval > 0, "ingest.interval must be positive")
+	check(c.Ingest.FullSync >= 0, "ingest.full_sync can't be negative")
+	check(c.Ingest.Stories >= 1 && c.Ingest.Stories <= MaxStoryCount, "ingest.stories must be 1-%d, got %d", MaxStoryCount, c.Ingest.Stories)
+	check(c.Ingest.SummaryWorkers >= 1 && c.Ingest.SummaryWorkers <= 64, "ingest.summary_workers must be 1-64, got %d", c.Ingest.SummaryWorkers)
+	check(c.Ingest.SensitiveBatch >= 0 && c.Ingest.EmbeddingBatch >= 0, "ingest batch sizes can't be negative")
+	check(c.Ingest.QueueMaxDepth >= 0 && c.Ingest.QueueKeepRank >= 0, "ingest summary queue limits can't be negative")
+
+	check(c.AI.OpenAI.BaseURL == "" || isHTTPURL(c.AI.OpenAI.BaseURL), "ai.openai.base_url: %q is not an http(s) URL", c.AI.OpenAI.BaseURL)
+	switch c.AI.Embeddings {
+	case "local", "fake", "off":
+	case "gemini":
+		check(c.AI.Gemini.APIKey != "", "ai.embeddings gemini needs ai.gemini.api_key (GEMINI_API_KEY)")
+	default:
+		check(false, "ai.embeddings must be local, gemini, fake or off, got %q", c.AI.Embeddings)
+	}
+	check(len(c.AI.Languages) > 0, "ai.languages is empty; use * for every language")
+	check(c.AI.OtherLanguages == "translate" || c.AI.OtherLanguages == "skip", "ai.other_languages must be translate or skip, got %q", c.AI.OtherLanguages)
+	check(c.AI.FakeLatency >= 0, "ai.fake_latency can't be negative")
+	check(c.AI.FakeFailureRate >= 0 && c.AI.FakeFailureRate <= 1, "ai.fake_failure_rate must be 0-1, got %g", c.AI.FakeFailureRate)
+
+	n := c.Notify
+	check((n.SMTP.Host == "") == (n.SMTP.From == ""), "notify.smtp: host and from must be set together")
+	check(n.SMTP.Port > 0 && n.SMTP.Port <= 65535, "notify.smtp.port %d is not a valid port", n.SMTP.Port)
+	check(n.SMTP.From == "" || isEmail(n.SMTP.From), "notify.smtp.from: %q is not an email address", n.SMTP.From)
+	for _, u := range n.WebhookURLs {
+		check(isHTTPURL(u), "notify.webhook_urls: %q is not an http(s) URL", u)
+	}
+	check(n.PushURL == "" || isHTTPURL(n.PushURL), "notify.push_url: %q is not an http(s) URL", n.PushURL)
+	check(n.Alerts.Interval > 0 && n.Alerts.Repeat > 0 && n.Alerts.FailureWindow > 0,
+		"notify.alerts: interval, repeat and failure_window must be positive")
+	check(n.Alerts.FailurePct >= 0 && n.Alerts.FailurePct <= 100, "notify.alerts.failure_pct must be 0-100, got %g", n.Alerts.FailurePct)
+	check(n.Alerts.IngestStale >= 0, "notify.alerts.ingest_stale can't be negative")
+	check(n.Alerts.WebhookURL == "" || isHTTPURL(n.Alerts.WebhookURL), "notify.alerts.webhook_url: %q is not an http(s) URL", n.Alerts.WebhookURL)
+	for _, e := range n.Alerts.Emails {
+		check(isEmail(e), "notify.alerts.emails: %q is not an email address", e)
+	}
+
+	check(c.HTTP.MaxIdleConns >= 0 && c.HTTP.MaxIdleConnsPerHost >= 0, "http connection counts can't be negative")
+	check(c.HTTP.IdleConnTimeout >= 0 && c.HTTP.DialTimeout >= 0 && c.HTTP.DNSCacheTTL >= 0, "http durations can't be negative")
+
+	check(c.Fetch.MaxHTMLBytes > 0 && c.Fetch.MaxTextBytes > 0 && c.Fetch.MaxPDFBytes > 0, "fetch size limits must be positive")
+
+	return errors.Join(errs...)
+}
+
+func (m Models) validate(section string) error {
+	var errs []error
+	if m.Temperature != nil && (*m.Temperature < 0 || *m.Temperature > 2) {
+		errs = append(errs, fmt.Errorf("%s.temperature must be 0-2, got %g", section, *m.Temperature))
+	}
+	if m.NumCtx != 0 && (m.NumCtx < 512 || m.NumCtx > 131072) {
+		errs = append(errs, fmt.Errorf("%s.num_ctx must be 512-131072, got %d", section, m.NumCtx))
+	}
+	return errors.Join(errs...)
+}
+
+// ParseKeepAlive parses a keep-alive spec (see Ollama.KeepAlive): comma-
+// separated entries that are either a bare default duration or
+// model=duration. Durations are Go durations or whole seconds; negative
+// keeps the model loaded forever. The result maps model names to Ollama
+// keep-alives, with the "" entry applying to other models.
+func ParseKeepAlive(spec string) (map[string]string, error) {
+	keepAlive := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, value := "", entry
+		if i := strings.LastIndex(entry, "="); i >= 0 {
+			model, value = strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		}
+		if secs, err := strconv.Atoi(value); err == nil {
+			// Ollama only accepts strings with a unit.
+			value = fmt.Sprintf("%ds", secs)
+		} else if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid keep-alive %q", entry)
+		}
+		keepAlive[model] = value
+	}
+	return keepAlive, nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
+
+// envReader applies environment variables over settings, collecting the
+// values that don't parse.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) str(dst *string, key string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func (e *envReader) int(dst *int, key string) {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("invalid %s=%q: not a number", key, v))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) duration(dst *time.Duration, key string) {
+	if v := os.Getenv(key); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("invalid %s=%q: not a duration like 30s or 5m", key, v))
+			return
+		}
+		*dst = d
+	}
+}
+
+func (e *envReader) int64(dst *int64, key string) {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("invalid %s=%q: not a number", key, v))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) float(dst *float64, key string) {
+	if v := os.Getenv(key); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("invalid %s=%q: not a number", key, v))
+			return
+		}
+		*dst = f
+	}
+}
+
+func (e *envReader) bool(dst *bool, key string) {
+	if v := os.Getenv(key); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("invalid %s=%q: not true or false", key, v))
+			return
+		}
+		*dst = b
+	}
+}
+
+// models reads <prefix>_SUMMARY_MODEL, _CHAT_MODEL, _EMBED_MODEL,
+// _TEMPERATURE and _NUM_CTX. Like the other variables, an empty _TEMPERATURE
+// is unset and keeps the model's default.
+func (e *envReader) models(dst *Models, prefix string) {
+	e.str(&dst.SummaryModel, prefix+"_SUMMARY_MODEL")
+	e.str(&dst.ChatModel, prefix+"_CHAT_MODEL")
+	e.str(&dst.EmbedModel, prefix+"_EMBED_MODEL")
+	if os.Getenv(prefix+"_TEMPERATURE") != "" {
+		var t float64
+		e.float(&t, prefix+"_TEMPERATURE")
+		dst.Temperature = &t
+	}
+	e.int(&dst.NumCtx, prefix+"_NUM_CTX")
+}
+
+// list reads a comma-separated list.
+func (e *envReader) list(dst *[]string, key string) {
+	if v := os.Getenv(key); v != "" {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
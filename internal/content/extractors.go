@@ -0,0 +1,142 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+)
+
+// An Extractor gets the article behind the URLs of one site, from the
+// site's API or its markup, where go-readability's generic extraction does
+// poorly. FetchArticle tries the registered extractors in order and falls
+// back to go-readability when none matches or the one that does returns
+// nil.
+type Extractor interface {
+	Name() string
+	// Match reports whether the extractor handles u. It's called with
+	// URLs that passed httpclient.CheckURL.
+	Match(u *url.URL) bool
+	// Extract fetches and extracts the article at u. A nil result with a
+	// nil error means the generic fetch should be used instead.
+	Extract(ctx context.Context, u *url.URL) (*FetchResult, error)
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = []Extractor{
+		githubExtractor{},
+		substackExtractor{},
+		mediumExtractor{},
+		wikipediaExtractor{},
+		stackExchangeExtractor{},
+		redditExtractor{},
+	}
+)
+
+// RegisterExtractor adds e after the built-in extractors.
+func RegisterExtractor(e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, e)
+}
+
+// matchExtractor returns the first registered extractor for u, or nil.
+func matchExtractor(u *url.URL) Extractor {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	for _, e := range extractors {
+		if e.Match(u) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Sites that ask API clients to identify themselves get this user agent
+// rather than a browser's.
+const apiUserAgent = "HNStation/1.0 (+https://hnstation.dev)"
+
+// getBody fetches urlStr with the article fetcher's client and returns up
+// to limit bytes of its body and its headers. Anything but a 200 is an
+// error.
+func getBody(ctx context.Context, urlStr, userAgent string, limit int64) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := httpclient.New(httpclient.Fetcher).Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("GET %s: HTTP %d", urlStr, resp.StatusCode)
+	}
+	body, _, err := readLimited(resp.Body, limit)
+	return body, resp.Header, err
+}
+
+// getJSON fetches a JSON API response into v.
+func getJSON(ctx context.Context, urlStr string, v interface{}) error {
+	body, _, err := getBody(ctx, urlStr, apiUserAgent, settings.MaxTextBytes)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("GET %s: %w", urlStr, err)
+	}
+	return nil
+}
+
+// pathParts splits a URL path into its non-empty segments.
+func pathParts(u *url.URL) []string {
+	var parts []string
+	for _, p := range strings.Split(u.Path, "/") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// githubExtractor reads a repository's README instead of its GitHub page.
+type githubExtractor struct{}
+
+func (githubExtractor) Name() string { return "github" }
+
+// Match accepts repository roots: github.com/owner/repo.
+func (githubExtractor) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return (host == "github.com" || host == "www.github.com") && len(pathParts(u)) == 2
+}
+
+func (githubExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	parts := pathParts(u)
+	owner, repo := parts[0], parts[1]
+	// Try master then main
+	for _, branch := range []string{"master", "main"} {
+		rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/README.md", owner, repo, branch)
+		readme, _, err := getBody(ctx, rawURL, browserUserAgent, settings.MaxTextBytes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		return &FetchResult{
+			Content:     string(readme),
+			Title:       fmt.Sprintf("GitHub README: %s/%s", owner, repo),
+			CanIframe:   false,
+			ContentType: "markdown",
+			StatusCode:  http.StatusOK,
+		}, nil
+	}
+	return nil, nil
+}
@@ -2,8 +2,9 @@ package content
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"io"
+	"html"
 	"log"
 	"net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 
 	readability "github.com/go-shiori/go-readability"
 	"github.com/ledongthuc/pdf"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
 )
 
 // FetchResult contains the result of an article fetch
@@ -20,84 +22,153 @@ type FetchResult struct {
 	Title       string
 	CanIframe   bool
 	ContentType string // 'html', 'markdown', or 'text'
+	StatusCode  int    // HTTP status of the article response
+	Blocked     bool   // anti-bot protection served a challenge instead of the article
+	// Archived is set when the article was unusable and Content comes from
+	// its latest Wayback Machine snapshot, ArchiveURL, taken at ArchivedAt.
+	Archived   bool
+	ArchiveURL string
+	ArchivedAt time.Time
 }
 
-// FetchArticle attempts to fetch and parse the article content.
-func FetchArticle(urlStr string) (*FetchResult, error) {
-	parsedURL, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, err
+// allowsFraming reports whether a page's headers let it be framed.
+func allowsFraming(h http.Header) bool {
+	xFrame := strings.ToUpper(h.Get("X-Frame-Options"))
+	if xFrame == "DENY" || xFrame == "SAMEORIGIN" {
+		return false
 	}
+	return !strings.Contains(strings.ToLower(h.Get("Content-Security-Policy")), "frame-ancestors")
+}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+// FetchArticle attempts to fetch and parse the article content. Cancelling
+// ctx aborts the fetch, including reading the body. Only http and https URLs
+// on public addresses are fetched, redirects included; others fail with
+// httpclient.ErrBlockedScheme or httpclient.ErrBlockedAddress. Responses
+// that aren't a page, text or PDF fail with ErrUnsupportedContent without
+// their body being read, and bodies are read up to the configured limit for
+// their kind.
+//
+// URLs an Extractor matches (GitHub repositories, Substack, Medium,
+// Wikipedia, Stack Exchange and Reddit) are read the way that site needs,
+// falling back to go-readability if the extractor fails or declines.
+//
+// An article behind a paywall, login wall or bot challenge, or one that's
+// gone (HTTP 401, 402, 403, 404, 410, 451) or yields next to no content, is
+// replaced by its latest Wayback Machine snapshot if there is a usable one,
+// unless fetch.wayback_fallback is off.
+func FetchArticle(ctx context.Context, urlStr string) (*FetchResult, error) {
+	res, err := fetchArticle(ctx, urlStr)
+	if err != nil || !needsArchive(res) || !settings.WaybackFallback {
+		return res, err
+	}
+	archived, err := fetchArchived(ctx, urlStr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Fetcher: No Wayback Machine snapshot of %s: %v", urlStr, err)
+		return res, nil
+	}
+	if archived == nil {
+		return res, nil
 	}
-	req, _ := http.NewRequest("GET", urlStr, nil)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	log.Printf("Fetcher: Using the Wayback Machine snapshot of %s from %s (original: HTTP %d)", urlStr, archived.ArchivedAt.Format("2006-01-02"), res.StatusCode)
+	return archived, nil
+}
 
-	resp, err := client.Do(req)
+// browserUserAgent is sent for pages, which some sites only serve to
+// browsers.
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+func fetchArticle(ctx context.Context, urlStr string) (*FetchResult, error) {
+	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if err := httpclient.CheckURL(parsedURL); err != nil {
+		return nil, err
+	}
 
-	// GitHub Handling: Direct README extraction
-	if strings.Contains(urlStr, "github.com") {
-		// If it's a repo root (no blob/tree/pull etc)
-		u, _ := url.Parse(urlStr)
-		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
-		if len(parts) == 2 {
-			// Try master then main
-			for _, branch := range []string{"master", "main"} {
-				rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/README.md", parts[0], parts[1], branch)
-				req, _ = http.NewRequest("GET", rawURL, nil)
-				resp, err = client.Do(req)
-				if err == nil && resp.StatusCode == 200 {
-					defer resp.Body.Close()
-					bodyBytes, _ := io.ReadAll(resp.Body)
-					return &FetchResult{
-						Content:     string(bodyBytes),
-						Title:       fmt.Sprintf("GitHub README: %s/%s", parts[0], parts[1]),
-						CanIframe:   false,
-						ContentType: "markdown",
-					}, nil
-				}
+	if e := matchExtractor(parsedURL); e != nil {
+		res, err := e.Extract(ctx, parsedURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("Fetcher: %s extractor failed for %s: %v", e.Name(), urlStr, err)
+		} else if res != nil {
+			if res.ContentType == "html" {
+				res.Content = sanitizeHTML(res.Content)
 			}
+			return res, nil
 		}
 	}
 
-	// 1. Check Iframe Compatibility
-	canIframe := true
-	xFrame := strings.ToUpper(resp.Header.Get("X-Frame-Options"))
-	if xFrame == "DENY" || xFrame == "SAMEORIGIN" {
-		canIframe = false
+	client := httpclient.New(httpclient.Fetcher)
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("User-Agent", browserUserAgent)
 
-	csp := strings.ToLower(resp.Header.Get("Content-Security-Policy"))
-	if strings.Contains(csp, "frame-ancestors") {
-		canIframe = false
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Detect PDF by Content-Type or extension
-	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
-	isPDF := strings.Contains(contentType, "application/pdf") || strings.HasSuffix(strings.ToLower(urlStr), ".pdf")
+	// 1. Check Iframe Compatibility
+	canIframe := allowsFraming(resp.Header)
+
+	// Decide from the headers whether the body is worth reading at all, so
+	// a link to a video or a multi-gigabyte archive costs one request.
+	kind := contentKind(resp.Header, urlStr)
+	if kind == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContent, resp.Header.Get("Content-Type"))
+	}
 
-	if isPDF {
-		log.Printf("Fetcher: Detected PDF content for %s. Returning as PDF type.", urlStr)
+	if kind == kindPDF {
+		// The frontend shows PDFs from their URL, so a PDF whose text can't
+		// be had is still a result, just one too short to summarize.
+		text, err := fetchPDFText(ctx, resp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("Fetcher: No text from PDF %s: %v", urlStr, err)
+		}
 		return &FetchResult{
-			Content:     "PDF content", // Placeholder, frontend will use the URL directly
+			Content:     text,
 			Title:       "PDF Document: " + urlStr,
 			CanIframe:   true, // We pretend it can iframe so the frontend doesn't show the "might block embed" warning, but we'll use <object>
 			ContentType: "pdf",
+			StatusCode:  resp.StatusCode,
 		}, nil
 	}
 
-	// 2. Read Body
-	// Limit to 2MB to prevent memory exhaustion
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	// 2. Read Body, up to the limit for its kind; readability parses what
+	// fits.
+	limit := settings.MaxHTMLBytes
+	if kind == kindText {
+		limit = settings.MaxTextBytes
+	}
+	bodyBytes, truncated, err := readLimited(resp.Body, limit)
 	if err != nil {
 		return nil, err
 	}
+	if truncated {
+		log.Printf("Fetcher: Truncated %s to %d bytes", urlStr, limit)
+	}
+
+	if kind == kindText && resp.StatusCode < 400 {
+		return &FetchResult{
+			Content:     string(bodyBytes),
+			Title:       "Unknown Title",
+			CanIframe:   canIframe,
+			ContentType: "text",
+			StatusCode:  resp.StatusCode,
+		}, nil
+	}
 
 	bodyStr := string(bodyBytes)
 
@@ -118,30 +189,40 @@ func FetchArticle(urlStr string) (*FetchResult, error) {
 			Title:       "Protection Challenge",
 			CanIframe:   true, // Force iframe true since the block is just on our server IP
 			ContentType: "html",
+			StatusCode:  resp.StatusCode,
+			Blocked:     true,
 		}, nil
 	}
 
 	// 3. Attempt Parsing with go-readability
-	article, err := readability.FromReader(strings.NewReader(string(bodyBytes)), parsedURL)
+	article, err := readability.FromReader(bytes.NewReader(bodyBytes), parsedURL)
 	if err == nil && article.Content != "" {
 		return &FetchResult{
-			Content:     article.Content, // Use full HTML content instead of stripped TextContent
+			Content:     sanitizeHTML(article.Content), // Use full HTML content instead of stripped TextContent
 			Title:       article.Title,
 			CanIframe:   canIframe,
 			ContentType: "html",
+			StatusCode:  resp.StatusCode,
 		}, nil
 	}
 
 	// 4. Fallback to Raw HTML but strip tags (poor man's strip)
-	raw := string(bodyBytes)
 	return &FetchResult{
-		Content:     stripTags(raw),
+		Content:     stripTags(bodyStr),
 		Title:       "Unknown Title",
 		CanIframe:   canIframe,
 		ContentType: "text",
+		StatusCode:  resp.StatusCode,
 	}, nil
 }
 
+// PlainText converts an HN text field, which is HTML, to plain text.
+func PlainText(s string) string {
+	// HN separates paragraphs with a bare <p>; keep the words apart.
+	s = strings.ReplaceAll(s, "<p>", " <p>")
+	return html.UnescapeString(stripTags(s))
+}
+
 func stripTags(html string) string {
 	var sb strings.Builder
 	inTag := false
@@ -161,16 +242,28 @@ func stripTags(html string) string {
 	return strings.Join(strings.Fields(sb.String()), " ")
 }
 
-// extractTextFromPDF reads PDF content from a reader and returns the extracted text.
-func extractTextFromPDF(r io.Reader) (string, error) {
-	// We need to read the whole body into a temp file or buffer because ledongthuc/pdf
-	// often needs seekable access or a reader that can be reread.
-	bodyBytes, err := io.ReadAll(r)
+// fetchPDFText reads a PDF response, up to the PDF limit, and extracts its
+// text.
+func fetchPDFText(ctx context.Context, resp *http.Response) (string, error) {
+	limit := settings.MaxPDFBytes
+	if err := checkLength(resp, limit); err != nil {
+		return "", err
+	}
+	// ledongthuc/pdf needs random access, so the PDF is buffered whole.
+	body, truncated, err := readLimited(resp.Body, limit)
 	if err != nil {
 		return "", err
 	}
+	if truncated {
+		return "", fmt.Errorf("%w: PDF past %d bytes", errTooLarge, limit)
+	}
+	return extractTextFromPDF(ctx, body)
+}
 
-	reader, err := pdf.NewReader(bytes.NewReader(bodyBytes), int64(len(bodyBytes)))
+// extractTextFromPDF returns the text of a PDF's first pages. It stops
+// between pages once ctx is done.
+func extractTextFromPDF(ctx context.Context, data []byte) (string, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return "", err
 	}
@@ -183,6 +276,9 @@ func extractTextFromPDF(r io.Reader) (string, error) {
 	}
 
 	for i := 1; i <= numPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		page := reader.Page(i)
 		if page.V.IsNull() {
 			continue
@@ -0,0 +1,20 @@
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Fingerprint hashes the visible text of fetched content, ignoring markup,
+// case and whitespace, so only material changes produce a new hash. It also
+// returns the length of the normalized text.
+func Fingerprint(content string) (string, int) {
+	text := tagPattern.ReplaceAllString(content, " ")
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:]), len(text)
+}
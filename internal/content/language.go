@@ -0,0 +1,145 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageSample caps how much text DetectLanguage looks at.
+const languageSample = 10000
+
+// stopwords are frequent short words that set Latin-script languages apart.
+// Words shared by several of them (e.g. "de", "a", "en") are left out.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "it", "with", "for", "this", "was", "are", "be", "have", "you", "not", "but", "which", "from", "they"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "sich", "auf", "ein", "eine", "auch", "dem", "den", "zu", "wird", "sind", "wir", "ich", "für"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "du", "que", "pour", "dans", "qui", "pas", "sur", "au", "avec", "sont", "nous", "mais", "ce"},
+	"es": {"el", "los", "las", "del", "y", "que", "es", "por", "una", "para", "con", "pero", "como", "más", "su", "al", "está", "lo", "muy", "sobre"},
+	"it": {"il", "di", "che", "e", "della", "per", "non", "sono", "una", "gli", "è", "nel", "anche", "alla", "come", "più", "dei", "questo", "del", "ma"},
+	"pt": {"o", "os", "do", "da", "que", "não", "uma", "para", "com", "em", "é", "mais", "como", "dos", "das", "ao", "seu", "sua", "também", "mas"},
+	"nl": {"het", "een", "van", "en", "is", "dat", "niet", "op", "zijn", "voor", "met", "ook", "maar", "wordt", "aan", "bij", "als", "nog", "naar", "wij"},
+	"sv": {"och", "att", "det", "som", "är", "av", "för", "med", "inte", "den", "till", "har", "på", "om", "ett", "men", "kan", "jag", "vi", "också"},
+	"pl": {"i", "w", "nie", "na", "się", "jest", "że", "do", "to", "z", "jak", "ale", "od", "przez", "dla", "czy", "tak", "być", "są", "oraz"},
+	"tr": {"ve", "bir", "bu", "için", "ile", "da", "olarak", "çok", "daha", "gibi", "olan", "ama", "kadar", "değil", "var", "ne", "en", "sonra", "mi", "ise"},
+}
+
+// languageNames are the English names of the languages DetectLanguage
+// returns.
+var languageNames = map[string]string{
+	"en": "English", "de": "German", "fr": "French", "es": "Spanish", "it": "Italian",
+	"pt": "Portuguese", "nl": "Dutch", "sv": "Swedish", "pl": "Polish", "tr": "Turkish",
+	"ru": "Russian", "uk": "Ukrainian", "zh": "Chinese", "ja": "Japanese", "ko": "Korean",
+	"ar": "Arabic", "he": "Hebrew", "el": "Greek", "hi": "Hindi", "th": "Thai",
+}
+
+var stopwordIndex = func() map[string][]string {
+	idx := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// LanguageName returns the English name of an ISO 639-1 code DetectLanguage
+// returns, or the code itself.
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// DetectLanguage guesses the language of text, returning an ISO 639-1 code
+// such as "en", or "" if there's too little text to tell. Non-Latin scripts
+// are told apart by their characters, Latin-script languages by their most
+// common words.
+func DetectLanguage(text string) string {
+	if len(text) > languageSample {
+		text = strings.ToValidUTF8(text[:languageSample], "")
+	}
+
+	var latin, kana, total int
+	scripts := make(map[string]int)
+	ukrainian := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				ukrainian = true
+			}
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		}
+	}
+	if total < 20 {
+		return ""
+	}
+
+	if latin*2 < total {
+		// Japanese mixes kana with kanji; any real amount of kana decides it.
+		if kana > 0 && kana*10 >= total {
+			return "ja"
+		}
+		best, bestCount := "", 0
+		for lang, n := range scripts {
+			if n > bestCount {
+				best, bestCount = lang, n
+			}
+		}
+		if best == "ru" && ukrainian {
+			return "uk"
+		}
+		return best
+	}
+
+	hits := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, w := range words {
+		for _, lang := range stopwordIndex[w] {
+			hits[lang]++
+		}
+	}
+	best := ""
+	for lang, n := range hits {
+		if n > hits[best] {
+			best = lang
+		}
+	}
+	second := 0
+	for lang, n := range hits {
+		if lang != best && n > second {
+			second = n
+		}
+	}
+	// Require a handful of matches and a clear lead, so that a page of code
+	// or a list of names isn't given a language.
+	if hits[best] < 5 || hits[best]*2 < second*3 {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,85 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+)
+
+// ErrUnsupportedContent is returned for responses that aren't a page, text
+// or PDF, e.g. video or archives. Their bodies aren't read.
+var ErrUnsupportedContent = errors.New("unsupported content type")
+
+// errTooLarge is returned for PDFs past fetch.max_pdf_bytes, which can't be
+// parsed once truncated.
+var errTooLarge = errors.New("content too large")
+
+// settings cap how many bytes FetchArticle reads per kind of content and
+// turn the Wayback Machine fallback on or off; see Configure.
+var settings = config.Default().Fetch
+
+// Configure sets FetchArticle's limits and fallback. It's meant to be called
+// once by main, before anything is fetched.
+func Configure(cfg config.Fetch) {
+	settings = cfg
+}
+
+// Kinds of content FetchArticle reads.
+const (
+	kindHTML = "html"
+	kindText = "text"
+	kindPDF  = "pdf"
+)
+
+// contentKind classifies a response by its Content-Type, falling back to the
+// URL's extension for PDFs served as generic binaries. It returns "" for
+// content that isn't worth reading.
+func contentKind(header http.Header, urlStr string) string {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Missing or malformed: most likely a page.
+		mediaType = ""
+	}
+	isPDFURL := strings.HasSuffix(strings.ToLower(urlStr), ".pdf")
+	switch {
+	case mediaType == "application/pdf":
+		return kindPDF
+	case isPDFURL && (mediaType == "" || mediaType == "application/octet-stream"):
+		return kindPDF
+	case mediaType == "", mediaType == "text/html", mediaType == "application/xhtml+xml":
+		return kindHTML
+	case mediaType == "text/plain", mediaType == "text/markdown", mediaType == "text/x-markdown":
+		return kindText
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/xml":
+		// Other markup (e.g. text/xml); readability copes or falls back to
+		// stripped text.
+		return kindHTML
+	}
+	return ""
+}
+
+// readLimited reads at most limit bytes of r, reporting whether there was
+// more.
+func readLimited(r io.Reader, limit int64) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, nil
+	}
+	return body, false, nil
+}
+
+// checkLength fails early when a response declares a body past limit.
+func checkLength(resp *http.Response, limit int64) error {
+	if resp.ContentLength > limit {
+		return fmt.Errorf("%w: %d bytes, limit %d", errTooLarge, resp.ContentLength, limit)
+	}
+	return nil
+}
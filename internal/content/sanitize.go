@@ -0,0 +1,49 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/go-shiori/dom"
+)
+
+// unsafeElements are removed from article HTML along with their contents.
+const unsafeElements = "script, style, iframe, frame, frameset, object, embed, applet, base, meta, link, form"
+
+// sanitizeHTML strips what could run code or restyle the page from article
+// HTML before it's stored or served: unsafeElements, on* event handler
+// attributes, and javascript: URLs.
+func sanitizeHTML(s string) string {
+	doc, err := dom.Parse(strings.NewReader(s))
+	if err != nil {
+		return stripTags(s)
+	}
+	body := dom.QuerySelector(doc, "body")
+	if body == nil {
+		return ""
+	}
+	dom.RemoveNodes(dom.QuerySelectorAll(body, unsafeElements), nil)
+	for _, n := range dom.QuerySelectorAll(body, "*") {
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			name := strings.ToLower(a.Key)
+			if strings.HasPrefix(name, "on") || isScriptURL(a.Val) {
+				continue
+			}
+			attrs = append(attrs, a)
+		}
+		n.Attr = attrs
+	}
+	return dom.InnerHTML(body)
+}
+
+// isScriptURL reports whether an attribute value is a javascript: or
+// vbscript: URL, which browsers run when it's followed.
+func isScriptURL(v string) bool {
+	v = strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1 // browsers ignore whitespace and control characters here
+		}
+		return r
+	}, v))
+	return strings.HasPrefix(v, "javascript:") || strings.HasPrefix(v, "vbscript:")
+}
@@ -0,0 +1,34 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sensitiveTerms are words and phrases that mark a story as potentially
+// sensitive, by category. Matching is on whole words, case-insensitively.
+// The list is deliberately short: it should catch the obvious cases without
+// flagging every story about, say, security "exploits".
+var sensitiveTerms = map[string][]string{
+	"adult":     {"porn", "porno", "pornography", "pornographic", "nsfw", "xxx", "hentai", "onlyfans", "nudes", "erotica", "sex tape"},
+	"violence":  {"gore", "beheading", "beheaded", "graphic violence", "mass shooting", "execution video"},
+	"self_harm": {"suicide", "suicidal", "self-harm", "self harm"},
+}
+
+// ClassifySensitive returns why texts look sensitive, as "<category>: <term>"
+// (e.g. "adult: nsfw"), or "" if nothing matched.
+func ClassifySensitive(texts ...string) string {
+	words := strings.FieldsFunc(strings.ToLower(strings.Join(texts, " ")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	// Pad with spaces so phrases only match whole words.
+	joined := " " + strings.Join(words, " ") + " "
+	for _, category := range []string{"adult", "violence", "self_harm"} {
+		for _, term := range sensitiveTerms[category] {
+			if strings.Contains(joined, " "+term+" ") {
+				return category + ": " + term
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-shiori/dom"
+)
+
+// substackExtractor reads posts on *.substack.com from Substack's post API,
+// which has the body without the subscribe prompts around it.
+type substackExtractor struct{}
+
+func (substackExtractor) Name() string { return "substack" }
+
+func (substackExtractor) Match(u *url.URL) bool {
+	parts := pathParts(u)
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), ".substack.com") && len(parts) >= 2 && parts[0] == "p"
+}
+
+func (substackExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	var post struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		BodyHTML string `json:"body_html"`
+	}
+	apiURL := fmt.Sprintf("https://%s/api/v1/posts/%s", u.Hostname(), url.PathEscape(pathParts(u)[1]))
+	if err := getJSON(ctx, apiURL, &post); err != nil {
+		return nil, err
+	}
+	if post.BodyHTML == "" {
+		return nil, nil
+	}
+	body := post.BodyHTML
+	if post.Subtitle != "" {
+		body = "<p><em>" + html.EscapeString(post.Subtitle) + "</em></p>" + body
+	}
+	return &FetchResult{Content: body, Title: post.Title, ContentType: "html", StatusCode: http.StatusOK}, nil
+}
+
+// mediumExtractor keeps the <article> of a Medium page, without the clap,
+// share and follow buttons go-readability lets through.
+type mediumExtractor struct{}
+
+func (mediumExtractor) Name() string { return "medium" }
+
+func (mediumExtractor) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return (host == "medium.com" || strings.HasSuffix(host, ".medium.com")) && len(pathParts(u)) >= 1
+}
+
+func (mediumExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	page, header, err := getBody(ctx, u.String(), browserUserAgent, settings.MaxHTMLBytes)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := dom.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	article := dom.QuerySelector(doc, "article")
+	if article == nil {
+		return nil, nil
+	}
+	dom.RemoveNodes(dom.QuerySelectorAll(article, "button, svg, noscript, aside, [role=button], [data-testid=authorPhoto]"), nil)
+
+	title := ""
+	if h1 := dom.QuerySelector(article, "h1"); h1 != nil {
+		title = strings.TrimSpace(dom.TextContent(h1))
+		dom.DetachChild(h1) // shown as the title
+	}
+	if len(strings.TrimSpace(dom.TextContent(article))) < minArticleBytes {
+		return nil, nil
+	}
+	return &FetchResult{
+		Content:     dom.InnerHTML(article),
+		Title:       title,
+		CanIframe:   allowsFraming(header),
+		ContentType: "html",
+		StatusCode:  http.StatusOK,
+	}, nil
+}
+
+// wikipediaExtractor reads articles through the MediaWiki API's plain
+// extracts, which leave out infoboxes, navigation boxes and edit links.
+type wikipediaExtractor struct{}
+
+func (wikipediaExtractor) Name() string { return "wikipedia" }
+
+func (wikipediaExtractor) Match(u *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), ".wikipedia.org") &&
+		strings.HasPrefix(u.Path, "/wiki/") && len(u.Path) > len("/wiki/")
+}
+
+func (wikipediaExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	// The mobile site (en.m.wikipedia.org) has the same API.
+	host := strings.Replace(strings.ToLower(u.Hostname()), ".m.wikipedia.org", ".wikipedia.org", 1)
+	title := strings.TrimPrefix(u.Path, "/wiki/")
+	apiURL := fmt.Sprintf("https://%s/w/api.php?action=query&prop=extracts&redirects=1&format=json&formatversion=2&titles=%s",
+		host, url.QueryEscape(title))
+
+	var resp struct {
+		Query struct {
+			Pages []struct {
+				Title   string `json:"title"`
+				Extract string `json:"extract"`
+				Missing bool   `json:"missing"`
+			} `json:"pages"`
+		} `json:"query"`
+	}
+	if err := getJSON(ctx, apiURL, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Query.Pages) == 0 || resp.Query.Pages[0].Missing || resp.Query.Pages[0].Extract == "" {
+		return nil, nil
+	}
+	page := resp.Query.Pages[0]
+	return &FetchResult{Content: page.Extract, Title: page.Title, CanIframe: true, ContentType: "html", StatusCode: http.StatusOK}, nil
+}
+
+// stackExchangeExtractor reads a question and its top answers from the
+// Stack Exchange API, for Stack Overflow and its sibling sites.
+type stackExchangeExtractor struct{}
+
+// stackExchangeAnswers is how many answers, highest voted first, are kept.
+const stackExchangeAnswers = 5
+
+func (stackExchangeExtractor) Name() string { return "stackexchange" }
+
+func (stackExchangeExtractor) Match(u *url.URL) bool {
+	parts := pathParts(u)
+	return stackExchangeSite(u) != "" && len(parts) >= 2 && (parts[0] == "questions" || parts[0] == "q")
+}
+
+// stackExchangeSite returns the API's site parameter for u's host, or "".
+func stackExchangeSite(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch host {
+	case "stackoverflow.com", "serverfault.com", "superuser.com", "askubuntu.com", "mathoverflow.net":
+		return strings.TrimSuffix(strings.TrimSuffix(host, ".com"), ".net")
+	}
+	if site, ok := strings.CutSuffix(host, ".stackexchange.com"); ok && site != "api" && !strings.Contains(site, ".") {
+		return site
+	}
+	return ""
+}
+
+func (stackExchangeExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	site, id := stackExchangeSite(u), pathParts(u)[1]
+	type post struct {
+		Title      string `json:"title"`
+		Body       string `json:"body"`
+		Score      int    `json:"score"`
+		IsAccepted bool   `json:"is_accepted"`
+	}
+	var question, answers struct {
+		Items []post `json:"items"`
+	}
+	base := "https://api.stackexchange.com/2.3/questions/" + url.PathEscape(id)
+	if err := getJSON(ctx, base+"?filter=withbody&site="+url.QueryEscape(site), &question); err != nil {
+		return nil, err
+	}
+	if len(question.Items) == 0 {
+		return nil, nil
+	}
+	answersURL := fmt.Sprintf("%s/answers?filter=withbody&sort=votes&order=desc&pagesize=%d&site=%s", base, stackExchangeAnswers, url.QueryEscape(site))
+	if err := getJSON(ctx, answersURL, &answers); err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(question.Items[0].Body)
+	for _, a := range answers.Items {
+		label := fmt.Sprintf("Answer (%d votes)", a.Score)
+		if a.IsAccepted {
+			label = fmt.Sprintf("Accepted answer (%d votes)", a.Score)
+		}
+		fmt.Fprintf(&sb, "<h2>%s</h2>%s", label, a.Body)
+	}
+	return &FetchResult{
+		Content:     sb.String(),
+		Title:       html.UnescapeString(question.Items[0].Title), // the API escapes titles
+		ContentType: "html",
+		StatusCode:  http.StatusOK,
+	}, nil
+}
+
+// redditExtractor reads a post and its top comments from Reddit's JSON
+// view of the thread.
+type redditExtractor struct{}
+
+// redditComments is how many top-level comments, top voted first, are kept.
+const redditComments = 10
+
+func (redditExtractor) Name() string { return "reddit" }
+
+func (redditExtractor) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	parts := pathParts(u)
+	return (host == "reddit.com" || strings.HasSuffix(host, ".reddit.com")) &&
+		len(parts) >= 4 && parts[0] == "r" && parts[2] == "comments"
+}
+
+func (redditExtractor) Extract(ctx context.Context, u *url.URL) (*FetchResult, error) {
+	type thing struct {
+		Kind string `json:"kind"`
+		Data struct {
+			Title        string `json:"title"`
+			SelftextHTML string `json:"selftext_html"`
+			URL          string `json:"url"`
+			IsSelf       bool   `json:"is_self"`
+			Author       string `json:"author"`
+			Score        int    `json:"score"`
+			BodyHTML     string `json:"body_html"`
+		} `json:"data"`
+	}
+	var listings []struct {
+		Data struct {
+			Children []thing `json:"children"`
+		} `json:"data"`
+	}
+	jsonURL := fmt.Sprintf("https://www.reddit.com/%s.json?raw_json=1&sort=top&limit=%d", strings.Join(pathParts(u), "/"), redditComments)
+	if err := getJSON(ctx, jsonURL, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 || len(listings[0].Data.Children) == 0 {
+		return nil, nil
+	}
+	post := listings[0].Data.Children[0].Data
+
+	var sb strings.Builder
+	sb.WriteString(post.SelftextHTML)
+	if !post.IsSelf && post.URL != "" {
+		fmt.Fprintf(&sb, `<p>Link: <a href="%s">%s</a></p>`, html.EscapeString(post.URL), html.EscapeString(post.URL))
+	}
+	if len(listings) > 1 {
+		n := 0
+		for _, c := range listings[1].Data.Children {
+			if c.Kind != "t1" || c.Data.BodyHTML == "" || n == redditComments {
+				continue
+			}
+			if n == 0 {
+				sb.WriteString("<h2>Top comments</h2>")
+			}
+			fmt.Fprintf(&sb, "<blockquote><p><b>%s</b> (%d points)</p>%s</blockquote>", html.EscapeString(c.Data.Author), c.Data.Score, c.Data.BodyHTML)
+			n++
+		}
+	}
+	return &FetchResult{Content: sb.String(), Title: post.Title, ContentType: "html", StatusCode: http.StatusOK}, nil
+}
@@ -0,0 +1,105 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+)
+
+// waybackAPI finds the latest Internet Archive snapshot of a URL.
+const waybackAPI = "https://archive.org/wayback/available"
+
+// minArticleBytes is the least extracted content worth summarizing; less
+// usually means a paywall teaser or a page built by JavaScript.
+const minArticleBytes = 100
+
+// needsArchive reports whether a fetched article is unusable in a way an
+// archived copy can fix: a bot challenge, a paywall or login wall, a page
+// that's gone, or next to no content.
+func needsArchive(res *FetchResult) bool {
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden,
+		http.StatusNotFound, http.StatusGone, http.StatusUnavailableForLegalReasons:
+		return true
+	}
+	if res.Blocked {
+		return true
+	}
+	return res.StatusCode < 400 && res.ContentType != "pdf" && len(res.Content) < minArticleBytes
+}
+
+// fetchArchived fetches the latest Wayback Machine snapshot of urlStr. It
+// returns nil if there's none or it's no better than the original.
+func fetchArchived(ctx context.Context, urlStr string) (*FetchResult, error) {
+	snapshot, archivedAt, err := waybackSnapshot(ctx, urlStr)
+	if err != nil || snapshot == "" {
+		return nil, err
+	}
+	res, err := fetchArticle(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 || needsArchive(res) {
+		return nil, nil
+	}
+	res.CanIframe = false // the original is what would be framed, and it's unusable
+	res.Archived = true
+	res.ArchiveURL = snapshot
+	res.ArchivedAt = archivedAt
+	return res, nil
+}
+
+// waybackSnapshot returns the raw-content URL of the latest successful
+// snapshot of urlStr and when it was taken, or "" if there's none.
+func waybackSnapshot(ctx context.Context, urlStr string) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, waybackAPI+"?url="+url.QueryEscape(urlStr), nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	resp, err := httpclient.New(httpclient.Wayback).Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("wayback lookup: HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		ArchivedSnapshots struct {
+			Closest *struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+				Timestamp string `json:"timestamp"`
+				Status    string `json:"status"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("wayback lookup: %w", err)
+	}
+	closest := body.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.Status != "200" || closest.Timestamp == "" {
+		return "", time.Time{}, nil
+	}
+	archivedAt, err := time.Parse("20060102150405", closest.Timestamp)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("wayback lookup: bad timestamp %q", closest.Timestamp)
+	}
+
+	// The "id_" form serves the page as archived, without the Wayback
+	// Machine's banner and rewritten links.
+	marker := "/web/" + closest.Timestamp + "/"
+	i := strings.Index(closest.URL, marker)
+	if i < 0 {
+		return "", time.Time{}, fmt.Errorf("wayback lookup: unexpected snapshot URL %q", closest.URL)
+	}
+	original := closest.URL[i+len(marker):]
+	return "https://web.archive.org/web/" + closest.Timestamp + "id_/" + original, archivedAt, nil
+}
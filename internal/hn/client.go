@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+	"strconv"
+
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
 )
 
 const (
@@ -42,14 +45,43 @@ type Item struct {
 
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient: httpclient.New(httpclient.HN),
 	}
 }
 
-func (c *Client) GetTopStories(ctx context.Context) ([]int, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/topstories.json", BaseURL), nil)
+// HN story lists, by the name used in HN Station's API and config.
+const (
+	FeedTop  = "top"
+	FeedNew  = "new"
+	FeedBest = "best"
+	FeedAsk  = "ask"
+	FeedShow = "show"
+	FeedJobs = "jobs"
+)
+
+// feedPaths maps each feed to its Firebase endpoint.
+var feedPaths = map[string]string{
+	FeedTop:  "topstories",
+	FeedNew:  "newstories",
+	FeedBest: "beststories",
+	FeedAsk:  "askstories",
+	FeedShow: "showstories",
+	FeedJobs: "jobstories",
+}
+
+// IsFeed reports whether name is a supported story list.
+func IsFeed(name string) bool {
+	_, ok := feedPaths[name]
+	return ok
+}
+
+// GetFeed returns the item IDs of a story list, in HN's order.
+func (c *Client) GetFeed(ctx context.Context, feed string) ([]int, error) {
+	path, ok := feedPaths[feed]
+	if !ok {
+		return nil, fmt.Errorf("unknown feed %q", feed)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s.json", BaseURL, path), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +104,24 @@ func (c *Client) GetTopStories(ctx context.Context) ([]int, error) {
 	return ids, nil
 }
 
+func (c *Client) GetTopStories(ctx context.Context) ([]int, error) {
+	return c.GetFeed(ctx, FeedTop)
+}
+
 func (c *Client) GetNewStories(ctx context.Context) ([]int, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/newstories.json", BaseURL), nil)
+	return c.GetFeed(ctx, FeedNew)
+}
+
+// Updates lists the items and profiles that changed on HN in the last few
+// minutes, as reported by the updates endpoint.
+type Updates struct {
+	Items    []int    `json:"items"`
+	Profiles []string `json:"profiles"`
+}
+
+// GetUpdates returns recently changed items and profiles.
+func (c *Client) GetUpdates(ctx context.Context) (*Updates, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/updates.json", BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -88,12 +136,12 @@ func (c *Client) GetNewStories(ctx context.Context) ([]int, error) {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	var ids []int
-	if err := json.NewDecoder(resp.Body).Decode(&ids); err != nil {
+	var updates Updates
+	if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
 		return nil, err
 	}
 
-	return ids, nil
+	return &updates, nil
 }
 
 func (c *Client) GetItem(ctx context.Context, id int) (*Item, error) {
@@ -143,3 +191,73 @@ func (c *Client) GetUser(ctx context.Context, username string) (*UserItem, error
 
 	return &item, nil
 }
+
+// AlgoliaURL is the HN Search API, used for lookups the Firebase API can't do.
+const AlgoliaURL = "https://hn.algolia.com/api/v1"
+
+// Submission is a story returned by HN Search.
+type Submission struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Score       int    `json:"score"`
+	By          string `json:"by"`
+	Descendants int    `json:"descendants"`
+	Time        int64  `json:"time"`
+}
+
+// SearchByURL returns the HN submissions of a URL known to HN Search.
+func (c *Client) SearchByURL(ctx context.Context, pageURL string) ([]Submission, error) {
+	q := url.Values{}
+	q.Set("query", pageURL)
+	q.Set("restrictSearchableAttributes", "url")
+	q.Set("tags", "story")
+	q.Set("hitsPerPage", "20")
+	req, err := http.NewRequestWithContext(ctx, "GET", AlgoliaURL+"/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Hits []struct {
+			ObjectID    string `json:"objectID"`
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Points      int    `json:"points"`
+			Author      string `json:"author"`
+			NumComments int    `json:"num_comments"`
+			CreatedAtI  int64  `json:"created_at_i"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	subs := make([]Submission, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		id, err := strconv.Atoi(h.ObjectID)
+		if err != nil {
+			continue
+		}
+		subs = append(subs, Submission{
+			ID:          id,
+			Title:       h.Title,
+			URL:         h.URL,
+			Score:       h.Points,
+			By:          h.Author,
+			Descendants: h.NumComments,
+			Time:        h.CreatedAtI,
+		})
+	}
+	return subs, nil
+}
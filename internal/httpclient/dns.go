@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxDNSEntries bounds the cache; article fetches reach arbitrary hosts.
+const maxDNSEntries = 1000
+
+// dnsCache remembers host lookups for ttl, so the many requests ingest makes
+// to the same few hosts don't each wait on the resolver.
+type dnsCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+
+	mu    sync.Mutex
+	hosts map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{ttl: ttl, resolver: net.DefaultResolver, hosts: make(map[string]dnsEntry)}
+}
+
+// lookup returns host's addresses, from the cache while they're fresh.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.hosts[host]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.hosts) >= maxDNSEntries {
+		for h, e := range c.hosts {
+			if now.After(e.expires) {
+				delete(c.hosts, h)
+			}
+		}
+	}
+	if len(c.hosts) < maxDNSEntries {
+		c.hosts[host] = dnsEntry{addrs: addrs, expires: now.Add(c.ttl)}
+	}
+	return addrs, nil
+}
+
+// forget drops host, e.g. when none of its cached addresses answer.
+func (c *dnsCache) forget(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.hosts, host)
+}
+
+// dialContext dials through dialer, resolving host names with the cache and
+// trying each address in turn.
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		c.forget(host)
+		return nil, lastErr
+	}
+}
@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrBlockedAddress is returned when an untrusted client is asked to connect
+// to a loopback, private, link-local or otherwise internal address.
+var ErrBlockedAddress = errors.New("address not allowed")
+
+// ErrBlockedScheme is returned for URLs that aren't http or https.
+var ErrBlockedScheme = errors.New("URL scheme not allowed")
+
+// allowPrivate reports whether http.allow_private_networks lifts the
+// address checks, e.g. to summarize pages served on a development machine.
+// Schemes are checked regardless.
+func allowPrivate() bool {
+	return settings.AllowPrivateNetworks
+}
+
+// blockedPrefixes are internal ranges the netip predicates don't cover.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT; Alibaba Cloud's metadata service is 100.100.100.200
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("fec0::/10"),      // deprecated site-local
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("100::/64"),       // discard-only
+}
+
+// IsBlockedAddr reports whether addr is one an untrusted client mustn't
+// reach: loopback, RFC 1918 and unique-local, link-local (including the
+// 169.254.169.254 and fd00:ec2::254 metadata services), multicast,
+// unspecified and reserved ranges.
+func IsBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL rejects URLs an untrusted client mustn't fetch: schemes other
+// than http and https, and hosts given as blocked IP addresses. Host names
+// are checked when they're dialed, against the addresses they resolve to.
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %q", ErrBlockedScheme, u.Scheme)
+	}
+	if allowPrivate() {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && IsBlockedAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// maxRedirects matches net/http's default limit.
+const maxRedirects = 10
+
+// checkRedirect applies CheckURL to every redirect an untrusted client
+// follows.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if err := CheckURL(req.URL); err != nil {
+		return fmt.Errorf("redirect to %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// guardDial refuses connections to blocked addresses. It runs as the
+// dialer's Control hook, after name resolution, so a host name that
+// resolves (or rebinds) to an internal address is caught too.
+func guardDial(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if IsBlockedAddr(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ap.Addr())
+	}
+	return nil
+}
+
+// guardDialer wraps dialer so that it refuses blocked addresses.
+func guardDialer(dialer *net.Dialer) *net.Dialer {
+	guarded := *dialer
+	guarded.Control = guardDial
+	return &guarded
+}
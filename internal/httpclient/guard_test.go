@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"127.8.9.10", true},
+		{"::1", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"172.31.255.255", true},
+		{"192.168.1.1", true},
+		{"fd00::1", true},
+		{"169.254.169.254", true}, // cloud metadata
+		{"fd00:ec2::254", true},   // AWS metadata over IPv6
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true}, // IPv4-mapped IPv6
+		{"::ffff:10.1.2.3", true},
+		{"::ffff:169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"100.64.0.1", true}, // CGNAT
+		{"100.100.100.200", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"100.128.0.1", false},
+		{"2606:4700:4700::1111", false},
+		{"::ffff:1.1.1.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.blocked, IsBlockedAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+	assert.True(t, IsBlockedAddr(netip.Addr{}), "the zero Addr")
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		err error
+	}{
+		{"https://example.com/a", nil},
+		{"http://93.184.216.34/", nil},
+		{"http://localhost:8080/", nil}, // host names are checked when dialed
+		{"ftp://example.com/", ErrBlockedScheme},
+		{"file:///etc/passwd", ErrBlockedScheme},
+		{"gopher://example.com/", ErrBlockedScheme},
+		{"http://127.0.0.1/", ErrBlockedAddress},
+		{"http://[::1]:8080/", ErrBlockedAddress},
+		{"http://10.0.0.5/", ErrBlockedAddress},
+		{"http://169.254.169.254/latest/meta-data/", ErrBlockedAddress},
+		{"http://[::ffff:192.168.0.1]/", ErrBlockedAddress},
+		{"http://0.0.0.0:6379/", ErrBlockedAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			err = CheckURL(u)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := func(raw string) *http.Request {
+		r, err := http.NewRequest("GET", raw, nil)
+		require.NoError(t, err)
+		return r
+	}
+	via := []*http.Request{req("https://example.com/")}
+
+	assert.NoError(t, checkRedirect(req("https://example.org/next"), via))
+	assert.ErrorIs(t, checkRedirect(req("http://127.0.0.1/admin"), via), ErrBlockedAddress)
+	assert.ErrorIs(t, checkRedirect(req("http://169.254.169.254/"), via), ErrBlockedAddress)
+	assert.ErrorIs(t, checkRedirect(req("file:///etc/passwd"), via), ErrBlockedScheme)
+
+	hops := make([]*http.Request, maxRedirects)
+	for i := range hops {
+		hops[i] = req("https://example.com/")
+	}
+	err := checkRedirect(req("https://example.org/"), hops)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after")
+}
+
+// TestGuardedClient checks that the dial guard stops an untrusted client
+// from connecting to a local server, while trusted clients still can.
+func TestGuardedClient(t *testing.T) {
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer local.Close()
+
+	_, err := New(Fetcher).Get(local.URL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBlockedAddress), "got %v", err)
+
+	// A trusted client reaches it.
+	resp, err := New(Notify).Get(local.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
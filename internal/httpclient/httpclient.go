@@ -0,0 +1,140 @@
+// Package httpclient builds the outbound HTTP clients. Every client shares
+// one pooled transport with cached DNS lookups, and requests are counted per
+// client.
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+)
+
+// Client names. Each has its own timeout and metrics.
+const (
+	HN      = "hn"      // the HN Firebase API
+	Fetcher = "fetcher" // article pages
+	GitHub  = "github"  // READMEs from raw.githubusercontent.com
+	Wayback = "wayback" // the Internet Archive's snapshot lookup
+	Ollama  = "ollama"
+	OpenAI  = "openai"
+	OAuth   = "oauth" // token exchange and userinfo
+	Notify  = "notify"
+	// UserWebhook delivers to webhook URLs users entered themselves.
+	UserWebhook = "user_webhook"
+)
+
+// timeouts bound each client's requests, body included.
+var timeouts = map[string]time.Duration{
+	HN:      10 * time.Second,
+	Fetcher: 30 * time.Second,
+	GitHub:  10 * time.Second,
+	Wayback: 10 * time.Second,
+	Ollama:  30 * time.Minute, // generation on a CPU can take minutes
+	OpenAI:  10 * time.Minute,
+	OAuth:   10 * time.Second,
+	Notify:  15 * time.Second,
+
+	UserWebhook: 15 * time.Second,
+}
+
+// defaultTimeout applies to names without their own.
+const defaultTimeout = 30 * time.Second
+
+// untrusted clients fetch URLs taken from HN or users. They only speak http
+// and https and can't reach internal addresses (see IsBlockedAddr), even
+// through redirects or DNS.
+var untrusted = map[string]bool{
+	Fetcher:     true,
+	UserWebhook: true,
+}
+
+// settings configure the transports; see Configure.
+var settings = config.Default().HTTP
+
+// Configure sets the transports' settings. It must be called before the
+// first client is made, as main does once the configuration is loaded.
+func Configure(cfg config.HTTP) {
+	settings = cfg
+}
+
+// NewTransport builds a pooled transport from cfg.
+func NewTransport(cfg config.HTTP) *http.Transport {
+	return newTransport(cfg, false)
+}
+
+// newTransport builds a pooled transport from cfg. A guarded transport
+// refuses to dial internal addresses and ignores proxy settings, since a
+// proxy would resolve hosts where the guard can't check them.
+func newTransport(cfg config.HTTP, guarded bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	proxy := http.ProxyFromEnvironment
+	if guarded {
+		dialer = guardDialer(dialer)
+		proxy = nil
+	}
+	t := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if cfg.DNSCacheTTL > 0 {
+		t.DialContext = newDNSCache(cfg.DNSCacheTTL).dialContext(dialer)
+	}
+	return t
+}
+
+// shared is the transport behind every trusted client, built on first use
+// so that it sees the settings passed to Configure.
+var shared = sync.OnceValue(func() *http.Transport {
+	return NewTransport(settings)
+})
+
+// guarded is the transport behind untrusted clients.
+var guarded = sync.OnceValue(func() *http.Transport {
+	if allowPrivate() {
+		return shared()
+	}
+	return newTransport(settings, true)
+})
+
+// New returns the client called name, on the shared transport, or the
+// guarded one if the client is untrusted.
+func New(name string) *http.Client {
+	timeout, ok := timeouts[name]
+	if !ok {
+		timeout = defaultTimeout
+	}
+	c := &http.Client{
+		Timeout:   timeout,
+		Transport: &instrumented{name: name, stats: statsFor(name), transport: shared},
+	}
+	if untrusted[name] {
+		c.Transport = &instrumented{name: name, stats: statsFor(name), transport: guarded}
+		c.CheckRedirect = checkRedirect
+	}
+	return c
+}
+
+// instrumented counts a client's requests on its transport.
+type instrumented struct {
+	name      string
+	stats     *counters
+	transport func() *http.Transport
+}
+
+func (t *instrumented) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	t.stats.inFlight.Add(1)
+	resp, err := t.transport().RoundTrip(req)
+	t.stats.inFlight.Add(-1)
+	t.stats.record(resp, err, time.Since(start))
+	return resp, err
+}
@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are a client's request counts since the process started.
+type Stats struct {
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"` // no response: timeouts, refused connections
+	ServerErrors int64   `json:"server_errors"`
+	InFlight     int64   `json:"in_flight"`
+	AvgLatencyMS float64 `json:"avg_latency_ms"` // until response headers
+}
+
+type counters struct {
+	requests, errors, serverErrors, inFlight atomic.Int64
+	latency                                  atomic.Int64 // total, in microseconds
+}
+
+func (c *counters) record(resp *http.Response, err error, d time.Duration) {
+	c.requests.Add(1)
+	c.latency.Add(d.Microseconds())
+	switch {
+	case err != nil:
+		c.errors.Add(1)
+	case resp.StatusCode >= 500:
+		c.serverErrors.Add(1)
+	}
+}
+
+var metrics sync.Map // client name -> *counters
+
+func statsFor(name string) *counters {
+	c, _ := metrics.LoadOrStore(name, &counters{})
+	return c.(*counters)
+}
+
+// Snapshot returns every client's stats, by name.
+func Snapshot() map[string]Stats {
+	out := make(map[string]Stats)
+	metrics.Range(func(k, v any) bool {
+		c := v.(*counters)
+		s := Stats{
+			Requests:     c.requests.Load(),
+			Errors:       c.errors.Load(),
+			ServerErrors: c.serverErrors.Load(),
+			InFlight:     c.inFlight.Load(),
+		}
+		if s.Requests > 0 {
+			s.AvgLatencyMS = float64(c.latency.Load()) / float64(s.Requests) / 1000
+		}
+		out[k.(string)] = s
+		return true
+	})
+	return out
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Alert names, as sent in the alert payload.
+const (
+	AlertSummaryFailures = "summary_failure_rate"
+	AlertIngestStale     = "ingest_stale"
+)
+
+// Alerter checks the pipeline against thresholds and alerts the admins
+// through the outbox when it looks broken. It delivers its own alerts, so
+// that they go out even when the ingest service (which runs the main
+// dispatcher) is the thing that's down.
+type Alerter struct {
+	store      *storage.Store
+	dispatcher *Dispatcher
+	// recipients are the admin channel; empty means the admins' emails.
+	recipients []storage.OutboxMessage
+
+	Interval time.Duration // between checks
+	Repeat   time.Duration // an alert that keeps firing is resent this often
+
+	FailureRate   float64       // percent of summary jobs given up on; 0 disables the check
+	FailureWindow time.Duration // the jobs finished in this window are counted
+	MinJobs       int           // below this many jobs the rate means little
+	IngestStale   time.Duration // since the last completed ingest run; 0 disables the check
+}
+
+// NewAlerterFromConfig configures alerts from cfg.Alerts, with its senders
+// set up as in NewDispatcherFromConfig. It returns nil if every check is
+// disabled.
+func NewAlerterFromConfig(store *storage.Store, cfg config.Notify) *Alerter {
+	a := &Alerter{
+		store:         store,
+		dispatcher:    NewDispatcherFromConfig(store, cfg),
+		Interval:      cfg.Alerts.Interval,
+		Repeat:        cfg.Alerts.Repeat,
+		FailureRate:   cfg.Alerts.FailurePct,
+		FailureWindow: cfg.Alerts.FailureWindow,
+		MinJobs:       10,
+		IngestStale:   cfg.Alerts.IngestStale,
+	}
+	if a.FailureRate <= 0 && a.IngestStale <= 0 {
+		return nil
+	}
+	a.dispatcher.Events = []string{storage.EventAlert}
+	if u := cfg.Alerts.WebhookURL; u != "" {
+		a.recipients = append(a.recipients, storage.OutboxMessage{Channel: ChannelWebhook, Recipient: u})
+	}
+	for _, e := range cfg.Alerts.Emails {
+		a.recipients = append(a.recipients, storage.OutboxMessage{Channel: ChannelEmail, Recipient: e})
+	}
+	return a
+}
+
+// Run checks the thresholds and delivers alerts until ctx is cancelled.
+func (a *Alerter) Run(ctx context.Context) {
+	go a.dispatcher.Run(ctx)
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+	for {
+		a.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs every enabled check once, queueing alerts for those failing.
+func (a *Alerter) Check(ctx context.Context) {
+	if a.FailureRate > 0 {
+		done, failed, err := a.store.SummaryJobOutcomes(ctx, time.Now().Add(-a.FailureWindow))
+		if err != nil {
+			log.Printf("Alerts: %v", err)
+		} else if total := done + failed; total >= a.MinJobs && float64(failed)*100 > a.FailureRate*float64(total) {
+			a.fire(ctx, AlertSummaryFailures,
+				fmt.Sprintf("HN Station: %d%% of summaries failing", failed*100/total),
+				fmt.Sprintf("%d of the %d summary jobs finished in the last %v failed for good (threshold %g%%). Check the AI provider and the failures in the admin stats.", failed, total, a.FailureWindow, a.FailureRate))
+		}
+	}
+
+	if a.IngestStale > 0 {
+		last, err := a.store.LastIngestSuccess(ctx)
+		switch {
+		case err != nil:
+			log.Printf("Alerts: failed to look up the last ingest run: %v", err)
+		case last.IsZero():
+			// Ingest has never completed a run; nothing to compare against.
+		case time.Since(last) > a.IngestStale:
+			a.fire(ctx, AlertIngestStale,
+				"HN Station: ingest hasn't completed since "+last.UTC().Format("2006-01-02 15:04 UTC"),
+				fmt.Sprintf("The last ingestion run that completed finished %v ago (threshold %v). Check that the ingest service is running and can reach HN and the database.", time.Since(last).Round(time.Minute), a.IngestStale))
+		}
+	}
+}
+
+// fire queues an alert for each recipient. Deliveries are deduplicated per
+// Repeat period, so repeated checks (or several API servers) send it once.
+func (a *Alerter) fire(ctx context.Context, name, subject, body string) {
+	recipients := a.recipients
+	if len(recipients) == 0 {
+		emails, err := a.store.GetAdminEmails(ctx)
+		if err != nil {
+			log.Printf("Alerts: failed to look up admin emails: %v", err)
+			return
+		}
+		for _, e := range emails {
+			recipients = append(recipients, storage.OutboxMessage{Channel: ChannelEmail, Recipient: e})
+		}
+	}
+	if len(recipients) == 0 {
+		log.Printf("Alerts: %s fired with nobody to send it to: %s", name, subject)
+		return
+	}
+
+	payload, err := json.Marshal(map[string]string{"alert": name, "subject": subject, "body": body})
+	if err != nil {
+		log.Printf("Alerts: failed to marshal %s: %v", name, err)
+		return
+	}
+	period := time.Now().Truncate(a.Repeat).Unix()
+	for _, r := range recipients {
+		msg := storage.OutboxMessage{
+			EventType: storage.EventAlert,
+			Channel:   r.Channel,
+			Recipient: r.Recipient,
+			Payload:   payload,
+			DedupKey:  fmt.Sprintf("%s:%s:%s:%s:%d", storage.EventAlert, name, r.Channel, r.Recipient, period),
+		}
+		if err := a.store.EnqueueNotification(ctx, msg); err != nil {
+			log.Printf("Alerts: failed to queue %s for %s: %v", name, r.Recipient, err)
+		}
+	}
+	log.Printf("Alerts: %s: %s", name, subject)
+}
@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Delivery channels.
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelPush    = "push"
+)
+
+// Sender delivers a single outbox message over one channel.
+// Implementations must be safe to call again for the same message ID.
+type Sender interface {
+	Send(ctx context.Context, msg storage.OutboxMessage) error
+}
+
+// Resolver fans a broadcast event out into per-channel deliveries.
+// Returning no deliveries completes the event without sending anything.
+type Resolver func(ctx context.Context, event storage.OutboxMessage) ([]storage.OutboxMessage, error)
+
+// Dispatcher drains the notification outbox, fanning out broadcast events and
+// delivering the resulting messages with exponential-backoff retries.
+type Dispatcher struct {
+	store        *storage.Store
+	senders      map[string]Sender
+	resolver     Resolver
+	Events       []string // event types to deliver; nil for all
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// NewDispatcher creates a Dispatcher with the default retry policy.
+func NewDispatcher(store *storage.Store, resolver Resolver) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		senders:      make(map[string]Sender),
+		resolver:     resolver,
+		PollInterval: 5 * time.Second,
+		BatchSize:    50,
+		Lease:        2 * time.Minute,
+		MaxAttempts:  8,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   1 * time.Hour,
+	}
+}
+
+// Register attaches a Sender for a channel. Deliveries for channels without a
+// sender are parked as dead rather than retried forever.
+func (d *Dispatcher) Register(channel string, sender Sender) {
+	d.senders[channel] = sender
+}
+
+// Channels returns the names of the registered channels.
+func (d *Dispatcher) Channels() []string {
+	channels := make([]string, 0, len(d.senders))
+	for c := range d.senders {
+		channels = append(channels, c)
+	}
+	return channels
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log.Printf("Notification dispatcher started (channels: %s)", strings.Join(d.Channels(), ", "))
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything that is due before sleeping again.
+		for {
+			n, err := d.RunOnce(ctx)
+			if err != nil {
+				log.Printf("Notification dispatcher: %v", err)
+				break
+			}
+			if n < d.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Notification dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and processes a single batch, returning how many messages it claimed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	msgs, err := d.store.ClaimOutboxBatch(ctx, d.BatchSize, d.Lease, d.Events)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox batch: %w", err)
+	}
+
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			// Unprocessed rows keep their lease and are reclaimed after it expires.
+			return len(msgs), ctx.Err()
+		}
+		if msg.Channel == "" {
+			d.expand(ctx, msg)
+		} else {
+			d.deliver(ctx, msg)
+		}
+	}
+	return len(msgs), nil
+}
+
+func (d *Dispatcher) expand(ctx context.Context, event storage.OutboxMessage) {
+	var deliveries []storage.OutboxMessage
+	if d.resolver != nil {
+		var err error
+		deliveries, err = d.resolver(ctx, event)
+		if err != nil {
+			d.fail(ctx, event, fmt.Errorf("resolve recipients: %w", err))
+			return
+		}
+	}
+	for i := range deliveries {
+		deliveries[i].EventType = event.EventType
+		if len(deliveries[i].Payload) == 0 {
+			deliveries[i].Payload = event.Payload
+		}
+	}
+	if err := d.store.ExpandOutboxEvent(ctx, event.ID, deliveries); err != nil {
+		d.fail(ctx, event, fmt.Errorf("expand event: %w", err))
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg storage.OutboxMessage) {
+	if msg.UserID != "" {
+		prefs, err := d.store.GetNotificationPreferences(ctx, msg.UserID)
+		if err != nil {
+			log.Printf("Notification dispatcher: failed to load preferences for message %d: %v", msg.ID, err)
+		} else if until, quiet := prefs.QuietUntil(time.Now()); quiet {
+			if err := d.store.DeferOutbox(ctx, msg.ID, until); err != nil {
+				log.Printf("Notification dispatcher: failed to defer message %d: %v", msg.ID, err)
+			}
+			return
+		}
+	}
+
+	sender, ok := d.senders[msg.Channel]
+	if !ok {
+		if err := d.store.MarkOutboxFailed(ctx, msg.ID, "no sender registered for channel "+msg.Channel, time.Now(), true); err != nil {
+			log.Printf("Notification dispatcher: failed to park message %d: %v", msg.ID, err)
+		}
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, d.Lease/2)
+	err := sender.Send(sendCtx, msg)
+	cancel()
+	if err != nil {
+		d.fail(ctx, msg, err)
+		return
+	}
+	if err := d.store.MarkOutboxSent(ctx, msg.ID); err != nil {
+		// The lease will expire and the message will be retried; receivers
+		// dedupe on the delivery ID.
+		log.Printf("Notification dispatcher: failed to mark message %d sent: %v", msg.ID, err)
+	}
+}
+
+func (d *Dispatcher) fail(ctx context.Context, msg storage.OutboxMessage, sendErr error) {
+	dead := msg.Attempts >= d.MaxAttempts
+	retryAt := time.Now().Add(d.backoff(msg.Attempts))
+	if dead {
+		log.Printf("Notification dispatcher: giving up on message %d (%s/%s) after %d attempts: %v", msg.ID, msg.EventType, msg.Channel, msg.Attempts, sendErr)
+	} else {
+		log.Printf("Notification dispatcher: message %d (%s/%s) attempt %d failed, retrying at %s: %v", msg.ID, msg.EventType, msg.Channel, msg.Attempts, retryAt.Format(time.RFC3339), sendErr)
+	}
+	if err := d.store.MarkOutboxFailed(ctx, msg.ID, sendErr.Error(), retryAt, dead); err != nil {
+		log.Printf("Notification dispatcher: failed to record failure for message %d: %v", msg.ID, err)
+	}
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// WebhookBroadcastResolver sends every broadcast event to each instance-level
+// webhook URL (notify.webhook_urls).
+func WebhookBroadcastResolver(urls []string) Resolver {
+	return func(ctx context.Context, event storage.OutboxMessage) ([]storage.OutboxMessage, error) {
+		deliveries := make([]storage.OutboxMessage, 0, len(urls))
+		for _, u := range urls {
+			deliveries = append(deliveries, storage.OutboxMessage{Channel: ChannelWebhook, Recipient: u})
+		}
+		return deliveries, nil
+	}
+}
+
+// NewDispatcherFromConfig wires up the senders cfg configures.
+func NewDispatcherFromConfig(store *storage.Store, cfg config.Notify) *Dispatcher {
+	d := NewDispatcher(store, WebhookBroadcastResolver(cfg.WebhookURLs))
+	trusted := cfg.WebhookURLs
+	if cfg.Alerts.WebhookURL != "" {
+		trusted = append(slices.Clone(trusted), cfg.Alerts.WebhookURL)
+	}
+	d.Register(ChannelWebhook, NewWebhookSender(cfg.WebhookSecret, trusted...))
+	if mailer := NewSMTPSender(cfg.SMTP); mailer != nil {
+		d.Register(ChannelEmail, mailer)
+	}
+	if cfg.PushURL != "" {
+		d.Register(ChannelPush, NewPushSender(cfg.PushURL, cfg.PushToken))
+	}
+	return d
+}
@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/config"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// WebhookSender POSTs a JSON envelope to the recipient URL. The delivery ID is
+// sent so receivers can drop retried duplicates, and the body is HMAC-signed
+// when a secret is configured.
+//
+// Only the operator's own URLs may point at internal addresses; users'
+// webhooks go through the guarded client, like article fetches.
+type WebhookSender struct {
+	client  *http.Client // for the operator's URLs
+	guarded *http.Client // for everyone else's
+	trusted map[string]bool
+	secret  []byte
+}
+
+// NewWebhookSender creates a WebhookSender; an empty secret disables signing.
+// trusted are the operator-configured URLs, which aren't guarded.
+func NewWebhookSender(secret string, trusted ...string) *WebhookSender {
+	s := &WebhookSender{
+		client:  httpclient.New(httpclient.Notify),
+		guarded: httpclient.New(httpclient.UserWebhook),
+		trusted: make(map[string]bool, len(trusted)),
+		secret:  []byte(secret),
+	}
+	for _, u := range trusted {
+		s.trusted[u] = true
+	}
+	return s
+}
+
+type webhookEnvelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, msg storage.OutboxMessage) error {
+	body, err := json.Marshal(webhookEnvelope{ID: msg.ID, Event: msg.EventType, CreatedAt: msg.CreatedAt, Data: msg.Payload})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-HNStation-Event", msg.EventType)
+	req.Header.Set("X-HNStation-Delivery", strconv.FormatInt(msg.ID, 10))
+	if len(s.secret) > 0 {
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		req.Header.Set("X-HNStation-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := s.guarded
+	if s.trusted[msg.Recipient] {
+		client = s.client
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// The body isn't kept: errors are shown to the webhook's owner, and the
+	// URL may not be theirs to read.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailPayload is the payload shape for email deliveries. Messages without a
+// subject fall back to the event type and the raw payload as the body.
+type EmailPayload struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// headerLineBreaks flattens a header value onto one line, so a subject can't
+// end the header early or add headers of its own.
+var headerLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// SMTPSender sends plain-text email through an SMTP relay.
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender returns nil unless cfg's Host and From are set.
+func NewSMTPSender(cfg config.SMTP) *SMTPSender {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return &SMTPSender{addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)), auth: auth, from: cfg.From}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg storage.OutboxMessage) error {
+	var p EmailPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || p.Subject == "" {
+		p = EmailPayload{Subject: "HN Station: " + msg.EventType, Body: string(msg.Payload)}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("From: " + s.from + "\r\n")
+	sb.WriteString("To: " + msg.Recipient + "\r\n")
+	sb.WriteString("Subject: " + headerLineBreaks.Replace(p.Subject) + "\r\n")
+	sb.WriteString(fmt.Sprintf("Message-ID: <outbox-%d@hnstation>\r\n", msg.ID))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(p.Body)
+
+	// net/smtp has no context support; run it in the background and honour ctx.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(s.addr, s.auth, s.from, []string{msg.Recipient}, []byte(sb.String()))
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// PushSender forwards push notifications to a push gateway which owns the
+// device tokens (e.g. an ntfy/Gotify/FCM relay).
+type PushSender struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewPushSender creates a PushSender for the gateway at baseURL.
+func NewPushSender(baseURL, token string) *PushSender {
+	return &PushSender{
+		client:  httpclient.New(httpclient.Notify),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+	}
+}
+
+func (s *PushSender) Send(ctx context.Context, msg storage.OutboxMessage) error {
+	var data struct {
+		Title string `json:"title"`
+	}
+	json.Unmarshal(msg.Payload, &data)
+	if data.Title == "" {
+		data.Title = msg.EventType
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"to":    msg.Recipient,
+		"title": data.Title,
+		"event": msg.EventType,
+		"id":    msg.ID,
+		"data":  msg.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal push body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("push gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
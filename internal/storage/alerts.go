@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// EventAlert is an internal alert sent to the instance's admins, e.g. when
+// too many summaries fail.
+const EventAlert = "alert"
+
+// SummaryJobOutcomes counts the summary jobs that finished, and those given
+// up on, since the given time.
+func (s *Store) SummaryJobOutcomes(ctx context.Context, since time.Time) (done, failed int, err error) {
+	err = s.db.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'done'), COUNT(*) FILTER (WHERE status = 'failed')
+		FROM summary_jobs
+		WHERE status IN ('done', 'failed') AND updated_at >= $1
+	`, since).Scan(&done, &failed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count summary job outcomes: %w", err)
+	}
+	return done, failed, nil
+}
+
+// LastIngestSuccess returns when the last completed ingestion run finished,
+// or the zero time if none has.
+func (s *Store) LastIngestSuccess(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	err := s.db.QueryRow(ctx, `
+		SELECT finished_at FROM ingest_runs
+		WHERE status = 'done' AND finished_at IS NOT NULL
+		ORDER BY finished_at DESC LIMIT 1
+	`).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return t, err
+}
+
+// GetAdminEmails returns the email addresses of the instance's admins.
+func (s *Store) GetAdminEmails(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT email FROM auth_users WHERE is_admin AND email <> '' ORDER BY email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var e string
+		if err := rows.Scan(&e); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+	return emails, rows.Err()
+}
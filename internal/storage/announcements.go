@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Announcement levels.
+const (
+	AnnouncementInfo        = "info"
+	AnnouncementWarning     = "warning"
+	AnnouncementMaintenance = "maintenance"
+)
+
+// Announcement is an admin-managed instance notice shown as a banner while
+// it is active (StartsAt <= now < EndsAt).
+type Announcement struct {
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Level     string     `json:"level"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"` // nil runs until deleted
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+const announcementColumns = `id, title, body, level, starts_at, ends_at, created_at`
+
+func scanAnnouncements(rows pgx.Rows) ([]Announcement, error) {
+	defer rows.Close()
+	var out []Announcement
+	for rows.Next() {
+		var a Announcement
+		if err := rows.Scan(&a.ID, &a.Title, &a.Body, &a.Level, &a.StartsAt, &a.EndsAt, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// CreateAnnouncement stores a new announcement. A zero StartsAt starts it now.
+func (s *Store) CreateAnnouncement(ctx context.Context, createdBy string, a Announcement) (*Announcement, error) {
+	var startsAt *time.Time
+	if !a.StartsAt.IsZero() {
+		startsAt = &a.StartsAt
+	}
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO announcements (title, body, level, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, COALESCE($4, NOW()), $5, NULLIF($6, '')::uuid)
+		RETURNING `+announcementColumns,
+		a.Title, a.Body, a.Level, startsAt, a.EndsAt, createdBy,
+	).Scan(&a.ID, &a.Title, &a.Body, &a.Level, &a.StartsAt, &a.EndsAt, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *Store) DeleteAnnouncement(ctx context.Context, id int64) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAllAnnouncements returns every announcement, including scheduled and
+// expired ones, newest first.
+func (s *Store) GetAllAnnouncements(ctx context.Context) ([]Announcement, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+announcementColumns+` FROM announcements ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAnnouncements(rows)
+}
+
+// GetActiveAnnouncements returns the announcements currently running, newest
+// first. With a userID, ones the user has dismissed are left out.
+func (s *Store) GetActiveAnnouncements(ctx context.Context, userID string) ([]Announcement, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+announcementColumns+` FROM announcements a
+		WHERE a.starts_at <= NOW() AND (a.ends_at IS NULL OR a.ends_at > NOW())
+		AND ($1 = '' OR NOT EXISTS (
+			SELECT 1 FROM announcement_dismissals d WHERE d.announcement_id = a.id AND d.user_id::text = $1
+		))
+		ORDER BY a.starts_at DESC, a.id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAnnouncements(rows)
+}
+
+// DismissAnnouncement hides an announcement for the user. Dismissing twice is
+// a no-op.
+func (s *Store) DismissAnnouncement(ctx context.Context, userID string, id int64) error {
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO announcement_dismissals (user_id, announcement_id)
+		SELECT $1, id FROM announcements WHERE id = $2
+		ON CONFLICT DO NOTHING
+	`, userID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM announcements WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+	}
+	return nil
+}
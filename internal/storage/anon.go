@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UpsertAnonInteraction records an interaction for an anonymous visitor. It
+// mirrors UpsertInteraction.
+func (s *Store) UpsertAnonInteraction(ctx context.Context, anonID string, storyID int, isRead *bool, isSaved *bool, isHidden *bool) error {
+	query := `
+		INSERT INTO anon_interactions (anon_id, story_id, is_read, is_saved, is_hidden, updated_at)
+		VALUES ($1, $2, COALESCE($3, FALSE), COALESCE($4, FALSE), COALESCE($5, FALSE), NOW())
+		ON CONFLICT (anon_id, story_id) DO UPDATE SET
+			is_read = COALESCE($3, anon_interactions.is_read),
+			is_saved = COALESCE($4, anon_interactions.is_saved),
+			is_hidden = COALESCE($5, anon_interactions.is_hidden),
+			updated_at = NOW()
+	`
+	_, err := s.db.Exec(ctx, query, anonID, storyID, isRead, isSaved, isHidden)
+	return err
+}
+
+// ApplyAnonInteractions fills the interaction flags of stories from an
+// anonymous visitor's recorded interactions.
+func (s *Store) ApplyAnonInteractions(ctx context.Context, anonID string, stories []Story) error {
+	if len(stories) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(stories))
+	for i, st := range stories {
+		ids[i] = st.ID
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT story_id, is_read, is_saved, is_hidden FROM anon_interactions WHERE anon_id = $1 AND story_id = ANY($2)`, anonID, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type flags struct{ read, saved, hidden bool }
+	byID := make(map[int64]flags)
+	for rows.Next() {
+		var id int64
+		var f flags
+		if err := rows.Scan(&id, &f.read, &f.saved, &f.hidden); err != nil {
+			return err
+		}
+		byID[id] = f
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range stories {
+		if f, ok := byID[stories[i].ID]; ok {
+			stories[i].IsRead, stories[i].IsSaved, stories[i].IsHidden = &f.read, &f.saved, &f.hidden
+		}
+	}
+	return nil
+}
+
+// MergeAnonInteractions moves an anonymous visitor's interactions onto a user
+// account. Flags are OR-ed with any the user already has, and the anonymous
+// rows are deleted. It returns the number of interactions merged.
+func (s *Store) MergeAnonInteractions(ctx context.Context, anonID, userID string) (int, error) {
+	var merged int
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO user_interactions (user_id, story_id, is_read, is_saved, is_hidden, saved_at, updated_at)
+			SELECT $2, story_id, is_read, is_saved, is_hidden, CASE WHEN is_saved THEN updated_at END, updated_at
+			FROM anon_interactions WHERE anon_id = $1
+			ON CONFLICT (user_id, story_id) DO UPDATE SET
+				is_read = user_interactions.is_read OR EXCLUDED.is_read,
+				is_saved = user_interactions.is_saved OR EXCLUDED.is_saved,
+				is_hidden = user_interactions.is_hidden OR EXCLUDED.is_hidden,
+				saved_at = CASE WHEN user_interactions.is_saved THEN user_interactions.saved_at ELSE EXCLUDED.saved_at END,
+				updated_at = GREATEST(user_interactions.updated_at, EXCLUDED.updated_at)
+		`, anonID, userID)
+		if err != nil {
+			return err
+		}
+		merged = int(tag.RowsAffected())
+		if _, err := tx.Exec(ctx, `DELETE FROM anon_interactions WHERE anon_id = $1`, anonID); err != nil {
+			return err
+		}
+		return recountUserInteractions(ctx, tx, userID)
+	})
+	return merged, err
+}
+
+// PruneAnonInteractions drops anonymous interactions untouched for
+// daysToKeep, and the least recently updated beyond MaxAnonInteractions per
+// visitor.
+func (s *Store) PruneAnonInteractions(ctx context.Context, daysToKeep int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM anon_interactions WHERE updated_at < NOW() - make_interval(days => $1)`, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune anonymous interactions: %w", err)
+	}
+	_, err = s.db.Exec(ctx, `
+		DELETE FROM anon_interactions a
+		USING (
+			SELECT anon_id, story_id, ROW_NUMBER() OVER (PARTITION BY anon_id ORDER BY updated_at DESC) AS n
+			FROM anon_interactions
+		) ranked
+		WHERE a.anon_id = ranked.anon_id AND a.story_id = ranked.story_id AND ranked.n > $1
+	`, MaxAnonInteractions)
+	if err != nil {
+		return fmt.Errorf("failed to cap anonymous interactions: %w", err)
+	}
+	return nil
+}
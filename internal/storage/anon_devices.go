@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MaxAnonDevices caps how many devices can share one anonymous identity.
+	MaxAnonDevices = 5
+	// MaxAnonInteractions caps how many interactions are kept per anonymous
+	// identity; the least recently updated are pruned first.
+	MaxAnonInteractions = 1000
+)
+
+// ErrDeviceLimit is returned when an anonymous identity already has
+// MaxAnonDevices devices.
+var ErrDeviceLimit = fmt.Errorf("at most %d devices can sync without signing in", MaxAnonDevices)
+
+// AnonDevice is a device syncing an anonymous visitor's interactions.
+type AnonDevice struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// RegisterAnonDevice records a device as belonging to an anonymous identity,
+// moving it from any identity it belonged to before, and marks it as seen.
+func (s *Store) RegisterAnonDevice(ctx context.Context, anonID, deviceID, name string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		return registerAnonDevice(ctx, tx, anonID, deviceID, name)
+	})
+}
+
+func registerAnonDevice(ctx context.Context, tx pgx.Tx, anonID, deviceID, name string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO anon_devices (device_id, anon_id, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (device_id) DO UPDATE SET
+			anon_id = EXCLUDED.anon_id,
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), anon_devices.name),
+			last_seen_at = NOW()
+	`, deviceID, anonID, name)
+	if err != nil {
+		return err
+	}
+	var n int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM anon_devices WHERE anon_id = $1`, anonID).Scan(&n); err != nil {
+		return err
+	}
+	if n > MaxAnonDevices {
+		return ErrDeviceLimit
+	}
+	return nil
+}
+
+// GetAnonDevices lists the devices sharing an anonymous identity.
+func (s *Store) GetAnonDevices(ctx context.Context, anonID string) ([]AnonDevice, error) {
+	rows, err := s.db.Query(ctx, `SELECT device_id, name, created_at, last_seen_at FROM anon_devices WHERE anon_id = $1 ORDER BY created_at`, anonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []AnonDevice
+	for rows.Next() {
+		var d AnonDevice
+		if err := rows.Scan(&d.ID, &d.Name, &d.CreatedAt, &d.LastSeenAt); err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// RemoveAnonDevice unregisters one of an anonymous identity's devices.
+func (s *Store) RemoveAnonDevice(ctx context.Context, anonID, deviceID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM anon_devices WHERE device_id = $1 AND anon_id = $2`, deviceID, anonID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// pairingAlphabet leaves out characters that are easy to misread.
+const pairingAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// CreatePairingCode issues a short code another device can redeem with
+// PairAnonDevice to join anonID. Only the code's hash is stored.
+func (s *Store) CreatePairingCode(ctx context.Context, anonID string, ttl time.Duration) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = pairingAlphabet[int(b[i])%len(pairingAlphabet)]
+	}
+	code := string(b)
+
+	query := `INSERT INTO anon_pairing_codes (code_hash, anon_id, expires_at) VALUES ($1, $2, NOW() + make_interval(secs => $3))`
+	if _, err := s.db.Exec(ctx, query, hashToken(code), anonID, ttl.Seconds()); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// PairAnonDevice redeems a pairing code: the device joins the code's
+// anonymous identity, bringing along the interactions and devices of
+// currentAnonID (its identity so far, if any). It returns the identity joined,
+// or ErrInvalidToken for unknown or expired codes.
+func (s *Store) PairAnonDevice(ctx context.Context, code, currentAnonID, deviceID, name string) (string, error) {
+	var anonID string
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `DELETE FROM anon_pairing_codes WHERE code_hash = $1 AND expires_at > NOW() RETURNING anon_id`, hashToken(code)).Scan(&anonID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if currentAnonID != "" && currentAnonID != anonID {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO anon_interactions (anon_id, story_id, is_read, is_saved, is_hidden, updated_at)
+				SELECT $2, story_id, is_read, is_saved, is_hidden, updated_at
+				FROM anon_interactions WHERE anon_id = $1
+				ON CONFLICT (anon_id, story_id) DO UPDATE SET
+					is_read = anon_interactions.is_read OR EXCLUDED.is_read,
+					is_saved = anon_interactions.is_saved OR EXCLUDED.is_saved,
+					is_hidden = anon_interactions.is_hidden OR EXCLUDED.is_hidden,
+					updated_at = GREATEST(anon_interactions.updated_at, EXCLUDED.updated_at)
+			`, currentAnonID, anonID)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM anon_interactions WHERE anon_id = $1`, currentAnonID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `UPDATE anon_devices SET anon_id = $2 WHERE anon_id = $1`, currentAnonID, anonID); err != nil {
+				return err
+			}
+		}
+		return registerAnonDevice(ctx, tx, anonID, deviceID, name)
+	})
+	return anonID, err
+}
+
+// PruneAnonDevices drops devices not seen for daysToKeep and expired pairing
+// codes.
+func (s *Store) PruneAnonDevices(ctx context.Context, daysToKeep int) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM anon_devices WHERE last_seen_at < NOW() - make_interval(days => $1)`, daysToKeep); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(ctx, `DELETE FROM anon_pairing_codes WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// APIKeyPrefix marks HN Station API keys so they can be told apart from
+// session tokens.
+const APIKeyPrefix = "hns_"
+
+// APIKey is a user's API key for the browser extension and other clients.
+// The key itself is only returned once, on creation.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreateAPIKey generates a new API key for the user and returns it together
+// with the raw key. Only the key's hash is stored.
+func (s *Store) CreateAPIKey(ctx context.Context, userID, name string) (*APIKey, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	raw := APIKeyPrefix + hex.EncodeToString(b)
+
+	k := APIKey{Name: name, Prefix: raw[:len(APIKeyPrefix)+6]}
+	query := `
+		INSERT INTO api_keys (user_id, name, key_prefix, key_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	if err := s.db.QueryRow(ctx, query, userID, name, k.Prefix, hashToken(raw)).Scan(&k.ID, &k.CreatedAt); err != nil {
+		return nil, "", err
+	}
+	return &k, raw, nil
+}
+
+func (s *Store) GetAPIKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	query := `SELECT id, name, key_prefix, created_at, last_used_at FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.CreatedAt, &k.LastUsedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s *Store) DeleteAPIKey(ctx context.Context, userID string, keyID int64) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ResolveAPIKey returns the ID of the user owning the raw key and records the
+// key as used. It returns ErrNotFound for unknown keys.
+func (s *Store) ResolveAPIKey(ctx context.Context, raw string) (string, error) {
+	var userID string
+	query := `UPDATE api_keys SET last_used_at = NOW() WHERE key_hash = $1 RETURNING user_id`
+	err := s.db.QueryRow(ctx, query, hashToken(raw)).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return userID, err
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateAuthCode issues a one-time authorization code for a native app
+// sign-in, bound to the app's PKCE code challenge and redirect URI. Only the
+// code's hash is stored.
+func (s *Store) CreateAuthCode(ctx context.Context, userID, challenge, redirectURI string, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+
+	query := `
+		INSERT INTO auth_codes (code_hash, user_id, code_challenge, redirect_uri, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+	`
+	if _, err := s.db.Exec(ctx, query, hashToken(code), userID, challenge, redirectURI, ttl.Seconds()); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// RedeemAuthCode consumes an authorization code issued for redirectURI and
+// returns the user it was issued to and its PKCE code challenge. Codes can be
+// redeemed once; unknown, expired and already redeemed codes return
+// ErrInvalidToken.
+func (s *Store) RedeemAuthCode(ctx context.Context, code, redirectURI string) (string, string, error) {
+	var userID, challenge string
+	query := `
+		DELETE FROM auth_codes
+		WHERE code_hash = $1 AND redirect_uri = $2 AND expires_at > NOW()
+		RETURNING user_id::text, code_challenge
+	`
+	err := s.db.QueryRow(ctx, query, hashToken(code), redirectURI).Scan(&userID, &challenge)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrInvalidToken
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return userID, challenge, nil
+}
+
+// PruneAuthCodes deletes expired authorization codes.
+func (s *Store) PruneAuthCodes(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM auth_codes WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// HiringAccount is the HN account that posts the monthly hiring threads.
+const HiringAccount = "whoishiring"
+
+// GetRecurringThreads returns the tracked threads posted by the whoishiring
+// account since the given time, newest first.
+func (s *Store) GetRecurringThreads(ctx context.Context, since time.Time) ([]Story, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics
+		FROM stories
+		WHERE by = $1 AND posted_at >= $2
+		ORDER BY posted_at DESC
+	`
+	return s.querySummarized(ctx, query, HiringAccount, since)
+}
+
+// GetSavedLaunchThreads returns the "Launch HN" threads the user has saved
+// that were posted since the given time, newest first.
+func (s *Store) GetSavedLaunchThreads(ctx context.Context, userID string, since time.Time) ([]Story, error) {
+	query := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics
+		FROM stories s
+		INNER JOIN user_interactions ui ON ui.story_id = s.id AND ui.user_id = $1 AND ui.is_saved = TRUE
+		WHERE s.title ILIKE 'Launch HN:%' AND s.posted_at >= $2
+		ORDER BY s.posted_at DESC
+	`
+	return s.querySummarized(ctx, query, userID, since)
+}
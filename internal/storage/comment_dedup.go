@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+)
+
+// Duplicate comment detection. Comments are hashed by their normalized text
+// and a text posted on spamMinStories different stories marks every copy
+// as a spam suspect. Short comments ("Thanks!", "+1") repeat innocently and
+// aren't hashed.
+const (
+	spamMinTextLen = 80 // normalized characters
+	spamMinStories = 3
+)
+
+// commentTextHash returns the fingerprint of a comment's text, or nil if
+// it's too short to judge.
+func commentTextHash(text string) *string {
+	hash, length := content.Fingerprint(text)
+	if length < spamMinTextLen {
+		return nil
+	}
+	return &hash
+}
+
+// markSpamSuspects flags every comment sharing one of hashes with comments
+// on at least spamMinStories stories. Flags are never cleared, so an edit
+// doesn't launder a spam comment.
+func markSpamSuspects(ctx context.Context, db execer, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE comments SET spam_suspect = TRUE
+		WHERE NOT spam_suspect AND text_hash IN (
+			SELECT text_hash FROM comments
+			WHERE text_hash = ANY($1)
+			GROUP BY text_hash
+			HAVING COUNT(DISTINCT story_id) >= $2
+		)
+	`
+	if _, err := db.Exec(ctx, query, hashes, spamMinStories); err != nil {
+		return fmt.Errorf("failed to mark duplicate comments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CommentPermalink returns a comment's page on Hacker News.
+func CommentPermalink(id int64) string {
+	return fmt.Sprintf("https://news.ycombinator.com/item?id=%d", id)
+}
+
+// annotateComments fills in the permalink and position metadata of a
+// story's comments, which must be in display order. rootOffset is the
+// position of the first top-level comment.
+func annotateComments(comments []Comment, rootOffset int) {
+	index := make(map[int64]int, len(comments))
+	for i := range comments {
+		index[comments[i].ID] = i
+	}
+	siblings := map[int64]int{0: rootOffset} // parent ID (0 for top level) -> children seen
+	for i := range comments {
+		c := &comments[i]
+		c.Permalink = CommentPermalink(c.ID)
+		var parent int64
+		if c.ParentID != nil {
+			parent = *c.ParentID
+			if p, ok := index[parent]; ok && p < i {
+				c.Depth = comments[p].Depth + 1
+				comments[p].Replies++
+			}
+		}
+		c.Position = siblings[parent]
+		siblings[parent]++
+	}
+}
+
+// CommentPage is a window of a story's top-level comments with all their
+// replies, so threads are never split across pages.
+type CommentPage struct {
+	Comments      []Comment `json:"comments"`
+	TotalThreads  int       `json:"total_threads"`  // top-level comments on the story
+	TotalComments int       `json:"total_comments"` // all comments on the story
+}
+
+// GetCommentsPage returns limit top-level comments, oldest first, starting
+// at offset, along with their replies. A limit of 0 returns every thread.
+func (s *Store) GetCommentsPage(ctx context.Context, storyID, limit, offset int) (*CommentPage, error) {
+	page := &CommentPage{}
+	err := s.db.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE parent_id IS NULL), COUNT(*) FROM comments WHERE story_id = $1
+	`, storyID).Scan(&page.TotalThreads, &page.TotalComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var limitArg *int // NULL means no limit
+	if limit > 0 {
+		limitArg = &limit
+	}
+	page.Comments, err = s.queryComments(ctx, `
+		WITH RECURSIVE roots AS (
+			SELECT id FROM comments
+			WHERE story_id = $1 AND parent_id IS NULL
+			ORDER BY posted_at ASC, id ASC
+			LIMIT $2 OFFSET $3
+		), thread AS (
+			SELECT c.id, c.story_id, c.parent_id, c.text, c.by, c.posted_at, c.spam_suspect FROM comments c JOIN roots ON c.id = roots.id
+			UNION ALL
+			SELECT c.id, c.story_id, c.parent_id, c.text, c.by, c.posted_at, c.spam_suspect FROM comments c JOIN thread t ON c.parent_id = t.id
+		)
+		SELECT id, story_id, parent_id, COALESCE(text, ''), COALESCE(by, ''), posted_at, spam_suspect FROM thread ORDER BY posted_at ASC, id ASC
+	`, storyID, limitArg, offset)
+	if err != nil {
+		return nil, err
+	}
+	annotateComments(page.Comments, offset)
+	return page, nil
+}
+
+// CommentThread is a comment with the chain of comments above it, root
+// first, and its direct replies.
+type CommentThread struct {
+	Comment   Comment   `json:"comment"`
+	Ancestors []Comment `json:"ancestors"`
+	Replies   []Comment `json:"replies"`
+}
+
+// GetCommentThread resolves a bare HN comment ID to its thread context,
+// returning ErrNotFound for comments that haven't been ingested.
+func (s *Store) GetCommentThread(ctx context.Context, id int64) (*CommentThread, error) {
+	var t CommentThread
+	c := &t.Comment
+	err := s.db.QueryRow(ctx, `
+		SELECT c.id, c.story_id, c.parent_id, COALESCE(c.text, ''), COALESCE(c.by, ''), c.posted_at, c.spam_suspect,
+		       (SELECT COUNT(*) FROM comments sib
+		        WHERE sib.story_id = c.story_id AND sib.parent_id IS NOT DISTINCT FROM c.parent_id
+		          AND (sib.posted_at, sib.id) < (c.posted_at, c.id)),
+		       (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id)
+		FROM comments c WHERE c.id = $1
+	`, id).Scan(&c.ID, &c.StoryID, &c.ParentID, &c.Text, &c.By, &c.PostedAt, &c.SpamSuspect, &c.Position, &c.Replies)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := s.queryComments(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT p.*, 1 AS hops FROM comments p WHERE p.id = $1
+			UNION ALL
+			SELECT p.*, chain.hops + 1 FROM comments p JOIN chain ON p.id = chain.parent_id
+		)
+		SELECT id, story_id, parent_id, COALESCE(text, ''), COALESCE(by, ''), posted_at, spam_suspect FROM chain ORDER BY hops DESC
+	`, c.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ancestors {
+		ancestors[i].Depth = i
+		ancestors[i].Permalink = CommentPermalink(ancestors[i].ID)
+	}
+	t.Ancestors = ancestors
+	c.Depth = len(ancestors)
+	c.Permalink = CommentPermalink(c.ID)
+
+	replies, err := s.queryComments(ctx, `
+		SELECT id, story_id, parent_id, COALESCE(text, ''), COALESCE(by, ''), posted_at, spam_suspect FROM comments
+		WHERE parent_id = $1 ORDER BY posted_at ASC, id ASC
+	`, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range replies {
+		replies[i].Depth = c.Depth + 1
+		replies[i].Position = i
+		replies[i].Permalink = CommentPermalink(replies[i].ID)
+	}
+	t.Replies = replies
+	return &t, nil
+}
+
+// queryComments runs a query selecting id, story_id, parent_id, text, by,
+// posted_at and spam_suspect from comments.
+func (s *Store) queryComments(ctx context.Context, query string, args ...interface{}) ([]Comment, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.StoryID, &c.ParentID, &c.Text, &c.By, &c.PostedAt, &c.SpamSuspect); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// GetUserKarma returns the karma of the given HN users that have been
+// ingested.
+func (s *Store) GetUserKarma(ctx context.Context, usernames []string) (map[string]int, error) {
+	karma := make(map[string]int)
+	rows, err := s.db.Query(ctx, `SELECT id, karma FROM users WHERE id = ANY($1)`, usernames)
+	if err != nil {
+		return karma, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var k int
+		if err := rows.Scan(&name, &k); err != nil {
+			return karma, err
+		}
+		karma[name] = k
+	}
+	return karma, rows.Err()
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SettingStaleOnContentChange controls whether a changed article marks its
+// summary stale ("false" turns it off).
+const SettingStaleOnContentChange = "stale_summary_on_content_change"
+
+// ContentVersion is one distinct version of a story's article text.
+type ContentVersion struct {
+	ID            int64  `json:"id"`
+	ContentHash   string `json:"content_hash"`
+	ContentLength int    `json:"content_length"`
+}
+
+// RecordContentVersion compares a freshly fetched article's hash with the
+// latest stored version. An unchanged article only bumps last_seen_at; a
+// changed one is stored as a new version and, if markStale is set and the
+// story has a summary, flags the summary for regeneration. It reports whether
+// the content changed since the previous fetch (the first fetch is not a change).
+func (s *Store) RecordContentVersion(ctx context.Context, storyID int, hash string, length int, markStale bool) (bool, error) {
+	var changed bool
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		var latestID int64
+		var latestHash string
+		err := tx.QueryRow(ctx, `
+			SELECT id, content_hash FROM story_content_versions
+			WHERE story_id = $1 ORDER BY id DESC LIMIT 1 FOR UPDATE
+		`, storyID).Scan(&latestID, &latestHash)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			return err
+		case latestHash == hash:
+			_, err := tx.Exec(ctx, `UPDATE story_content_versions SET last_seen_at = NOW() WHERE id = $1`, latestID)
+			return err
+		default:
+			changed = true
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO story_content_versions (story_id, content_hash, content_length) VALUES ($1, $2, $3)
+		`, storyID, hash, length); err != nil {
+			return err
+		}
+		if changed && markStale {
+			_, err := tx.Exec(ctx, `UPDATE stories SET summary_stale = TRUE WHERE id = $1 AND summary IS NOT NULL AND summary != ''`, storyID)
+			return err
+		}
+		return nil
+	})
+	return changed, err
+}
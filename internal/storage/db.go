@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pgvector "github.com/pgvector/pgvector-go"
+)
+
+// ErrNotFound is returned when a row doesn't exist or isn't owned by the caller.
+var ErrNotFound = errors.New("not found")
+
+// DB is the storage contract the API server depends on. *Store (Postgres)
+// implements it; local mode can provide a lighter-weight implementation.
+type DB interface {
+	// Stories
+	GetStories(ctx context.Context, limit, offset int, sortStrategy string, topics, languages []string, userID string, showHidden bool) ([]Story, int, error)
+	GetStory(ctx context.Context, id int) (*Story, error)
+	GetStoriesByIDs(ctx context.Context, ids []int64) ([]Story, error)
+	GetPopularStories(ctx context.Context, by string, since time.Time, limit int) ([]Story, error)
+	GetHNJobs(ctx context.Context, limit, offset int) ([]Story, int, error)
+	LastIngestSuccess(ctx context.Context) (time.Time, error)
+	GetTitleHistory(ctx context.Context, storyID int) ([]TitleChange, error)
+	FindStoryByURL(ctx context.Context, rawURL string) (*Story, error)
+	GetSubmissionsByURL(ctx context.Context, rawURL string, excludeID int64) ([]Story, error)
+	GetComments(ctx context.Context, storyID int) ([]Comment, error)
+	GetCommentThread(ctx context.Context, id int64) (*CommentThread, error)
+	GetCommentsPage(ctx context.Context, storyID, limit, offset int) (*CommentPage, error)
+	GetUserKarma(ctx context.Context, usernames []string) (map[string]int, error)
+	GetKarmaHistory(ctx context.Context, username string, since time.Time) ([]KarmaSnapshot, error)
+	MuteAuthor(ctx context.Context, userID, username string) error
+	UnmuteAuthor(ctx context.Context, userID, username string) error
+	GetMutedAuthors(ctx context.Context, userID string) ([]MutedAuthor, error)
+	UpsertStory(ctx context.Context, story Story) error
+	UpsertComment(ctx context.Context, comment Comment) error
+	UpsertUser(ctx context.Context, user User) error
+	NotifyHNUserActivity(ctx context.Context, storyID int) error
+	SetStoryFlags(ctx context.Context, id int, pinned, frozen *bool) error
+	ReviewSensitive(ctx context.Context, id int, sensitive bool, reviewerID string) error
+	UpdateStorySummaryAndTopics(ctx context.Context, id int, summary string, topics []string) error
+	UpdateStoryDiscussionSummary(ctx context.Context, id int, summary string, topics []string) error
+	SetSummaryStatus(ctx context.Context, id int, status string) error
+	RecordSummaryFailure(ctx context.Context, id int, code, detail string) error
+	EnqueueDiscussionJob(ctx context.Context, storyID int, userID string) (int64, error)
+	GetSummaryJob(ctx context.Context, id int64) (*SummaryJob, error)
+	ClaimSummaryJob(ctx context.Context, kind string, lease time.Duration) (*SummaryJob, error)
+	MarkSummaryJobDone(ctx context.Context, id int64) error
+	MarkSummaryJobFailed(ctx context.Context, id int64, errMsg string, retryAt time.Time, dead bool) error
+	RecordContentVersion(ctx context.Context, storyID int, hash string, length int, markStale bool) (bool, error)
+	SetArticleText(ctx context.Context, id int, text string) error
+	SearchStories(ctx context.Context, embedding pgvector.Vector, model string, limit, offset int) ([]Story, int, error)
+	SearchKeyword(ctx context.Context, query string, limit int) ([]Story, error)
+	CountStoryEmbeddings(ctx context.Context, since time.Time) (int, error)
+	EachStoryEmbedding(ctx context.Context, since time.Time, fn func(StoryEmbedding) error) error
+	GetWeeklyThemes(ctx context.Context, weekStart time.Time) (time.Time, []Theme, error)
+	GetSummarizedStoriesByTopic(ctx context.Context, topic string, since time.Time, limit int) ([]Story, error)
+	SearchSummarizedStories(ctx context.Context, question string, since time.Time, limit int) ([]Story, error)
+
+	// Users & interactions
+	UpsertAuthUser(ctx context.Context, googleID, email, name, avatarURL string) (*AuthUser, error)
+	GetAuthUser(ctx context.Context, userID string) (*AuthUser, error)
+	ResolveIdentity(ctx context.Context, ident Identity, linkUserID string) (*AuthUser, error)
+	GetIdentities(ctx context.Context, userID string) ([]Identity, error)
+	UnlinkIdentity(ctx context.Context, userID, provider string) error
+	RequestEmailChange(ctx context.Context, userID, newEmail string, ttl time.Duration, render func(token string) (subject, body string)) error
+	ConfirmEmailChange(ctx context.Context, token string) (string, string, error)
+	CreateAPIKey(ctx context.Context, userID, name string) (*APIKey, string, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID string, keyID int64) error
+	ResolveAPIKey(ctx context.Context, raw string) (string, error)
+	CreateAuthCode(ctx context.Context, userID, challenge, redirectURI string, ttl time.Duration) (string, error)
+	RedeemAuthCode(ctx context.Context, code, redirectURI string) (string, string, error)
+	UpdateUserGeminiKey(ctx context.Context, userID, apiKey string) error
+	UpsertInteraction(ctx context.Context, userID string, storyID int, isRead *bool, isSaved *bool, isHidden *bool) error
+	SetInteractionNote(ctx context.Context, userID string, storyID int, note string) error
+	RecordStoryVisit(ctx context.Context, userID string, storyID, descendants int) (StoryVisit, error)
+	GetSavedStories(ctx context.Context, userID, search string, limit, offset int) ([]Story, int, error)
+	GetUnreadSavedStories(ctx context.Context, userID string, limit int) ([]Story, error)
+	SaveLibraryItem(ctx context.Context, userID, url string) (*LibraryItem, error)
+	GetLibraryItems(ctx context.Context, userID string, limit, offset int) ([]LibraryItem, int, error)
+	DeleteLibraryItem(ctx context.Context, userID string, itemID int64) error
+	CompleteLibraryItem(ctx context.Context, itemID int64, title, summary string, topics []string) error
+	FailLibraryItem(ctx context.Context, itemID int64, title, reason string) error
+	UpsertAnonInteraction(ctx context.Context, anonID string, storyID int, isRead *bool, isSaved *bool, isHidden *bool) error
+	ApplyAnonInteractions(ctx context.Context, anonID string, stories []Story) error
+	MergeAnonInteractions(ctx context.Context, anonID, userID string) (int, error)
+	RegisterAnonDevice(ctx context.Context, anonID, deviceID, name string) error
+	GetAnonDevices(ctx context.Context, anonID string) ([]AnonDevice, error)
+	RemoveAnonDevice(ctx context.Context, anonID, deviceID string) error
+	CreatePairingCode(ctx context.Context, anonID string, ttl time.Duration) (string, error)
+	PairAnonDevice(ctx context.Context, code, currentAnonID, deviceID, name string) (string, error)
+
+	// Subscriptions & notifications
+	SubscribeTopic(ctx context.Context, userID, topic string) error
+	UnsubscribeTopic(ctx context.Context, userID, topic string) error
+	GetTopicSubscriptions(ctx context.Context, userID string) ([]TopicSubscription, error)
+	WatchStory(ctx context.Context, userID string, storyID int, scoreThreshold, commentThreshold *int, notifySummary bool) error
+	UnwatchStory(ctx context.Context, userID string, storyID int) error
+	GetStoryWatches(ctx context.Context, userID string) ([]StoryWatch, error)
+	ShareStoryByEmail(ctx context.Context, userID string, storyID int, recipient, subject, body string, limit int, window time.Duration) error
+	GetRecurringThreads(ctx context.Context, since time.Time) ([]Story, error)
+	GetSavedLaunchThreads(ctx context.Context, userID string, since time.Time) ([]Story, error)
+	GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, userID string, p NotificationPreferences) error
+
+	// Social
+	UpdateUserSavesPublic(ctx context.Context, userID string, public bool) error
+	GetGenerationSettings(ctx context.Context, userID string) (GenerationSettings, error)
+	UpdateGenerationSettings(ctx context.Context, userID string, g GenerationSettings) error
+	GetSensitivePreference(ctx context.Context, userID string) (string, error)
+	UpdateSensitivePreference(ctx context.Context, userID, preference string) error
+	RecordAIUsage(ctx context.Context, u AIUsage) error
+	GetUserAIUsage(ctx context.Context, userID string) (*UserUsage, error)
+	FollowUser(ctx context.Context, followerID, followeeID string) error
+	UnfollowUser(ctx context.Context, followerID, followeeID string) error
+	GetFollowing(ctx context.Context, userID string) ([]PublicUser, error)
+	GetFollowingFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error)
+	FollowHNUser(ctx context.Context, userID, username string) error
+	UnfollowHNUser(ctx context.Context, userID, username string) error
+	GetFollowedHNUsers(ctx context.Context, userID string) ([]FollowedHNUser, error)
+	GetHNFollowingFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error)
+	FollowTopics(ctx context.Context, userID string, topics []string) error
+	UnfollowTopic(ctx context.Context, userID, topic string) error
+	GetFollowedTopics(ctx context.Context, userID string) ([]FollowedTopic, error)
+	NeedsOnboarding(ctx context.Context, userID string) (bool, error)
+	SuggestTopics(ctx context.Context, userID string, days, limit int) ([]TopicSuggestion, error)
+	GetTopicsFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error)
+
+	// Local comments
+	AddLocalComment(ctx context.Context, storyID int, userID string, parentID *int64, text string) (*LocalComment, error)
+	GetLocalComments(ctx context.Context, storyID int) ([]LocalComment, error)
+	UpdateLocalComment(ctx context.Context, id int64, userID, text string) error
+	DeleteLocalComment(ctx context.Context, id int64, userID string, isAdmin bool) error
+
+	// Lists
+	CreateList(ctx context.Context, userID, title, description string, isPublic bool, workspaceID *int64) (*List, error)
+	GetUserLists(ctx context.Context, userID string) ([]List, error)
+	GetWorkspaceLists(ctx context.Context, workspaceID int64) ([]List, error)
+	UpdateList(ctx context.Context, userID string, listID int64, title, description *string, isPublic *bool) (*List, error)
+	DeleteList(ctx context.Context, userID string, listID int64) error
+	AddListItem(ctx context.Context, userID string, listID int64, storyID int, note string) error
+	RemoveListItem(ctx context.Context, userID string, listID int64, storyID int) error
+	GetListBySlug(ctx context.Context, slug string) (*List, []ListItem, error)
+
+	// Workspaces
+	CreateWorkspace(ctx context.Context, ownerID, name string) (*Workspace, error)
+	GetUserWorkspaces(ctx context.Context, userID string) ([]Workspace, error)
+	GetWorkspace(ctx context.Context, workspaceID int64, userID string) (*Workspace, error)
+	UpdateWorkspace(ctx context.Context, workspaceID int64, name, geminiKey *string, monthlyQuota *int) error
+	DeleteWorkspace(ctx context.Context, workspaceID int64) error
+	GetWorkspaceMembers(ctx context.Context, workspaceID int64) ([]WorkspaceMember, error)
+	AddWorkspaceMember(ctx context.Context, workspaceID int64, email, role string) (*WorkspaceMember, error)
+	UpdateWorkspaceMemberRole(ctx context.Context, workspaceID int64, userID, role string) error
+	RemoveWorkspaceMember(ctx context.Context, workspaceID int64, userID string) error
+	GetWorkspaceGeminiKey(ctx context.Context, userID string) (int64, string, error)
+	CheckWorkspaceQuota(ctx context.Context, workspaceID int64) error
+	ConsumeWorkspaceQuota(ctx context.Context, workspaceID int64) error
+	GetWorkspaceNote(ctx context.Context, workspaceID int64, storyID int) (*WorkspaceNote, error)
+	SetWorkspaceNote(ctx context.Context, workspaceID int64, storyID int, userID, note string) error
+
+	// Chat
+	SaveChatMessage(ctx context.Context, userID string, storyID int, role, content string) error
+
+	// Admin
+	GetAppStats(ctx context.Context) (*AppStats, error)
+	GetAllUsers(ctx context.Context) ([]*AuthUser, error)
+	MergeUsers(ctx context.Context, sourceID, targetID string) error
+	GetSummaryQueueEvents(ctx context.Context, limit int) ([]SummaryQueueEvent, error)
+	GetRejectedTopics(ctx context.Context, days, limit int) ([]RejectedTopic, error)
+	GetMetricHistory(ctx context.Context, days int) ([]MetricSnapshot, error)
+	GetSensitiveStories(ctx context.Context, source string, flagged *bool, limit int) ([]SensitiveStory, error)
+
+	// Announcements
+	CreateAnnouncement(ctx context.Context, createdBy string, a Announcement) (*Announcement, error)
+	DeleteAnnouncement(ctx context.Context, id int64) error
+	GetAllAnnouncements(ctx context.Context) ([]Announcement, error)
+	GetActiveAnnouncements(ctx context.Context, userID string) ([]Announcement, error)
+	DismissAnnouncement(ctx context.Context, userID string, id int64) error
+
+	// Settings
+	GetSetting(ctx context.Context, key string) (string, error)
+	SetSetting(ctx context.Context, key, value string) error
+}
+
+var _ DB = (*Store)(nil)
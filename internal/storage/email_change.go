@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Outbox event type for email verification messages.
+const EventEmailVerify = "email.verify"
+
+var (
+	// ErrInvalidToken is returned for unknown or expired verification tokens.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrEmailTaken is returned when another account already uses the email.
+	ErrEmailTaken = errors.New("email is already in use")
+)
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestEmailChange stores a verification token for changing the user's
+// email (replacing any pending request) and enqueues the verification email
+// built by render from the raw token. Only the token's hash is stored.
+func (s *Store) RequestEmailChange(ctx context.Context, userID, newEmail string, ttl time.Duration, render func(token string) (subject, body string)) error {
+	var taken bool
+	if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM auth_users WHERE lower(email) = lower($1) AND id <> $2)`, newEmail, userID).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return ErrEmailTaken
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b)
+	hash := hashToken(token)
+
+	subject, body := render(token)
+	evt, err := newEvent(EventEmailVerify, map[string]string{"subject": subject, "body": body})
+	if err != nil {
+		return err
+	}
+	evt.Channel = "email"
+	evt.Recipient = newEmail
+	evt.DedupKey = EventEmailVerify + ":" + hash
+
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM email_change_requests WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO email_change_requests (token_hash, user_id, new_email, expires_at)
+			VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		`, hash, userID, newEmail, ttl.Seconds()); err != nil {
+			return err
+		}
+		return insertOutbox(ctx, tx, evt)
+	})
+}
+
+// ConfirmEmailChange applies the email change for a valid token and returns
+// the user ID and new email.
+func (s *Store) ConfirmEmailChange(ctx context.Context, token string) (string, string, error) {
+	var userID, newEmail string
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			DELETE FROM email_change_requests WHERE token_hash = $1 AND expires_at > NOW()
+			RETURNING user_id, new_email
+		`, hashToken(token)).Scan(&userID, &newEmail)
+		if err == pgx.ErrNoRows {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE auth_users SET email = $2 WHERE id = $1`, userID, newEmail)
+		if isUniqueViolation(err) {
+			return ErrEmailTaken
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return userID, newEmail, nil
+}
+
+// PruneEmailChangeRequests deletes expired verification tokens.
+func (s *Store) PruneEmailChangeRequests(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM email_change_requests WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pgvector "github.com/pgvector/pgvector-go"
+)
+
+// EmbeddingDims is the size of the stories.embedding vector column.
+const EmbeddingDims = 768
+
+// embeddingInputSQL is the text a story's embedding is computed from: its
+// title, summary and the start of its article (or post body).
+const embeddingInputSQL = `
+	title || E'\n\n' || coalesce(summary, '') || E'\n\n' ||
+	left(coalesce(nullif(article_text, ''), text), 2000)`
+
+// EmbeddingInput is a story's text to embed, with the hash that
+// SetStoryEmbedding records to tell when it changes.
+type EmbeddingInput struct {
+	ID   int
+	Text string
+	Hash string
+}
+
+// StoriesNeedingEmbedding returns up to limit stories with no embedding from
+// model, or one computed from text that has since changed, unembedded stories
+// first, then newest first. Stories being summarized wait until their summary
+// is saved.
+func (s *Store) StoriesNeedingEmbedding(ctx context.Context, model string, limit int) ([]EmbeddingInput, error) {
+	query := `
+		SELECT id, input, md5(input)
+		FROM (
+			SELECT id, posted_at, embedding IS NULL AS missing, embedding_model, embedding_hash,
+			       ` + embeddingInputSQL + ` AS input
+			FROM stories
+			WHERE summary_status NOT IN ($3, $4)
+		) s
+		WHERE missing OR embedding_model IS DISTINCT FROM $1 OR embedding_hash IS DISTINCT FROM md5(input)
+		ORDER BY missing DESC, posted_at DESC
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, model, limit, SummaryFetching, SummaryGenerating)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []EmbeddingInput
+	for rows.Next() {
+		var in EmbeddingInput
+		if err := rows.Scan(&in.ID, &in.Text, &in.Hash); err != nil {
+			return nil, err
+		}
+		out = append(out, in)
+	}
+	return out, rows.Err()
+}
+
+// SetStoryEmbedding stores a story's embedding along with the model and the
+// input hash it was computed from.
+func (s *Store) SetStoryEmbedding(ctx context.Context, id int, vec []float32, model, hash string) error {
+	if len(vec) != EmbeddingDims {
+		return fmt.Errorf("embedding has %d dimensions, want %d", len(vec), EmbeddingDims)
+	}
+	_, err := s.db.Exec(ctx, `
+		UPDATE stories SET embedding = $2, embedding_model = $3, embedding_hash = $4, embedded_at = NOW()
+		WHERE id = $1
+	`, id, pgvector.NewVector(vec), model, hash)
+	return err
+}
+
+// StoryEmbedding is a story's embedding with the metadata needed to label it
+// outside the app.
+type StoryEmbedding struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Score     int       `json:"score"`
+	PostedAt  time.Time `json:"time"`
+	Topics    []string  `json:"topics"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// CountStoryEmbeddings returns how many stories posted since the given time
+// have an embedding.
+func (s *Store) CountStoryEmbeddings(ctx context.Context, since time.Time) (int, error) {
+	var n int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM stories WHERE embedding IS NOT NULL AND posted_at >= $1`, since).Scan(&n)
+	return n, err
+}
+
+// EachStoryEmbedding calls fn for every story posted since the given time
+// that has an embedding, in ID order, without loading them all at once.
+func (s *Store) EachStoryEmbedding(ctx context.Context, since time.Time, fn func(StoryEmbedding) error) error {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, posted_at, COALESCE(topics, '{}'), embedding
+		FROM stories
+		WHERE embedding IS NOT NULL AND posted_at >= $1
+		ORDER BY id ASC
+	`
+	rows, err := s.db.Query(ctx, query, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e StoryEmbedding
+		var vec pgvector.Vector
+		if err := rows.Scan(&e.ID, &e.Title, &e.URL, &e.Score, &e.PostedAt, &e.Topics, &vec); err != nil {
+			return err
+		}
+		e.Embedding = vec.Slice()
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
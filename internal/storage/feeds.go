@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// rankedFeeds are the HN lists besides the front page whose order is stored
+// in story_feed_ranks; GetStories accepts each as a sort strategy.
+var rankedFeeds = map[string]bool{
+	"new":  true,
+	"best": true,
+	"ask":  true,
+	"show": true,
+	"jobs": true,
+}
+
+// IsRankedFeed reports whether feed is one of the stored HN lists.
+func IsRankedFeed(feed string) bool {
+	return rankedFeeds[feed]
+}
+
+// SetFeedRanks replaces a feed's ranking with ids, in order. IDs of stories
+// that haven't been ingested are skipped.
+func (s *Store) SetFeedRanks(ctx context.Context, feed string, ids []int) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM story_feed_ranks WHERE feed = $1`, feed); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO story_feed_ranks (feed, story_id, rank)
+			SELECT $1, t.id, t.rank FROM unnest($2::bigint[]) WITH ORDINALITY AS t(id, rank)
+			WHERE EXISTS (SELECT 1 FROM stories WHERE id = t.id)
+			ON CONFLICT DO NOTHING
+		`, feed, ids)
+		return err
+	})
+}
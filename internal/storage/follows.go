@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PublicUser is the subset of a user's profile shown to other users.
+type PublicUser struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	AvatarURL   string    `json:"avatar_url"`
+	SavesPublic bool      `json:"saves_public"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+func (s *Store) UpdateUserSavesPublic(ctx context.Context, userID string, public bool) error {
+	_, err := s.db.Exec(ctx, `UPDATE auth_users SET saves_public = $1 WHERE id = $2`, public, userID)
+	return err
+}
+
+// FollowUser makes followerID follow followeeID. It returns ErrNotFound if
+// the followee doesn't exist.
+func (s *Store) FollowUser(ctx context.Context, followerID, followeeID string) error {
+	query := `INSERT INTO user_follows (follower_id, followee_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := s.db.Exec(ctx, query, followerID, followeeID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "23503" || pgErr.Code == "22P02") {
+		// foreign_key_violation / invalid UUID text
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *Store) UnfollowUser(ctx context.Context, followerID, followeeID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	return err
+}
+
+func (s *Store) GetFollowing(ctx context.Context, userID string) ([]PublicUser, error) {
+	query := `
+		SELECT u.id, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), u.saves_public, f.created_at
+		FROM user_follows f
+		INNER JOIN auth_users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []PublicUser
+	for rows.Next() {
+		var u PublicUser
+		if err := rows.Scan(&u.ID, &u.Name, &u.AvatarURL, &u.SavesPublic, &u.FollowedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// GetFollowingFeed returns stories saved by the users userID follows, limited
+// to followees who made their saves public, most recently saved first. Stories
+// the viewer has hidden are left out.
+func (s *Store) GetFollowingFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error) {
+	fromClause := `
+		FROM user_follows f
+		INNER JOIN auth_users u ON u.id = f.followee_id AND u.saves_public = TRUE
+		INNER JOIN user_interactions fui ON fui.user_id = f.followee_id AND fui.is_saved = TRUE
+		INNER JOIN stories s ON s.id = fui.story_id
+		LEFT JOIN user_interactions ui ON ui.story_id = s.id AND ui.user_id = $1
+		WHERE f.follower_id = $1 AND (ui.is_hidden IS NULL OR ui.is_hidden = FALSE)
+	`
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(DISTINCT s.id) `+fromClause, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics,
+		       ui.is_read, ui.is_saved, ui.is_hidden, array_agg(DISTINCT u.name) FILTER (WHERE u.name IS NOT NULL)
+	` + fromClause + `
+		GROUP BY s.id, ui.is_read, ui.is_saved, ui.is_hidden
+		ORDER BY MAX(fui.updated_at) DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.IsRead, &story.IsSaved, &story.IsHidden, &story.SavedBy); err != nil {
+			return nil, 0, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, total, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+)
+
+// Settings for the summary freshness policy.
+const (
+	SettingSummaryRefreshGrowthPct = "summary_refresh_growth_pct"
+	SettingSummaryRefreshMax       = "summary_refresh_max"
+)
+
+// DefaultSummaryRefreshMax caps regenerations when summary_refresh_max is unset.
+const DefaultSummaryRefreshMax = 3
+
+// FreshnessPolicy decides when a summary is regenerated because the
+// discussion kept growing after it was written.
+type FreshnessPolicy struct {
+	GrowthPct        int // comment growth since the summary that triggers a refresh; 0 disables
+	MaxRegenerations int // per-story cap
+}
+
+// GetFreshnessPolicy reads the policy from the settings table.
+func (s *Store) GetFreshnessPolicy(ctx context.Context) (FreshnessPolicy, error) {
+	p := FreshnessPolicy{MaxRegenerations: DefaultSummaryRefreshMax}
+	growth, err := s.GetSetting(ctx, SettingSummaryRefreshGrowthPct)
+	if err != nil {
+		return p, err
+	}
+	if n, err := strconv.Atoi(growth); err == nil && n > 0 {
+		p.GrowthPct = n
+	}
+	max, err := s.GetSetting(ctx, SettingSummaryRefreshMax)
+	if err != nil {
+		return p, err
+	}
+	if n, err := strconv.Atoi(max); err == nil && n >= 0 {
+		p.MaxRegenerations = n
+	}
+	return p, nil
+}
+
+// NeedsRefresh reports whether the story's summary should be regenerated
+// under the policy.
+func (p FreshnessPolicy) NeedsRefresh(st *Story) bool {
+	if p.GrowthPct <= 0 || st.Summary == nil || *st.Summary == "" || st.SummaryDescendants == nil {
+		return false
+	}
+	if st.SummaryRegenerations >= p.MaxRegenerations {
+		return false
+	}
+	base := *st.SummaryDescendants
+	if base < 1 {
+		base = 1
+	}
+	return st.Descendants*100 >= base*(100+p.GrowthPct)
+}
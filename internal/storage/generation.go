@@ -0,0 +1,28 @@
+package storage
+
+import "context"
+
+// GenerationSettings are a user's model generation knobs. Nil fields keep
+// the provider's default.
+type GenerationSettings struct {
+	Temperature *float64 `json:"temperature"`
+	MaxTokens   *int     `json:"max_tokens"`
+	NumCtx      *int     `json:"num_ctx"` // Ollama only
+}
+
+// GetGenerationSettings returns the user's generation settings.
+func (s *Store) GetGenerationSettings(ctx context.Context, userID string) (GenerationSettings, error) {
+	var g GenerationSettings
+	err := s.db.QueryRow(ctx, `
+		SELECT gen_temperature, gen_max_tokens, gen_num_ctx FROM auth_users WHERE id = $1
+	`, userID).Scan(&g.Temperature, &g.MaxTokens, &g.NumCtx)
+	return g, err
+}
+
+// UpdateGenerationSettings replaces the user's generation settings.
+func (s *Store) UpdateGenerationSettings(ctx context.Context, userID string, g GenerationSettings) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE auth_users SET gen_temperature = $2, gen_max_tokens = $3, gen_num_ctx = $4 WHERE id = $1
+	`, userID, g.Temperature, g.MaxTokens, g.NumCtx)
+	return err
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// EventHNUserActivity notifies a user that an HN user they follow submitted
+// or commented on an ingested story.
+const EventHNUserActivity = "hn_user.activity"
+
+// FollowedHNUser is an HN username an app user follows.
+type FollowedHNUser struct {
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (s *Store) FollowHNUser(ctx context.Context, userID, username string) error {
+	_, err := s.db.Exec(ctx, `INSERT INTO followed_hn_users (user_id, username) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, username)
+	return err
+}
+
+func (s *Store) UnfollowHNUser(ctx context.Context, userID, username string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM followed_hn_users WHERE user_id = $1 AND username = $2`, userID, username)
+	return err
+}
+
+// GetFollowedHNUsers returns the HN users the user follows, most recent first.
+func (s *Store) GetFollowedHNUsers(ctx context.Context, userID string) ([]FollowedHNUser, error) {
+	rows, err := s.db.Query(ctx, `SELECT username, created_at FROM followed_hn_users WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []FollowedHNUser
+	for rows.Next() {
+		var u FollowedHNUser
+		if err := rows.Scan(&u.Username, &u.FollowedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// GetHNFollowingFeed returns stories submitted or commented on by the HN users
+// the user follows, most recent activity first. Story.HNUsers lists who.
+func (s *Store) GetHNFollowingFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error) {
+	fromClause := `
+		FROM (
+			SELECT s.id AS story_id, s.by AS username, s.posted_at AS at
+			FROM stories s INNER JOIN followed_hn_users f ON f.username = s.by AND f.user_id = $1
+			UNION ALL
+			SELECT c.story_id, c.by, c.posted_at
+			FROM comments c INNER JOIN followed_hn_users f ON f.username = c.by AND f.user_id = $1
+		) a
+		INNER JOIN stories s ON s.id = a.story_id
+		LEFT JOIN user_interactions ui ON ui.story_id = s.id AND ui.user_id = $1
+		WHERE (ui.is_hidden IS NULL OR ui.is_hidden = FALSE)
+	`
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(DISTINCT s.id) `+fromClause, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics,
+		       ui.is_read, ui.is_saved, ui.is_hidden, array_agg(DISTINCT a.username)
+	` + fromClause + `
+		GROUP BY s.id, ui.is_read, ui.is_saved, ui.is_hidden
+		ORDER BY MAX(a.at) DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.IsRead, &story.IsSaved, &story.IsHidden, &story.HNUsers); err != nil {
+			return nil, 0, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, total, rows.Err()
+}
+
+// NotifyHNUserActivity queues notifications for activity on a story by
+// followed HN users: the submission itself and any comments, posted after the
+// follow began. Deliveries are deduplicated per user, item and channel, so it
+// is safe to call on every ingestion pass.
+func (s *Store) NotifyHNUserActivity(ctx context.Context, storyID int) error {
+	query := `
+		WITH activity AS (
+			SELECT id AS item_id, by AS username, posted_at, 'story' AS kind FROM stories WHERE id = $1
+			UNION ALL
+			SELECT id, by, posted_at, 'comment' FROM comments WHERE story_id = $1
+		)
+		SELECT a.item_id, a.username, a.kind, st.title, f.user_id::text, u.email,
+		       COALESCE(np.channels, '{email}'), COALESCE(np.webhook_url, ''), COALESCE(np.push_token, '')
+		FROM activity a
+		INNER JOIN followed_hn_users f ON f.username = a.username AND a.posted_at >= f.created_at
+		INNER JOIN auth_users u ON u.id = f.user_id
+		INNER JOIN stories st ON st.id = $1
+		LEFT JOIN notification_preferences np ON np.user_id = f.user_id
+	`
+	rows, err := s.db.Query(ctx, query, storyID)
+	if err != nil {
+		return err
+	}
+	var deliveries []OutboxMessage
+	for rows.Next() {
+		var itemID int64
+		var username, kind, title, userID, email, webhookURL, pushToken string
+		var channels []string
+		if err := rows.Scan(&itemID, &username, &kind, &title, &userID, &email, &channels, &webhookURL, &pushToken); err != nil {
+			rows.Close()
+			return err
+		}
+
+		verb := "commented on"
+		if kind == "story" {
+			verb = "submitted"
+		}
+		subject := fmt.Sprintf("%s %s %q", username, verb, title)
+		link := CommentPermalink(itemID)
+		evt, err := newEvent(EventHNUserActivity, map[string]interface{}{
+			"subject":  subject,
+			"title":    subject,
+			"body":     subject + "\n\n" + link,
+			"username": username,
+			"kind":     kind,
+			"item_id":  itemID,
+			"story_id": storyID,
+			"url":      link,
+		})
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		evt.UserID = userID
+
+		for _, channel := range channels {
+			d := evt
+			d.Channel = channel
+			switch channel {
+			case "email":
+				d.Recipient = email
+			case "webhook":
+				d.Recipient = webhookURL
+			case "push":
+				d.Recipient = pushToken
+			}
+			if d.Recipient == "" {
+				continue
+			}
+			d.DedupKey = fmt.Sprintf("%s:%s:%d:%s", EventHNUserActivity, userID, itemID, channel)
+			deliveries = append(deliveries, d)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		for _, d := range deliveries {
+			if err := insertOutbox(ctx, tx, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
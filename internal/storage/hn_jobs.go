@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// HN item types stored in stories.item_type.
+const (
+	ItemStory = "story"
+	ItemJob   = "job" // a YC company's job post; no score to speak of and no comments
+)
+
+// GetHNJobs lists ingested job posts, newest first.
+func (s *Store) GetHNJobs(ctx context.Context, limit, offset int) ([]Story, int, error) {
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM stories WHERE item_type = 'job'`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count job posts: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, COALESCE(url, ''), text, score, COALESCE(by, ''), posted_at, created_at, summary, topics, item_type
+		FROM stories
+		WHERE item_type = 'job'
+		ORDER BY posted_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list job posts: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Story
+	for rows.Next() {
+		var st Story
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.Text, &st.Score, &st.By, &st.PostedAt, &st.CreatedAt, &st.Summary, &st.Topics, &st.Type); err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, st)
+	}
+	return jobs, total, rows.Err()
+}
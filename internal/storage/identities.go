@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrIdentityInUse is returned when linking a provider identity that
+	// already belongs to another account.
+	ErrIdentityInUse = errors.New("identity is linked to another account")
+	// ErrLastIdentity is returned when unlinking would leave an account with
+	// no way to sign in.
+	ErrLastIdentity = errors.New("cannot unlink the only login identity")
+)
+
+// Identity is a provider login (e.g. "google", "github") attached to a user.
+type Identity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"`
+	Email     string    `json:"email"`
+	Name      string    `json:"-"`
+	AvatarURL string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const authUserColumns = `id, COALESCE(google_id, ''), email, COALESCE(name, ''), COALESCE(avatar_url, ''), is_admin, COALESCE(gemini_api_key, ''), saves_public, created_at`
+
+func scanAuthUser(row pgx.Row) (*AuthUser, error) {
+	var u AuthUser
+	if err := row.Scan(&u.ID, &u.GoogleID, &u.Email, &u.Name, &u.AvatarURL, &u.IsAdmin, &u.GeminiAPIKey, &u.SavesPublic, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ResolveIdentity signs in with a provider identity and returns its account:
+//   - a known identity returns its user (profile name/avatar refreshed);
+//   - otherwise, if linkUserID is set, the identity is linked to that user;
+//   - otherwise it is linked to an existing account with the same email;
+//   - otherwise a new account is created.
+//
+// Providers must only pass verified emails.
+func (s *Store) ResolveIdentity(ctx context.Context, ident Identity, linkUserID string) (*AuthUser, error) {
+	var user *AuthUser
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		var userID string
+		err := tx.QueryRow(ctx, `SELECT user_id FROM auth_identities WHERE provider = $1 AND subject = $2`, ident.Provider, ident.Subject).Scan(&userID)
+		switch {
+		case err == nil:
+			if linkUserID != "" && linkUserID != userID {
+				return ErrIdentityInUse
+			}
+			// The provider's current email is recorded on the identity only;
+			// the account email changes through the verified email-change flow.
+			if _, err := tx.Exec(ctx, `UPDATE auth_identities SET email = $3 WHERE provider = $1 AND subject = $2`, ident.Provider, ident.Subject, ident.Email); err != nil {
+				return err
+			}
+			u, err := scanAuthUser(tx.QueryRow(ctx, `
+				UPDATE auth_users SET name = COALESCE(NULLIF($2, ''), name), avatar_url = COALESCE(NULLIF($3, ''), avatar_url)
+				WHERE id = $1 RETURNING `+authUserColumns, userID, ident.Name, ident.AvatarURL))
+			user = u
+			return err
+		case err != pgx.ErrNoRows:
+			return err
+		}
+
+		if linkUserID != "" {
+			userID = linkUserID
+		} else {
+			err := tx.QueryRow(ctx, `SELECT id FROM auth_users WHERE lower(email) = lower($1)`, ident.Email).Scan(&userID)
+			if err == pgx.ErrNoRows {
+				err = tx.QueryRow(ctx, `INSERT INTO auth_users (email, name, avatar_url) VALUES ($1, $2, $3) RETURNING id`,
+					ident.Email, ident.Name, ident.AvatarURL).Scan(&userID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `INSERT INTO auth_identities (provider, subject, user_id, email) VALUES ($1, $2, $3, $4)`,
+			ident.Provider, ident.Subject, userID, ident.Email); err != nil {
+			return err
+		}
+		// Keep the legacy google_id column in sync for Google logins.
+		if ident.Provider == "google" {
+			if _, err := tx.Exec(ctx, `UPDATE auth_users SET google_id = $2 WHERE id = $1 AND google_id IS NULL`, userID, ident.Subject); err != nil {
+				return err
+			}
+		}
+
+		u, err := scanAuthUser(tx.QueryRow(ctx, `SELECT `+authUserColumns+` FROM auth_users WHERE id = $1`, userID))
+		user = u
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *Store) GetIdentities(ctx context.Context, userID string) ([]Identity, error) {
+	rows, err := s.db.Query(ctx, `SELECT provider, subject, email, created_at FROM auth_identities WHERE user_id = $1 ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var idents []Identity
+	for rows.Next() {
+		var id Identity
+		if err := rows.Scan(&id.Provider, &id.Subject, &id.Email, &id.CreatedAt); err != nil {
+			return nil, err
+		}
+		idents = append(idents, id)
+	}
+	return idents, nil
+}
+
+// UnlinkIdentity removes a provider login from the user. It refuses to remove
+// the last one.
+func (s *Store) UnlinkIdentity(ctx context.Context, userID, provider string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		var count int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM auth_identities WHERE user_id = $1`, userID).Scan(&count); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM auth_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		if int(tag.RowsAffected()) >= count {
+			return ErrLastIdentity
+		}
+		if provider == "google" {
+			_, err = tx.Exec(ctx, `UPDATE auth_users SET google_id = NULL WHERE id = $1`, userID)
+		}
+		return err
+	})
+}
+
+// MergeUsers folds sourceID into targetID: interactions, chats, subscriptions,
+// lists, comments, follows, workspace memberships, announcement dismissals and
+// login identities move to the target, settings the target lacks are copied,
+// and the source account is deleted.
+func (s *Store) MergeUsers(ctx context.Context, sourceID, targetID string) error {
+	if sourceID == targetID {
+		return errors.New("cannot merge a user into itself")
+	}
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		var exists int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM auth_users WHERE id IN ($1, $2)`, sourceID, targetID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists != 2 {
+			return ErrNotFound
+		}
+
+		stmts := []string{
+			`INSERT INTO user_interactions (user_id, story_id, is_read, is_saved, is_hidden, saved_at, note, last_seen_at, seen_descendants, updated_at)
+			 SELECT $2, story_id, is_read, is_saved, is_hidden, saved_at, note, last_seen_at, seen_descendants, updated_at FROM user_interactions WHERE user_id = $1
+			 ON CONFLICT (user_id, story_id) DO UPDATE SET
+				is_read = user_interactions.is_read OR EXCLUDED.is_read,
+				is_saved = user_interactions.is_saved OR EXCLUDED.is_saved,
+				is_hidden = user_interactions.is_hidden OR EXCLUDED.is_hidden,
+				saved_at = CASE WHEN user_interactions.is_saved THEN user_interactions.saved_at ELSE EXCLUDED.saved_at END,
+				note = CASE WHEN user_interactions.note = '' THEN EXCLUDED.note ELSE user_interactions.note END,
+				last_seen_at = GREATEST(user_interactions.last_seen_at, EXCLUDED.last_seen_at),
+				seen_descendants = GREATEST(user_interactions.seen_descendants, EXCLUDED.seen_descendants),
+				updated_at = GREATEST(user_interactions.updated_at, EXCLUDED.updated_at)`,
+			`UPDATE chat_messages SET user_id = $2 WHERE user_id = $1`,
+			`INSERT INTO topic_subscriptions (user_id, topic, last_digest_at, created_at)
+			 SELECT $2, topic, last_digest_at, created_at FROM topic_subscriptions WHERE user_id = $1
+			 ON CONFLICT (user_id, topic) DO NOTHING`,
+			`UPDATE lists SET user_id = $2 WHERE user_id = $1`,
+			`UPDATE local_comments SET user_id = $2 WHERE user_id = $1`,
+			`UPDATE workspace_notes SET updated_by = $2 WHERE updated_by = $1`,
+			`INSERT INTO user_follows (follower_id, followee_id, created_at)
+			 SELECT $2, followee_id, created_at FROM user_follows WHERE follower_id = $1 AND followee_id <> $2
+			 ON CONFLICT DO NOTHING`,
+			`INSERT INTO user_follows (follower_id, followee_id, created_at)
+			 SELECT follower_id, $2, created_at FROM user_follows WHERE followee_id = $1 AND follower_id <> $2
+			 ON CONFLICT DO NOTHING`,
+			`INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+			 SELECT workspace_id, $2, role, joined_at FROM workspace_members WHERE user_id = $1
+			 ON CONFLICT (workspace_id, user_id) DO NOTHING`,
+			`INSERT INTO followed_hn_users (user_id, username, created_at)
+			 SELECT $2, username, created_at FROM followed_hn_users WHERE user_id = $1
+			 ON CONFLICT DO NOTHING`,
+			`INSERT INTO followed_topics (user_id, topic, created_at)
+			 SELECT $2, topic, created_at FROM followed_topics WHERE user_id = $1
+			 ON CONFLICT DO NOTHING`,
+			`INSERT INTO user_muted_authors (user_id, username, created_at)
+			 SELECT $2, username, created_at FROM user_muted_authors WHERE user_id = $1
+			 ON CONFLICT DO NOTHING`,
+			`INSERT INTO announcement_dismissals (user_id, announcement_id, dismissed_at)
+			 SELECT $2, announcement_id, dismissed_at FROM announcement_dismissals WHERE user_id = $1
+			 ON CONFLICT DO NOTHING`,
+			`UPDATE auth_identities SET user_id = $2 WHERE user_id = $1`,
+		}
+		for _, q := range stmts {
+			if _, err := tx.Exec(ctx, q, sourceID, targetID); err != nil {
+				return err
+			}
+		}
+
+		// google_id is UNIQUE, so release it on the source before copying it over.
+		var srcGoogleID *string
+		var srcKey string
+		var srcAdmin bool
+		if err := tx.QueryRow(ctx, `
+			UPDATE auth_users a SET google_id = NULL FROM (SELECT google_id, gemini_api_key, is_admin FROM auth_users WHERE id = $1) old
+			WHERE a.id = $1 RETURNING old.google_id, COALESCE(old.gemini_api_key, ''), old.is_admin
+		`, sourceID).Scan(&srcGoogleID, &srcKey, &srcAdmin); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE auth_users SET
+				gemini_api_key = COALESCE(NULLIF(gemini_api_key, ''), NULLIF($2, '')),
+				is_admin = is_admin OR $3,
+				google_id = COALESCE(google_id, $4)
+			WHERE id = $1
+		`, targetID, srcKey, srcAdmin, srcGoogleID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM auth_users WHERE id = $1`, sourceID); err != nil {
+			return err
+		}
+		return recountUserInteractions(ctx, tx, targetID)
+	})
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ingest run statuses.
+const (
+	RunRunning  = "running"
+	RunDone     = "done"
+	RunTimedOut = "timed_out" // stopped by its time budget or a shutdown
+)
+
+// StartIngestRun records the start of an ingestion run.
+func (s *Store) StartIngestRun(ctx context.Context, full bool) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `INSERT INTO ingest_runs (full_sync) VALUES ($1) RETURNING id`, full).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record ingest run: %w", err)
+	}
+	return id, nil
+}
+
+// FinishIngestRun records how an ingestion run ended.
+func (s *Store) FinishIngestRun(ctx context.Context, id int64, status string) error {
+	_, err := s.db.Exec(ctx, `UPDATE ingest_runs SET status = $2, finished_at = NOW() WHERE id = $1`, id, status)
+	return err
+}
+
+// LastFullSync returns when the last completed full sync started, or the
+// zero time if there hasn't been one.
+func (s *Store) LastFullSync(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	err := s.db.QueryRow(ctx, `
+		SELECT started_at FROM ingest_runs
+		WHERE full_sync AND status = 'done'
+		ORDER BY started_at DESC LIMIT 1
+	`).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return t, err
+}
+
+// PruneIngestRuns deletes runs older than daysToKeep.
+func (s *Store) PruneIngestRuns(ctx context.Context, daysToKeep int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM ingest_runs WHERE started_at < NOW() - make_interval(days => $1)`, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune ingest runs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Orderings for GetPopularStories.
+const (
+	PopularBySaves = "saves"
+	PopularByReads = "reads"
+)
+
+// bumpInteractionCounts applies the change in one user's flags on a story to
+// the story's save/read/hide counters.
+func bumpInteractionCounts(ctx context.Context, db execer, storyID int, before, after [3]bool) error {
+	var delta [3]int
+	changed := false
+	for i := range before {
+		switch {
+		case after[i] && !before[i]:
+			delta[i], changed = 1, true
+		case before[i] && !after[i]:
+			delta[i], changed = -1, true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	_, err := db.Exec(ctx, `
+		UPDATE stories SET
+			read_count = GREATEST(read_count + $2, 0),
+			save_count = GREATEST(save_count + $3, 0),
+			hide_count = GREATEST(hide_count + $4, 0)
+		WHERE id = $1
+	`, storyID, delta[0], delta[1], delta[2])
+	return err
+}
+
+// recountUserInteractions recomputes the counters of every story userID has
+// interacted with, after bulk changes such as account merges.
+func recountUserInteractions(ctx context.Context, db execer, userID string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE stories s SET
+			save_count = c.saves,
+			read_count = c.reads,
+			hide_count = c.hides
+		FROM (
+			SELECT story_id,
+			       COUNT(*) FILTER (WHERE is_saved) AS saves,
+			       COUNT(*) FILTER (WHERE is_read) AS reads,
+			       COUNT(*) FILTER (WHERE is_hidden) AS hides
+			FROM user_interactions
+			WHERE story_id IN (SELECT story_id FROM user_interactions WHERE user_id = $1)
+			GROUP BY story_id
+		) c
+		WHERE s.id = c.story_id
+	`, userID)
+	return err
+}
+
+// interactionFlags reads a user's current read/saved/hidden flags on a story,
+// locking the row; all false if there is none.
+func interactionFlags(ctx context.Context, tx pgx.Tx, userID string, storyID int) ([3]bool, error) {
+	var f [3]bool
+	err := tx.QueryRow(ctx, `
+		SELECT is_read, is_saved, is_hidden FROM user_interactions
+		WHERE user_id = $1 AND story_id = $2 FOR UPDATE
+	`, userID, storyID).Scan(&f[0], &f[1], &f[2])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return f, nil
+	}
+	return f, err
+}
+
+// GetMostSaved returns the stories saved by the most users, for the admin
+// dashboard.
+func (s *Store) GetMostSaved(ctx context.Context, limit int) ([]Story, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, save_count, read_count, hide_count
+		FROM stories WHERE save_count > 0
+		ORDER BY save_count DESC, read_count DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var st Story
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.Score, &st.By, &st.Descendants, &st.PostedAt, &st.CreatedAt, &st.SaveCount, &st.ReadCount, &st.HideCount); err != nil {
+			return nil, err
+		}
+		stories = append(stories, st)
+	}
+	return stories, rows.Err()
+}
+
+// GetPopularStories returns the stories this instance's users saved (or
+// read) the most among those posted since the given time, regardless of HN
+// score.
+func (s *Store) GetPopularStories(ctx context.Context, by string, since time.Time, limit int) ([]Story, error) {
+	orderBy := "save_count DESC, read_count DESC"
+	if by == PopularByReads {
+		orderBy = "read_count DESC, save_count DESC"
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics, save_count, read_count
+		FROM stories
+		WHERE posted_at >= $1 AND (save_count > 0 OR read_count > 0)
+		ORDER BY `+orderBy+`, score DESC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var st Story
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.Score, &st.By, &st.Descendants, &st.PostedAt, &st.CreatedAt, &st.HNRank, &st.Summary, &st.Topics, &st.SaveCount, &st.ReadCount); err != nil {
+			return nil, err
+		}
+		stories = append(stories, st)
+	}
+	return stories, rows.Err()
+}
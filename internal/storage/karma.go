@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// KarmaSnapshot is an HN user's karma at a point in time.
+type KarmaSnapshot struct {
+	Karma      int       `json:"karma"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// GetStaleUsernames returns up to limit ingested HN users not refreshed
+// since before, least recently refreshed first.
+func (s *Store) GetStaleUsernames(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT id FROM users WHERE updated_at < $1 ORDER BY updated_at ASC LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// SnapshotUser updates an HN user's profile and records their current karma.
+func (s *Store) SnapshotUser(ctx context.Context, user User) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if err := upsertUser(ctx, tx, user); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO user_karma_snapshots (username, karma) VALUES ($1, $2) ON CONFLICT DO NOTHING`, user.ID, user.Karma)
+		return err
+	})
+}
+
+// GetKarmaHistory returns an HN user's karma snapshots since the given time,
+// oldest first. It returns ErrNotFound for users that haven't been ingested.
+func (s *Store) GetKarmaHistory(ctx context.Context, username string, since time.Time) ([]KarmaSnapshot, error) {
+	var exists bool
+	if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, username).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT karma, captured_at FROM user_karma_snapshots
+		WHERE username = $1 AND captured_at >= $2
+		ORDER BY captured_at ASC
+	`, username, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []KarmaSnapshot{}
+	for rows.Next() {
+		var k KarmaSnapshot
+		if err := rows.Scan(&k.Karma, &k.CapturedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, k)
+	}
+	return history, rows.Err()
+}
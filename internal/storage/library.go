@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Library item states.
+const (
+	LibraryPending = "pending"
+	LibraryReady   = "ready"
+	LibraryFailed  = "failed"
+)
+
+// LibraryItem is an arbitrary page a user saved (e.g. with the bookmarklet)
+// that isn't an HN story. It is summarized in the background.
+type LibraryItem struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Summary   *string   `json:"summary"`
+	Topics    []string  `json:"topics"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveLibraryItem adds a page to the user's library. Saving a page again
+// returns the existing item, queueing it again if summarizing it failed.
+func (s *Store) SaveLibraryItem(ctx context.Context, userID, url string) (*LibraryItem, error) {
+	query := `
+		INSERT INTO library_items (user_id, url) VALUES ($1, $2)
+		ON CONFLICT (user_id, url) DO UPDATE SET
+			status = CASE WHEN library_items.status = 'failed' THEN 'pending' ELSE library_items.status END,
+			error = CASE WHEN library_items.status = 'failed' THEN '' ELSE library_items.error END,
+			updated_at = NOW()
+		RETURNING id, url, title, summary, topics, status, error, created_at, updated_at
+	`
+	var it LibraryItem
+	err := s.db.QueryRow(ctx, query, userID, url).Scan(&it.ID, &it.URL, &it.Title, &it.Summary, &it.Topics, &it.Status, &it.Error, &it.CreatedAt, &it.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
+
+func (s *Store) GetLibraryItems(ctx context.Context, userID string, limit, offset int) ([]LibraryItem, int, error) {
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM library_items WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, url, title, summary, topics, status, error, created_at, updated_at
+		FROM library_items
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []LibraryItem
+	for rows.Next() {
+		var it LibraryItem
+		if err := rows.Scan(&it.ID, &it.URL, &it.Title, &it.Summary, &it.Topics, &it.Status, &it.Error, &it.CreatedAt, &it.UpdatedAt); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, it)
+	}
+	return items, total, nil
+}
+
+func (s *Store) DeleteLibraryItem(ctx context.Context, userID string, itemID int64) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM library_items WHERE id = $1 AND user_id = $2`, itemID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CompleteLibraryItem stores the fetched title and the summary of an item.
+// Topics go through the same quality gate as stories'.
+func (s *Store) CompleteLibraryItem(ctx context.Context, itemID int64, title, summary string, topics []string) error {
+	topics, _ = CleanTopics(topics)
+	query := `UPDATE library_items SET title = $2, summary = $3, topics = $4, status = 'ready', error = '', updated_at = NOW() WHERE id = $1`
+	_, err := s.db.Exec(ctx, query, itemID, title, summary, topics)
+	return err
+}
+
+// FailLibraryItem records why an item couldn't be summarized. The title is
+// kept if the page could be fetched.
+func (s *Store) FailLibraryItem(ctx context.Context, itemID int64, title, reason string) error {
+	query := `UPDATE library_items SET title = COALESCE(NULLIF($2, ''), title), status = 'failed', error = $3, updated_at = NOW() WHERE id = $1`
+	_, err := s.db.Exec(ctx, query, itemID, title, reason)
+	return err
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// List is a user-curated collection of stories. Public lists are readable by
+// anyone through their slug.
+type List struct {
+	ID          int64     `json:"id"`
+	UserID      string    `json:"owner_id"`
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	IsPublic    bool      `json:"is_public"`
+	WorkspaceID *int64    `json:"workspace_id,omitempty"`
+	OwnerName   string    `json:"owner_name,omitempty"`
+	ItemCount   int       `json:"item_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListItem is a story in a list together with the curator's note.
+type ListItem struct {
+	Story
+	Note    string    `json:"note"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newListSlug derives a URL-safe slug from the title plus a random suffix so
+// two "Favorites" lists don't collide.
+func newListSlug(title string) (string, error) {
+	base := strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(base) > 48 {
+		base = strings.TrimRight(base[:48], "-")
+	}
+	if base == "" {
+		base = "list"
+	}
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return base + "-" + hex.EncodeToString(suffix), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// listWriteAccess restricts a lists query aliased "l" to lists the user ($2)
+// owns or that belong to one of their workspaces.
+const listWriteAccess = `(l.user_id = $2 OR l.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2))`
+
+// CreateList creates a personal list, or a shared one when workspaceID is set.
+// Callers must check workspace membership.
+func (s *Store) CreateList(ctx context.Context, userID, title, description string, isPublic bool, workspaceID *int64) (*List, error) {
+	query := `
+		INSERT INTO lists (user_id, slug, title, description, is_public, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, slug, title, description, is_public, workspace_id, created_at, updated_at
+	`
+	// Retry on the (unlikely) slug collision.
+	for attempt := 0; ; attempt++ {
+		slug, err := newListSlug(title)
+		if err != nil {
+			return nil, err
+		}
+		var l List
+		err = s.db.QueryRow(ctx, query, userID, slug, title, description, isPublic, workspaceID).Scan(
+			&l.ID, &l.UserID, &l.Slug, &l.Title, &l.Description, &l.IsPublic, &l.WorkspaceID, &l.CreatedAt, &l.UpdatedAt)
+		if err == nil {
+			return &l, nil
+		}
+		if !isUniqueViolation(err) || attempt >= 3 {
+			return nil, err
+		}
+	}
+}
+
+// GetUserLists returns the user's personal (non-workspace) lists.
+func (s *Store) GetUserLists(ctx context.Context, userID string) ([]List, error) {
+	return s.queryLists(ctx, `WHERE l.user_id = $1 AND l.workspace_id IS NULL`, userID)
+}
+
+func (s *Store) GetWorkspaceLists(ctx context.Context, workspaceID int64) ([]List, error) {
+	return s.queryLists(ctx, `WHERE l.workspace_id = $1`, workspaceID)
+}
+
+func (s *Store) queryLists(ctx context.Context, where string, args ...interface{}) ([]List, error) {
+	query := `
+		SELECT l.id, l.user_id, l.slug, l.title, l.description, l.is_public, l.workspace_id, l.created_at, l.updated_at,
+		       (SELECT COUNT(*) FROM list_items li WHERE li.list_id = l.id)
+		FROM lists l
+		` + where + `
+		ORDER BY l.updated_at DESC
+	`
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []List
+	for rows.Next() {
+		var l List
+		if err := rows.Scan(&l.ID, &l.UserID, &l.Slug, &l.Title, &l.Description, &l.IsPublic, &l.WorkspaceID, &l.CreatedAt, &l.UpdatedAt, &l.ItemCount); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, nil
+}
+
+// UpdateList changes the fields that are non-nil. It returns ErrNotFound if
+// the list doesn't exist or the user can't edit it.
+func (s *Store) UpdateList(ctx context.Context, userID string, listID int64, title, description *string, isPublic *bool) (*List, error) {
+	query := `
+		UPDATE lists l
+		SET title = COALESCE($3, l.title),
+			description = COALESCE($4, l.description),
+			is_public = COALESCE($5, l.is_public),
+			updated_at = NOW()
+		WHERE l.id = $1 AND ` + listWriteAccess + `
+		RETURNING l.id, l.user_id, l.slug, l.title, l.description, l.is_public, l.workspace_id, l.created_at, l.updated_at
+	`
+	var l List
+	err := s.db.QueryRow(ctx, query, listID, userID, title, description, isPublic).Scan(
+		&l.ID, &l.UserID, &l.Slug, &l.Title, &l.Description, &l.IsPublic, &l.WorkspaceID, &l.CreatedAt, &l.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *Store) DeleteList(ctx context.Context, userID string, listID int64) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM lists l WHERE l.id = $1 AND `+listWriteAccess, listID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddListItem adds a story to one of the user's lists, or updates its note if
+// it is already there.
+func (s *Store) AddListItem(ctx context.Context, userID string, listID int64, storyID int, note string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE lists l SET updated_at = NOW() WHERE l.id = $1 AND `+listWriteAccess, listID, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO list_items (list_id, story_id, note) VALUES ($1, $2, $3)
+			ON CONFLICT (list_id, story_id) DO UPDATE SET note = EXCLUDED.note
+		`, listID, storyID, note)
+		return err
+	})
+}
+
+func (s *Store) RemoveListItem(ctx context.Context, userID string, listID int64, storyID int) error {
+	query := `
+		DELETE FROM list_items li
+		USING lists l
+		WHERE li.list_id = l.id AND l.id = $1 AND li.story_id = $3 AND ` + listWriteAccess + `
+	`
+	tag, err := s.db.Exec(ctx, query, listID, userID, storyID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetListBySlug returns a list with its owner's display name and its stories,
+// most recently added first. Visibility is left to the caller.
+func (s *Store) GetListBySlug(ctx context.Context, slug string) (*List, []ListItem, error) {
+	listQuery := `
+		SELECT l.id, l.user_id, l.slug, l.title, l.description, l.is_public, l.workspace_id, COALESCE(u.name, ''), l.created_at, l.updated_at
+		FROM lists l
+		INNER JOIN auth_users u ON u.id = l.user_id
+		WHERE l.slug = $1
+	`
+	var l List
+	err := s.db.QueryRow(ctx, listQuery, slug).Scan(
+		&l.ID, &l.UserID, &l.Slug, &l.Title, &l.Description, &l.IsPublic, &l.WorkspaceID, &l.OwnerName, &l.CreatedAt, &l.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	itemsQuery := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics,
+		       li.note, li.added_at
+		FROM list_items li
+		INNER JOIN stories s ON s.id = li.story_id
+		WHERE li.list_id = $1
+		ORDER BY li.added_at DESC
+	`
+	rows, err := s.db.Query(ctx, itemsQuery, l.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var items []ListItem
+	for rows.Next() {
+		var it ListItem
+		if err := rows.Scan(&it.ID, &it.Title, &it.URL, &it.Score, &it.By, &it.Descendants, &it.PostedAt, &it.CreatedAt, &it.HNRank, &it.Summary, &it.Topics, &it.Note, &it.AddedAt); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, it)
+	}
+	l.ItemCount = len(items)
+	return &l, items, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LocalComment is a comment posted on this instance, kept apart from the
+// mirrored HN comments in the comments table.
+type LocalComment struct {
+	ID           int64     `json:"id"`
+	StoryID      int64     `json:"story_id"`
+	ParentID     *int64    `json:"parent_id,omitempty"`
+	UserID       string    `json:"user_id"`
+	AuthorName   string    `json:"author_name"`
+	AuthorAvatar string    `json:"author_avatar"`
+	Text         string    `json:"text"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AddLocalComment stores a comment. A non-nil parentID must belong to the same
+// story, otherwise ErrNotFound is returned.
+func (s *Store) AddLocalComment(ctx context.Context, storyID int, userID string, parentID *int64, text string) (*LocalComment, error) {
+	query := `
+		WITH ins AS (
+			INSERT INTO local_comments (story_id, user_id, parent_id, text)
+			SELECT $1, $2, $3, $4
+			WHERE $3::BIGINT IS NULL OR EXISTS (SELECT 1 FROM local_comments WHERE id = $3 AND story_id = $1)
+			RETURNING id, story_id, parent_id, user_id, text, created_at, updated_at
+		)
+		SELECT ins.id, ins.story_id, ins.parent_id, ins.user_id, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), ins.text, ins.created_at, ins.updated_at
+		FROM ins INNER JOIN auth_users u ON u.id = ins.user_id
+	`
+	var c LocalComment
+	err := s.db.QueryRow(ctx, query, storyID, userID, parentID, text).Scan(
+		&c.ID, &c.StoryID, &c.ParentID, &c.UserID, &c.AuthorName, &c.AuthorAvatar, &c.Text, &c.CreatedAt, &c.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetLocalComments returns a story's local comments oldest first; clients
+// thread them by parent_id.
+func (s *Store) GetLocalComments(ctx context.Context, storyID int) ([]LocalComment, error) {
+	query := `
+		SELECT c.id, c.story_id, c.parent_id, c.user_id, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), c.text, c.created_at, c.updated_at
+		FROM local_comments c
+		INNER JOIN auth_users u ON u.id = c.user_id
+		WHERE c.story_id = $1
+		ORDER BY c.created_at ASC
+	`
+	rows, err := s.db.Query(ctx, query, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []LocalComment
+	for rows.Next() {
+		var c LocalComment
+		if err := rows.Scan(&c.ID, &c.StoryID, &c.ParentID, &c.UserID, &c.AuthorName, &c.AuthorAvatar, &c.Text, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+// UpdateLocalComment edits the text of a comment the user wrote.
+func (s *Store) UpdateLocalComment(ctx context.Context, id int64, userID, text string) error {
+	tag, err := s.db.Exec(ctx, `UPDATE local_comments SET text = $3, updated_at = NOW() WHERE id = $1 AND user_id = $2`, id, userID, text)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteLocalComment removes a comment (and its replies). Admins may delete
+// any comment; other users only their own.
+func (s *Store) DeleteLocalComment(ctx context.Context, id int64, userID string, isAdmin bool) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM local_comments WHERE id = $1 AND (user_id = $2 OR $3)`, id, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
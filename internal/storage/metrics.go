@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MetricSnapshot is a day's instance metrics.
+type MetricSnapshot struct {
+	Day                 time.Time `json:"day"`
+	StoriesIngested     int       `json:"stories_ingested"`
+	SummariesGenerated  int       `json:"summaries_generated"` // summary jobs finished
+	SummariesFailed     int       `json:"summaries_failed"`    // summary jobs given up on
+	ActiveUsers         int       `json:"active_users"`        // users who read, saved or hid a story
+	AICalls             int       `json:"ai_calls"`            // cloud AI calls, as in AIUsage
+	AICostUSD           float64   `json:"ai_cost_usd"`
+	SummaryLatencyAvgMS *int      `json:"summary_latency_avg_ms"` // from claim to finish; nil without finished jobs
+	SummaryLatencyP95MS *int      `json:"summary_latency_p95_ms"`
+}
+
+// SnapshotMetrics computes the metrics of the UTC day containing day and
+// stores them, replacing that day's earlier snapshot.
+func (s *Store) SnapshotMetrics(ctx context.Context, day time.Time) error {
+	y, m, d := day.UTC().Date()
+	_, err := s.db.Exec(ctx, `
+		WITH bounds AS (SELECT $1::date AS day, $1::date::timestamp AT TIME ZONE 'UTC' AS since, ($1::date + 1)::timestamp AT TIME ZONE 'UTC' AS until),
+		jobs AS (
+			SELECT
+				COUNT(*) FILTER (WHERE j.status = 'done') AS done,
+				COUNT(*) FILTER (WHERE j.status = 'failed') AS failed,
+				AVG(j.duration_ms) FILTER (WHERE j.status = 'done') AS avg_ms,
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY j.duration_ms) FILTER (WHERE j.status = 'done') AS p95_ms
+			FROM summary_jobs j, bounds b
+			WHERE j.status IN ('done', 'failed') AND j.updated_at >= b.since AND j.updated_at < b.until
+		),
+		ai AS (
+			SELECT COUNT(*) AS calls, COALESCE(SUM(u.cost_usd), 0) AS cost
+			FROM ai_usage u, bounds b
+			WHERE u.created_at >= b.since AND u.created_at < b.until
+		)
+		INSERT INTO metric_snapshots (day, stories_ingested, summaries_generated, summaries_failed, active_users,
+			ai_calls, ai_cost_usd, summary_latency_avg_ms, summary_latency_p95_ms, updated_at)
+		SELECT b.day,
+			(SELECT COUNT(*) FROM stories st WHERE st.created_at >= b.since AND st.created_at < b.until),
+			jobs.done, jobs.failed,
+			(SELECT COUNT(DISTINCT ui.user_id) FROM user_interactions ui WHERE ui.updated_at >= b.since AND ui.updated_at < b.until),
+			ai.calls, ai.cost, jobs.avg_ms::int, jobs.p95_ms::int, NOW()
+		FROM bounds b, jobs, ai
+		ON CONFLICT (day) DO UPDATE SET
+			stories_ingested = EXCLUDED.stories_ingested,
+			summaries_generated = EXCLUDED.summaries_generated,
+			summaries_failed = EXCLUDED.summaries_failed,
+			active_users = EXCLUDED.active_users,
+			ai_calls = EXCLUDED.ai_calls,
+			ai_cost_usd = EXCLUDED.ai_cost_usd,
+			summary_latency_avg_ms = EXCLUDED.summary_latency_avg_ms,
+			summary_latency_p95_ms = EXCLUDED.summary_latency_p95_ms,
+			updated_at = NOW()
+	`, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return fmt.Errorf("failed to snapshot metrics: %w", err)
+	}
+	return nil
+}
+
+// GetMetricHistory returns the snapshots of the last days, oldest first.
+// Days without a snapshot (ingest wasn't running) are left out.
+func (s *Store) GetMetricHistory(ctx context.Context, days int) ([]MetricSnapshot, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT day, stories_ingested, summaries_generated, summaries_failed, active_users,
+		       ai_calls, ai_cost_usd, summary_latency_avg_ms, summary_latency_p95_ms
+		FROM metric_snapshots
+		WHERE day > (NOW() AT TIME ZONE 'UTC')::date - $1::int
+		ORDER BY day ASC
+	`, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metric history: %w", err)
+	}
+	defer rows.Close()
+
+	var out []MetricSnapshot
+	for rows.Next() {
+		var m MetricSnapshot
+		if err := rows.Scan(&m.Day, &m.StoriesIngested, &m.SummariesGenerated, &m.SummariesFailed, &m.ActiveUsers,
+			&m.AICalls, &m.AICostUSD, &m.SummaryLatencyAvgMS, &m.SummaryLatencyP95MS); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
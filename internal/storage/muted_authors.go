@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// MutedAuthor is an HN username a user has muted.
+type MutedAuthor struct {
+	Username string    `json:"username"`
+	MutedAt  time.Time `json:"muted_at"`
+}
+
+func (s *Store) MuteAuthor(ctx context.Context, userID, username string) error {
+	_, err := s.db.Exec(ctx, `INSERT INTO user_muted_authors (user_id, username) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, username)
+	return err
+}
+
+func (s *Store) UnmuteAuthor(ctx context.Context, userID, username string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM user_muted_authors WHERE user_id = $1 AND username = $2`, userID, username)
+	return err
+}
+
+// GetMutedAuthors returns the user's muted HN usernames, most recent first.
+func (s *Store) GetMutedAuthors(ctx context.Context, userID string) ([]MutedAuthor, error) {
+	rows, err := s.db.Query(ctx, `SELECT username, created_at FROM user_muted_authors WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var muted []MutedAuthor
+	for rows.Next() {
+		var m MutedAuthor
+		if err := rows.Scan(&m.Username, &m.MutedAt); err != nil {
+			return nil, err
+		}
+		muted = append(muted, m)
+	}
+	return muted, rows.Err()
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Digest frequencies.
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// NotificationPreferences controls how and when a user is notified.
+type NotificationPreferences struct {
+	DigestFrequency string          `json:"digest_frequency"`
+	Channels        []string        `json:"channels"`
+	WebhookURL      string          `json:"webhook_url"`
+	PushToken       string          `json:"push_token"`
+	QuietStart      *int            `json:"quiet_start"` // local hour, 0-23
+	QuietEnd        *int            `json:"quiet_end"`
+	Timezone        string          `json:"timezone"`
+	Topics          map[string]bool `json:"topics"` // subscribed topic -> digest enabled
+}
+
+// DefaultNotificationPreferences matches the column defaults.
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		DigestFrequency: DigestWeekly,
+		Channels:        []string{"email"},
+		Timezone:        "UTC",
+		Topics:          map[string]bool{},
+	}
+}
+
+// DigestPeriod is how often a digest is sent for a frequency.
+func DigestPeriod(frequency string) time.Duration {
+	if frequency == DigestDaily {
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// QuietUntil reports whether t falls inside the quiet hours and, if so, when
+// they end.
+func (p *NotificationPreferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if p.QuietStart == nil || p.QuietEnd == nil || *p.QuietStart == *p.QuietEnd {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	start, end, h := *p.QuietStart, *p.QuietEnd, local.Hour()
+
+	var quiet bool
+	if start < end {
+		quiet = h >= start && h < end
+	} else { // wraps midnight, e.g. 22-7
+		quiet = h >= start || h < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end, 0, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+func (s *Store) GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	p := DefaultNotificationPreferences()
+	query := `
+		SELECT digest_frequency, channels, webhook_url, push_token, quiet_start, quiet_end, timezone
+		FROM notification_preferences WHERE user_id = $1
+	`
+	err := s.db.QueryRow(ctx, query, userID).Scan(&p.DigestFrequency, &p.Channels, &p.WebhookURL, &p.PushToken, &p.QuietStart, &p.QuietEnd, &p.Timezone)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT topic, enabled FROM topic_subscriptions WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var topic string
+		var enabled bool
+		if err := rows.Scan(&topic, &enabled); err != nil {
+			return nil, err
+		}
+		p.Topics[topic] = enabled
+	}
+	return &p, rows.Err()
+}
+
+// UpdateNotificationPreferences saves the preferences and applies the per-topic
+// toggles to the user's existing subscriptions; unknown topics are ignored.
+func (s *Store) UpdateNotificationPreferences(ctx context.Context, userID string, p NotificationPreferences) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO notification_preferences (user_id, digest_frequency, channels, webhook_url, push_token, quiet_start, quiet_end, timezone, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+			ON CONFLICT (user_id) DO UPDATE SET
+				digest_frequency = EXCLUDED.digest_frequency,
+				channels = EXCLUDED.channels,
+				webhook_url = EXCLUDED.webhook_url,
+				push_token = EXCLUDED.push_token,
+				quiet_start = EXCLUDED.quiet_start,
+				quiet_end = EXCLUDED.quiet_end,
+				timezone = EXCLUDED.timezone,
+				updated_at = NOW()
+		`, userID, p.DigestFrequency, p.Channels, p.WebhookURL, p.PushToken, p.QuietStart, p.QuietEnd, p.Timezone)
+		if err != nil {
+			return err
+		}
+		for topic, enabled := range p.Topics {
+			if _, err := tx.Exec(ctx, `UPDATE topic_subscriptions SET enabled = $3 WHERE user_id = $1 AND topic = $2`, userID, NormalizeTopic(topic), enabled); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Outbox event types written alongside story/summary updates.
+const (
+	EventStoryNew     = "story.new"
+	EventSummaryReady = "summary.ready"
+)
+
+// Outbox delivery statuses.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+// OutboxMessage is a row in notification_outbox. An empty Channel marks a
+// broadcast event that still has to be fanned out into deliveries.
+type OutboxMessage struct {
+	ID        int64           `json:"id"`
+	EventType string          `json:"event_type"`
+	Channel   string          `json:"channel"`
+	Recipient string          `json:"recipient"`
+	UserID    string          `json:"user_id,omitempty"` // set for per-user deliveries
+	Payload   json.RawMessage `json:"payload"`
+	DedupKey  string          `json:"dedup_key,omitempty"`
+	Status    string          `json:"status"`
+	Attempts  int             `json:"attempts"`
+	LastError *string         `json:"last_error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// execer is satisfied by both *pgxpool.Pool and pgx.Tx so outbox rows can be
+// written inside the caller's transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func insertOutbox(ctx context.Context, db execer, msg OutboxMessage) error {
+	payload := msg.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	var dedupKey *string
+	if msg.DedupKey != "" {
+		dedupKey = &msg.DedupKey
+	}
+	query := `
+		INSERT INTO notification_outbox (event_type, channel, recipient, payload, dedup_key, user_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid)
+		ON CONFLICT (dedup_key) DO NOTHING
+	`
+	_, err := db.Exec(ctx, query, msg.EventType, msg.Channel, msg.Recipient, payload, dedupKey, msg.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return nil
+}
+
+// newEvent builds a broadcast outbox event from any JSON-serializable payload.
+func newEvent(eventType string, payload interface{}) (OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxMessage{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return OutboxMessage{EventType: eventType, Payload: data}, nil
+}
+
+// userDeliveries addresses evt to each of a user's alert channels that has a
+// recipient, with a dedup key per channel.
+func userDeliveries(evt OutboxMessage, email string, prefs *NotificationPreferences, dedupKey func(channel string) string) []OutboxMessage {
+	var deliveries []OutboxMessage
+	for _, channel := range prefs.Channels {
+		d := evt
+		d.Channel = channel
+		switch channel {
+		case "email":
+			d.Recipient = email
+		case "webhook":
+			d.Recipient = prefs.WebhookURL
+		case "push":
+			d.Recipient = prefs.PushToken
+		}
+		if d.Recipient == "" {
+			continue
+		}
+		d.DedupKey = dedupKey(channel)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
+}
+
+// EnqueueNotification writes a single outbox row outside of any other write.
+func (s *Store) EnqueueNotification(ctx context.Context, msg OutboxMessage) error {
+	return insertOutbox(ctx, s.db, msg)
+}
+
+// ClaimOutboxBatch leases up to limit due messages for lease duration,
+// only of eventTypes if any are given. Rows whose lease expires (dispatcher
+// crashed mid-send) become claimable again.
+func (s *Store) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration, eventTypes []string) ([]OutboxMessage, error) {
+	query := `
+		UPDATE notification_outbox
+		SET locked_until = NOW() + make_interval(secs => $2),
+			attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending'
+			  AND next_attempt_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			  AND ($3::text[] IS NULL OR event_type = ANY($3))
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, channel, recipient, COALESCE(user_id::text, ''), payload, COALESCE(dedup_key, ''), status, attempts, last_error, created_at
+	`
+	rows, err := s.db.Query(ctx, query, limit, lease.Seconds(), eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		if err := rows.Scan(&m.ID, &m.EventType, &m.Channel, &m.Recipient, &m.UserID, &m.Payload, &m.DedupKey, &m.Status, &m.Attempts, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, rows.Err()
+}
+
+// ExpandOutboxEvent atomically replaces a broadcast event with its per-channel
+// deliveries, so a crash can never deliver an event twice or drop it.
+func (s *Store) ExpandOutboxEvent(ctx context.Context, eventID int64, deliveries []OutboxMessage) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		for _, d := range deliveries {
+			if d.DedupKey == "" {
+				d.DedupKey = fmt.Sprintf("%d:%s:%s", eventID, d.Channel, d.Recipient)
+			}
+			if err := insertOutbox(ctx, tx, d); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `UPDATE notification_outbox SET status = 'sent', sent_at = NOW(), locked_until = NULL WHERE id = $1`, eventID)
+		return err
+	})
+}
+
+// MarkOutboxSent records a successful delivery.
+func (s *Store) MarkOutboxSent(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, `UPDATE notification_outbox SET status = 'sent', sent_at = NOW(), locked_until = NULL, last_error = NULL WHERE id = $1`, id)
+	return err
+}
+
+// MarkOutboxFailed records a failed attempt. When dead is true the message is
+// parked and no longer retried; otherwise it becomes due again at retryAt.
+func (s *Store) MarkOutboxFailed(ctx context.Context, id int64, errMsg string, retryAt time.Time, dead bool) error {
+	status := OutboxPending
+	if dead {
+		status = OutboxDead
+	}
+	query := `UPDATE notification_outbox SET status = $2, last_error = $3, next_attempt_at = $4, locked_until = NULL WHERE id = $1`
+	_, err := s.db.Exec(ctx, query, id, status, errMsg, retryAt)
+	return err
+}
+
+// DeferOutbox postpones a claimed message to until without counting the claim
+// as a delivery attempt (e.g. during the recipient's quiet hours).
+func (s *Store) DeferOutbox(ctx context.Context, id int64, until time.Time) error {
+	query := `UPDATE notification_outbox SET next_attempt_at = $2, locked_until = NULL, attempts = GREATEST(attempts - 1, 0) WHERE id = $1`
+	_, err := s.db.Exec(ctx, query, id, until)
+	return err
+}
+
+// PruneOutbox deletes delivered messages older than daysToKeep.
+func (s *Store) PruneOutbox(ctx context.Context, daysToKeep int) error {
+	query := `DELETE FROM notification_outbox WHERE status = 'sent' AND sent_at < NOW() - make_interval(days => $1)`
+	_, err := s.db.Exec(ctx, query, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	return nil
+}
+
+// inTx runs fn inside a transaction, committing on success.
+func (s *Store) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig holds the connection pool and query tuning knobs.
+// Zero values leave the pgxpool defaults in place.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementCacheMode is one of cache_statement, cache_describe,
+	// describe_exec, exec or simple_protocol (use exec/simple_protocol behind PgBouncer).
+	StatementCacheMode string
+	// StatementTimeout is sent as the session statement_timeout so a stuck
+	// query can't hold a pooled connection for the full request timeout.
+	StatementTimeout time.Duration
+	// SlowQueryThreshold logs any query slower than this. 0 disables.
+	SlowQueryThreshold time.Duration
+}
+
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// NewPool creates a pgx connection pool for dbURL with cfg applied on top of
+// whatever the connection string specifies.
+func NewPool(ctx context.Context, dbURL string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database url: %w", err)
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if poolCfg.MinConns > poolCfg.MaxConns {
+		return nil, fmt.Errorf("DB_MIN_CONNS (%d) exceeds DB_MAX_CONNS (%d)", poolCfg.MinConns, poolCfg.MaxConns)
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.StatementCacheMode != "" {
+		mode, ok := queryExecModes[strings.ToLower(cfg.StatementCacheMode)]
+		if !ok {
+			return nil, fmt.Errorf("unknown DB_STATEMENT_CACHE_MODE %q", cfg.StatementCacheMode)
+		}
+		poolCfg.ConnConfig.DefaultQueryExecMode = mode
+	}
+	if cfg.StatementTimeout > 0 {
+		poolCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	if cfg.SlowQueryThreshold > 0 {
+		poolCfg.ConnConfig.Tracer = &slowQueryTracer{threshold: cfg.SlowQueryThreshold}
+	}
+
+	log.Printf("DB pool: max_conns=%d min_conns=%d health_check=%v exec_mode=%v slow_query=%v",
+		poolCfg.MaxConns, poolCfg.MinConns, poolCfg.HealthCheckPeriod, poolCfg.ConnConfig.DefaultQueryExecMode, cfg.SlowQueryThreshold)
+
+	return pgxpool.NewWithConfig(ctx, poolCfg)
+}
+
+type slowQueryStartKey struct{}
+
+type slowQueryStart struct {
+	at  time.Time
+	sql string
+}
+
+// slowQueryTracer logs queries that exceed the configured threshold.
+type slowQueryTracer struct {
+	threshold time.Duration
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryStartKey{}, slowQueryStart{at: time.Now(), sql: data.SQL})
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(slowQueryStartKey{}).(slowQueryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at)
+	if elapsed < t.threshold {
+		return
+	}
+	status := "ok"
+	if data.Err != nil {
+		status = data.Err.Error()
+	}
+	log.Printf("Slow query (%v, threshold %v, %s): %s", elapsed.Round(time.Millisecond), t.threshold, status, compactSQL(start.sql))
+}
+
+// compactSQL collapses whitespace so multi-line queries log on one line.
+func compactSQL(sql string) string {
+	s := strings.Join(strings.Fields(sql), " ")
+	if len(s) > 500 {
+		s = s[:500] + "..."
+	}
+	return s
+}
@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+// SearchKeyword ranks stories against a search query by full-text relevance
+// over search_vector, which weights title, topics, summary and article text.
+func (s *Store) SearchKeyword(ctx context.Context, query string, limit int) ([]Story, error) {
+	q := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS tsq)
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics
+		FROM stories s, q
+		WHERE s.search_vector @@ q.tsq
+		ORDER BY ts_rank_cd(s.search_vector, q.tsq) DESC, s.score DESC
+		LIMIT $2
+	`
+	return s.querySummarized(ctx, q, query, limit)
+}
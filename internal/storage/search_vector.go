@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+)
+
+// searchVectorSQL computes a story's search_vector from its own columns:
+// title, then topics, then summary, then post body and article text.
+const searchVectorSQL = `
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', array_to_string(coalesce(topics, '{}'), ' ')), 'B') ||
+	setweight(to_tsvector('english', coalesce(summary, '')), 'C') ||
+	setweight(to_tsvector('english', text || ' ' || article_text), 'D')`
+
+// maxArticleText caps the article text kept for search.
+const maxArticleText = 20000
+
+// refreshSearchVector recomputes a story's search_vector. Every write to a
+// column in searchVectorSQL must call it.
+func refreshSearchVector(ctx context.Context, db execer, id int64) error {
+	_, err := db.Exec(ctx, `
+		UPDATE stories SET search_vector = `+searchVectorSQL+`
+		WHERE id = $1 AND search_vector IS DISTINCT FROM (`+searchVectorSQL+`)
+	`, id)
+	return err
+}
+
+// SetArticleText stores the extracted text of a story's article for search,
+// along with the language detected from it.
+func (s *Store) SetArticleText(ctx context.Context, id int, text string) error {
+	if len(text) > maxArticleText {
+		text = strings.ToValidUTF8(text[:maxArticleText], "")
+	}
+	lang := content.DetectLanguage(text)
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE stories SET article_text = $2, language = coalesce(nullif($3, ''), language) WHERE id = $1`, id, text, lang); err != nil {
+			return err
+		}
+		return refreshSearchVector(ctx, tx, int64(id))
+	})
+}
+
+// SetStoryLanguage records a story's language, e.g. one detected from the
+// body of a text post. An empty language leaves the stored one.
+func (s *Store) SetStoryLanguage(ctx context.Context, id int, language string) error {
+	if language == "" {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `UPDATE stories SET language = $2 WHERE id = $1`, id, language)
+	return err
+}
+
+// ReindexSearchVectors recomputes search_vector for every story and returns
+// how many changed.
+func (s *Store) ReindexSearchVectors(ctx context.Context) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE stories SET search_vector = `+searchVectorSQL+`
+		WHERE search_vector IS DISTINCT FROM (`+searchVectorSQL+`)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+)
+
+// What flagged (or cleared) a story as sensitive.
+const (
+	SensitiveKeyword = "keyword" // content.ClassifySensitive matched; not yet checked
+	SensitiveAI      = "ai"      // an AI model's verdict
+	SensitiveAdmin   = "admin"   // an admin's review
+)
+
+// How a user wants sensitive stories shown. The API flags them either way;
+// "hide" also leaves them out of story lists.
+const (
+	SensitiveShow = "show"
+	SensitiveBlur = "blur"
+	SensitiveHide = "hide"
+)
+
+// IsSensitivePreference reports whether p is a valid sensitive_content value.
+func IsSensitivePreference(p string) bool {
+	return p == SensitiveShow || p == SensitiveBlur || p == SensitiveHide
+}
+
+// SensitiveStory is a story's sensitive-content classification, for admin
+// review.
+type SensitiveStory struct {
+	ID         int64      `json:"id"`
+	Title      string     `json:"title"`
+	URL        string     `json:"url"`
+	Sensitive  bool       `json:"sensitive"`
+	Source     string     `json:"source"`
+	Reason     string     `json:"reason,omitempty"`
+	ReviewedBy *string    `json:"reviewed_by,omitempty"`
+	At         *time.Time `json:"at,omitempty"`
+}
+
+// flagSensitive flags a story whose texts match the sensitive keyword list,
+// unless a model or an admin has already judged it.
+func flagSensitive(ctx context.Context, db execer, id int64, texts ...string) error {
+	reason := content.ClassifySensitive(texts...)
+	if reason == "" {
+		return nil
+	}
+	_, err := db.Exec(ctx, `
+		UPDATE stories SET sensitive = TRUE, sensitive_source = 'keyword', sensitive_reason = $2, sensitive_at = NOW()
+		WHERE id = $1 AND sensitive_source IS NULL
+	`, id, reason)
+	return err
+}
+
+// StoriesNeedingSensitiveCheck returns keyword-flagged stories no model has
+// checked yet, most recent first, with their summary as Summary.
+func (s *Store) StoriesNeedingSensitiveCheck(ctx context.Context, limit int) ([]Story, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, COALESCE(url, ''), summary, topics FROM stories
+		WHERE sensitive_source = 'keyword'
+		ORDER BY sensitive_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stories []Story
+	for rows.Next() {
+		var st Story
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.Summary, &st.Topics); err != nil {
+			return nil, err
+		}
+		stories = append(stories, st)
+	}
+	return stories, rows.Err()
+}
+
+// SetSensitiveVerdict records a model's verdict on a keyword-flagged story.
+// It doesn't override an admin's review.
+func (s *Store) SetSensitiveVerdict(ctx context.Context, id int64, sensitive bool, reason string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE stories SET sensitive = $2, sensitive_source = 'ai', sensitive_reason = $3, sensitive_at = NOW()
+		WHERE id = $1 AND sensitive_source IS DISTINCT FROM 'admin'
+	`, id, sensitive, reason)
+	return err
+}
+
+// ReviewSensitive records an admin's decision on whether a story is
+// sensitive, which later classification leaves alone.
+func (s *Store) ReviewSensitive(ctx context.Context, id int, sensitive bool, reviewerID string) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE stories SET sensitive = $2, sensitive_source = 'admin', sensitive_reviewed_by = $3, sensitive_at = NOW()
+		WHERE id = $1
+	`, id, sensitive, reviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to review story %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetSensitiveStories lists classified stories, most recently classified
+// first. source filters by what classified them ("" for all); flagged, if
+// set, by the outcome.
+func (s *Store) GetSensitiveStories(ctx context.Context, source string, flagged *bool, limit int) ([]SensitiveStory, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, COALESCE(url, ''), sensitive, sensitive_source, COALESCE(sensitive_reason, ''), sensitive_reviewed_by::text, sensitive_at
+		FROM stories
+		WHERE sensitive_source IS NOT NULL
+		  AND ($1 = '' OR sensitive_source = $1)
+		  AND ($2::boolean IS NULL OR sensitive = $2)
+		ORDER BY sensitive_at DESC NULLS LAST
+		LIMIT $3
+	`, source, flagged, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sensitive stories: %w", err)
+	}
+	defer rows.Close()
+	var out []SensitiveStory
+	for rows.Next() {
+		var st SensitiveStory
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.Sensitive, &st.Source, &st.Reason, &st.ReviewedBy, &st.At); err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+// GetSensitivePreference returns how the user wants sensitive stories shown.
+func (s *Store) GetSensitivePreference(ctx context.Context, userID string) (string, error) {
+	var p string
+	err := s.db.QueryRow(ctx, `SELECT sensitive_content FROM auth_users WHERE id = $1`, userID).Scan(&p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return p, err
+}
+
+// UpdateSensitivePreference sets how the user wants sensitive stories shown.
+func (s *Store) UpdateSensitivePreference(ctx context.Context, userID, preference string) error {
+	_, err := s.db.Exec(ctx, `UPDATE auth_users SET sensitive_content = $2 WHERE id = $1`, userID, preference)
+	return err
+}
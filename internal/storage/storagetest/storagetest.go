@@ -0,0 +1,238 @@
+// Package storagetest runs tests against a real, throwaway Postgres with
+// every migration applied.
+//
+// The server is the one at TEST_DATABASE_URL (whose user must be able to
+// create databases and extensions) or, without it, a pgvector container
+// started with the docker CLI and removed when the tests finish. Tests are
+// skipped when neither is available. Each test gets its own database,
+// cloned from a migrated template, so tests can run in parallel and leave
+// nothing behind.
+//
+// Tests using it are built with the integration tag:
+//
+//	go test -tags integration ./internal/storage/...
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// Image is the Postgres image started when TEST_DATABASE_URL isn't set; it
+// has the vector extension the migrations need.
+const Image = "pgvector/pgvector:pg16"
+
+const (
+	startTimeout = time.Minute
+	password     = "storagetest"
+)
+
+var (
+	setupOnce sync.Once
+	setupErr  error
+	adminURL  string // the server's maintenance database
+	template  string // migrated database each test's database is cloned from
+	container string // docker container ID; "" when using TEST_DATABASE_URL
+
+	dbCounter atomic.Int64
+)
+
+// Main runs the package's tests and then removes the container started for
+// them. Call it from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	teardown()
+	os.Exit(code)
+}
+
+// New returns a Store on a fresh, fully migrated database that is dropped
+// when the test ends.
+func New(t testing.TB) *storage.Store {
+	return storage.New(NewPool(t))
+}
+
+// NewPool is New for tests that also need to query the database directly.
+func NewPool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	setupOnce.Do(func() { setupErr = setup() })
+	if setupErr != nil {
+		if container == "" && os.Getenv("TEST_DATABASE_URL") == "" {
+			t.Skipf("Skipping: no test database: %v", setupErr)
+		}
+		t.Fatalf("Failed to set up the test database: %v", setupErr)
+	}
+
+	ctx := context.Background()
+	name := fmt.Sprintf("%s_%d", template, dbCounter.Add(1))
+	if err := adminExec(ctx, fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s`, name, template)); err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	pool, err := pgxpool.New(ctx, withDatabase(adminURL, name))
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if err := adminExec(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, name)); err != nil {
+			t.Logf("Failed to drop test database %s: %v", name, err)
+		}
+	})
+	return pool
+}
+
+func setup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	if u := os.Getenv("TEST_DATABASE_URL"); u != "" {
+		adminURL = u
+	} else {
+		if err := startContainer(ctx); err != nil {
+			return err
+		}
+	}
+	if err := waitReady(ctx); err != nil {
+		return err
+	}
+
+	// Per process, so concurrent `go test` runs of several packages against
+	// one TEST_DATABASE_URL don't trip over each other.
+	template = fmt.Sprintf("hn_test_%d", os.Getpid())
+	if err := adminExec(ctx, fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, template)); err != nil {
+		return err
+	}
+	if err := adminExec(ctx, fmt.Sprintf(`CREATE DATABASE %s`, template)); err != nil {
+		return err
+	}
+	return migrate(ctx, withDatabase(adminURL, template))
+}
+
+func startContainer(ctx context.Context) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("TEST_DATABASE_URL is not set and docker is not installed")
+	}
+	out, err := exec.CommandContext(ctx, "docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD="+password,
+		"-p", "127.0.0.1::5432",
+		"--label", "hn_station.storagetest=1",
+		Image,
+	).Output()
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", Image, commandError(err))
+	}
+	container = strings.TrimSpace(string(out))
+
+	out, err = exec.CommandContext(ctx, "docker", "port", container, "5432/tcp").Output()
+	if err != nil {
+		return fmt.Errorf("failed to look up the container's port: %w", commandError(err))
+	}
+	// One line per address family, e.g. "127.0.0.1:49153".
+	hostPort := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	adminURL = fmt.Sprintf("postgres://postgres:%s@%s/postgres?sslmode=disable", password, hostPort)
+	return nil
+}
+
+// waitReady polls until the server accepts connections. The image's init
+// runs a server that only listens on its socket, so a TCP connection means
+// the real one is up.
+func waitReady(ctx context.Context) error {
+	for {
+		conn, err := pgx.Connect(ctx, adminURL)
+		if err == nil {
+			err = conn.Ping(ctx)
+			conn.Close(ctx)
+			if err == nil {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("postgres didn't become ready: %w", err)
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+// migrate applies every up migration, in order.
+func migrate(ctx context.Context, dbURL string) error {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations")
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migrations found in %s", dir)
+	}
+	sort.Strings(files)
+
+	conn, err := pgx.Connect(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	for _, f := range files {
+		sql, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		// Without arguments Exec uses the simple protocol, which runs a
+		// file's statements in one go.
+		if _, err := conn.Exec(ctx, string(sql)); err != nil {
+			return fmt.Errorf("migration %s: %w", filepath.Base(f), err)
+		}
+	}
+	return nil
+}
+
+func teardown() {
+	if template != "" && adminURL != "" {
+		adminExec(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, template))
+	}
+	if container != "" {
+		exec.Command("docker", "rm", "-f", container).Run()
+	}
+}
+
+// adminExec runs a statement on the maintenance database; CREATE and DROP
+// DATABASE can't run on the database they affect.
+func adminExec(ctx context.Context, sql string) error {
+	conn, err := pgx.Connect(ctx, adminURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+func withDatabase(dbURL, name string) string {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return dbURL
+	}
+	u.Path = "/" + name
+	return u.String()
+}
+
+func commandError(err error) error {
+	if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,22 +13,44 @@ import (
 )
 
 type Story struct {
-	ID          int64            `json:"id"`
-	Title       string           `json:"title"`
-	URL         string           `json:"url"`
-	Score       int              `json:"score"`
-	By          string           `json:"by"`
-	Descendants int              `json:"descendants"`
-	PostedAt    time.Time        `json:"time"`
-	CreatedAt   time.Time        `json:"created_at"`
-	HNRank      *int             `json:"hn_rank,omitempty"`
-	IsRead      *bool            `json:"is_read,omitempty"`
-	IsSaved     *bool            `json:"is_saved,omitempty"`
-	IsHidden    *bool            `json:"is_hidden,omitempty"`
-	Summary     *string          `json:"summary,omitempty"`
-	Topics      []string         `json:"topics,omitempty"`
-	Embedding   *pgvector.Vector `json:"-"`
-	Similarity  *float64         `json:"similarity,omitempty"`
+	ID                   int64            `json:"id"`
+	Title                string           `json:"title"`
+	Type                 string           `json:"type,omitempty"`           // ItemStory or ItemJob; "" is saved as ItemStory
+	OriginalTitle        *string          `json:"original_title,omitempty"` // set once the story has been retitled
+	URL                  string           `json:"url"`
+	Text                 string           `json:"text,omitempty"` // body of text posts; only loaded by GetStory
+	Score                int              `json:"score"`
+	By                   string           `json:"by"`
+	Descendants          int              `json:"descendants"`
+	PostedAt             time.Time        `json:"time"`
+	CreatedAt            time.Time        `json:"created_at"`
+	HNRank               *int             `json:"hn_rank,omitempty"`
+	IsRead               *bool            `json:"is_read,omitempty"`
+	IsSaved              *bool            `json:"is_saved,omitempty"`
+	IsHidden             *bool            `json:"is_hidden,omitempty"`
+	Summary              *string          `json:"summary,omitempty"`
+	SummaryStatus        string           `json:"summary_status,omitempty"` // see SummaryPending etc.
+	SummaryStale         bool             `json:"summary_stale,omitempty"`  // article changed since it was summarized
+	SummaryDescendants   *int             `json:"-"`                        // comment count when the summary was generated
+	SummaryRegenerations int              `json:"-"`
+	DiscussionSummary    *string          `json:"discussion_summary,omitempty"` // of the comment thread; only loaded by GetStory
+	DiscussionSummaryAt  *time.Time       `json:"discussion_summary_at,omitempty"`
+	Topics               []string         `json:"topics,omitempty"`
+	Language             string           `json:"language,omitempty"`  // ISO 639-1, detected from the article; "" if unknown
+	Sensitive            bool             `json:"sensitive,omitempty"` // flagged as potentially sensitive; see sensitive.go
+	Embedding            *pgvector.Vector `json:"-"`
+	Similarity           *float64         `json:"similarity,omitempty"`
+	SavedBy              []string         `json:"saved_by,omitempty"` // following feed only
+	HNUsers              []string         `json:"hn_users,omitempty"` // followed HN users active on the story; hn_following feed only
+	SavedAt              *time.Time       `json:"saved_at,omitempty"` // saved stories only
+	Note                 string           `json:"note,omitempty"`     // the user's note; saved stories only
+	LastSeenAt           *time.Time       `json:"last_seen_at,omitempty"`
+	NewCommentCount      *int             `json:"new_comment_count,omitempty"` // comments since LastSeenAt
+	PinnedAt             *time.Time       `json:"pinned_at,omitempty"`         // set while an admin has pinned the story
+	Frozen               bool             `json:"frozen,omitempty"`            // exempt from pruning
+	SaveCount            int              `json:"save_count,omitempty"`        // users who saved the story
+	ReadCount            int              `json:"read_count,omitempty"`
+	HideCount            int              `json:"hide_count,omitempty"` // admin views only
 }
 
 type AuthUser struct {
@@ -40,6 +63,7 @@ type AuthUser struct {
 	TotalViews   int        `json:"total_views"`
 	LastSeen     *time.Time `json:"last_seen"` // Pointer to handle nulls
 	GeminiAPIKey string     `json:"-"`         // Never expose to frontend
+	SavesPublic  bool       `json:"saves_public"`
 	CreatedAt    time.Time  `json:"created_at"`
 }
 
@@ -48,6 +72,17 @@ type AppStats struct {
 	TotalInteractions int `json:"total_interactions"`
 	TotalStories      int `json:"total_stories"`
 	TotalComments     int `json:"total_comments"`
+	// Stories whose last summary attempt failed, by failure code.
+	SummaryFailures map[string]int `json:"summary_failures"`
+	// Cloud AI calls and their estimated cost.
+	AIUsage          UsageTotals `json:"ai_usage"`
+	AIUsageThisMonth UsageTotals `json:"ai_usage_this_month"`
+	// The summary queue's backlog and backpressure decisions.
+	SummaryQueue SummaryQueueStats `json:"summary_queue"`
+	// Topic tags dropped by the quality gate in the last day, by reason.
+	TopicRejections map[string]int `json:"topic_rejections"`
+	// Stories saved by the most users, with their interaction counters.
+	MostSaved []Story `json:"most_saved"`
 }
 
 type Store struct {
@@ -60,26 +95,71 @@ func New(db *pgxpool.Pool) *Store {
 
 func (s *Store) UpsertStory(ctx context.Context, story Story) error {
 	query := `
-		INSERT INTO stories (id, title, url, score, by, descendants, posted_at, hn_rank, embedding, topics, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, '{}'::text[]), NOW())
+		INSERT INTO stories (id, title, url, text, score, by, descendants, posted_at, hn_rank, embedding, topics, item_type, created_at)
+		VALUES ($1, $2, $3, $11, $4, $5, $6, $7, $8, $9, COALESCE($10, '{}'::text[]), COALESCE(NULLIF($12, ''), 'story'), NOW())
 		ON CONFLICT (id) DO UPDATE
 		SET title = EXCLUDED.title,
+			item_type = EXCLUDED.item_type,
 			url = EXCLUDED.url,
+			text = EXCLUDED.text,
 			score = EXCLUDED.score,
 			by = EXCLUDED.by,
 			descendants = EXCLUDED.descendants,
 			posted_at = EXCLUDED.posted_at,
 			hn_rank = EXCLUDED.hn_rank,
-			topics = COALESCE(EXCLUDED.topics, stories.topics),
-			embedding = COALESCE(EXCLUDED.embedding, stories.embedding);
+			topics = CASE WHEN $10::text[] IS NULL THEN stories.topics ELSE EXCLUDED.topics END,
+			embedding = COALESCE(EXCLUDED.embedding, stories.embedding)
+		RETURNING (xmax = 0) AS inserted
 	`
-	_, err := s.db.Exec(ctx, query, story.ID, story.Title, story.URL, story.Score, story.By, story.Descendants, story.PostedAt, story.HNRank, story.Embedding, story.Topics)
-	return err
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		var previousTitle string
+		err := tx.QueryRow(ctx, `SELECT title FROM stories WHERE id = $1 FOR UPDATE`, story.ID).Scan(&previousTitle)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		var inserted bool
+		if err := tx.QueryRow(ctx, query, story.ID, story.Title, story.URL, story.Score, story.By, story.Descendants, story.PostedAt, story.HNRank, story.Embedding, story.Topics, story.Text, story.Type).Scan(&inserted); err != nil {
+			return err
+		}
+		if err := refreshSearchVector(ctx, tx, story.ID); err != nil {
+			return err
+		}
+		if err := flagSensitive(ctx, tx, story.ID, story.Title, story.Text); err != nil {
+			return err
+		}
+		if !inserted {
+			if previousTitle != story.Title {
+				return recordRetitle(ctx, tx, story.ID, previousTitle, story.Title)
+			}
+			return nil
+		}
+		// First sighting of this story: record the notification in the same transaction.
+		evt, err := newEvent(EventStoryNew, map[string]interface{}{
+			"story_id":  story.ID,
+			"title":     story.Title,
+			"url":       story.URL,
+			"by":        story.By,
+			"score":     story.Score,
+			"posted_at": story.PostedAt,
+		})
+		if err != nil {
+			return err
+		}
+		evt.DedupKey = fmt.Sprintf("%s:%d", EventStoryNew, story.ID)
+		return insertOutbox(ctx, tx, evt)
+	})
 }
 
-func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy string, topics []string, userID string, showHidden bool) ([]Story, int, error) {
+// GetStories lists stories for a feed. Topics filter by full-text match and
+// languages by detected language, keeping stories whose language isn't
+// known yet. Job posts are only listed by the "jobs" feed (and GetHNJobs).
+func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy string, topics, languages []string, userID string, showHidden bool) ([]Story, int, error) {
 	// 1. Build common WHERE clause
 	whereClause := " WHERE 1=1"
+	if sortStrategy != "jobs" {
+		whereClause += ` AND s.item_type = '` + ItemStory + `'`
+	}
 	var args []interface{}
 	argID := 1
 	hasUser := userID != ""
@@ -90,24 +170,44 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 		if !showHidden {
 			whereClause += ` AND (ui.is_hidden IS NULL OR ui.is_hidden = FALSE)`
 		}
+		// Users who hide sensitive stories don't see them at all.
+		whereClause += ` AND NOT (s.sensitive AND EXISTS (SELECT 1 FROM auth_users au WHERE au.id = $1 AND au.sensitive_content = 'hide'))`
 	}
 
+	// topicClause is the full-text match; fuzzyClause matches the same
+	// arguments by trigram similarity to the title and is used only if the
+	// full-text match finds nothing.
+	var topicClause, fuzzyClause string
 	if len(topics) > 0 {
 		tsqueryParts := make([]string, len(topics))
+		fuzzyParts := make([]string, len(topics))
 		for i, t := range topics {
 			tsqueryParts[i] = fmt.Sprintf("plainto_tsquery('english', $%d)", argID)
+			fuzzyParts[i] = fmt.Sprintf("$%d <%% s.title", argID)
 			args = append(args, t)
 			argID++
 		}
-		whereClause += ` AND s.search_vector @@ (` + strings.Join(tsqueryParts, " || ") + `)`
+		topicClause = ` AND s.search_vector @@ (` + strings.Join(tsqueryParts, " || ") + `)`
+		fuzzyClause = ` AND (` + strings.Join(fuzzyParts, " OR ") + `)`
+		whereClause += topicClause
+	}
+
+	if len(languages) > 0 {
+		whereClause += fmt.Sprintf(` AND (s.language IS NULL OR s.language = ANY($%d))`, argID)
+		args = append(args, languages)
+		argID++
 	}
 
-	if sortStrategy == "show" {
-		whereClause += ` AND s.title ILIKE 'Show HN:%'`
+	// HN lists other than the front page come from their stored ranking.
+	var feedJoin string
+	if IsRankedFeed(sortStrategy) {
+		feedJoin = fmt.Sprintf(` INNER JOIN story_feed_ranks fr ON fr.story_id = s.id AND fr.feed = $%d`, argID)
+		args = append(args, sortStrategy)
+		argID++
 	}
 
 	// 2. Get Total Count
-	countQuery := `SELECT COUNT(*) FROM stories s`
+	countQuery := `SELECT COUNT(*) FROM stories s` + feedJoin
 	if hasUser {
 		countQuery += ` LEFT JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1`
 	}
@@ -117,23 +217,34 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 	if err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
+	if total == 0 && topicClause != "" {
+		// Nothing matched exactly; fall back to trigram matching so a typo
+		// like "Kubernets" still finds Kubernetes stories.
+		whereClause = strings.Replace(whereClause, topicClause, fuzzyClause, 1)
+		countQuery = strings.Replace(countQuery, topicClause, fuzzyClause, 1)
+		if err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	// 3. Get Stories
-	selectCols := `s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics`
-	fromClause := `FROM stories s`
+	selectCols := `s.id, s.title, s.original_title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics, s.summary_status, s.pinned_at, s.is_frozen, coalesce(s.language, ''), s.sensitive`
+	fromClause := `FROM stories s` + feedJoin
 	if hasUser {
-		selectCols += `, ui.is_read, ui.is_saved, ui.is_hidden`
+		selectCols += `, ui.is_read, ui.is_saved, ui.is_hidden, ui.last_seen_at, ` + newCommentCountSQL
 		fromClause += ` LEFT JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1`
 	}
 
-	orderBy := "s.hn_rank ASC NULLS LAST"
+	// Pinned stories lead the default front page, most recently pinned first.
+	orderBy := "s.pinned_at DESC NULLS LAST, s.hn_rank ASC NULLS LAST"
 	switch sortStrategy {
 	case "votes":
 		orderBy = "s.score DESC"
 	case "latest":
 		orderBy = "s.posted_at DESC"
-	case "show":
-		orderBy = "s.posted_at DESC"
+	}
+	if feedJoin != "" {
+		orderBy = "fr.rank ASC"
 	}
 
 	query := `SELECT ` + selectCols + ` ` + fromClause + whereClause + ` ORDER BY ` + orderBy
@@ -150,11 +261,11 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 	for rows.Next() {
 		var story Story
 		if hasUser {
-			if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.IsRead, &story.IsSaved, &story.IsHidden); err != nil {
+			if err := rows.Scan(&story.ID, &story.Title, &story.OriginalTitle, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.SummaryStatus, &story.PinnedAt, &story.Frozen, &story.Language, &story.Sensitive, &story.IsRead, &story.IsSaved, &story.IsHidden, &story.LastSeenAt, &story.NewCommentCount); err != nil {
 				return nil, 0, err
 			}
 		} else {
-			if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics); err != nil {
+			if err := rows.Scan(&story.ID, &story.Title, &story.OriginalTitle, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.SummaryStatus, &story.PinnedAt, &story.Frozen, &story.Language, &story.Sensitive); err != nil {
 				return nil, 0, err
 			}
 		}
@@ -164,15 +275,51 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 }
 
 func (s *Store) GetStory(ctx context.Context, id int) (*Story, error) {
-	query := `SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, topics FROM stories WHERE id = $1`
+	query := `SELECT id, title, original_title, COALESCE(url, ''), text, score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics, summary_status, summary_stale, summary_descendants, summary_regenerations, pinned_at, is_frozen, save_count, read_count, coalesce(language, ''), sensitive, discussion_summary, discussion_summary_at, item_type FROM stories WHERE id = $1`
 	var story Story
-	err := s.db.QueryRow(ctx, query, id).Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics)
+	err := s.db.QueryRow(ctx, query, id).Scan(&story.ID, &story.Title, &story.OriginalTitle, &story.URL, &story.Text, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.SummaryStatus, &story.SummaryStale, &story.SummaryDescendants, &story.SummaryRegenerations, &story.PinnedAt, &story.Frozen, &story.SaveCount, &story.ReadCount, &story.Language, &story.Sensitive, &story.DiscussionSummary, &story.DiscussionSummaryAt, &story.Type)
 	if err != nil {
 		return nil, err
 	}
 	return &story, nil
 }
 
+// GetStoriesByIDs loads several stories in one query, in the order of ids.
+// IDs that don't exist are skipped. Text isn't loaded.
+func (s *Store) GetStoriesByIDs(ctx context.Context, ids []int64) ([]Story, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT id, title, original_title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics, summary_status, summary_stale, pinned_at, is_frozen
+		FROM stories WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]Story, len(ids))
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.OriginalTitle, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.SummaryStatus, &story.SummaryStale, &story.PinnedAt, &story.Frozen); err != nil {
+			return nil, err
+		}
+		byID[story.ID] = story
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stories := make([]Story, 0, len(byID))
+	for _, id := range ids {
+		if story, ok := byID[id]; ok {
+			stories = append(stories, story)
+		}
+	}
+	return stories, nil
+}
+
 // GetStoriesStatus returns a map of IDs to their summary status for a list of story IDs.
 func (s *Store) GetStoriesStatus(ctx context.Context, ids []int) (map[int]bool, error) {
 	if len(ids) == 0 {
@@ -199,21 +346,12 @@ func (s *Store) GetStoriesStatus(ctx context.Context, ids []int) (map[int]bool,
 }
 
 func (s *Store) GetComments(ctx context.Context, storyID int) ([]Comment, error) {
-	query := `SELECT id, story_id, parent_id, text, by, posted_at FROM comments WHERE story_id = $1 ORDER BY posted_at ASC`
-	rows, err := s.db.Query(ctx, query, storyID)
+	query := `SELECT id, story_id, parent_id, COALESCE(text, ''), COALESCE(by, ''), posted_at, spam_suspect FROM comments WHERE story_id = $1 ORDER BY posted_at ASC, id ASC`
+	comments, err := s.queryComments(ctx, query, storyID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var comments []Comment
-	for rows.Next() {
-		var c Comment
-		if err := rows.Scan(&c.ID, &c.StoryID, &c.ParentID, &c.Text, &c.By, &c.PostedAt); err != nil {
-			return nil, err
-		}
-		comments = append(comments, c)
-	}
+	annotateComments(comments, 0)
 	return comments, nil
 }
 
@@ -224,6 +362,20 @@ type Comment struct {
 	Text     string    `json:"text"`
 	By       string    `json:"by"`
 	PostedAt time.Time `json:"time"`
+
+	// Set by GetComments and GetCommentThread.
+	Permalink string `json:"permalink,omitempty"` // the comment on HN
+	Depth     int    `json:"depth"`               // 0 for top-level comments
+	Position  int    `json:"position"`            // index among its siblings, oldest first
+	Replies   int    `json:"replies"`             // direct replies
+
+	// The same text was posted on several stories, as bots and spammers do.
+	SpamSuspect bool `json:"spam_suspect,omitempty"`
+
+	// Set by the API. Clients may auto-collapse a SuggestedCollapsed subtree.
+	SuggestedCollapsed bool `json:"suggested_collapsed,omitempty"`
+	Muted              bool `json:"muted,omitempty"` // by an author the user muted; Text is blanked
+	New                bool `json:"new,omitempty"`   // posted since the user's last visit
 }
 
 type User struct {
@@ -234,19 +386,56 @@ type User struct {
 	Submitted []int  `json:"submitted"`
 }
 
+const upsertCommentQuery = `
+	INSERT INTO comments (id, story_id, parent_id, text, by, posted_at, text_hash, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	ON CONFLICT (id) DO UPDATE
+	SET text = EXCLUDED.text,
+		posted_at = EXCLUDED.posted_at,
+		text_hash = EXCLUDED.text_hash;
+`
+
+// UpsertComment saves a comment and flags it, and its copies, if the same
+// text is on other stories; see markSpamSuspects.
 func (s *Store) UpsertComment(ctx context.Context, comment Comment) error {
-	query := `
-		INSERT INTO comments (id, story_id, parent_id, text, by, posted_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-		ON CONFLICT (id) DO UPDATE
-		SET text = EXCLUDED.text,
-			posted_at = EXCLUDED.posted_at;
-	`
-	_, err := s.db.Exec(ctx, query, comment.ID, comment.StoryID, comment.ParentID, comment.Text, comment.By, comment.PostedAt)
-	return err
+	hash := commentTextHash(comment.Text)
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, upsertCommentQuery, comment.ID, comment.StoryID, comment.ParentID, comment.Text, comment.By, comment.PostedAt, hash); err != nil {
+			return err
+		}
+		if hash == nil {
+			return nil
+		}
+		return markSpamSuspects(ctx, tx, []string{*hash})
+	})
+}
+
+// UpsertComments saves comments in one round trip, flagging duplicates as
+// UpsertComment does. Parents must come before their replies. If any
+// comment fails, none are saved.
+func (s *Store) UpsertComments(ctx context.Context, comments []Comment) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		var hashes []string
+		for _, c := range comments {
+			hash := commentTextHash(c.Text)
+			if hash != nil {
+				hashes = append(hashes, *hash)
+			}
+			batch.Queue(upsertCommentQuery, c.ID, c.StoryID, c.ParentID, c.Text, c.By, c.PostedAt, hash)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+		return markSpamSuspects(ctx, tx, hashes)
+	})
 }
 
 func (s *Store) UpsertUser(ctx context.Context, user User) error {
+	return upsertUser(ctx, s.db, user)
+}
+
+func upsertUser(ctx context.Context, db execer, user User) error {
 	query := `
 		INSERT INTO users (id, created, karma, about, submitted, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW())
@@ -256,7 +445,7 @@ func (s *Store) UpsertUser(ctx context.Context, user User) error {
 			submitted = EXCLUDED.submitted,
 			updated_at = NOW();
 	`
-	_, err := s.db.Exec(ctx, query, user.ID, user.Created, user.Karma, user.About, user.Submitted)
+	_, err := db.Exec(ctx, query, user.ID, user.Created, user.Karma, user.About, user.Submitted)
 	return err
 }
 
@@ -289,32 +478,117 @@ func (s *Store) UpdateRanks(ctx context.Context, rankMap map[int]int) error {
 }
 
 func (s *Store) UpdateStorySummary(ctx context.Context, id int, summary string) error {
-	query := `UPDATE stories SET summary = $1 WHERE id = $2`
-	_, err := s.db.Exec(ctx, query, summary, id)
-	return err
+	query := `UPDATE stories SET summary = $1 WHERE id = $2 RETURNING title`
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		var title string
+		if err := tx.QueryRow(ctx, query, summary, id).Scan(&title); err != nil {
+			return err
+		}
+		if err := refreshSearchVector(ctx, tx, int64(id)); err != nil {
+			return err
+		}
+		return insertSummaryReady(ctx, tx, id, title, summary, nil)
+	})
 }
 
+// UpdateStorySummaryAndTopics saves a story's summary and topic tags. Tags
+// go through the quality gate (CleanTopics) first, take the casing other
+// stories use for them, and rejected ones are recorded.
 func (s *Store) UpdateStorySummaryAndTopics(ctx context.Context, id int, summary string, topics []string) error {
-	query := `UPDATE stories SET summary = $1, topics = $2 WHERE id = $3`
-	_, err := s.db.Exec(ctx, query, summary, topics, id)
-	return err
+	topics, rejected := CleanTopics(topics)
+	query := `
+		UPDATE stories SET summary = $1, topics = $2, summary_status = 'done', summary_failure = NULL, summary_failure_detail = NULL,
+			summary_stale = FALSE, summary_descendants = descendants,
+			summary_regenerations = summary_regenerations + CASE WHEN summary IS NOT NULL AND summary != '' THEN 1 ELSE 0 END
+		WHERE id = $3
+		RETURNING title
+	`
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		topics, err := canonicalTopics(ctx, tx, topics)
+		if err != nil {
+			return err
+		}
+		var title string
+		if err := tx.QueryRow(ctx, query, summary, topics, id).Scan(&title); err != nil {
+			return err
+		}
+		if err := recordTopicRejections(ctx, tx, id, rejected); err != nil {
+			return err
+		}
+		if err := refreshSearchVector(ctx, tx, int64(id)); err != nil {
+			return err
+		}
+		if err := flagSensitive(ctx, tx, int64(id), summary, strings.Join(topics, ", ")); err != nil {
+			return err
+		}
+		return insertSummaryReady(ctx, tx, id, title, summary, topics)
+	})
+}
+
+// UpdateStoryDiscussionSummary saves the summary of a story's comment
+// thread, leaving the article summary and its status alone. The
+// discussion's topic tags are only used if the story has none yet, and go
+// through the same quality gate as UpdateStorySummaryAndTopics.
+func (s *Store) UpdateStoryDiscussionSummary(ctx context.Context, id int, summary string, topics []string) error {
+	topics, rejected := CleanTopics(topics)
+	query := `
+		UPDATE stories s SET discussion_summary = $1, discussion_summary_at = NOW(),
+			topics = CASE WHEN cardinality(old.topics) > 0 THEN old.topics ELSE $2 END
+		FROM (SELECT topics FROM stories WHERE id = $3 FOR UPDATE) old
+		WHERE s.id = $3
+		RETURNING COALESCE(cardinality(old.topics), 0) = 0
+	`
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		topics, err := canonicalTopics(ctx, tx, topics)
+		if err != nil {
+			return err
+		}
+		var tagged bool
+		if err := tx.QueryRow(ctx, query, summary, topics, id).Scan(&tagged); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !tagged {
+			return nil
+		}
+		if err := recordTopicRejections(ctx, tx, id, rejected); err != nil {
+			return err
+		}
+		return refreshSearchVector(ctx, tx, int64(id))
+	})
+}
+
+// insertSummaryReady records a summary.ready event inside the summary write's transaction.
+func insertSummaryReady(ctx context.Context, tx pgx.Tx, id int, title, summary string, topics []string) error {
+	evt, err := newEvent(EventSummaryReady, map[string]interface{}{
+		"story_id": id,
+		"title":    title,
+		"summary":  summary,
+		"topics":   topics,
+	})
+	if err != nil {
+		return err
+	}
+	return insertOutbox(ctx, tx, evt)
 }
 
 // UpsertAuthUser creates or updates a user based on their Google ID.
-// Returns the user (with ID) after upsert.
+// Returns the user (with ID) after upsert. The email of an existing user is
+// left alone; it only changes through the verified email-change flow.
 func (s *Store) UpsertAuthUser(ctx context.Context, googleID, email, name, avatarURL string) (*AuthUser, error) {
 	query := `
 		INSERT INTO auth_users (google_id, email, name, avatar_url)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (google_id) DO UPDATE
-		SET email = EXCLUDED.email,
-			name = EXCLUDED.name,
+		SET name = EXCLUDED.name,
 			avatar_url = EXCLUDED.avatar_url
-		RETURNING id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at
+		RETURNING id, COALESCE(google_id, ''), email, COALESCE(name, ''), COALESCE(avatar_url, ''), is_admin, COALESCE(gemini_api_key, ''), saves_public, created_at
 	`
 	var user AuthUser
 	err := s.db.QueryRow(ctx, query, googleID, email, name, avatarURL).Scan(
-		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt,
+		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.SavesPublic, &user.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -324,10 +598,10 @@ func (s *Store) UpsertAuthUser(ctx context.Context, googleID, email, name, avata
 
 // GetAuthUser fetches a user by their UUID.
 func (s *Store) GetAuthUser(ctx context.Context, userID string) (*AuthUser, error) {
-	query := `SELECT id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at FROM auth_users WHERE id = $1`
+	query := `SELECT id, COALESCE(google_id, ''), email, COALESCE(name, ''), COALESCE(avatar_url, ''), is_admin, COALESCE(gemini_api_key, ''), saves_public, created_at FROM auth_users WHERE id = $1`
 	var user AuthUser
 	err := s.db.QueryRow(ctx, query, userID).Scan(
-		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt,
+		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.SavesPublic, &user.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -344,62 +618,190 @@ func (s *Store) UpdateUserGeminiKey(ctx context.Context, userID, apiKey string)
 // UpsertInteraction creates or updates a user-story interaction.
 func (s *Store) UpsertInteraction(ctx context.Context, userID string, storyID int, isRead *bool, isSaved *bool, isHidden *bool) error {
 	query := `
-		INSERT INTO user_interactions (user_id, story_id, is_read, is_saved, is_hidden, updated_at)
-		VALUES ($1, $2, COALESCE($3, FALSE), COALESCE($4, FALSE), COALESCE($5, FALSE), NOW())
+		INSERT INTO user_interactions (user_id, story_id, is_read, is_saved, is_hidden, saved_at, updated_at)
+		VALUES ($1, $2, COALESCE($3, FALSE), COALESCE($4, FALSE), COALESCE($5, FALSE), CASE WHEN $4 THEN NOW() END, NOW())
 		ON CONFLICT (user_id, story_id) DO UPDATE SET
 			is_read = COALESCE($3, user_interactions.is_read),
 			is_saved = COALESCE($4, user_interactions.is_saved),
 			is_hidden = COALESCE($5, user_interactions.is_hidden),
+			saved_at = CASE
+				WHEN $4 IS NULL OR ($4 AND user_interactions.is_saved) THEN user_interactions.saved_at
+				WHEN $4 THEN NOW()
+			END,
 			updated_at = NOW()
+		RETURNING is_read, is_saved, is_hidden
+	`
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := interactionFlags(ctx, tx, userID, storyID)
+		if err != nil {
+			return err
+		}
+		var after [3]bool
+		if err := tx.QueryRow(ctx, query, userID, storyID, isRead, isSaved, isHidden).Scan(&after[0], &after[1], &after[2]); err != nil {
+			return err
+		}
+		return bumpInteractionCounts(ctx, tx, storyID, before, after)
+	})
+}
+
+// SetInteractionNote stores the user's private note on a story.
+func (s *Store) SetInteractionNote(ctx context.Context, userID string, storyID int, note string) error {
+	query := `
+		INSERT INTO user_interactions (user_id, story_id, note, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET note = $3, updated_at = NOW()
 	`
-	_, err := s.db.Exec(ctx, query, userID, storyID, isRead, isSaved, isHidden)
+	_, err := s.db.Exec(ctx, query, userID, storyID, note)
 	return err
 }
 
-// GetSavedStories returns stories saved by a user, newest first.
-func (s *Store) GetSavedStories(ctx context.Context, userID string, limit, offset int) ([]Story, int, error) {
-	countQuery := `SELECT COUNT(*) FROM user_interactions WHERE user_id = $1 AND is_saved = TRUE`
+// savedStoriesFrom selects a user's ($1) saved stories, narrowed by a search
+// query ($2) over the story's search_vector and the user's note when the
+// query is non-empty.
+const savedStoriesFrom = `
+		FROM stories s
+		INNER JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1
+		WHERE ui.is_saved = TRUE
+		  AND ($2 = '' OR s.search_vector @@ websearch_to_tsquery('english', $2) OR strpos(lower(ui.note), lower($2)) > 0)
+`
+
+// GetSavedStories returns stories saved by a user with the user's read state,
+// save time and note. Without a search query they're most recently saved
+// first; with one, best match first.
+func (s *Store) GetSavedStories(ctx context.Context, userID, search string, limit, offset int) ([]Story, int, error) {
+	search = strings.TrimSpace(search)
 	var total int
-	if err := s.db.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) `+savedStoriesFrom, userID, search).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	query := `
-		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics, ui.is_read, ui.is_saved
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics,
+		       s.summary_status, ui.is_read, ui.is_saved, COALESCE(ui.saved_at, ui.updated_at), ui.note
+		` + savedStoriesFrom + `
+		ORDER BY CASE WHEN $2 = '' THEN 0 ELSE ts_rank_cd(s.search_vector, websearch_to_tsquery('english', $2)) END DESC,
+		         COALESCE(ui.saved_at, ui.updated_at) DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := s.db.Query(ctx, query, userID, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics,
+			&story.SummaryStatus, &story.IsRead, &story.IsSaved, &story.SavedAt, &story.Note); err != nil {
+			return nil, 0, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, total, nil
+}
+
+// GetUnreadSavedStories returns the stories a user saved and hasn't read,
+// most recently saved first, with their stored summaries and notes.
+func (s *Store) GetUnreadSavedStories(ctx context.Context, userID string, limit int) ([]Story, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.summary, s.topics,
+		       ui.is_read, ui.is_saved, COALESCE(ui.saved_at, ui.updated_at), ui.note
 		FROM stories s
 		INNER JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1
-		WHERE ui.is_saved = TRUE
-		ORDER BY ui.updated_at DESC
-		LIMIT $2 OFFSET $3
+		WHERE ui.is_saved = TRUE AND NOT ui.is_read
+		ORDER BY COALESCE(ui.saved_at, ui.updated_at) DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.Summary, &story.Topics,
+			&story.IsRead, &story.IsSaved, &story.SavedAt, &story.Note); err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, rows.Err()
+}
+
+// minSimilarity is the cosine similarity below which SearchStories treats a
+// story as unrelated to the query.
+const minSimilarity = 0.5
+
+// SearchStories ranks stories by the cosine similarity of their embedding to
+// a query embedding computed by the same model, most similar first, and
+// returns a page of them with their Similarity set along with how many
+// matched. Stories still embedded by another model are left out, since
+// their vectors aren't comparable.
+func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, model string, limit, offset int) ([]Story, int, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics,
+		       1 - (embedding <=> $1) AS similarity, COUNT(*) OVER () AS total
+		FROM stories
+		WHERE embedding IS NOT NULL AND embedding_model = $2 AND 1 - (embedding <=> $1) > $3
+		ORDER BY embedding <=> $1
+		LIMIT $4 OFFSET $5
 	`
-	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+	rows, err := s.db.Query(ctx, query, embedding, model, minSimilarity, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
 	var stories []Story
+	total := 0
 	for rows.Next() {
 		var story Story
-		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.IsRead, &story.IsSaved); err != nil {
+		var similarity float64
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &similarity, &total); err != nil {
 			return nil, 0, err
 		}
+		story.Similarity = &similarity
 		stories = append(stories, story)
 	}
-	return stories, total, nil
+	return stories, total, rows.Err()
 }
 
-// SearchStories performs a semantic similarity search using a query embedding vector.
-func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, limit int) ([]Story, error) {
+// SearchSummarizedStories ranks stories that have a summary against a free-text
+// question using full-text search over title, summary and topics. Words are
+// OR-ed so natural-language questions still match. If nothing matches, the
+// highest-ranked summarized stories in the window are returned instead.
+func (s *Store) SearchSummarizedStories(ctx context.Context, question string, since time.Time, limit int) ([]Story, error) {
 	query := `
-		SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank,
-		       1 - (embedding <=> $1) as similarity
+		WITH q AS (
+			SELECT NULLIF(replace(plainto_tsquery('english', $1)::text, '&', '|'), '')::tsquery AS tsq
+		)
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics
+		FROM stories s, q
+		WHERE s.summary IS NOT NULL AND s.summary != '' AND s.posted_at >= $2
+		  AND q.tsq IS NOT NULL
+		  AND to_tsvector('english', s.title || ' ' || s.summary || ' ' || array_to_string(COALESCE(s.topics, '{}'), ' ')) @@ q.tsq
+		ORDER BY ts_rank(to_tsvector('english', s.title || ' ' || s.summary || ' ' || array_to_string(COALESCE(s.topics, '{}'), ' ')), q.tsq) DESC, s.score DESC
+		LIMIT $3
+	`
+	stories, err := s.querySummarized(ctx, query, question, since, limit)
+	if err != nil || len(stories) > 0 {
+		return stories, err
+	}
+
+	fallback := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics
 		FROM stories
-		WHERE embedding IS NOT NULL AND 1 - (embedding <=> $1) > 0.5
-		ORDER BY similarity DESC
+		WHERE summary IS NOT NULL AND summary != '' AND posted_at >= $1
+		ORDER BY hn_rank ASC NULLS LAST, score DESC
 		LIMIT $2
 	`
-	rows, err := s.db.Query(ctx, query, embedding, limit)
+	return s.querySummarized(ctx, fallback, since, limit)
+}
+
+func (s *Store) querySummarized(ctx context.Context, query string, args ...interface{}) ([]Story, error) {
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -408,11 +810,9 @@ func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, li
 	var stories []Story
 	for rows.Next() {
 		var story Story
-		var similarity float64
-		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &similarity); err != nil {
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics); err != nil {
 			return nil, err
 		}
-		story.Similarity = &similarity
 		stories = append(stories, story)
 	}
 	return stories, nil
@@ -461,6 +861,14 @@ func (s *Store) GetAppStats(ctx context.Context) (*AppStats, error) {
 		return nil, fmt.Errorf("failed to count users: %w", err)
 	}
 
+	stats.MostSaved, err = s.GetMostSaved(ctx, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load most saved stories: %w", err)
+	}
+	if stats.MostSaved == nil {
+		stats.MostSaved = []Story{}
+	}
+
 	// Total Interactions (only read ones as proxy for views)
 	err = s.db.QueryRow(ctx, "SELECT COUNT(*) FROM user_interactions WHERE is_read = TRUE").Scan(&stats.TotalInteractions)
 	if err != nil {
@@ -479,13 +887,47 @@ func (s *Store) GetAppStats(ctx context.Context) (*AppStats, error) {
 		return nil, fmt.Errorf("failed to count comments: %w", err)
 	}
 
+	// Summary failures by code
+	rows, err := s.db.Query(ctx, "SELECT summary_failure, COUNT(*) FROM stories WHERE summary_failure IS NOT NULL GROUP BY summary_failure")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count summary failures: %w", err)
+	}
+	defer rows.Close()
+	stats.SummaryFailures = make(map[string]int)
+	for rows.Next() {
+		var code string
+		var n int
+		if err := rows.Scan(&code, &n); err != nil {
+			return nil, fmt.Errorf("failed to count summary failures: %w", err)
+		}
+		stats.SummaryFailures[code] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count summary failures: %w", err)
+	}
+
+	stats.AIUsage, stats.AIUsageThisMonth, err = s.getAIUsageTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum AI usage: %w", err)
+	}
+
+	stats.SummaryQueue, err = s.getSummaryQueueStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summary queue stats: %w", err)
+	}
+
+	stats.TopicRejections, err = s.getTopicRejectionCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topic rejections: %w", err)
+	}
+
 	return stats, nil
 }
 
 func (s *Store) GetAllUsers(ctx context.Context) ([]*AuthUser, error) {
 	query := `
 		SELECT 
-			u.id, u.google_id, u.email, u.name, u.avatar_url, u.is_admin, COALESCE(u.gemini_api_key, ''), u.created_at,
+			u.id, COALESCE(u.google_id, ''), u.email, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), u.is_admin, COALESCE(u.gemini_api_key, ''), u.created_at,
 			COUNT(ui.story_id) FILTER (WHERE ui.is_read = TRUE) as total_views,
 			MAX(ui.updated_at) as last_seen
 		FROM auth_users u
@@ -526,16 +968,33 @@ func (s *Store) GetAnyAdminAPIKey(ctx context.Context) (string, error) {
 	return key, nil
 }
 
-// PruneStories removes stories that are older than daysToKeep and are not bookmarked.
-func (s *Store) PruneStories(ctx context.Context, daysToKeep int) error {
+// PruneStories removes stories that are older than daysToKeep and are not on
+// the ingested front page (hn_rank within keepTop), pinned, frozen,
+// bookmarked, in a list, locally discussed, annotated by a workspace, or
+// watched.
+func (s *Store) PruneStories(ctx context.Context, daysToKeep, keepTop int) error {
 	query := `
 		DELETE FROM stories 
 		WHERE created_at < NOW() - make_interval(days => $1)
+		AND (hn_rank IS NULL OR hn_rank > $2)
+		AND pinned_at IS NULL AND NOT is_frozen
 		AND id NOT IN (
 			SELECT story_id FROM user_interactions WHERE is_saved = TRUE
 		)
+		AND id NOT IN (
+			SELECT story_id FROM list_items
+		)
+		AND id NOT IN (
+			SELECT story_id FROM local_comments
+		)
+		AND id NOT IN (
+			SELECT story_id FROM workspace_notes
+		)
+		AND id NOT IN (
+			SELECT story_id FROM story_watches
+		)
 	`
-	_, err := s.db.Exec(ctx, query, daysToKeep)
+	_, err := s.db.Exec(ctx, query, daysToKeep, keepTop)
 	if err != nil {
 		return fmt.Errorf("failed to prune stories: %w", err)
 	}
@@ -0,0 +1,1016 @@
+//go:build integration
+
+package storage_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/rajeshkumarblr/hn_station/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run with: go test -tags integration ./internal/storage/...
+// See storagetest for where the database comes from.
+
+func TestMain(m *testing.M) {
+	storagetest.Main(m)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func story(id int64, title string, score int, postedAt time.Time) storage.Story {
+	return storage.Story{ID: id, Title: title, URL: "https://example.com/" + title, Score: score, By: "pg", Descendants: 3, PostedAt: postedAt}
+}
+
+func newUser(t *testing.T, s *storage.Store, googleID string) *storage.AuthUser {
+	t.Helper()
+	u, err := s.UpsertAuthUser(context.Background(), googleID, googleID+"@example.com", "User "+googleID, "")
+	require.NoError(t, err)
+	return u
+}
+
+func outboxEvents(t *testing.T, s *storage.Store, eventTypes ...string) []storage.OutboxMessage {
+	t.Helper()
+	msgs, err := s.ClaimOutboxBatch(context.Background(), 100, time.Minute, eventTypes)
+	require.NoError(t, err)
+	return msgs
+}
+
+func TestUpsertStory(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	posted := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	st := story(1, "Show HN: Postgres in a container", 10, posted)
+	st.HNRank = ptr(3)
+	require.NoError(t, s.UpsertStory(ctx, st))
+
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, st.Title, got.Title)
+	assert.Equal(t, st.URL, got.URL)
+	assert.Equal(t, 10, got.Score)
+	assert.Equal(t, "pg", got.By)
+	assert.True(t, posted.Equal(got.PostedAt))
+	require.NotNil(t, got.HNRank)
+	assert.Equal(t, 3, *got.HNRank)
+	assert.Nil(t, got.OriginalTitle)
+	assert.Nil(t, got.Summary)
+
+	// A first sighting queues one story.new event, keyed by the story.
+	events := outboxEvents(t, s, storage.EventStoryNew)
+	require.Len(t, events, 1)
+	assert.Equal(t, "story.new:1", events[0].DedupKey)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	assert.Equal(t, st.Title, payload["title"])
+
+	// Updating doesn't queue another, and a new title keeps the original.
+	st.Score = 42
+	st.Title = "Postgres in a container"
+	require.NoError(t, s.UpsertStory(ctx, st))
+	got, err = s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 42, got.Score)
+	assert.Equal(t, "Postgres in a container", got.Title)
+	require.NotNil(t, got.OriginalTitle)
+	assert.Equal(t, "Show HN: Postgres in a container", *got.OriginalTitle)
+	assert.Empty(t, outboxEvents(t, s, storage.EventStoryNew))
+
+	history, err := s.GetTitleHistory(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	_, err = s.GetStory(ctx, 999)
+	assert.Error(t, err)
+}
+
+func TestUpsertStoryKeepsTopics(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	st := story(1, "Rust", 1, time.Now())
+	st.Topics = []string{"Rust"}
+	require.NoError(t, s.UpsertStory(ctx, st))
+
+	// Re-ingesting without topics leaves the stored ones alone.
+	st.Topics = nil
+	require.NoError(t, s.UpsertStory(ctx, st))
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Rust"}, got.Topics)
+}
+
+func TestGetStories(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, sc := range []struct {
+		score int
+		rank  *int
+	}{{5, ptr(2)}, {50, ptr(1)}, {20, nil}} {
+		st := story(int64(i+1), "Story", sc.score, now.Add(time.Duration(i)*time.Minute))
+		st.HNRank = sc.rank
+		require.NoError(t, s.UpsertStory(ctx, st))
+	}
+
+	ids := func(stories []storage.Story) []int64 {
+		var ids []int64
+		for _, st := range stories {
+			ids = append(ids, st.ID)
+		}
+		return ids
+	}
+
+	stories, total, err := s.GetStories(ctx, 10, 0, "", nil, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []int64{2, 1, 3}, ids(stories), "front page is by rank, unranked last")
+
+	stories, _, err = s.GetStories(ctx, 10, 0, "votes", nil, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 1}, ids(stories))
+
+	stories, _, err = s.GetStories(ctx, 10, 0, "latest", nil, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 2, 1}, ids(stories))
+
+	stories, total, err = s.GetStories(ctx, 1, 1, "votes", nil, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total, "total ignores pagination")
+	assert.Equal(t, []int64{3}, ids(stories))
+
+	// A user's hidden stories drop out unless asked for; the rest carry
+	// the user's flags.
+	u := newUser(t, s, "g1")
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 1, nil, nil, ptr(true)))
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 2, ptr(true), nil, nil))
+
+	stories, total, err = s.GetStories(ctx, 10, 0, "", nil, nil, u.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []int64{2, 3}, ids(stories))
+	require.NotNil(t, stories[0].IsRead)
+	assert.True(t, *stories[0].IsRead)
+	assert.Nil(t, stories[1].IsRead)
+
+	_, total, err = s.GetStories(ctx, 10, 0, "", nil, nil, u.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+}
+
+func TestGetStoriesByTopic(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Kubernetes operators explained", 1, time.Now())))
+	require.NoError(t, s.UpsertStory(ctx, story(2, "A history of typewriters", 1, time.Now())))
+
+	stories, total, err := s.GetStories(ctx, 10, 0, "", []string{"kubernetes"}, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, stories, 1)
+	assert.EqualValues(t, 1, stories[0].ID)
+
+	// A misspelling falls back to matching titles by similarity.
+	stories, _, err = s.GetStories(ctx, 10, 0, "", []string{"Kubernets"}, nil, "", false)
+	require.NoError(t, err)
+	require.Len(t, stories, 1)
+	assert.EqualValues(t, 1, stories[0].ID)
+}
+
+func TestGetStoriesByIDs(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, s.UpsertStory(ctx, story(id, "Story", 1, time.Now())))
+	}
+
+	stories, err := s.GetStoriesByIDs(ctx, []int64{3, 99, 1})
+	require.NoError(t, err)
+	require.Len(t, stories, 2)
+	assert.EqualValues(t, 3, stories[0].ID)
+	assert.EqualValues(t, 1, stories[1].ID)
+
+	stories, err = s.GetStoriesByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, stories)
+}
+
+func TestRanks(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	for id := int64(1); id <= 3; id++ {
+		st := story(id, "Story", 1, time.Now())
+		st.HNRank = ptr(int(id))
+		require.NoError(t, s.UpsertStory(ctx, st))
+	}
+
+	require.NoError(t, s.ClearRanksNotIn(ctx, []int{1, 2}))
+	require.NoError(t, s.UpdateRanks(ctx, map[int]int{1: 2, 2: 1, 99: 3}))
+
+	rank := func(id int) *int {
+		st, err := s.GetStory(ctx, id)
+		require.NoError(t, err)
+		return st.HNRank
+	}
+	assert.Equal(t, ptr(2), rank(1))
+	assert.Equal(t, ptr(1), rank(2))
+	assert.Nil(t, rank(3))
+}
+
+func TestSummaries(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+	require.NoError(t, s.UpsertStory(ctx, story(2, "Other", 1, time.Now())))
+
+	status, err := s.GetStoriesStatus(ctx, []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: false, 2: false}, status)
+
+	require.NoError(t, s.UpdateStorySummaryAndTopics(ctx, 1, "A summary.", []string{"Databases", "databases", ""}))
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, got.Summary)
+	assert.Equal(t, "A summary.", *got.Summary)
+	assert.Equal(t, storage.SummaryDone, got.SummaryStatus)
+	assert.Equal(t, []string{"Databases"}, got.Topics)
+	assert.Equal(t, 0, got.SummaryRegenerations)
+
+	// Summarizing again counts as a regeneration.
+	require.NoError(t, s.UpdateStorySummaryAndTopics(ctx, 1, "A better summary.", []string{"Databases"}))
+	got, err = s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.SummaryRegenerations)
+
+	status, err = s.GetStoriesStatus(ctx, []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 2: false}, status)
+
+	require.NoError(t, s.UpdateStorySummary(ctx, 2, "Plain summary."))
+	assert.Len(t, outboxEvents(t, s, storage.EventSummaryReady), 3)
+
+	assert.Error(t, s.UpdateStorySummaryAndTopics(ctx, 99, "Nothing.", nil))
+}
+
+func TestDiscussionSummary(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Untagged", 1, time.Now())))
+	tagged := story(2, "Tagged", 1, time.Now())
+	tagged.Topics = []string{"Go"}
+	require.NoError(t, s.UpsertStory(ctx, tagged))
+
+	require.NoError(t, s.UpdateStoryDiscussionSummary(ctx, 1, "People argued.", []string{"Compilers"}))
+	require.NoError(t, s.UpdateStoryDiscussionSummary(ctx, 2, "People agreed.", []string{"Compilers"}))
+
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, got.DiscussionSummary)
+	assert.Equal(t, "People argued.", *got.DiscussionSummary)
+	assert.NotNil(t, got.DiscussionSummaryAt)
+	assert.Equal(t, []string{"Compilers"}, got.Topics, "untagged stories take the discussion's topics")
+	assert.Nil(t, got.Summary, "the article summary is left alone")
+
+	got, err = s.GetStory(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go"}, got.Topics, "existing topics are kept")
+
+	assert.ErrorIs(t, s.UpdateStoryDiscussionSummary(ctx, 99, "Nothing.", nil), storage.ErrNotFound)
+}
+
+func TestComments(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	require.NoError(t, s.UpsertComment(ctx, storage.Comment{ID: 10, StoryID: 1, Text: "First", By: "a", PostedAt: base}))
+	require.NoError(t, s.UpsertComments(ctx, []storage.Comment{
+		{ID: 11, StoryID: 1, ParentID: ptr(int64(10)), Text: "Reply", By: "b", PostedAt: base.Add(time.Minute)},
+		{ID: 12, StoryID: 1, Text: "Second", By: "c", PostedAt: base.Add(2 * time.Minute)},
+	}))
+	// Upserting again edits the text.
+	require.NoError(t, s.UpsertComment(ctx, storage.Comment{ID: 10, StoryID: 1, Text: "First, edited", By: "a", PostedAt: base}))
+
+	comments, err := s.GetComments(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	byID := map[int64]storage.Comment{}
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+	assert.Equal(t, "First, edited", byID[10].Text)
+	assert.Equal(t, 0, byID[10].Depth)
+	assert.Equal(t, 1, byID[10].Replies)
+	assert.Equal(t, 1, byID[11].Depth)
+	assert.Equal(t, 1, byID[12].Position)
+
+	// A batch with a bad comment saves nothing.
+	err = s.UpsertComments(ctx, []storage.Comment{
+		{ID: 13, StoryID: 1, Text: "Fine", By: "d", PostedAt: base},
+		{ID: 14, StoryID: 999, Text: "No such story", By: "d", PostedAt: base},
+	})
+	assert.Error(t, err)
+	comments, err = s.GetComments(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, comments, 3)
+}
+
+func TestDuplicateComments(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, s.UpsertStory(ctx, story(id, "Story", 1, time.Now())))
+	}
+	spam := "Check out my amazing productivity app, it will change your life! Sign up today at example.com for free."
+	now := time.Now()
+
+	// Twice is a coincidence; short texts never count.
+	require.NoError(t, s.UpsertComment(ctx, storage.Comment{ID: 10, StoryID: 1, Text: spam, By: "bot", PostedAt: now}))
+	require.NoError(t, s.UpsertComments(ctx, []storage.Comment{
+		{ID: 20, StoryID: 2, Text: "<p>" + spam + "</p>\n", By: "bot2", PostedAt: now},
+		{ID: 21, StoryID: 2, Text: "Thanks!", By: "a", PostedAt: now},
+	}))
+	require.NoError(t, s.UpsertComments(ctx, []storage.Comment{
+		{ID: 30, StoryID: 3, Text: "Thanks!", By: "b", PostedAt: now},
+		{ID: 31, StoryID: 1, Text: "Thanks!", By: "c", PostedAt: now},
+	}))
+	comments, err := s.GetComments(ctx, 2)
+	require.NoError(t, err)
+	for _, c := range comments {
+		assert.False(t, c.SpamSuspect, c.ID)
+	}
+
+	// A third story flags every copy.
+	require.NoError(t, s.UpsertComment(ctx, storage.Comment{ID: 32, StoryID: 3, Text: spam, By: "bot", PostedAt: now}))
+	for _, storyID := range []int{1, 2, 3} {
+		comments, err := s.GetComments(ctx, storyID)
+		require.NoError(t, err)
+		for _, c := range comments {
+			assert.Equal(t, c.ID == 10 || c.ID == 20 || c.ID == 32, c.SpamSuspect, c.ID)
+		}
+	}
+	thread, err := s.GetCommentThread(ctx, 20)
+	require.NoError(t, err)
+	assert.True(t, thread.Comment.SpamSuspect)
+
+	// The summarizer's fallback input leaves them out.
+	top, err := s.GetTopComments(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, int64(31), top[0].ID)
+}
+
+func TestUpsertUser(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.UpsertUser(ctx, storage.User{ID: "pg", Created: 1160418092, Karma: 100, Submitted: []int{1, 2}}))
+	require.NoError(t, s.UpsertUser(ctx, storage.User{ID: "pg", Created: 1160418092, Karma: 150, About: "Hi"}))
+
+	karma, err := s.GetUserKarma(ctx, []string{"pg", "nobody"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"pg": 150}, karma)
+}
+
+func TestAuthUsers(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	u := newUser(t, s, "g1")
+	assert.NotEmpty(t, u.ID)
+	assert.Equal(t, "g1@example.com", u.Email)
+	assert.False(t, u.IsAdmin)
+
+	// Signing in again updates the profile but not the email.
+	again, err := s.UpsertAuthUser(ctx, "g1", "changed@example.com", "New Name", "https://example.com/a.png")
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, again.ID)
+	assert.Equal(t, "g1@example.com", again.Email)
+	assert.Equal(t, "New Name", again.Name)
+
+	require.NoError(t, s.UpdateUserGeminiKey(ctx, u.ID, "key-1"))
+	got, err := s.GetAuthUser(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", got.GeminiAPIKey)
+
+	// Only admins' keys are shared.
+	_, err = s.GetAnyAdminAPIKey(ctx)
+	assert.Error(t, err)
+
+	users, err := s.GetAllUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Empty(t, users[0].GeminiAPIKey, "keys are redacted")
+
+	_, err = s.GetAuthUser(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.Error(t, err)
+}
+
+func TestAuthCodes(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	u := newUser(t, s, "app")
+	const redirect = "hnstation://auth"
+
+	code, err := s.CreateAuthCode(ctx, u.ID, "challenge", redirect, time.Minute)
+	require.NoError(t, err)
+
+	// A code is only good for the redirect URI it was issued for, and a
+	// mismatch doesn't use it up.
+	_, _, err = s.RedeemAuthCode(ctx, code, "hnstation://other")
+	assert.ErrorIs(t, err, storage.ErrInvalidToken)
+
+	userID, challenge, err := s.RedeemAuthCode(ctx, code, redirect)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, userID)
+	assert.Equal(t, "challenge", challenge)
+
+	_, _, err = s.RedeemAuthCode(ctx, code, redirect)
+	assert.ErrorIs(t, err, storage.ErrInvalidToken, "codes are single-use")
+
+	expired, err := s.CreateAuthCode(ctx, u.ID, "challenge", redirect, -time.Second)
+	require.NoError(t, err)
+	_, _, err = s.RedeemAuthCode(ctx, expired, redirect)
+	assert.ErrorIs(t, err, storage.ErrInvalidToken, "expired")
+
+	_, _, err = s.RedeemAuthCode(ctx, "not-a-code", redirect)
+	assert.ErrorIs(t, err, storage.ErrInvalidToken)
+}
+
+func TestInteractions(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, s.UpsertStory(ctx, story(id, "Story", 1, time.Now())))
+	}
+	u := newUser(t, s, "g1")
+
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 1, nil, ptr(true), nil))
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 2, ptr(true), ptr(true), nil))
+	require.NoError(t, s.SetInteractionNote(ctx, u.ID, 2, "worth rereading"))
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 3, nil, ptr(true), nil))
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 3, nil, ptr(false), nil))
+
+	saved, total, err := s.GetSavedStories(ctx, u.ID, "", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, saved, 2)
+
+	saved, total, err = s.GetSavedStories(ctx, u.ID, "rereading", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, saved, 1)
+	assert.EqualValues(t, 2, saved[0].ID)
+	assert.Equal(t, "worth rereading", saved[0].Note)
+
+	unread, err := s.GetUnreadSavedStories(ctx, u.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.EqualValues(t, 1, unread[0].ID)
+
+	// The story's counters follow the flags.
+	got, err := s.GetStory(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.SaveCount)
+	assert.Equal(t, 1, got.ReadCount)
+	got, err = s.GetStory(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.SaveCount)
+}
+
+func TestChatHistory(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+	u := newUser(t, s, "g1")
+
+	require.NoError(t, s.SaveChatMessage(ctx, u.ID, 1, "user", "What is this about?"))
+	require.NoError(t, s.SaveChatMessage(ctx, u.ID, 1, "model", "Containers."))
+
+	msgs, err := s.GetChatHistory(ctx, u.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "user", msgs[0].Role)
+	assert.Equal(t, "Containers.", msgs[1].Content)
+
+	other := newUser(t, s, "g2")
+	msgs, err = s.GetChatHistory(ctx, other.ID, 1)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestAppStats(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+	require.NoError(t, s.UpsertComment(ctx, storage.Comment{ID: 10, StoryID: 1, Text: "Hi", By: "a", PostedAt: time.Now()}))
+	u := newUser(t, s, "g1")
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 1, ptr(true), ptr(true), nil))
+
+	stats, err := s.GetAppStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalUsers)
+	assert.Equal(t, 1, stats.TotalStories)
+	assert.Equal(t, 1, stats.TotalComments)
+	assert.Equal(t, 1, stats.TotalInteractions)
+	require.Len(t, stats.MostSaved, 1)
+	assert.Equal(t, 1, stats.MostSaved[0].SaveCount)
+}
+
+func TestPruneStories(t *testing.T) {
+	pool := storagetest.NewPool(t)
+	s := storage.New(pool)
+	ctx := context.Background()
+	for id := int64(1); id <= 6; id++ {
+		require.NoError(t, s.UpsertStory(ctx, story(id, "Story", 1, time.Now())))
+	}
+	_, err := pool.Exec(ctx, `UPDATE stories SET created_at = NOW() - INTERVAL '30 days'`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE stories SET hn_rank = 1 WHERE id = 2`)
+	require.NoError(t, err)
+	u := newUser(t, s, "g1")
+	require.NoError(t, s.UpsertInteraction(ctx, u.ID, 3, nil, ptr(true), nil))
+	require.NoError(t, s.SetStoryFlags(ctx, 4, nil, ptr(true)))
+	ws, err := s.CreateWorkspace(ctx, u.ID, "Team")
+	require.NoError(t, err)
+	require.NoError(t, s.SetWorkspaceNote(ctx, ws.ID, 5, u.ID, "Read before Monday"))
+	require.NoError(t, s.WatchStory(ctx, u.ID, 6, ptr(100), nil, true))
+
+	require.NoError(t, s.PruneStories(ctx, 7, 10))
+
+	_, err = s.GetStory(ctx, 1)
+	assert.Error(t, err, "old and unreferenced")
+	for _, id := range []int{2, 3, 4, 5, 6} {
+		_, err := s.GetStory(ctx, id)
+		assert.NoError(t, err, "story %d is ranked, saved, frozen, annotated or watched", id)
+	}
+}
+
+func TestWorkspaceOwners(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	owner := newUser(t, s, "owner")
+	member := newUser(t, s, "member")
+	ws, err := s.CreateWorkspace(ctx, owner.ID, "Team")
+	require.NoError(t, err)
+	_, err = s.AddWorkspaceMember(ctx, ws.ID, member.Email, storage.RoleMember)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.UpdateWorkspaceMemberRole(ctx, ws.ID, owner.ID, storage.RoleAdmin), storage.ErrLastOwner)
+	assert.ErrorIs(t, s.RemoveWorkspaceMember(ctx, ws.ID, owner.ID), storage.ErrLastOwner)
+	members, err := s.GetWorkspaceMembers(ctx, ws.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2, "the refused changes were rolled back")
+
+	// With a second owner, either can step down.
+	require.NoError(t, s.UpdateWorkspaceMemberRole(ctx, ws.ID, member.ID, storage.RoleOwner))
+	require.NoError(t, s.UpdateWorkspaceMemberRole(ctx, ws.ID, owner.ID, storage.RoleMember))
+	require.NoError(t, s.RemoveWorkspaceMember(ctx, ws.ID, owner.ID))
+	assert.ErrorIs(t, s.RemoveWorkspaceMember(ctx, ws.ID, member.ID), storage.ErrLastOwner)
+}
+
+func TestWorkspaceQuota(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	u := newUser(t, s, "quota")
+	ws, err := s.CreateWorkspace(ctx, u.ID, "Team")
+	require.NoError(t, err)
+
+	// 0 is unlimited.
+	require.NoError(t, s.ConsumeWorkspaceQuota(ctx, ws.ID))
+	assert.NoError(t, s.CheckWorkspaceQuota(ctx, ws.ID))
+
+	require.NoError(t, s.UpdateWorkspace(ctx, ws.ID, nil, nil, ptr(2)))
+	assert.NoError(t, s.CheckWorkspaceQuota(ctx, ws.ID), "checking doesn't count")
+	assert.NoError(t, s.CheckWorkspaceQuota(ctx, ws.ID))
+	require.NoError(t, s.ConsumeWorkspaceQuota(ctx, ws.ID))
+	assert.ErrorIs(t, s.CheckWorkspaceQuota(ctx, ws.ID), storage.ErrQuotaExceeded)
+
+	assert.ErrorIs(t, s.CheckWorkspaceQuota(ctx, ws.ID+1000), storage.ErrNotFound)
+}
+
+func TestSettings(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	v, err := s.GetSetting(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	require.NoError(t, s.SetSetting(ctx, "ai_provider", "gemini"))
+	require.NoError(t, s.SetSetting(ctx, "ai_provider", "local"))
+	v, err = s.GetSetting(ctx, "ai_provider")
+	require.NoError(t, err)
+	assert.Equal(t, "local", v)
+}
+
+func TestSummaryJobs(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+
+	queued, err := s.EnqueueSummaryJob(ctx, 1, "m", "local", 0, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, queued)
+	queued, err = s.EnqueueSummaryJob(ctx, 1, "m", "local", 0, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, queued, "already pending")
+
+	job, err := s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 1, job.StoryID)
+	assert.Equal(t, "Story", job.Title)
+	assert.Equal(t, 1, job.Attempts)
+
+	none, err := s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none, "leased")
+
+	// Released jobs are due again without counting the attempt.
+	require.NoError(t, s.ReleaseSummaryJob(ctx, job.ID))
+	job, err = s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 1, job.Attempts)
+
+	// A failed attempt retries at retryAt.
+	require.NoError(t, s.MarkSummaryJobFailed(ctx, job.ID, "timeout", time.Now().Add(time.Hour), false))
+	none, err = s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+	require.NoError(t, s.MarkSummaryJobFailed(ctx, job.ID, "timeout", time.Now().Add(-time.Second), false))
+	job, err = s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 2, job.Attempts)
+
+	// Given up on, it blocks re-queueing during the cooldown.
+	require.NoError(t, s.MarkSummaryJobFailed(ctx, job.ID, "timeout", time.Now(), true))
+	queued, err = s.EnqueueSummaryJob(ctx, 1, "m", "local", 0, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, queued)
+
+	done, failed, err := s.SummaryJobOutcomes(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, done)
+	assert.Equal(t, 1, failed)
+
+	got, err := s.GetSummaryJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, storage.JobFailed, got.Status)
+	require.NotNil(t, got.LastError)
+	assert.Equal(t, "timeout", *got.LastError)
+
+	_, err = s.GetSummaryJob(ctx, 999)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestDiscussionJobs(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+	u := newUser(t, s, "g1")
+
+	id, err := s.EnqueueDiscussionJob(ctx, 1, u.ID)
+	require.NoError(t, err)
+	again, err := s.EnqueueDiscussionJob(ctx, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, id, again, "a pending job is reused")
+
+	// Kinds are claimed separately.
+	job, err := s.ClaimSummaryJob(ctx, storage.JobArticle, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	job, err = s.ClaimSummaryJob(ctx, storage.JobDiscussion, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, id, job.ID)
+	assert.Equal(t, u.ID, job.UserID)
+
+	require.NoError(t, s.MarkSummaryJobDone(ctx, job.ID))
+	done, _, err := s.SummaryJobOutcomes(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, done)
+
+	next, err := s.EnqueueDiscussionJob(ctx, 1, u.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, next, "finished jobs aren't reused")
+}
+
+func TestOutbox(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	msg := storage.OutboxMessage{EventType: storage.EventAlert, Channel: "email", Recipient: "ops@example.com", DedupKey: "alert:1"}
+	require.NoError(t, s.EnqueueNotification(ctx, msg))
+	require.NoError(t, s.EnqueueNotification(ctx, msg), "duplicates are ignored")
+	require.NoError(t, s.EnqueueNotification(ctx, storage.OutboxMessage{EventType: storage.EventSummaryReady}))
+
+	alerts := outboxEvents(t, s, storage.EventAlert)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "ops@example.com", alerts[0].Recipient)
+	assert.JSONEq(t, `{}`, string(alerts[0].Payload))
+	assert.Equal(t, 1, alerts[0].Attempts)
+
+	// Claimed rows are leased; the rest are still claimable.
+	all := outboxEvents(t, s)
+	require.Len(t, all, 1)
+	assert.Equal(t, storage.EventSummaryReady, all[0].EventType)
+
+	require.NoError(t, s.MarkOutboxSent(ctx, alerts[0].ID))
+	require.NoError(t, s.MarkOutboxFailed(ctx, all[0].ID, "smtp down", time.Now().Add(-time.Second), false))
+	retried := outboxEvents(t, s)
+	require.Len(t, retried, 1)
+	assert.Equal(t, all[0].ID, retried[0].ID)
+	assert.Equal(t, 2, retried[0].Attempts)
+}
+
+func TestIngestRuns(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	last, err := s.LastIngestSuccess(ctx)
+	require.NoError(t, err)
+	assert.True(t, last.IsZero())
+
+	id, err := s.StartIngestRun(ctx, true)
+	require.NoError(t, err)
+	full, err := s.LastFullSync(ctx)
+	require.NoError(t, err)
+	assert.True(t, full.IsZero(), "still running")
+
+	require.NoError(t, s.FinishIngestRun(ctx, id, storage.RunDone))
+	full, err = s.LastFullSync(ctx)
+	require.NoError(t, err)
+	assert.False(t, full.IsZero())
+	last, err = s.LastIngestSuccess(ctx)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), last, time.Minute)
+}
+
+func TestTopicFollows(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	u := newUser(t, s, "g1")
+
+	needs, err := s.NeedsOnboarding(ctx, u.ID)
+	require.NoError(t, err)
+	assert.True(t, needs)
+
+	require.NoError(t, s.FollowTopics(ctx, u.ID, []string{"Rust", "rust", "Go"}))
+	needs, err = s.NeedsOnboarding(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, needs)
+
+	topics, err := s.GetFollowedTopics(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, topics, 2)
+
+	require.NoError(t, s.UnfollowTopic(ctx, u.ID, "RUST"))
+	topics, err = s.GetFollowedTopics(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, topics, 1)
+}
+
+func TestMetricSnapshots(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Story", 1, time.Now())))
+
+	require.NoError(t, s.SnapshotMetrics(ctx, time.Now()))
+	require.NoError(t, s.SnapshotMetrics(ctx, time.Now()), "snapshots of a day replace each other")
+
+	history, err := s.GetMetricHistory(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].StoriesIngested)
+	assert.Nil(t, history[0].SummaryLatencyAvgMS)
+}
+
+// HN items come in shapes the usual fixtures don't: jobs with no comments,
+// text posts with no URL, dead posts with no title or author.
+func TestUpsertStoryEdgeCaseItems(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+
+	items := []storage.Story{
+		{ID: 1, Title: "Acme (YC S21) is hiring engineers", URL: "https://acme.example/jobs", Score: 1, By: "acme", PostedAt: time.Now()},
+		{ID: 2, Title: "Ask HN: How do you test SQL?", Text: "<p>Curious what people use.", Score: 5, By: "asker", Descendants: 2, PostedAt: time.Now()},
+		{ID: 3, PostedAt: time.Now()},
+	}
+	for _, it := range items {
+		require.NoError(t, s.UpsertStory(ctx, it))
+	}
+
+	for _, want := range items {
+		got, err := s.GetStory(ctx, int(want.ID))
+		require.NoError(t, err)
+		assert.Equal(t, want.Title, got.Title)
+		assert.Equal(t, want.URL, got.URL)
+		assert.Equal(t, want.Text, got.Text)
+		assert.Equal(t, want.By, got.By)
+	}
+
+	stories, total, err := s.GetStories(ctx, 10, 0, "latest", nil, nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, stories, 3)
+}
+
+// Rows written before the storage layer always set them can have NULL in
+// the nullable text columns; they read back as empty strings.
+func TestNullColumns(t *testing.T) {
+	pool := storagetest.NewPool(t)
+	s := storage.New(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `INSERT INTO stories (id, title, url, by, posted_at) VALUES (1, 'Legacy story', NULL, NULL, NOW())`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `INSERT INTO comments (id, story_id, text, by, posted_at) VALUES (10, 1, NULL, NULL, NOW()), (11, 1, 'Reply', NULL, NOW())`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE comments SET parent_id = 10 WHERE id = 11`)
+	require.NoError(t, err)
+	var userID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO auth_users (google_id, email) VALUES ('g-null', 'null@example.com') RETURNING id`).Scan(&userID))
+	_, err = s.ReindexSearchVectors(ctx)
+	require.NoError(t, err)
+
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, got.URL)
+	assert.Empty(t, got.By)
+
+	_, _, err = s.GetStories(ctx, 10, 0, "", nil, nil, "", false)
+	require.NoError(t, err)
+	_, err = s.GetStoriesByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	found, err := s.SearchKeyword(ctx, "legacy", 10)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	comments, err := s.GetComments(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Empty(t, comments[0].Text)
+	assert.Empty(t, comments[0].By)
+	_, err = s.GetCommentsPage(ctx, 1, 10, 0)
+	require.NoError(t, err)
+	thread, err := s.GetCommentThread(ctx, 11)
+	require.NoError(t, err)
+	assert.Len(t, thread.Ancestors, 1)
+	_, err = s.GetTopComments(ctx, 1, 5)
+	require.NoError(t, err)
+
+	user, err := s.GetAuthUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, user.Name)
+	assert.Empty(t, user.AvatarURL)
+	_, err = s.GetAllUsers(ctx)
+	require.NoError(t, err)
+
+	// Saving pulls the story into the saved and popular lists.
+	require.NoError(t, s.UpsertInteraction(ctx, userID, 1, nil, ptr(true), nil))
+	_, _, err = s.GetStories(ctx, 10, 0, "", nil, nil, userID, false)
+	require.NoError(t, err)
+	_, _, err = s.GetSavedStories(ctx, userID, "", 10, 0)
+	require.NoError(t, err)
+	_, err = s.GetUnreadSavedStories(ctx, userID, 10)
+	require.NoError(t, err)
+	_, err = s.GetMostSaved(ctx, 10)
+	require.NoError(t, err)
+	popular, err := s.GetPopularStories(ctx, storage.PopularBySaves, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, popular, 1)
+
+	local, err := s.AddLocalComment(ctx, 1, userID, nil, "Nice")
+	require.NoError(t, err)
+	assert.Empty(t, local.AuthorName)
+	_, err = s.GetLocalComments(ctx, 1)
+	require.NoError(t, err)
+}
+
+func TestHNJobs(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, s.UpsertStory(ctx, story(1, "A story", 10, now)))
+	for id := int64(2); id <= 4; id++ {
+		job := story(id, "Acme is hiring", 1, now.Add(time.Duration(id)*time.Minute))
+		job.Type = storage.ItemJob
+		require.NoError(t, s.UpsertStory(ctx, job))
+	}
+
+	got, err := s.GetStory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ItemStory, got.Type, "no type is a story")
+
+	jobs, total, err := s.GetHNJobs(ctx, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, jobs, 2)
+	assert.EqualValues(t, 4, jobs[0].ID, "newest first")
+	assert.Equal(t, storage.ItemJob, jobs[0].Type)
+
+	jobs, _, err = s.GetHNJobs(ctx, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.EqualValues(t, 2, jobs[0].ID)
+
+	for _, sort := range []string{"", "latest", "votes"} {
+		stories, total, err := s.GetStories(ctx, 10, 0, sort, nil, nil, "", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total, "jobs aren't listed with stories (sort %q)", sort)
+		require.Len(t, stories, 1)
+		assert.EqualValues(t, 1, stories[0].ID)
+	}
+}
+
+func TestStoryWatches(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	u := newUser(t, s, "watcher")
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Watched", 10, time.Now())))
+	outboxEvents(t, s) // drop the story.new event
+
+	assert.ErrorIs(t, s.WatchStory(ctx, u.ID, 99, ptr(50), nil, true), storage.ErrNotFound)
+	require.NoError(t, s.WatchStory(ctx, u.ID, 1, ptr(50), ptr(5), true))
+	watches, err := s.GetStoryWatches(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, watches, 1)
+	assert.Equal(t, "Watched", watches[0].Title)
+	assert.Equal(t, ptr(50), watches[0].ScoreThreshold)
+
+	alerts, err := s.GetDueWatchAlerts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// The story passes its score threshold and gets summarized.
+	st := story(1, "Watched", 60, time.Now())
+	require.NoError(t, s.UpsertStory(ctx, st))
+	require.NoError(t, s.UpdateStorySummary(ctx, 1, "A summary."))
+	alerts, err = s.GetDueWatchAlerts(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, storage.WatchScore, alerts[0].Reason)
+	assert.Equal(t, 50, alerts[0].Threshold)
+	assert.Equal(t, storage.WatchSummary, alerts[1].Reason)
+	assert.Equal(t, u.Email, alerts[0].Email)
+
+	prefs := storage.DefaultNotificationPreferences()
+	for _, a := range alerts {
+		require.NoError(t, s.EnqueueWatchAlert(ctx, a, &prefs, "subject", "body"))
+	}
+	sent := outboxEvents(t, s, storage.EventStoryWatch)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "email", sent[0].Channel)
+	assert.Equal(t, u.Email, sent[0].Recipient)
+
+	// Each fires once; changing a threshold re-arms it.
+	alerts, err = s.GetDueWatchAlerts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+	require.NoError(t, s.WatchStory(ctx, u.ID, 1, ptr(55), ptr(5), true))
+	alerts, err = s.GetDueWatchAlerts(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, 55, alerts[0].Threshold)
+
+	require.NoError(t, s.UnwatchStory(ctx, u.ID, 1))
+	watches, err = s.GetStoryWatches(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Empty(t, watches)
+}
+
+func TestShareStoryByEmail(t *testing.T) {
+	s := storagetest.New(t)
+	ctx := context.Background()
+	u := newUser(t, s, "sharer")
+	require.NoError(t, s.UpsertStory(ctx, story(1, "Shared", 10, time.Now())))
+	outboxEvents(t, s) // drop the story.new event
+
+	assert.ErrorIs(t, s.ShareStoryByEmail(ctx, u.ID, 99, "friend@example.com", "subject", "body", 2, time.Hour), storage.ErrNotFound)
+	require.NoError(t, s.ShareStoryByEmail(ctx, u.ID, 1, "friend@example.com", "subject", "body", 2, time.Hour))
+	require.NoError(t, s.ShareStoryByEmail(ctx, u.ID, 1, "other@example.com", "subject", "body", 2, time.Hour))
+	assert.ErrorIs(t, s.ShareStoryByEmail(ctx, u.ID, 1, "third@example.com", "subject", "body", 2, time.Hour), storage.ErrRateLimited)
+
+	sent := outboxEvents(t, s, storage.EventStoryShare)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "email", sent[0].Channel)
+	assert.Equal(t, "friend@example.com", sent[0].Recipient)
+	assert.Empty(t, sent[0].UserID)
+
+	// Another user's allowance is separate.
+	other := newUser(t, s, "other-sharer")
+	require.NoError(t, s.ShareStoryByEmail(ctx, other.ID, 1, "friend@example.com", "subject", "body", 2, time.Hour))
+}
@@ -0,0 +1,23 @@
+package storage
+
+import "context"
+
+// SetStoryFlags pins/unpins and freezes/unfreezes a story; nil leaves a flag
+// unchanged. Re-pinning a pinned story keeps its original pin time.
+func (s *Store) SetStoryFlags(ctx context.Context, id int, pinned, frozen *bool) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE stories SET
+			pinned_at = CASE WHEN $2::boolean IS NULL THEN pinned_at
+				WHEN $2 THEN COALESCE(pinned_at, NOW())
+				ELSE NULL END,
+			is_frozen = COALESCE($3, is_frozen)
+		WHERE id = $1
+	`, id, pinned, frozen)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
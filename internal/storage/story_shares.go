@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Outbox event type for stories shared by email.
+const EventStoryShare = "story.share"
+
+// ErrRateLimited is returned when a user has used up an action's allowance
+// for now.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ShareStoryByEmail queues an email to recipient and records the share. It
+// returns ErrRateLimited if the user already shared limit stories within the
+// window, and ErrNotFound if the story doesn't exist.
+func (s *Store) ShareStoryByEmail(ctx context.Context, userID string, storyID int, recipient, subject, body string, limit int, window time.Duration) error {
+	evt, err := newEvent(EventStoryShare, map[string]string{"subject": subject, "body": body})
+	if err != nil {
+		return err
+	}
+	// Not addressed to a user: the recipient isn't the sharer, so the
+	// sharer's quiet hours don't apply.
+	evt.Channel = "email"
+	evt.Recipient = recipient
+
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		// Serializes a user's shares so concurrent requests can't overrun the limit.
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM auth_users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+			return err
+		}
+		var recent int
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM story_shares
+			WHERE user_id = $1 AND created_at > NOW() - make_interval(secs => $2)
+		`, userID, window.Seconds()).Scan(&recent); err != nil {
+			return err
+		}
+		if recent >= limit {
+			return ErrRateLimited
+		}
+
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO story_shares (user_id, story_id, recipient)
+			SELECT $1, id, $3 FROM stories WHERE id = $2
+		`, userID, storyID, recipient)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return insertOutbox(ctx, tx, evt)
+	})
+}
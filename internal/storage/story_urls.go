@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// storyURLVariants returns the spellings of a page URL that HN submissions
+// commonly use for the same page: with or without "www.", http or https and
+// a trailing slash. Fragments are dropped.
+func storyURLVariants(rawURL string) []string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return []string{rawURL}
+	}
+	u.Fragment = ""
+	host := strings.TrimPrefix(u.Host, "www.")
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+
+	var variants []string
+	for _, scheme := range []string{"https", "http"} {
+		for _, h := range []string{host, "www." + host} {
+			for _, p := range []string{path, path + "/"} {
+				v := scheme + "://" + h + p
+				if u.RawQuery != "" {
+					v += "?" + u.RawQuery
+				}
+				variants = append(variants, v)
+			}
+		}
+	}
+	return variants
+}
+
+// FindStoryByURL returns the highest-scoring story submitted for the page at
+// rawURL, or ErrNotFound.
+func (s *Store) FindStoryByURL(ctx context.Context, rawURL string) (*Story, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics, summary_stale
+		FROM stories
+		WHERE url = ANY($1)
+		ORDER BY score DESC
+		LIMIT 1
+	`
+	var story Story
+	err := s.db.QueryRow(ctx, query, storyURLVariants(rawURL)).Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.SummaryStale)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
+// GetSubmissionsByURL returns the other stories submitted for the same page
+// as rawURL, most discussed first.
+func (s *Store) GetSubmissionsByURL(ctx context.Context, rawURL string, excludeID int64) ([]Story, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics
+		FROM stories
+		WHERE url = ANY($1) AND id <> $2
+		ORDER BY descendants DESC
+		LIMIT 20
+	`
+	return s.querySummarized(ctx, query, storyURLVariants(rawURL), excludeID)
+}
+
+// SameStoryURL reports whether two URLs point to the same page by the rules
+// of storyURLVariants.
+func SameStoryURL(a, b string) bool {
+	return slices.Contains(storyURLVariants(a), b)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// newCommentCountSQL is the number of comments a user hasn't seen on a story,
+// NULL if they have never opened it. It needs stories s joined with the
+// user's user_interactions ui.
+const newCommentCountSQL = `CASE WHEN ui.seen_descendants IS NULL THEN NULL ELSE GREATEST(s.descendants - ui.seen_descendants, 0) END`
+
+// StoryVisit is the user's previous visit to a story.
+type StoryVisit struct {
+	SeenAt          *time.Time // nil on the first visit
+	SeenDescendants *int
+}
+
+// RecordStoryVisit marks the story as seen now with its current comment count
+// and returns the previous visit.
+func (s *Store) RecordStoryVisit(ctx context.Context, userID string, storyID, descendants int) (StoryVisit, error) {
+	var prev StoryVisit
+	err := s.db.QueryRow(ctx, `
+		WITH prev AS (
+			SELECT last_seen_at, seen_descendants FROM user_interactions WHERE user_id = $1 AND story_id = $2
+		)
+		INSERT INTO user_interactions (user_id, story_id, last_seen_at, seen_descendants, updated_at)
+		VALUES ($1, $2, NOW(), $3, NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET last_seen_at = NOW(), seen_descendants = $3
+		RETURNING (SELECT last_seen_at FROM prev), (SELECT seen_descendants FROM prev)
+	`, userID, storyID, descendants).Scan(&prev.SeenAt, &prev.SeenDescendants)
+	return prev, err
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Outbox event type for story watch notifications.
+const EventStoryWatch = "story.watch"
+
+// Why a watch fired.
+const (
+	WatchScore    = "score"
+	WatchComments = "comments"
+	WatchSummary  = "summary"
+)
+
+// StoryWatch is a user's watch on a story. Each threshold, and the summary,
+// notifies once; changing a threshold re-arms it.
+type StoryWatch struct {
+	StoryID            int64      `json:"story_id"`
+	Title              string     `json:"title"`
+	Score              int        `json:"score"`
+	Descendants        int        `json:"descendants"`
+	ScoreThreshold     *int       `json:"score_threshold"`   // nil: the score isn't watched
+	CommentThreshold   *int       `json:"comment_threshold"` // nil: the comment count isn't watched
+	NotifySummary      bool       `json:"notify_summary"`
+	ScoreNotifiedAt    *time.Time `json:"score_notified_at"`
+	CommentsNotifiedAt *time.Time `json:"comments_notified_at"`
+	SummaryNotifiedAt  *time.Time `json:"summary_notified_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// WatchAlert is a watch whose story crossed a threshold, or got its summary,
+// and that hasn't been notified yet.
+type WatchAlert struct {
+	UserID    string
+	Email     string
+	Reason    string // WatchScore, WatchComments or WatchSummary
+	Threshold int    // the one reached; 0 for WatchSummary
+	Story     Story  // ID, Title, URL, Score, Descendants and Summary
+}
+
+// WatchStory starts watching a story or replaces the watch's settings. It
+// returns ErrNotFound if the story doesn't exist.
+func (s *Store) WatchStory(ctx context.Context, userID string, storyID int, scoreThreshold, commentThreshold *int, notifySummary bool) error {
+	query := `
+		INSERT INTO story_watches (user_id, story_id, score_threshold, comment_threshold, notify_summary)
+		SELECT $1, id, $3, $4, $5 FROM stories WHERE id = $2
+		ON CONFLICT (user_id, story_id) DO UPDATE
+		SET score_threshold = EXCLUDED.score_threshold,
+			comment_threshold = EXCLUDED.comment_threshold,
+			notify_summary = EXCLUDED.notify_summary,
+			score_notified_at = CASE WHEN story_watches.score_threshold IS DISTINCT FROM EXCLUDED.score_threshold
+				THEN NULL ELSE story_watches.score_notified_at END,
+			comments_notified_at = CASE WHEN story_watches.comment_threshold IS DISTINCT FROM EXCLUDED.comment_threshold
+				THEN NULL ELSE story_watches.comments_notified_at END
+	`
+	tag, err := s.db.Exec(ctx, query, userID, storyID, scoreThreshold, commentThreshold, notifySummary)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) UnwatchStory(ctx context.Context, userID string, storyID int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM story_watches WHERE user_id = $1 AND story_id = $2`, userID, storyID)
+	return err
+}
+
+// GetStoryWatches returns the user's watches with their stories' current
+// score and comment count, newest first.
+func (s *Store) GetStoryWatches(ctx context.Context, userID string) ([]StoryWatch, error) {
+	query := `
+		SELECT w.story_id, s.title, s.score, s.descendants, w.score_threshold, w.comment_threshold, w.notify_summary,
+		       w.score_notified_at, w.comments_notified_at, w.summary_notified_at, w.created_at
+		FROM story_watches w
+		INNER JOIN stories s ON s.id = w.story_id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC, w.story_id DESC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []StoryWatch
+	for rows.Next() {
+		var w StoryWatch
+		if err := rows.Scan(&w.StoryID, &w.Title, &w.Score, &w.Descendants, &w.ScoreThreshold, &w.CommentThreshold, &w.NotifySummary,
+			&w.ScoreNotifiedAt, &w.CommentsNotifiedAt, &w.SummaryNotifiedAt, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+// GetDueWatchAlerts returns a WatchAlert for every watched threshold a story
+// has reached, and every watched summary that has arrived, that hasn't been
+// notified yet.
+func (s *Store) GetDueWatchAlerts(ctx context.Context) ([]WatchAlert, error) {
+	query := `
+		SELECT w.user_id, COALESCE(u.email, ''), r.reason, r.threshold,
+		       s.id, s.title, COALESCE(s.url, ''), s.score, s.descendants, s.summary
+		FROM story_watches w
+		INNER JOIN stories s ON s.id = w.story_id
+		INNER JOIN auth_users u ON u.id = w.user_id
+		CROSS JOIN LATERAL (VALUES
+			($1::text, w.score_threshold, w.score_notified_at IS NULL AND s.score >= w.score_threshold),
+			($2::text, w.comment_threshold, w.comments_notified_at IS NULL AND s.descendants >= w.comment_threshold),
+			($3::text, 0, w.notify_summary AND w.summary_notified_at IS NULL AND s.summary IS NOT NULL AND s.summary != '')
+		) AS r(reason, threshold, due)
+		WHERE r.due
+		ORDER BY w.user_id, s.id, r.reason
+	`
+	rows, err := s.db.Query(ctx, query, WatchScore, WatchComments, WatchSummary)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []WatchAlert
+	for rows.Next() {
+		var a WatchAlert
+		if err := rows.Scan(&a.UserID, &a.Email, &a.Reason, &a.Threshold,
+			&a.Story.ID, &a.Story.Title, &a.Story.URL, &a.Story.Score, &a.Story.Descendants, &a.Story.Summary); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// EnqueueWatchAlert records the alert in the outbox for each of the user's
+// alert channels and marks it notified in one transaction, so it is sent
+// once. An alert with no channel to go to is marked notified all the same.
+func (s *Store) EnqueueWatchAlert(ctx context.Context, a WatchAlert, prefs *NotificationPreferences, subject, body string) error {
+	var column string
+	switch a.Reason {
+	case WatchScore:
+		column = "score_notified_at"
+	case WatchComments:
+		column = "comments_notified_at"
+	case WatchSummary:
+		column = "summary_notified_at"
+	default:
+		return fmt.Errorf("unknown watch reason %q", a.Reason)
+	}
+
+	evt, err := newEvent(EventStoryWatch, map[string]interface{}{
+		"subject":  subject,
+		"body":     body,
+		"story_id": a.Story.ID,
+		"reason":   a.Reason,
+	})
+	if err != nil {
+		return err
+	}
+	evt.UserID = a.UserID
+	// The threshold is part of the key so a re-armed watch notifies again.
+	deliveries := userDeliveries(evt, a.Email, prefs, func(channel string) string {
+		return fmt.Sprintf("%s:%s:%d:%s:%d:%s", EventStoryWatch, a.UserID, a.Story.ID, a.Reason, a.Threshold, channel)
+	})
+
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		for _, d := range deliveries {
+			if err := insertOutbox(ctx, tx, d); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `UPDATE story_watches SET `+column+` = NOW() WHERE user_id = $1 AND story_id = $2`, a.UserID, a.Story.ID)
+		return err
+	})
+}
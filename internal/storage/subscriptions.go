@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Outbox event type for topic digests.
+const EventTopicDigest = "digest.topic"
+
+// TopicSubscription is a user's subscription to a weekly topic digest.
+type TopicSubscription struct {
+	UserID       string     `json:"user_id"`
+	Email        string     `json:"-"`
+	Topic        string     `json:"topic"`
+	Enabled      bool       `json:"enabled"`
+	Frequency    string     `json:"-"` // digest frequency, filled by GetDueTopicSubscriptions
+	LastDigestAt *time.Time `json:"last_digest_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NormalizeTopic lower-cases and trims a topic so "Rust" and " rust" subscribe to the same digest.
+func NormalizeTopic(topic string) string {
+	return strings.ToLower(strings.TrimSpace(topic))
+}
+
+func (s *Store) SubscribeTopic(ctx context.Context, userID, topic string) error {
+	query := `INSERT INTO topic_subscriptions (user_id, topic) VALUES ($1, $2) ON CONFLICT (user_id, topic) DO UPDATE SET enabled = TRUE`
+	_, err := s.db.Exec(ctx, query, userID, NormalizeTopic(topic))
+	return err
+}
+
+func (s *Store) UnsubscribeTopic(ctx context.Context, userID, topic string) error {
+	query := `DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic = $2`
+	_, err := s.db.Exec(ctx, query, userID, NormalizeTopic(topic))
+	return err
+}
+
+func (s *Store) GetTopicSubscriptions(ctx context.Context, userID string) ([]TopicSubscription, error) {
+	query := `SELECT user_id, topic, enabled, last_digest_at, created_at FROM topic_subscriptions WHERE user_id = $1 ORDER BY topic ASC`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []TopicSubscription
+	for rows.Next() {
+		var sub TopicSubscription
+		if err := rows.Scan(&sub.UserID, &sub.Topic, &sub.Enabled, &sub.LastDigestAt, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// GetDueTopicSubscriptions returns enabled subscriptions whose last digest is
+// older than the user's digest frequency (or that never received one), with
+// the subscriber's email. Users with digests turned off are skipped.
+func (s *Store) GetDueTopicSubscriptions(ctx context.Context) ([]TopicSubscription, error) {
+	query := `
+		SELECT ts.user_id, u.email, ts.topic, ts.enabled, COALESCE(np.digest_frequency, 'weekly') AS freq, ts.last_digest_at, ts.created_at
+		FROM topic_subscriptions ts
+		INNER JOIN auth_users u ON u.id = ts.user_id
+		LEFT JOIN notification_preferences np ON np.user_id = ts.user_id
+		WHERE ts.enabled = TRUE
+		  AND COALESCE(np.digest_frequency, 'weekly') != 'off'
+		  AND (ts.last_digest_at IS NULL OR ts.last_digest_at < NOW() -
+		       CASE COALESCE(np.digest_frequency, 'weekly') WHEN 'daily' THEN INTERVAL '1 day' ELSE INTERVAL '7 days' END)
+		ORDER BY ts.topic ASC, ts.user_id ASC
+	`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []TopicSubscription
+	for rows.Next() {
+		var sub TopicSubscription
+		if err := rows.Scan(&sub.UserID, &sub.Email, &sub.Topic, &sub.Enabled, &sub.Frequency, &sub.LastDigestAt, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// GetSummarizedStoriesByTopic returns summarized stories tagged with topic
+// (case-insensitive) posted since the given time, best first.
+func (s *Store) GetSummarizedStoriesByTopic(ctx context.Context, topic string, since time.Time, limit int) ([]Story, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), score, COALESCE(by, ''), descendants, posted_at, created_at, hn_rank, summary, topics
+		FROM stories
+		WHERE summary IS NOT NULL AND summary != '' AND posted_at >= $2
+		  AND EXISTS (SELECT 1 FROM unnest(topics) t WHERE lower(t) = $1)
+		ORDER BY score DESC
+		LIMIT $3
+	`
+	return s.querySummarized(ctx, query, NormalizeTopic(topic), since, limit)
+}
+
+// EnqueueTopicDigest records a digest in the outbox for each of the user's
+// alert channels and advances the subscription's last_digest_at in one
+// transaction, so a crash neither loses nor repeats the digest.
+func (s *Store) EnqueueTopicDigest(ctx context.Context, sub TopicSubscription, prefs *NotificationPreferences, subject, body string, sentAt time.Time) error {
+	evt, err := newEvent(EventTopicDigest, map[string]string{"subject": subject, "body": body})
+	if err != nil {
+		return err
+	}
+	evt.UserID = sub.UserID
+
+	bucket := sentAt.Format("2006-01-02")
+	if sub.Frequency != DigestDaily {
+		year, week := sentAt.ISOWeek()
+		bucket = fmt.Sprintf("%d-W%02d", year, week)
+	}
+
+	deliveries := userDeliveries(evt, sub.Email, prefs, func(channel string) string {
+		return fmt.Sprintf("%s:%s:%s:%s:%s", EventTopicDigest, sub.UserID, sub.Topic, channel, bucket)
+	})
+
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		for _, d := range deliveries {
+			if err := insertOutbox(ctx, tx, d); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `UPDATE topic_subscriptions SET last_digest_at = $3 WHERE user_id = $1 AND topic = $2`, sub.UserID, sub.Topic, sentAt)
+		return err
+	})
+}
+
+// SkipTopicDigest advances last_digest_at without sending anything, used when
+// a topic had no stories in the period.
+func (s *Store) SkipTopicDigest(ctx context.Context, sub TopicSubscription, at time.Time) error {
+	_, err := s.db.Exec(ctx, `UPDATE topic_subscriptions SET last_digest_at = $3 WHERE user_id = $1 AND topic = $2`, sub.UserID, sub.Topic, at)
+	return err
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FallbackComments is how many top-level comments a fallback summary is
+// built from.
+const FallbackComments = 15
+
+// fallbackMaxChars caps the comment text sent to the model.
+const fallbackMaxChars = 8000
+
+// GetTopComments returns up to limit of a story's top-level comments, those
+// with the most replies first. Spam suspects are left out.
+func (s *Store) GetTopComments(ctx context.Context, storyID, limit int) ([]Comment, error) {
+	query := `
+		SELECT c.id, c.story_id, c.parent_id, COALESCE(c.text, ''), COALESCE(c.by, ''), c.posted_at
+		FROM comments c
+		WHERE c.story_id = $1 AND c.parent_id IS NULL AND c.text != '' AND NOT c.spam_suspect
+		ORDER BY (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) DESC, c.posted_at ASC
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, storyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.StoryID, &c.ParentID, &c.Text, &c.By, &c.PostedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// FallbackSummaryInput builds the text to summarize for a story whose article
+// can't be fetched or extracted: its HN title and top comments.
+func FallbackSummaryInput(title string, comments []Comment) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Title: %s\n\n", title)
+	sb.WriteString("The linked article could not be retrieved. Summarize what it is about from the title and these top Hacker News comments:\n")
+	for _, c := range comments {
+		line := fmt.Sprintf("- %s: %s\n", c.By, c.Text)
+		if sb.Len()+len(line) > fallbackMaxChars {
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Summary job statuses.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobFailed  = "failed"
+	JobDone    = "done"
+)
+
+// Summary job kinds. Ingest runs article jobs; the API server runs the
+// discussion jobs its users queue.
+const (
+	JobArticle    = "article"
+	JobDiscussion = "discussion"
+)
+
+// SummaryJob is a queued background summarization of a story. URL, Text and
+// Title are loaded from the story when the job is claimed.
+type SummaryJob struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	StoryID   int       `json:"story_id"`
+	UserID    string    `json:"-"` // who queued a discussion job; "" for ingest's jobs
+	URL       string    `json:"url"`
+	Text      string    `json:"-"` // body of text posts, summarized in place of an article
+	Title     string    `json:"title"`
+	Model     string    `json:"model"`
+	Provider  string    `json:"provider"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	LastError *string   `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EnqueueSummaryJob queues a story's article for summarization, due after
+// delay. It reports false, doing nothing, if the story already has a pending
+// or running article job, or if its last one failed less than failedCooldown
+// ago.
+func (s *Store) EnqueueSummaryJob(ctx context.Context, storyID int, model, provider string, delay, failedCooldown time.Duration) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO summary_jobs (story_id, kind, model, provider, run_after)
+		SELECT $1, 'article', $2, $3, NOW() + make_interval(secs => $5)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM summary_jobs
+			WHERE story_id = $1 AND kind = 'article' AND status = 'failed' AND updated_at > NOW() - make_interval(secs => $4)
+		)
+		ON CONFLICT (story_id, kind) WHERE status IN ('pending', 'running') DO NOTHING
+	`, storyID, model, provider, failedCooldown.Seconds(), delay.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnqueueDiscussionJob queues a summary of a story's discussion for userID
+// ("" in local mode) and returns the job's ID. If the story already has one
+// pending or running, that job's ID is returned instead.
+func (s *Store) EnqueueDiscussionJob(ctx context.Context, storyID int, userID string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO summary_jobs (story_id, kind, user_id)
+		VALUES ($1, 'discussion', nullif($2, '')::uuid)
+		ON CONFLICT (story_id, kind) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING id
+	`, storyID, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = s.db.QueryRow(ctx, `
+			SELECT id FROM summary_jobs
+			WHERE story_id = $1 AND kind = 'discussion' AND status IN ('pending', 'running')
+		`, storyID).Scan(&id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue discussion job: %w", err)
+	}
+	return id, nil
+}
+
+// GetSummaryJob loads a job, or returns ErrNotFound.
+func (s *Store) GetSummaryJob(ctx context.Context, id int64) (*SummaryJob, error) {
+	var j SummaryJob
+	err := s.db.QueryRow(ctx, `
+		SELECT j.id, j.kind, j.story_id, COALESCE(j.user_id::text, ''), COALESCE(s.url, ''), s.title, j.model, j.provider, j.status, j.attempts, j.last_error, j.created_at
+		FROM summary_jobs j JOIN stories s ON s.id = j.story_id
+		WHERE j.id = $1
+	`, id).Scan(&j.ID, &j.Kind, &j.StoryID, &j.UserID, &j.URL, &j.Title, &j.Model, &j.Provider, &j.Status, &j.Attempts, &j.LastError, &j.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summary job: %w", err)
+	}
+	return &j, nil
+}
+
+// ClaimSummaryJob leases the next due job of kind for lease duration, or
+// returns nil if none is due. Running jobs whose lease expired are claimable
+// again.
+func (s *Store) ClaimSummaryJob(ctx context.Context, kind string, lease time.Duration) (*SummaryJob, error) {
+	var j SummaryJob
+	err := s.db.QueryRow(ctx, `
+		WITH claimed AS (
+			UPDATE summary_jobs
+			SET status = 'running', locked_until = NOW() + make_interval(secs => $1),
+				attempts = attempts + 1, updated_at = NOW()
+			WHERE id = (
+				SELECT id FROM summary_jobs
+				WHERE kind = $2
+				  AND ((status = 'pending' AND run_after <= NOW())
+				   OR (status = 'running' AND locked_until < NOW()))
+				ORDER BY run_after ASC, id ASC
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, kind, story_id, user_id, model, provider, status, attempts, last_error, created_at
+		)
+		SELECT c.id, c.kind, c.story_id, COALESCE(c.user_id::text, ''), COALESCE(s.url, ''), COALESCE(s.text, ''), s.title, c.model, c.provider, c.status, c.attempts, c.last_error, c.created_at
+		FROM claimed c JOIN stories s ON s.id = c.story_id
+	`, lease.Seconds(), kind).Scan(&j.ID, &j.Kind, &j.StoryID, &j.UserID, &j.URL, &j.Text, &j.Title, &j.Model, &j.Provider, &j.Status, &j.Attempts, &j.LastError, &j.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim summary job: %w", err)
+	}
+	return &j, nil
+}
+
+// MarkSummaryJobDone records a finished job and how long it ran: a running
+// job's updated_at is when it was claimed.
+func (s *Store) MarkSummaryJobDone(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE summary_jobs SET status = 'done', locked_until = NULL, last_error = NULL,
+			duration_ms = (EXTRACT(EPOCH FROM NOW() - updated_at) * 1000)::int, updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkSummaryJobFailed records a failed attempt. When dead is true the job is
+// marked failed for good; otherwise it becomes due again at retryAt.
+func (s *Store) MarkSummaryJobFailed(ctx context.Context, id int64, errMsg string, retryAt time.Time, dead bool) error {
+	status := JobPending
+	if dead {
+		status = JobFailed
+	}
+	query := `UPDATE summary_jobs SET status = $2, last_error = $3, run_after = $4, locked_until = NULL, updated_at = NOW() WHERE id = $1`
+	_, err := s.db.Exec(ctx, query, id, status, errMsg, retryAt)
+	return err
+}
+
+// ReleaseSummaryJob hands a claimed job back unfinished, e.g. when a run
+// stops at its time budget, so the next run picks it up right away. The
+// attempt isn't counted and the story goes back to pending.
+func (s *Store) ReleaseSummaryJob(ctx context.Context, id int64) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		var storyID int64
+		err := tx.QueryRow(ctx, `
+			UPDATE summary_jobs
+			SET status = 'pending', locked_until = NULL, attempts = GREATEST(attempts - 1, 0), updated_at = NOW()
+			WHERE id = $1 AND status = 'running'
+			RETURNING story_id
+		`, id).Scan(&storyID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE stories SET summary_status = $2 WHERE id = $1`, storyID, SummaryPending)
+		return err
+	})
+}
+
+// PruneSummaryJobs deletes finished and failed jobs older than daysToKeep.
+func (s *Store) PruneSummaryJobs(ctx context.Context, daysToKeep int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM summary_jobs WHERE status IN ('done', 'failed') AND updated_at < NOW() - make_interval(days => $1)`, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune summary jobs: %w", err)
+	}
+	return nil
+}
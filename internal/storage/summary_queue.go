@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Summary queue backpressure decisions.
+const (
+	QueueShed     = "shed"     // the job wasn't queued
+	QueueDeferred = "deferred" // the job was queued behind the backlog
+)
+
+// SummaryQueueEvent is a backpressure decision ingest took for a story.
+type SummaryQueueEvent struct {
+	ID         int64     `json:"id"`
+	StoryID    int64     `json:"story_id"`
+	Title      *string   `json:"title,omitempty"` // nil once the story is pruned
+	Decision   string    `json:"decision"`
+	QueueDepth int       `json:"queue_depth"`
+	HNRank     *int      `json:"hn_rank,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SummaryQueueStats describes the summary queue's backlog and the last day's
+// backpressure decisions.
+type SummaryQueueStats struct {
+	Depth       int `json:"depth"`        // pending and running jobs
+	NotDue      int `json:"not_due"`      // pending jobs deferred or waiting to retry
+	Shed24h     int `json:"shed_24h"`     // jobs shed in the last day
+	Deferred24h int `json:"deferred_24h"` // jobs deferred in the last day
+}
+
+// SummaryQueueDepth counts pending and running article summary jobs.
+func (s *Store) SummaryQueueDepth(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM summary_jobs WHERE kind = 'article' AND status IN ('pending', 'running')`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count summary jobs: %w", err)
+	}
+	return n, nil
+}
+
+// RecordSummaryQueueEvent records a backpressure decision for a story. A
+// story shed on every cycle while the backlog lasts is recorded once an hour.
+func (s *Store) RecordSummaryQueueEvent(ctx context.Context, storyID int, decision string, depth int, rank *int) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO summary_queue_events (story_id, decision, queue_depth, hn_rank)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM summary_queue_events
+			WHERE story_id = $1 AND decision = $2 AND created_at > NOW() - INTERVAL '1 hour'
+		)
+	`, storyID, decision, depth, rank)
+	return err
+}
+
+// GetSummaryQueueEvents returns the most recent backpressure decisions.
+func (s *Store) GetSummaryQueueEvents(ctx context.Context, limit int) ([]SummaryQueueEvent, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT e.id, e.story_id, s.title, e.decision, e.queue_depth, e.hn_rank, e.created_at
+		FROM summary_queue_events e
+		LEFT JOIN stories s ON s.id = e.story_id
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summary queue events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []SummaryQueueEvent
+	for rows.Next() {
+		var e SummaryQueueEvent
+		if err := rows.Scan(&e.ID, &e.StoryID, &e.Title, &e.Decision, &e.QueueDepth, &e.HNRank, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan summary queue event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// getSummaryQueueStats loads the queue's depth and the last day's decisions.
+func (s *Store) getSummaryQueueStats(ctx context.Context) (SummaryQueueStats, error) {
+	var st SummaryQueueStats
+	err := s.db.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM summary_jobs WHERE kind = 'article' AND status IN ('pending', 'running')),
+			(SELECT COUNT(*) FROM summary_jobs WHERE kind = 'article' AND status = 'pending' AND run_after > NOW()),
+			COUNT(*) FILTER (WHERE decision = 'shed'),
+			COUNT(*) FILTER (WHERE decision = 'deferred')
+		FROM summary_queue_events
+		WHERE created_at > NOW() - INTERVAL '24 hours'
+	`).Scan(&st.Depth, &st.NotDue, &st.Shed24h, &st.Deferred24h)
+	return st, err
+}
+
+// PruneSummaryQueueEvents deletes decisions older than daysToKeep.
+func (s *Store) PruneSummaryQueueEvents(ctx context.Context, daysToKeep int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM summary_queue_events WHERE created_at < NOW() - make_interval(days => $1)`, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune summary queue events: %w", err)
+	}
+	return nil
+}
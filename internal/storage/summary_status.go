@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/httpclient"
+)
+
+// Summary pipeline states, exposed as Story.SummaryStatus.
+const (
+	SummaryPending    = "pending"
+	SummaryFetching   = "fetching"
+	SummaryGenerating = "generating"
+	SummaryDone       = "done"
+)
+
+// Summarization failure codes, stored per story and counted in admin stats.
+const (
+	FailurePaywall         = "paywall"           // article blocked by a paywall, login wall or bot challenge
+	FailureFetchTimeout    = "fetch_timeout"     // article server didn't answer in time
+	FailureFetchError      = "fetch_error"       // any other fetch failure
+	FailureBlockedURL      = "blocked_url"       // the URL's scheme or address isn't allowed to be fetched
+	FailureUnsupported     = "unsupported"       // the link isn't an article, e.g. a video or an archive
+	FailureContentTooShort = "content_too_short" // too little text extracted to summarize
+	FailureLanguage        = "language"          // skipped for its language; see ai.LanguagePolicy
+	FailureLLMError        = "llm_error"         // every AI provider failed
+	FailureSafetyBlocked   = "safety_blocked"    // the provider refused the content on safety grounds
+	FailureJSONParse       = "json_parse"        // the model's response had no usable summary
+	FailureInternal        = "internal"          // e.g. the summary couldn't be saved
+)
+
+// SummaryFailed is the status of a story whose summary couldn't be generated.
+func SummaryFailed(code string) string {
+	return "failed:" + code
+}
+
+// ClassifyFetch returns the failure code for an article fetch, given the
+// fetch error or the response's status, whether a bot challenge was served
+// and the length of the extracted text. It returns "" if the article is
+// usable.
+func ClassifyFetch(err error, statusCode int, blocked bool, contentLen int) string {
+	var netErr net.Error
+	switch {
+	case err != nil && (errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()):
+		return FailureFetchTimeout
+	case errors.Is(err, httpclient.ErrBlockedScheme), errors.Is(err, httpclient.ErrBlockedAddress):
+		return FailureBlockedURL
+	case errors.Is(err, content.ErrUnsupportedContent):
+		return FailureUnsupported
+	case err != nil:
+		return FailureFetchError
+	case blocked, statusCode == http.StatusUnauthorized, statusCode == http.StatusPaymentRequired, statusCode == http.StatusForbidden:
+		return FailurePaywall
+	case statusCode >= 400:
+		return FailureFetchError
+	case contentLen < 100:
+		return FailureContentTooShort
+	}
+	return ""
+}
+
+// SetSummaryStatus records the story's progress through the summary pipeline.
+// UpdateStorySummaryAndTopics sets it to done.
+func (s *Store) SetSummaryStatus(ctx context.Context, id int, status string) error {
+	_, err := s.db.Exec(ctx, `UPDATE stories SET summary_status = $2 WHERE id = $1`, id, status)
+	return err
+}
+
+// RecordSummaryFailure marks the story's summary as failed with a failure
+// code and the underlying error text.
+func (s *Store) RecordSummaryFailure(ctx context.Context, id int, code, detail string) error {
+	query := `
+		UPDATE stories
+		SET summary_status = $2, summary_failure = $3, summary_failure_detail = $4, summary_failed_at = NOW()
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(ctx, query, id, SummaryFailed(code), code, detail)
+	return err
+}
@@ -0,0 +1,53 @@
+package storage
+
+import "context"
+
+// KnownItems reports which of the given HN item IDs are already stored, as a
+// map from item ID to the ID of the story it belongs to. Stories map to
+// themselves.
+func (s *Store) KnownItems(ctx context.Context, ids []int) (map[int]int64, error) {
+	known := make(map[int]int64)
+	if len(ids) == 0 {
+		return known, nil
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT id, id FROM stories WHERE id = ANY($1)
+		UNION ALL
+		SELECT id, story_id FROM comments WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, storyID int64
+		if err := rows.Scan(&id, &storyID); err != nil {
+			return nil, err
+		}
+		known[int(id)] = storyID
+	}
+	return known, rows.Err()
+}
+
+// KnownHNUsers returns which of the given HN usernames are already stored.
+func (s *Store) KnownHNUsers(ctx context.Context, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `SELECT id FROM users WHERE id = ANY($1)`, usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var known []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		known = append(known, id)
+	}
+	return known, rows.Err()
+}
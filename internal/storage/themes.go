@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Theme is a cluster of a week's stories with a generated label. Stories are
+// ordered closest to the cluster's center first.
+type Theme struct {
+	Label     string   `json:"label"`
+	StoryIDs  []int64  `json:"story_ids"`
+	TopTopics []string `json:"top_topics"`        // most common LLM tags among its stories
+	Stories   []Story  `json:"stories,omitempty"` // filled in by the API, in StoryIDs order
+}
+
+// WeekStart returns the Monday (UTC) of the week containing t.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// ReplaceWeeklyThemes stores the themes for a week, replacing any from an
+// earlier run.
+func (s *Store) ReplaceWeeklyThemes(ctx context.Context, weekStart time.Time, themes []Theme) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM weekly_themes WHERE week_start = $1`, weekStart); err != nil {
+			return err
+		}
+		for _, t := range themes {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO weekly_themes (week_start, label, story_ids, top_topics)
+				VALUES ($1, $2, $3, $4)
+			`, weekStart, t.Label, t.StoryIDs, t.TopTopics); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetWeeklyThemes returns the themes for the week starting at weekStart, or
+// for the latest clustered week if weekStart is zero, largest first. It
+// returns the week the themes belong to.
+func (s *Store) GetWeeklyThemes(ctx context.Context, weekStart time.Time) (time.Time, []Theme, error) {
+	if weekStart.IsZero() {
+		err := s.db.QueryRow(ctx, `SELECT week_start FROM weekly_themes ORDER BY week_start DESC LIMIT 1`).Scan(&weekStart)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil, nil
+		}
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT label, story_ids, top_topics
+		FROM weekly_themes
+		WHERE week_start = $1
+		ORDER BY cardinality(story_ids) DESC, id ASC
+	`, weekStart)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	defer rows.Close()
+
+	var themes []Theme
+	for rows.Next() {
+		var t Theme
+		if err := rows.Scan(&t.Label, &t.StoryIDs, &t.TopTopics); err != nil {
+			return time.Time{}, nil, err
+		}
+		themes = append(themes, t)
+	}
+	return weekStart, themes, rows.Err()
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TitleChange is a retitling of a story by HN moderators.
+type TitleChange struct {
+	OldTitle  string    `json:"old_title"`
+	NewTitle  string    `json:"new_title"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// recordRetitle stores a title change and keeps the story's first title as
+// original_title. The embedding was computed from the old title, so it is
+// cleared to be regenerated; UpsertStory refreshes search_vector.
+func recordRetitle(ctx context.Context, tx pgx.Tx, storyID int64, oldTitle, newTitle string) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO story_title_history (story_id, old_title, new_title)
+		VALUES ($1, $2, $3)
+	`, storyID, oldTitle, newTitle); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE stories
+		SET original_title = COALESCE(original_title, $2), embedding = NULL
+		WHERE id = $1
+	`, storyID, oldTitle)
+	return err
+}
+
+// GetTitleHistory returns a story's title changes, oldest first.
+func (s *Store) GetTitleHistory(ctx context.Context, storyID int) ([]TitleChange, error) {
+	query := `
+		SELECT old_title, new_title, changed_at
+		FROM story_title_history
+		WHERE story_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := s.db.Query(ctx, query, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []TitleChange
+	for rows.Next() {
+		var c TitleChange
+		if err := rows.Scan(&c.OldTitle, &c.NewTitle, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
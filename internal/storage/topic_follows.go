@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// FollowedTopic is a topic an app user follows.
+type FollowedTopic struct {
+	Topic      string    `json:"topic"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// TopicSuggestion is a topic common in recent stories, offered to new users.
+type TopicSuggestion struct {
+	Topic   string `json:"topic"`
+	Stories int    `json:"stories"` // recent stories tagged with it
+}
+
+// FollowTopics follows several topics at once and marks the user's
+// onboarding done, even if topics is empty (the user skipped it).
+func (s *Store) FollowTopics(ctx context.Context, userID string, topics []string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		for _, t := range topics {
+			if _, err := tx.Exec(ctx, `INSERT INTO followed_topics (user_id, topic) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, NormalizeTopic(t)); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `UPDATE auth_users SET onboarded_at = COALESCE(onboarded_at, NOW()) WHERE id = $1`, userID)
+		return err
+	})
+}
+
+func (s *Store) UnfollowTopic(ctx context.Context, userID, topic string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM followed_topics WHERE user_id = $1 AND topic = $2`, userID, NormalizeTopic(topic))
+	return err
+}
+
+// GetFollowedTopics returns the topics the user follows, alphabetically.
+func (s *Store) GetFollowedTopics(ctx context.Context, userID string) ([]FollowedTopic, error) {
+	rows, err := s.db.Query(ctx, `SELECT topic, created_at FROM followed_topics WHERE user_id = $1 ORDER BY topic`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []FollowedTopic
+	for rows.Next() {
+		var t FollowedTopic
+		if err := rows.Scan(&t.Topic, &t.FollowedAt); err != nil {
+			return nil, err
+		}
+		topics = append(topics, t)
+	}
+	return topics, rows.Err()
+}
+
+// NeedsOnboarding reports whether the user has yet to pick topics after
+// signing up.
+func (s *Store) NeedsOnboarding(ctx context.Context, userID string) (bool, error) {
+	var onboardedAt *time.Time
+	err := s.db.QueryRow(ctx, `SELECT onboarded_at FROM auth_users WHERE id = $1`, userID).Scan(&onboardedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	return onboardedAt == nil, nil
+}
+
+// SuggestTopics returns the topics tagged on the most stories posted in the
+// last days, in the casing most stories use, leaving out those the user
+// already follows. Topics on a single story aren't suggested.
+func (s *Store) SuggestTopics(ctx context.Context, userID string, days, limit int) ([]TopicSuggestion, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT mode() WITHIN GROUP (ORDER BY t), COUNT(DISTINCT s.id)
+		FROM stories s, unnest(s.topics) AS t
+		WHERE s.posted_at > NOW() - make_interval(days => $2)
+		  AND lower(t) NOT IN (SELECT topic FROM followed_topics WHERE user_id::text = $1)
+		GROUP BY lower(t)
+		HAVING COUNT(DISTINCT s.id) > 1
+		ORDER BY COUNT(DISTINCT s.id) DESC, lower(t)
+		LIMIT $3
+	`, userID, days, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []TopicSuggestion
+	for rows.Next() {
+		var t TopicSuggestion
+		if err := rows.Scan(&t.Topic, &t.Stories); err != nil {
+			return nil, err
+		}
+		topics = append(topics, t)
+	}
+	return topics, rows.Err()
+}
+
+// GetTopicsFeed returns stories tagged with any topic the user follows,
+// newest first, leaving out stories they've hidden.
+func (s *Store) GetTopicsFeed(ctx context.Context, userID string, limit, offset int) ([]Story, int, error) {
+	fromClause := `
+		FROM stories s
+		LEFT JOIN user_interactions ui ON ui.story_id = s.id AND ui.user_id = $1
+		WHERE EXISTS (
+			SELECT 1 FROM unnest(s.topics) t
+			INNER JOIN followed_topics f ON f.topic = lower(t) AND f.user_id = $1
+		)
+		  AND (ui.is_hidden IS NULL OR ui.is_hidden = FALSE)
+		  AND NOT (s.sensitive AND EXISTS (SELECT 1 FROM auth_users au WHERE au.id = $1 AND au.sensitive_content = 'hide'))
+	`
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) `+fromClause, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), s.score, COALESCE(s.by, ''), s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.topics, s.sensitive,
+		       ui.is_read, ui.is_saved, ui.is_hidden
+	` + fromClause + `
+		ORDER BY s.posted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Topics, &story.Sensitive, &story.IsRead, &story.IsSaved, &story.IsHidden); err != nil {
+			return nil, 0, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, total, rows.Err()
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Topic tag rules. Models sometimes return sentences, URLs or markup as
+// tags, which would fill the topic filters with junk.
+const (
+	maxTopics      = 5
+	maxTopicLength = 32 // characters
+	maxTopicWords  = 4
+)
+
+// Reasons a topic tag is rejected.
+const (
+	TopicTooLong      = "too_long"
+	TopicTooManyWords = "too_many_words"
+	TopicBadChars     = "bad_chars" // URLs, markup, emoji, or no letters at all
+	TopicOverLimit    = "over_limit"
+)
+
+// topicPunctuation is the punctuation allowed in a tag besides spaces, for
+// names like "C++", "C#", ".NET", "CI/CD" and "AT&T".
+const topicPunctuation = "+#.-/&'_"
+
+// TopicRejection is a topic tag the quality gate dropped.
+type TopicRejection struct {
+	Topic  string `json:"topic"`
+	Reason string `json:"reason"`
+}
+
+// RejectedTopic is a rejected tag with how often it was rejected.
+type RejectedTopic struct {
+	Topic    string    `json:"topic"`
+	Reason   string    `json:"reason"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// CleanTopics applies the quality gate to a summary's topic tags: it
+// collapses whitespace, merges case variants (keeping the first) and drops
+// tags that are too long, too wordy or contain anything but letters, digits,
+// spaces and topicPunctuation, keeping at most maxTopics.
+func CleanTopics(topics []string) ([]string, []TopicRejection) {
+	var kept []string
+	var rejected []TopicRejection
+	seen := make(map[string]bool)
+	for _, t := range topics {
+		t = strings.Join(strings.Fields(t), " ")
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		if reason := topicProblem(t); reason != "" {
+			rejected = append(rejected, TopicRejection{Topic: t, Reason: reason})
+			continue
+		}
+		if len(kept) == maxTopics {
+			rejected = append(rejected, TopicRejection{Topic: t, Reason: TopicOverLimit})
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, rejected
+}
+
+// topicProblem returns why a tag is rejected, or "" if it's fine.
+func topicProblem(t string) string {
+	if len([]rune(t)) > maxTopicLength {
+		return TopicTooLong
+	}
+	if len(strings.Fields(t)) > maxTopicWords {
+		return TopicTooManyWords
+	}
+	lower := strings.ToLower(t)
+	if strings.Contains(lower, "://") || strings.HasPrefix(lower, "www.") {
+		return TopicBadChars
+	}
+	letters := 0
+	for _, r := range t {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsDigit(r), r == ' ', strings.ContainsRune(topicPunctuation, r):
+		default:
+			return TopicBadChars
+		}
+	}
+	if letters == 0 {
+		return TopicBadChars
+	}
+	return ""
+}
+
+// canonicalTopics replaces each tag with the casing other stories use most
+// for it, so "rust" and "Rust" don't become two filters.
+func canonicalTopics(ctx context.Context, tx pgx.Tx, topics []string) ([]string, error) {
+	if len(topics) == 0 {
+		return topics, nil
+	}
+	lower := make([]string, len(topics))
+	for i, t := range topics {
+		lower[i] = strings.ToLower(t)
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (lower(t)) lower(t), t
+		FROM stories, unnest(topics) AS t
+		WHERE lower(t) = ANY($1)
+		GROUP BY t
+		ORDER BY lower(t), COUNT(*) DESC, t
+	`, lower)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	canonical := make(map[string]string)
+	for rows.Next() {
+		var l, t string
+		if err := rows.Scan(&l, &t); err != nil {
+			return nil, err
+		}
+		canonical[l] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(topics))
+	for i, t := range topics {
+		if c, ok := canonical[lower[i]]; ok {
+			t = c
+		}
+		out[i] = t
+	}
+	return out, nil
+}
+
+// recordTopicRejections stores the tags the gate dropped from a story's
+// summary.
+func recordTopicRejections(ctx context.Context, tx pgx.Tx, storyID int, rejected []TopicRejection) error {
+	for _, r := range rejected {
+		if _, err := tx.Exec(ctx, `INSERT INTO topic_rejections (story_id, topic, reason) VALUES ($1, $2, $3)`, storyID, r.Topic, r.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTopicRejectionCounts counts the last day's rejected tags by reason.
+func (s *Store) getTopicRejectionCounts(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT reason, COUNT(*) FROM topic_rejections
+		WHERE created_at > NOW() - INTERVAL '24 hours'
+		GROUP BY reason
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reason string
+		var n int
+		if err := rows.Scan(&reason, &n); err != nil {
+			return nil, err
+		}
+		counts[reason] = n
+	}
+	return counts, rows.Err()
+}
+
+// GetRejectedTopics returns the tags rejected most often in the last
+// days, most frequent first.
+func (s *Store) GetRejectedTopics(ctx context.Context, days, limit int) ([]RejectedTopic, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT topic, reason, COUNT(*), MAX(created_at)
+		FROM topic_rejections
+		WHERE created_at > NOW() - make_interval(days => $1)
+		GROUP BY topic, reason
+		ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+		LIMIT $2
+	`, days, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []RejectedTopic
+	for rows.Next() {
+		var t RejectedTopic
+		if err := rows.Scan(&t.Topic, &t.Reason, &t.Count, &t.LastSeen); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// PruneTopicRejections deletes rejections older than daysToKeep.
+func (s *Store) PruneTopicRejections(ctx context.Context, daysToKeep int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM topic_rejections WHERE created_at < NOW() - make_interval(days => $1)`, daysToKeep)
+	if err != nil {
+		return fmt.Errorf("failed to prune topic rejections: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import "context"
+
+// AIUsage is one cloud AI call. An empty UserID records a background job.
+type AIUsage struct {
+	UserID       string
+	Provider     string
+	Model        string
+	PromptTokens int
+	OutputTokens int
+	CostUSD      float64
+}
+
+// UsageTotals sums AI calls over a period.
+type UsageTotals struct {
+	Calls        int     `json:"calls"`
+	PromptTokens int64   `json:"prompt_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// ModelUsage is a user's all-time usage of one model.
+type ModelUsage struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	UsageTotals
+}
+
+// UserUsage is a user's AI usage, all-time and for the current month.
+type UserUsage struct {
+	Total     UsageTotals  `json:"total"`
+	ThisMonth UsageTotals  `json:"this_month"`
+	ByModel   []ModelUsage `json:"by_model"`
+}
+
+// RecordAIUsage stores one AI call.
+func (s *Store) RecordAIUsage(ctx context.Context, u AIUsage) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO ai_usage (user_id, provider, model, prompt_tokens, output_tokens, cost_usd)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6)
+	`, u.UserID, u.Provider, u.Model, u.PromptTokens, u.OutputTokens, u.CostUSD)
+	return err
+}
+
+const usageTotalsColumns = `COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost_usd), 0)`
+
+// GetUserAIUsage returns the user's cumulative AI usage and estimated cost.
+func (s *Store) GetUserAIUsage(ctx context.Context, userID string) (*UserUsage, error) {
+	usage := &UserUsage{ByModel: []ModelUsage{}}
+	rows, err := s.db.Query(ctx, `
+		SELECT provider, model, `+usageTotalsColumns+`
+		FROM ai_usage WHERE user_id = $1
+		GROUP BY provider, model
+		ORDER BY SUM(cost_usd) DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m ModelUsage
+		if err := rows.Scan(&m.Provider, &m.Model, &m.Calls, &m.PromptTokens, &m.OutputTokens, &m.CostUSD); err != nil {
+			return nil, err
+		}
+		usage.ByModel = append(usage.ByModel, m)
+		usage.Total.Calls += m.Calls
+		usage.Total.PromptTokens += m.PromptTokens
+		usage.Total.OutputTokens += m.OutputTokens
+		usage.Total.CostUSD += m.CostUSD
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	t := &usage.ThisMonth
+	err = s.db.QueryRow(ctx, `
+		SELECT `+usageTotalsColumns+`
+		FROM ai_usage WHERE user_id = $1 AND created_at >= date_trunc('month', NOW())
+	`, userID).Scan(&t.Calls, &t.PromptTokens, &t.OutputTokens, &t.CostUSD)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// getAIUsageTotals sums all AI calls, users' and background jobs' alike,
+// all-time and for the current month.
+func (s *Store) getAIUsageTotals(ctx context.Context) (total, month UsageTotals, err error) {
+	err = s.db.QueryRow(ctx, `SELECT `+usageTotalsColumns+` FROM ai_usage`).
+		Scan(&total.Calls, &total.PromptTokens, &total.OutputTokens, &total.CostUSD)
+	if err != nil {
+		return
+	}
+	err = s.db.QueryRow(ctx, `SELECT `+usageTotalsColumns+` FROM ai_usage WHERE created_at >= date_trunc('month', NOW())`).
+		Scan(&month.Calls, &month.PromptTokens, &month.OutputTokens, &month.CostUSD)
+	return
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Workspace roles, from most to least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var (
+	// ErrQuotaExceeded is returned when a workspace has used its monthly AI quota.
+	ErrQuotaExceeded = errors.New("workspace AI quota exceeded")
+	// ErrLastOwner is returned for a role change or removal that would leave
+	// a workspace without an owner.
+	ErrLastOwner = errors.New("a workspace must keep at least one owner")
+)
+
+// Workspace is a team sharing lists, notes and a Gemini key.
+type Workspace struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role,omitempty"` // the requesting user's role
+	HasGeminiKey   bool      `json:"has_gemini_key"`
+	GeminiAPIKey   string    `json:"-"`
+	MonthlyAIQuota int       `json:"monthly_ai_quota"`
+	AIRequestsUsed int       `json:"ai_requests_used"` // this calendar month
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	AvatarURL string    `json:"avatar_url"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type WorkspaceNote struct {
+	WorkspaceID int64     `json:"workspace_id"`
+	StoryID     int64     `json:"story_id"`
+	Note        string    `json:"note"`
+	UpdatedBy   *string   `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of min.
+func RoleAtLeast(role, min string) bool {
+	rank := map[string]int{RoleMember: 1, RoleAdmin: 2, RoleOwner: 3}
+	return rank[role] >= rank[min] && rank[role] > 0
+}
+
+const workspaceColumns = `w.id, w.name, w.gemini_api_key, w.monthly_ai_quota, w.created_at,
+	COALESCE((SELECT ai_requests FROM workspace_usage wu WHERE wu.workspace_id = w.id AND wu.month = date_trunc('month', NOW())::date), 0)`
+
+func scanWorkspace(row pgx.Row, extra ...interface{}) (*Workspace, error) {
+	var ws Workspace
+	dest := append([]interface{}{&ws.ID, &ws.Name, &ws.GeminiAPIKey, &ws.MonthlyAIQuota, &ws.CreatedAt, &ws.AIRequestsUsed}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	ws.HasGeminiKey = ws.GeminiAPIKey != ""
+	return &ws, nil
+}
+
+// CreateWorkspace creates a workspace with ownerID as its owner.
+func (s *Store) CreateWorkspace(ctx context.Context, ownerID, name string) (*Workspace, error) {
+	var ws Workspace
+	err := s.inTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `INSERT INTO workspaces (name) VALUES ($1) RETURNING id, name, created_at`, name).Scan(&ws.ID, &ws.Name, &ws.CreatedAt); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`, ws.ID, ownerID, RoleOwner)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	ws.Role = RoleOwner
+	return &ws, nil
+}
+
+func (s *Store) GetUserWorkspaces(ctx context.Context, userID string) ([]Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `, m.role
+		FROM workspaces w
+		INNER JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		ORDER BY w.name ASC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	for rows.Next() {
+		var role string
+		ws, err := scanWorkspace(rows, &role)
+		if err != nil {
+			return nil, err
+		}
+		ws.Role = role
+		workspaces = append(workspaces, *ws)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace returns a workspace with the user's role in it, or ErrNotFound
+// if the user isn't a member.
+func (s *Store) GetWorkspace(ctx context.Context, workspaceID int64, userID string) (*Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `, m.role
+		FROM workspaces w
+		INNER JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.id = $1
+	`
+	var role string
+	ws, err := scanWorkspace(s.db.QueryRow(ctx, query, workspaceID, userID), &role)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	ws.Role = role
+	return ws, nil
+}
+
+// UpdateWorkspace changes the non-nil fields. An empty geminiKey clears the
+// shared key.
+func (s *Store) UpdateWorkspace(ctx context.Context, workspaceID int64, name, geminiKey *string, monthlyQuota *int) error {
+	query := `
+		UPDATE workspaces
+		SET name = COALESCE($2, name),
+			gemini_api_key = COALESCE($3, gemini_api_key),
+			monthly_ai_quota = COALESCE($4, monthly_ai_quota)
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(ctx, query, workspaceID, name, geminiKey, monthlyQuota)
+	return err
+}
+
+func (s *Store) DeleteWorkspace(ctx context.Context, workspaceID int64) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM workspaces WHERE id = $1`, workspaceID)
+	return err
+}
+
+func (s *Store) GetWorkspaceMembers(ctx context.Context, workspaceID int64) ([]WorkspaceMember, error) {
+	query := `
+		SELECT u.id, u.email, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), m.role, m.joined_at
+		FROM workspace_members m
+		INNER JOIN auth_users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.joined_at ASC
+	`
+	rows, err := s.db.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []WorkspaceMember
+	for rows.Next() {
+		var m WorkspaceMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.AvatarURL, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// AddWorkspaceMember adds the registered user with the given email, or updates
+// their role if already a member. It returns ErrNotFound if no user has
+// signed in with that email yet.
+func (s *Store) AddWorkspaceMember(ctx context.Context, workspaceID int64, email, role string) (*WorkspaceMember, error) {
+	query := `
+		WITH upsert AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			SELECT $1, id, $3 FROM auth_users WHERE lower(email) = lower($2)
+			ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING user_id, role, joined_at
+		)
+		SELECT u.id, u.email, COALESCE(u.name, ''), COALESCE(u.avatar_url, ''), upsert.role, upsert.joined_at
+		FROM upsert INNER JOIN auth_users u ON u.id = upsert.user_id
+	`
+	var m WorkspaceMember
+	err := s.db.QueryRow(ctx, query, workspaceID, email, role).Scan(&m.UserID, &m.Email, &m.Name, &m.AvatarURL, &m.Role, &m.JoinedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// UpdateWorkspaceMemberRole changes a member's role. It returns ErrLastOwner
+// rather than demote the workspace's only owner.
+func (s *Store) UpdateWorkspaceMemberRole(ctx context.Context, workspaceID int64, userID, role string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID, role)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return checkWorkspaceOwner(ctx, tx, workspaceID)
+	})
+}
+
+// RemoveWorkspaceMember removes a member. It returns ErrLastOwner rather than
+// remove the workspace's only owner; the workspace is deleted instead.
+func (s *Store) RemoveWorkspaceMember(ctx context.Context, workspaceID int64, userID string) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return checkWorkspaceOwner(ctx, tx, workspaceID)
+	})
+}
+
+// checkWorkspaceOwner returns ErrLastOwner if the workspace has no owner left
+// after a change in tx. The workspace row is locked first, so concurrent
+// changes to different owners can't both pass.
+func checkWorkspaceOwner(ctx context.Context, tx pgx.Tx, workspaceID int64) error {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM workspaces WHERE id = $1 FOR UPDATE`, workspaceID); err != nil {
+		return err
+	}
+	var owners int
+	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`
+	if err := tx.QueryRow(ctx, query, workspaceID, RoleOwner).Scan(&owners); err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// GetWorkspaceGeminiKey returns the shared Gemini key of the user's oldest
+// workspace membership that has one. It returns ErrNotFound if none do.
+func (s *Store) GetWorkspaceGeminiKey(ctx context.Context, userID string) (int64, string, error) {
+	query := `
+		SELECT w.id, w.gemini_api_key
+		FROM workspaces w
+		INNER JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		WHERE w.gemini_api_key != ''
+		ORDER BY m.joined_at ASC
+		LIMIT 1
+	`
+	var id int64
+	var key string
+	err := s.db.QueryRow(ctx, query, userID).Scan(&id, &key)
+	if err == pgx.ErrNoRows {
+		return 0, "", ErrNotFound
+	}
+	return id, key, err
+}
+
+// CheckWorkspaceQuota returns ErrQuotaExceeded if the workspace has used its
+// monthly AI quota, so a shared-key request shouldn't be made.
+func (s *Store) CheckWorkspaceQuota(ctx context.Context, workspaceID int64) error {
+	query := `
+		SELECT w.monthly_ai_quota = 0 OR COALESCE(u.ai_requests, 0) < w.monthly_ai_quota
+		FROM workspaces w
+		LEFT JOIN workspace_usage u ON u.workspace_id = w.id AND u.month = date_trunc('month', NOW())::date
+		WHERE w.id = $1
+	`
+	var ok bool
+	err := s.db.QueryRow(ctx, query, workspaceID).Scan(&ok)
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// ConsumeWorkspaceQuota counts one shared-key AI request, once made, against
+// the workspace's monthly quota.
+func (s *Store) ConsumeWorkspaceQuota(ctx context.Context, workspaceID int64) error {
+	query := `
+		INSERT INTO workspace_usage (workspace_id, month, ai_requests)
+		VALUES ($1, date_trunc('month', NOW())::date, 1)
+		ON CONFLICT (workspace_id, month) DO UPDATE
+		SET ai_requests = workspace_usage.ai_requests + 1
+	`
+	_, err := s.db.Exec(ctx, query, workspaceID)
+	return err
+}
+
+func (s *Store) GetWorkspaceNote(ctx context.Context, workspaceID int64, storyID int) (*WorkspaceNote, error) {
+	query := `SELECT workspace_id, story_id, note, updated_by, updated_at FROM workspace_notes WHERE workspace_id = $1 AND story_id = $2`
+	var n WorkspaceNote
+	err := s.db.QueryRow(ctx, query, workspaceID, storyID).Scan(&n.WorkspaceID, &n.StoryID, &n.Note, &n.UpdatedBy, &n.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// SetWorkspaceNote replaces the workspace's shared note on a story; an empty
+// note deletes it.
+func (s *Store) SetWorkspaceNote(ctx context.Context, workspaceID int64, storyID int, userID, note string) error {
+	if note == "" {
+		_, err := s.db.Exec(ctx, `DELETE FROM workspace_notes WHERE workspace_id = $1 AND story_id = $2`, workspaceID, storyID)
+		return err
+	}
+	query := `
+		INSERT INTO workspace_notes (workspace_id, story_id, note, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspace_id, story_id) DO UPDATE
+		SET note = EXCLUDED.note, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+	_, err := s.db.Exec(ctx, query, workspaceID, storyID, note, userID)
+	return err
+}
@@ -0,0 +1,134 @@
+// Package themes finds emergent themes among stories by clustering their
+// embeddings.
+package themes
+
+import (
+	"math"
+	"math/rand"
+)
+
+// KMeans clusters vectors by cosine similarity (spherical k-means with
+// k-means++ seeding) and returns each vector's cluster index along with the
+// unit-length centroids. It runs until assignments settle or maxIter rounds
+// have passed. rng makes the seeding reproducible.
+func KMeans(vectors [][]float32, k, maxIter int, rng *rand.Rand) ([]int, [][]float64) {
+	points := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		points[i] = normalize(v)
+	}
+	if k > len(points) {
+		k = len(points)
+	}
+	if k == 0 {
+		return nil, nil
+	}
+
+	centroids := seed(points, k, rng)
+	assign := make([]int, len(points))
+	for i := range assign {
+		assign[i] = -1
+	}
+
+	for iter := 0; iter < maxIter; iter++ {
+		changed := false
+		for i, p := range points {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if sim := dot(p, centroid); sim > bestSim {
+					best, bestSim = c, sim
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][]float64, k)
+		for i, p := range points {
+			c := assign[i]
+			if sums[c] == nil {
+				sums[c] = make([]float64, len(p))
+			}
+			for d, x := range p {
+				sums[c][d] += x
+			}
+		}
+		for c, sum := range sums {
+			// An empty cluster keeps its previous centroid.
+			if sum != nil {
+				centroids[c] = unit(sum)
+			}
+		}
+	}
+	return assign, centroids
+}
+
+// Similarity returns the cosine similarity of v to a unit-length centroid.
+func Similarity(v []float32, centroid []float64) float64 {
+	return dot(normalize(v), centroid)
+}
+
+// seed picks k initial centroids with k-means++: each next centroid is drawn
+// with probability proportional to its cosine distance from the nearest one
+// already chosen.
+func seed(points [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centroids := [][]float64{points[rng.Intn(len(points))]}
+	dist := make([]float64, len(points))
+	for len(centroids) < k {
+		total := 0.0
+		for i, p := range points {
+			d := math.Inf(1)
+			for _, c := range centroids {
+				d = math.Min(d, 1-dot(p, c))
+			}
+			dist[i] = math.Max(d, 0)
+			total += dist[i]
+		}
+		if total == 0 {
+			// Every point coincides with a centroid; more clusters add nothing.
+			break
+		}
+		target := rng.Float64() * total
+		next := len(points) - 1
+		for i, d := range dist {
+			target -= d
+			if target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, points[next])
+	}
+	return centroids
+}
+
+func normalize(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return unit(out)
+}
+
+func unit(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
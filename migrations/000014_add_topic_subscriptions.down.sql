@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stories_topics;
+DROP TABLE IF EXISTS topic_subscriptions;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS topic_subscriptions (
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    topic TEXT NOT NULL,
+    last_digest_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, topic)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_topic ON topic_subscriptions(topic);
+CREATE INDEX IF NOT EXISTS idx_stories_topics ON stories USING GIN(topics);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS lists (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    slug TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lists_user ON lists(user_id);
+
+CREATE TABLE IF NOT EXISTS list_items (
+    list_id BIGINT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    note TEXT NOT NULL DEFAULT '',
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (list_id, story_id)
+);
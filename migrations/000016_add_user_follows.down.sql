@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_follows;
+ALTER TABLE auth_users DROP COLUMN IF EXISTS saves_public;
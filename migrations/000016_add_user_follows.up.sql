@@ -0,0 +1,12 @@
+-- Saves are private unless the user opts in.
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS saves_public BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS local_comments (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    parent_id BIGINT REFERENCES local_comments(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_local_comments_story ON local_comments(story_id, created_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_lists_workspace;
+ALTER TABLE lists DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_notes;
+DROP TABLE IF EXISTS workspace_usage;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
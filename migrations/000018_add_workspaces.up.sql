@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    gemini_api_key TEXT NOT NULL DEFAULT '',
+    -- Shared-key AI requests allowed per calendar month; 0 means unlimited.
+    monthly_ai_quota INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_usage (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    ai_requests INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (workspace_id, month)
+);
+
+CREATE TABLE IF NOT EXISTS workspace_notes (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    updated_by UUID REFERENCES auth_users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, story_id)
+);
+
+-- Lists owned by a workspace are editable by all of its members.
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_lists_workspace ON lists(workspace_id) WHERE workspace_id IS NOT NULL;
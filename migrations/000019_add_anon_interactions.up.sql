@@ -0,0 +1,13 @@
+-- Interactions of visitors who haven't signed in yet, keyed by the signed
+-- hn_anon cookie. Merged into user_interactions on login.
+CREATE TABLE IF NOT EXISTS anon_interactions (
+    anon_id TEXT NOT NULL,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    is_saved BOOLEAN NOT NULL DEFAULT FALSE,
+    is_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (anon_id, story_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anon_interactions_updated ON anon_interactions(updated_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_identities;
+-- google_id stays nullable: non-Google accounts may exist by now.
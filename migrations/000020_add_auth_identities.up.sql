@@ -0,0 +1,19 @@
+-- Login identities per provider, so one account can sign in with several
+-- providers (Google, GitHub).
+CREATE TABLE IF NOT EXISTS auth_identities (
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_identities_user ON auth_identities(user_id);
+
+INSERT INTO auth_identities (provider, subject, user_id, email, created_at)
+SELECT 'google', google_id, id, email, created_at FROM auth_users WHERE google_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Accounts created through another provider have no Google ID.
+ALTER TABLE auth_users ALTER COLUMN google_id DROP NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS user_id;
+ALTER TABLE topic_subscriptions DROP COLUMN IF EXISTS enabled;
+DROP TABLE IF EXISTS notification_preferences;
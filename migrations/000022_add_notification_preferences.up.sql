@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES auth_users(id) ON DELETE CASCADE,
+    digest_frequency TEXT NOT NULL DEFAULT 'weekly' CHECK (digest_frequency IN ('off', 'daily', 'weekly')),
+    channels TEXT[] NOT NULL DEFAULT '{email}',
+    webhook_url TEXT NOT NULL DEFAULT '',
+    push_token TEXT NOT NULL DEFAULT '',
+    -- Local hours [quiet_start, quiet_end) in which nothing is delivered.
+    quiet_start SMALLINT CHECK (quiet_start BETWEEN 0 AND 23),
+    quiet_end SMALLINT CHECK (quiet_end BETWEEN 0 AND 23),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE topic_subscriptions ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- The user a delivery is for, so the dispatcher can honour quiet hours.
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES auth_users(id) ON DELETE CASCADE;
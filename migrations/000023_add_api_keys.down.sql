@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stories_url;
+DROP TABLE IF EXISTS api_keys;
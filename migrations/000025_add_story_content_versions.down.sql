@@ -0,0 +1,2 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_stale;
+DROP TABLE IF EXISTS story_content_versions;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS story_content_versions (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    content_hash TEXT NOT NULL,
+    content_length INTEGER NOT NULL,
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_content_versions_story ON story_content_versions(story_id, id DESC);
+
+-- Set when the article changed after it was summarized; cleared by the next summary.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_stale BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_regenerations;
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_descendants;
@@ -0,0 +1,5 @@
+-- Comment count when the summary was generated, and how many times it has been regenerated.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_descendants INTEGER;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_regenerations INTEGER NOT NULL DEFAULT 0;
+
+UPDATE stories SET summary_descendants = descendants WHERE summary IS NOT NULL AND summary != '';
@@ -0,0 +1,4 @@
+-- AI pipeline progress: pending, fetching, generating, failed:<reason> or done ('' = never queued).
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_status TEXT NOT NULL DEFAULT '';
+
+UPDATE stories SET summary_status = 'done' WHERE summary IS NOT NULL AND summary != '';
@@ -0,0 +1,3 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_failed_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_failure_detail;
+ALTER TABLE stories DROP COLUMN IF EXISTS summary_failure;
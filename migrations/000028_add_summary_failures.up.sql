@@ -0,0 +1,4 @@
+-- Why the last summary attempt failed (see storage.Failure* codes); cleared by a successful summary.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_failure TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_failure_detail TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS summary_failed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Body of text posts (Ask HN, Tell HN), as the HTML HN serves.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS text TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS original_title;
+DROP TABLE IF EXISTS story_title_history;
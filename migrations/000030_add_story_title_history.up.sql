@@ -0,0 +1,13 @@
+-- Titles a story had before HN moderators retitled it.
+CREATE TABLE IF NOT EXISTS story_title_history (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    old_title TEXT NOT NULL,
+    new_title TEXT NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_title_history_story ON story_title_history(story_id, id);
+
+-- The title the story was submitted with; NULL until it is first retitled.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS original_title TEXT;
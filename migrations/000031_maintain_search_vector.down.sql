@@ -0,0 +1,4 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS article_text;
+ALTER TABLE stories DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE stories ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', title)) STORED;
+CREATE INDEX IF NOT EXISTS idx_stories_search ON stories USING GIN(search_vector);
@@ -0,0 +1,16 @@
+-- search_vector was generated from the title alone. It is now written by the
+-- storage layer (see storage.searchVectorSQL) and weights title, topics,
+-- summary and article text; run cmd/reindex to rebuild it.
+ALTER TABLE stories DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE stories ADD COLUMN search_vector tsvector;
+
+-- Extracted article text, truncated, kept for search.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS article_text TEXT NOT NULL DEFAULT '';
+
+UPDATE stories SET search_vector =
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', array_to_string(coalesce(topics, '{}'), ' ')), 'B') ||
+    setweight(to_tsvector('english', coalesce(summary, '')), 'C') ||
+    setweight(to_tsvector('english', text || ' ' || article_text), 'D');
+
+CREATE INDEX IF NOT EXISTS idx_stories_search ON stories USING GIN(search_vector);
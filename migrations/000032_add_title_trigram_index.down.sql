@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_stories_title_trgm;
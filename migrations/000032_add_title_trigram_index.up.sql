@@ -0,0 +1,4 @@
+-- Fuzzy topic search falls back to trigram matching on titles.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_stories_title_trgm ON stories USING GIN (title gin_trgm_ops);
@@ -0,0 +1,11 @@
+-- Emergent themes found by clustering a week's story embeddings (cmd/themes).
+CREATE TABLE IF NOT EXISTS weekly_themes (
+    id BIGSERIAL PRIMARY KEY,
+    week_start DATE NOT NULL,
+    label TEXT NOT NULL,
+    story_ids BIGINT[] NOT NULL DEFAULT '{}',
+    top_topics TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_weekly_themes_week ON weekly_themes(week_start);
@@ -0,0 +1,3 @@
+ALTER TABLE auth_users DROP COLUMN IF EXISTS gen_num_ctx;
+ALTER TABLE auth_users DROP COLUMN IF EXISTS gen_max_tokens;
+ALTER TABLE auth_users DROP COLUMN IF EXISTS gen_temperature;
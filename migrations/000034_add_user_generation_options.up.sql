@@ -0,0 +1,4 @@
+-- Per-user model generation knobs; NULL keeps the provider default.
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS gen_temperature DOUBLE PRECISION;
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS gen_max_tokens INTEGER;
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS gen_num_ctx INTEGER;
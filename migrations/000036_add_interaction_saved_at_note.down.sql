@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_interactions_saved_at;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS note;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS saved_at;
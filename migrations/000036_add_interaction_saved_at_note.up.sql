@@ -0,0 +1,7 @@
+-- When the story was last saved, and the user's private note on it.
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS saved_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+UPDATE user_interactions SET saved_at = updated_at WHERE is_saved AND saved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_interactions_saved_at ON user_interactions(user_id, saved_at DESC) WHERE is_saved = TRUE;
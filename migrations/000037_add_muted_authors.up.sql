@@ -0,0 +1,7 @@
+-- HN usernames whose comments a user doesn't want to see.
+CREATE TABLE IF NOT EXISTS user_muted_authors (
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    username TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, username)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS seen_descendants;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS last_seen_at;
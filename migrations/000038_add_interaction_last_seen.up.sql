@@ -0,0 +1,4 @@
+-- When the user last opened the story, and its comment count at the time,
+-- for "new since last visit" counts.
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS seen_descendants INTEGER;
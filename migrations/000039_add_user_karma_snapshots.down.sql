@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_updated_at;
+DROP TABLE IF EXISTS user_karma_snapshots;
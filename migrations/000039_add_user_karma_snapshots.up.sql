@@ -0,0 +1,9 @@
+-- Periodic karma readings of ingested HN users, for karma history charts.
+CREATE TABLE IF NOT EXISTS user_karma_snapshots (
+    username TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    karma INT NOT NULL,
+    captured_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (username, captured_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users(updated_at);
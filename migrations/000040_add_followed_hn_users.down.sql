@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_by;
+DROP INDEX IF EXISTS idx_stories_by;
+DROP TABLE IF EXISTS followed_hn_users;
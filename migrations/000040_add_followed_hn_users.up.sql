@@ -0,0 +1,12 @@
+-- HN usernames an app user follows; ingestion notifies them when a followed
+-- user submits or comments on an ingested story.
+CREATE TABLE IF NOT EXISTS followed_hn_users (
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    username TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_followed_hn_users_username ON followed_hn_users(username);
+CREATE INDEX IF NOT EXISTS idx_stories_by ON stories(by);
+CREATE INDEX IF NOT EXISTS idx_comments_by ON comments(by);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_stories_pinned_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS is_frozen;
+ALTER TABLE stories DROP COLUMN IF EXISTS pinned_at;
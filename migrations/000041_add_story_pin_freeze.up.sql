@@ -0,0 +1,6 @@
+-- Admin flags: pinned stories lead the default front page regardless of HN
+-- rank; pinned and frozen stories are never pruned.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS is_frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_stories_pinned_at ON stories(pinned_at) WHERE pinned_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_stories_read_count;
+DROP INDEX IF EXISTS idx_stories_save_count;
+ALTER TABLE stories DROP COLUMN IF EXISTS hide_count;
+ALTER TABLE stories DROP COLUMN IF EXISTS read_count;
+ALTER TABLE stories DROP COLUMN IF EXISTS save_count;
@@ -0,0 +1,22 @@
+-- Per-story totals of user saves, reads and hides, kept in step with
+-- user_interactions by the storage layer.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS save_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS read_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS hide_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE stories s SET
+    save_count = c.saves,
+    read_count = c.reads,
+    hide_count = c.hides
+FROM (
+    SELECT story_id,
+           COUNT(*) FILTER (WHERE is_saved) AS saves,
+           COUNT(*) FILTER (WHERE is_read) AS reads,
+           COUNT(*) FILTER (WHERE is_hidden) AS hides
+    FROM user_interactions
+    GROUP BY story_id
+) c
+WHERE s.id = c.story_id;
+
+CREATE INDEX IF NOT EXISTS idx_stories_save_count ON stories(save_count DESC) WHERE save_count > 0;
+CREATE INDEX IF NOT EXISTS idx_stories_read_count ON stories(read_count DESC) WHERE read_count > 0;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS story_feed_ranks;
@@ -0,0 +1,10 @@
+-- Positions of stories in HN's other lists (new, best, ask, show, jobs).
+-- The top list keeps using stories.hn_rank.
+CREATE TABLE IF NOT EXISTS story_feed_ranks (
+    feed TEXT NOT NULL,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    PRIMARY KEY (feed, story_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_feed_ranks_rank ON story_feed_ranks(feed, rank);
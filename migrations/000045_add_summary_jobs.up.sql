@@ -0,0 +1,20 @@
+-- Durable queue of background summarization work. Workers lease jobs with
+-- FOR UPDATE SKIP LOCKED; a running job whose lease expires (worker crashed)
+-- becomes claimable again.
+CREATE TABLE IF NOT EXISTS summary_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    model TEXT NOT NULL DEFAULT '',
+    provider TEXT NOT NULL DEFAULT 'local',
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- At most one queued or running job per story.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_summary_jobs_active ON summary_jobs(story_id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_due ON summary_jobs(run_after, id) WHERE status IN ('pending', 'running');
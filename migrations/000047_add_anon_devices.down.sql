@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS anon_pairing_codes;
+DROP TABLE IF EXISTS anon_devices;
@@ -0,0 +1,18 @@
+-- Devices of visitors who haven't signed in, grouped by the anon ID whose
+-- interactions they share. A second device joins a group by redeeming a
+-- short-lived pairing code issued to the first.
+CREATE TABLE IF NOT EXISTS anon_devices (
+    device_id TEXT PRIMARY KEY,
+    anon_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_anon_devices_anon ON anon_devices(anon_id);
+
+CREATE TABLE IF NOT EXISTS anon_pairing_codes (
+    code_hash TEXT PRIMARY KEY,
+    anon_id TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
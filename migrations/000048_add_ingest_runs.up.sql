@@ -0,0 +1,12 @@
+-- One row per ingestion run. One-shot runs under a time budget (scheduled
+-- serverless jobs) read the last completed full sync from here, so the
+-- full-sync cadence carries over between invocations.
+CREATE TABLE IF NOT EXISTS ingest_runs (
+    id BIGSERIAL PRIMARY KEY,
+    full_sync BOOLEAN NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_runs_full ON ingest_runs(started_at DESC) WHERE full_sync AND status = 'done';
@@ -0,0 +1,14 @@
+-- Backpressure decisions taken by ingest when the summary queue is backed up:
+-- low-ranked stories' jobs are shed (not queued) or deferred (queued to run
+-- after the backlog). Kept for the admin dashboard.
+CREATE TABLE IF NOT EXISTS summary_queue_events (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL,
+    decision TEXT NOT NULL,
+    queue_depth INTEGER NOT NULL,
+    hn_rank INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_queue_events_created ON summary_queue_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_summary_queue_events_story ON summary_queue_events(story_id, created_at DESC);
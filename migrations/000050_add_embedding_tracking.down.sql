@@ -0,0 +1,3 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS embedded_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS embedding_hash;
+ALTER TABLE stories DROP COLUMN IF EXISTS embedding_model;
@@ -0,0 +1,6 @@
+-- What each story's embedding was computed from: the model that produced it
+-- and a hash of its input, so ingest can re-embed stories whose summary or
+-- article changed, or all of them when the embedding model does.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS embedding_model TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS embedding_hash TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS embedded_at TIMESTAMP WITH TIME ZONE;
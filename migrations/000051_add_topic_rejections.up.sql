@@ -0,0 +1,12 @@
+-- Topic tags dropped by the quality gate before a summary was saved: too
+-- long, too wordy, odd characters, or past the per-story limit. Kept for the
+-- admin dashboard, to show what models are producing.
+CREATE TABLE IF NOT EXISTS topic_rejections (
+    id BIGSERIAL PRIMARY KEY,
+    story_id BIGINT NOT NULL,
+    topic TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_rejections_created ON topic_rejections(created_at DESC);
@@ -0,0 +1,5 @@
+-- The language detected from each story's article (or post body), as an
+-- ISO 639-1 code. NULL until the article has been fetched, or when there
+-- was too little text to tell.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS language TEXT;
+CREATE INDEX IF NOT EXISTS idx_stories_language ON stories (language);
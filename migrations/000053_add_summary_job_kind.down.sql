@@ -0,0 +1,7 @@
+DELETE FROM summary_jobs WHERE kind <> 'article';
+
+DROP INDEX IF EXISTS idx_summary_jobs_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_summary_jobs_active ON summary_jobs(story_id) WHERE status IN ('pending', 'running');
+
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS user_id;
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS kind;
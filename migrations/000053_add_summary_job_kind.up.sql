@@ -0,0 +1,8 @@
+-- The summary queue also holds discussion summaries users ask for through
+-- the API, run by the API server for that user. Ingest keeps to article
+-- jobs; a story can have one active job of each kind.
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'article';
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES auth_users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_summary_jobs_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_summary_jobs_active ON summary_jobs(story_id, kind) WHERE status IN ('pending', 'running');
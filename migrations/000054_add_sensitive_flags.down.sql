@@ -0,0 +1,8 @@
+ALTER TABLE auth_users DROP COLUMN IF EXISTS sensitive_content;
+
+DROP INDEX IF EXISTS idx_stories_sensitive_source;
+ALTER TABLE stories DROP COLUMN IF EXISTS sensitive_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS sensitive_reviewed_by;
+ALTER TABLE stories DROP COLUMN IF EXISTS sensitive_reason;
+ALTER TABLE stories DROP COLUMN IF EXISTS sensitive_source;
+ALTER TABLE stories DROP COLUMN IF EXISTS sensitive;
@@ -0,0 +1,12 @@
+-- Stories flagged as potentially sensitive (adult content, graphic violence,
+-- self-harm). source is what set the flag: 'keyword', 'ai' or 'admin'; an
+-- AI or admin verdict isn't overridden by later keyword matches.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS sensitive_source TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS sensitive_reason TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS sensitive_reviewed_by UUID REFERENCES auth_users(id) ON DELETE SET NULL;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS sensitive_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_stories_sensitive_source ON stories (sensitive_source, sensitive_at DESC) WHERE sensitive_source IS NOT NULL;
+
+-- How flagged stories are shown to each user: 'show', 'blur' or 'hide'.
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS sensitive_content TEXT NOT NULL DEFAULT 'blur';
@@ -0,0 +1,2 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS discussion_summary_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS discussion_summary;
@@ -0,0 +1,4 @@
+-- The summary of a story's comment thread, kept apart from the article
+-- summary in `summary` so that neither overwrites the other.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS discussion_summary TEXT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS discussion_summary_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE auth_users DROP COLUMN IF EXISTS onboarded_at;
+DROP TABLE IF EXISTS followed_topics;
@@ -0,0 +1,14 @@
+-- Topics an app user follows; they make up the user's "topics" feed.
+-- Unlike topic_subscriptions, following a topic sends no digests.
+CREATE TABLE IF NOT EXISTS followed_topics (
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    topic TEXT NOT NULL, -- lower-cased, see storage.NormalizeTopic
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, topic)
+);
+
+-- Set once the user has picked (or skipped) topics after their first login.
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS onboarded_at TIMESTAMP WITH TIME ZONE;
+
+-- Users from before onboarding existed don't need it.
+UPDATE auth_users SET onboarded_at = COALESCE(created_at, NOW()) WHERE onboarded_at IS NULL;
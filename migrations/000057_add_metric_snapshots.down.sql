@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS metric_snapshots;
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS duration_ms;
@@ -0,0 +1,18 @@
+-- How long each summary job took from being claimed to finishing.
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS duration_ms INTEGER;
+
+-- One row of instance metrics per UTC day, for trend charts. Ingest
+-- rewrites today's and yesterday's rows every cycle, so yesterday's is
+-- final once the day is over.
+CREATE TABLE IF NOT EXISTS metric_snapshots (
+    day DATE PRIMARY KEY,
+    stories_ingested INTEGER NOT NULL DEFAULT 0,
+    summaries_generated INTEGER NOT NULL DEFAULT 0,
+    summaries_failed INTEGER NOT NULL DEFAULT 0,
+    active_users INTEGER NOT NULL DEFAULT 0,
+    ai_calls INTEGER NOT NULL DEFAULT 0,
+    ai_cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    summary_latency_avg_ms INTEGER,
+    summary_latency_p95_ms INTEGER,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stories_jobs;
+ALTER TABLE stories DROP COLUMN IF EXISTS item_type;
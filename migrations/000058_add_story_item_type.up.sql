@@ -0,0 +1,9 @@
+-- HN item type: "story", or "job" for YC job posts, which have no comments
+-- and are listed on their own.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS item_type TEXT NOT NULL DEFAULT 'story';
+
+-- Job posts ingested so far are the ones ranked on HN's jobs list.
+UPDATE stories SET item_type = 'job'
+WHERE id IN (SELECT story_id FROM story_feed_ranks WHERE feed = 'jobs');
+
+CREATE INDEX IF NOT EXISTS idx_stories_jobs ON stories(posted_at DESC) WHERE item_type = 'job';
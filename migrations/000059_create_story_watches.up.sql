@@ -0,0 +1,17 @@
+-- Stories a user watches: they're notified once when the story's score or
+-- comment count reaches a threshold and once when its summary arrives.
+-- Ingest checks the watches every run; see storage.GetDueWatchAlerts.
+CREATE TABLE IF NOT EXISTS story_watches (
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    score_threshold INTEGER,   -- NULL: the score isn't watched
+    comment_threshold INTEGER, -- NULL: the comment count isn't watched
+    notify_summary BOOLEAN NOT NULL DEFAULT TRUE,
+    score_notified_at TIMESTAMP WITH TIME ZONE,
+    comments_notified_at TIMESTAMP WITH TIME ZONE,
+    summary_notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, story_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_watches_story ON story_watches(story_id);
@@ -0,0 +1,11 @@
+-- Stories users emailed to someone; also the ledger the share rate limit
+-- counts. The email itself goes through notification_outbox.
+CREATE TABLE IF NOT EXISTS story_shares (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    recipient TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_shares_user ON story_shares(user_id, created_at);
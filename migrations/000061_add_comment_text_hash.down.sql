@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_text_hash;
+ALTER TABLE comments DROP COLUMN IF EXISTS spam_suspect;
+ALTER TABLE comments DROP COLUMN IF EXISTS text_hash;
@@ -0,0 +1,9 @@
+-- Fingerprint of a comment's normalized text (content.Fingerprint), set for
+-- comments long enough that a repeat isn't a coincidence. The same text on
+-- several stories marks every copy as a spam_suspect: bots and spammers
+-- paste one comment everywhere. Existing comments are hashed as full syncs
+-- refetch them.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS text_hash TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS spam_suspect BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_text_hash ON comments(text_hash) WHERE text_hash IS NOT NULL;
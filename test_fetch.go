@@ -1,13 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rajeshkumarblr/hn_station/internal/content"
 )
 
 func main() {
-	res, err := content.FetchArticle("https://developer.chrome.com/docs/extensions/mv3/")
+	res, err := content.FetchArticle(context.Background(), "https://developer.chrome.com/docs/extensions/mv3/")
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
@@ -17,7 +17,7 @@ export function SettingsModal({ isOpen, onClose, user }: SettingsModalProps) {
     const [apiKey, setApiKey] = useState('');
     const [aiEnabled, setAiEnabled] = useState(false);
     const [ollamaModel, setOllamaModel] = useState('');
-    const [aiProvider, setAiProvider] = useState<'local' | 'gemini' | 'both'>('local');
+    const [aiProvider, setAiProvider] = useState<'local' | 'gemini' | 'both' | 'openai'>('local');
     const [saving, setSaving] = useState(false);
     const [success, setSuccess] = useState(false);
     const [error, setError] = useState<string | null>(null);
@@ -139,11 +139,12 @@ export function SettingsModal({ isOpen, onClose, user }: SettingsModalProps) {
                                     {/* Provider Selection */}
                                     <div className="space-y-3">
                                         <label className="text-[11px] font-black uppercase tracking-wider text-slate-400">AI Provider</label>
-                                        <div className="grid grid-cols-3 gap-3">
+                                        <div className="grid grid-cols-4 gap-3">
                                             {[
                                                 { id: 'local', label: 'Local Only', desc: 'Ollama only' },
                                                 { id: 'gemini', label: 'Cloud Only', desc: 'Gemini API' },
-                                                { id: 'both', label: 'Hybrid', desc: 'Local w/ Fallback' }
+                                                { id: 'both', label: 'Hybrid', desc: 'Local w/ Fallback' },
+                                                { id: 'openai', label: 'OpenAI API', desc: 'Any compatible server' }
                                             ].map(opt => (
                                                 <button
                                                     key={opt.id}